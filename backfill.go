@@ -0,0 +1,102 @@
+package main
+
+import (
+	"eigenlayer_hack/config"
+	"eigenlayer_hack/handlers"
+	"eigenlayer_hack/ingest"
+	"eigenlayer_hack/stream"
+	"eigenlayer_hack/utils"
+	"flag"
+	"fmt"
+	"solity/utils/logger"
+	"strings"
+	"time"
+)
+
+/*
+runBackfill scans the given block range of the node for the tracked events and pushes the found transactions through
+the normal processing path. Progress is checkpointed to the cursor file, so an interrupted backfill continues where it
+stopped. It runs a reduced pipeline without the metrics endpoint, the reorg monitor, the scoring and the
+reconciliation, these are left to the live tracker. It can run next to the tracker: the events that were already
+processed by either of them are skipped through the shared dedup store (DEDUP_STORE=redis, or file when they do not
+run at the same time), and the registry nonces taken by the tracker are reloaded by the backfill's writer
+*/
+func runBackfill(cfg config.Config, args []string) {
+	flags := flag.NewFlagSet("backfill", flag.ExitOnError)
+	fromBlock := flags.Uint64("from", 0, "first block of the range")
+	toBlock := flags.Uint64("to", 0, "last block of the range")
+	defaultRPC := cfg.Backfill.RPC
+	if defaultRPC == "" && cfg.Source.Type == "chain" {
+		defaultRPC = cfg.Source.Location
+	}
+
+	rpcURL := flags.String("rpc", defaultRPC, "RPC URL of the node to scan")
+	chunkSize := flags.Uint64("chunk", cfg.Chain.MaxRange, "initial number of blocks per eth_getLogs call, reduced on too many results")
+	cursorFile := flags.String("cursor", "", "checkpoint file of the backfill progress (default backfill-<from>-<to>.cursor)")
+	addresses := flags.String("addresses", strings.Join(cfg.Chain.Addresses, ","),
+		"comma separated contract addresses to limit the logs to")
+	deferredFile := flags.String("deferred", "",
+		"deferred enrichment queue of the backfill, the live tracker's queue is not shared (default backfill-<from>-<to>.deferred)")
+	snapshotFile := flags.String("snapshots", "",
+		"snapshot store of the read Dune stats, the live tracker's store is locked while it runs (empty disables)")
+	registryIndexFile := flags.String("registry-index", "",
+		"registry index journaling the writes, the live tracker's index is locked while it runs (empty disables)")
+	_ = flags.Parse(args)
+
+	cfg.Snapshots.File = *snapshotFile
+	cfg.Index.File = *registryIndexFile
+
+	if *fromBlock == 0 || *toBlock < *fromBlock {
+		logger.LogE("A valid block range has to be given with -from and -to")
+	}
+
+	// Every range has its own checkpoint, so a backfill does not continue from the progress of another range
+	if *cursorFile == "" {
+		*cursorFile = fmt.Sprintf("backfill-%d-%d.cursor", *fromBlock, *toBlock)
+	}
+
+	// The queue file is rewritten by its owner, the tracker would overwrite the items of the backfill and vice versa
+	if *deferredFile == "" {
+		*deferredFile = fmt.Sprintf("backfill-%d-%d.deferred", *fromBlock, *toBlock)
+	}
+
+	if *deferredFile == cfg.Deferred.QueueFile {
+		logger.LogE("The deferred queue ", *deferredFile, " is the queue of the live tracker, give another file with -deferred")
+	}
+
+	cfg.Deferred.QueueFile = *deferredFile
+
+	if *rpcURL == "" {
+		logger.LogE("RPC URL is not given, use -rpc or BACKFILL_RPC")
+	}
+
+	if strings.ToLower(cfg.Dedup.Store) == "memory" {
+		logger.LogE("DEDUP_STORE is memory, the backfill needs the dedup store of the live tracker (redis, or file " +
+			"when the tracker is stopped) so that the processed events are not written again")
+	}
+
+	logger.LogI("Backfilling the blocks ", *fromBlock, "-", *toBlock)
+
+	rErr := runPipeline(cfg, backfillPipeline, func(registry *handlers.Registry) (stream.Source, error) {
+		client, dErr := utils.DialChain(*rpcURL, cfg.Chain.ID)
+
+		if dErr != nil {
+			return nil, dErr
+		}
+
+		return ingest.NewChainSource(client, ingest.NewFileCursor(*cursorFile), ingest.ChainSourceConfig{
+			Channel:      cfg.Kafka.ListenChannels[0],
+			Addresses:    chainAddresses(strings.Split(*addresses, ","), registry),
+			Topics:       registry.Topics(),
+			StartBlock:   *fromBlock,
+			EndBlock:     *toBlock,
+			PollInterval: 5 * time.Second,
+			MaxRange:     *chunkSize,
+		}), nil
+	})
+	if rErr != nil {
+		logger.LogE("Backfill has stopped, it continues from the checkpoint when started again: ", rErr)
+	}
+
+	logger.LogI("Backfill of the blocks ", *fromBlock, "-", *toBlock, " is completed")
+}
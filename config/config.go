@@ -0,0 +1,342 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"gopkg.in/yaml.v3"
+	"io"
+	"os"
+	"time"
+)
+
+/*
+Config is the typed configuration of the tracker. It is loaded from a YAML file and every setting can be overridden
+by the env variable named in its env tag. Lists are given in the env separated by the sep tag (default ","), maps as
+"key=value,key=value"
+*/
+type Config struct {
+	Kafka     KafkaConfig     `yaml:"kafka"`
+	Source    StreamConfig    `yaml:"source"`
+	Sink      SinkConfig      `yaml:"sink"`
+	Chain     ChainConfig     `yaml:"chain"`
+	Registry  RegistryConfig  `yaml:"registry"`
+	Signer    SignerConfig    `yaml:"signer"`
+	Tx        TxConfig        `yaml:"transactions"`
+	Dune      DuneConfig      `yaml:"dune"`
+	Metadata  MetadataConfig  `yaml:"metadata"`
+	Handlers  HandlersConfig  `yaml:"handlers"`
+	Dedup     DedupConfig     `yaml:"dedup"`
+	Reorg     ReorgConfig     `yaml:"reorg"`
+	Deferred  DeferredConfig  `yaml:"deferred"`
+	Scoring   ScoringConfig   `yaml:"scoring"`
+	Snapshots SnapshotsConfig `yaml:"snapshots"`
+	Index     IndexConfig     `yaml:"registryIndex"`
+	Limits    LimitsConfig    `yaml:"limits"`
+	Backfill  BackfillConfig  `yaml:"backfill"`
+	Metrics   MetricsConfig   `yaml:"metrics"`
+	// File is the path the configuration was read from, empty without a file
+	File string `yaml:"-"`
+}
+
+type KafkaConfig struct {
+	URI                string   `yaml:"uri" env:"KAFKA_URI"`
+	GroupID            string   `yaml:"groupId" env:"KAFKA_GROUP_ID"`
+	ListenChannels     []string `yaml:"listenChannels" env:"KAFKA_LISTEN_CHANNEL" sep:":"`
+	OutputChannels     []string `yaml:"outputChannels" env:"KAFKA_OUTPUT_CHANNEL" sep:":"`
+	DeadLetterChannels []string `yaml:"deadLetterChannels" env:"KAFKA_DEAD_LETTER_CHANNEL" sep:":"`
+}
+
+type StreamConfig struct {
+	// kafka, file, stdin, webhook or chain
+	Type     string `yaml:"type" env:"SOURCE_TYPE"`
+	Location string `yaml:"location" env:"SOURCE_LOCATION"`
+	// MaxLineSize is the longest line in bytes of the file and stdin sources
+	MaxLineSize int `yaml:"maxLineSize" env:"SOURCE_MAX_LINE_SIZE"`
+}
+
+type SinkConfig struct {
+	// kafka, file or stdout
+	Type     string `yaml:"type" env:"SINK_TYPE"`
+	Location string `yaml:"location" env:"SINK_LOCATION"`
+}
+
+/*
+ChainConfig is the chain of the EigenLayer contracts. ID is checked against every RPC that is read for the events and
+the metadata: the chain source, the backfill, the reorg monitor and the on-chain metadata
+*/
+type ChainConfig struct {
+	ID            uint64        `yaml:"id" env:"CHAIN_ID"`
+	Addresses     []string      `yaml:"addresses" env:"CHAIN_ADDRESSES"`
+	StartBlock    uint64        `yaml:"startBlock" env:"CHAIN_START_BLOCK"`
+	Confirmations uint64        `yaml:"confirmations" env:"CHAIN_CONFIRMATIONS"`
+	MaxRange      uint64        `yaml:"maxRange" env:"CHAIN_MAX_RANGE"`
+	PollInterval  time.Duration `yaml:"pollInterval" env:"CHAIN_POLL_INTERVAL"`
+	CursorFile    string        `yaml:"cursorFile" env:"CHAIN_CURSOR_FILE"`
+}
+
+/*
+RegistryConfig is the registry contract, which can live on another chain than the EigenLayer contracts
+*/
+type RegistryConfig struct {
+	RPC string `yaml:"rpc" env:"REGISTRY_RPC"`
+	// ChainID of the registry chain, checked against REGISTRY_RPC. Zero accepts any chain
+	ChainID uint64 `yaml:"chainId" env:"REGISTRY_CHAIN_ID"`
+	Address string `yaml:"address" env:"REGISTRY_ADDRESS"`
+	// GasLimit of the writes, zero estimates every write
+	GasLimit uint64 `yaml:"gasLimit" env:"REGISTRY_GAS_LIMIT"`
+	// PrivateKey is the raw key of the key signer, for development only
+	PrivateKey string `yaml:"privateKey" env:"PRV_KEY"`
+	// DeploymentBlock is the block of the registry deployment, recorded by the deploy command
+	DeploymentBlock uint64 `yaml:"deploymentBlock" env:"REGISTRY_DEPLOYMENT_BLOCK"`
+	// BatchSize writes are sent in a single transaction, 1 sends every write alone
+	BatchSize int           `yaml:"batchSize" env:"REGISTRY_BATCH_SIZE"`
+	BatchWait time.Duration `yaml:"batchWait" env:"REGISTRY_BATCH_WAIT"`
+}
+
+type SignerConfig struct {
+	// keystore, remote or key, empty picks it from the settings that are given
+	Type           string `yaml:"type" env:"SIGNER_TYPE"`
+	KeystoreFile   string `yaml:"keystoreFile" env:"KEYSTORE_FILE"`
+	PassphraseFile string `yaml:"passphraseFile" env:"KEYSTORE_PASSPHRASE_FILE"`
+	// RemoteURL of a Clef compatible signer, http(s), ws(s) or an IPC path
+	RemoteURL string `yaml:"remoteUrl" env:"REMOTE_SIGNER_URL"`
+	// Address selects the account of the remote signer, empty takes its first account
+	Address string `yaml:"address" env:"SIGNER_ADDRESS"`
+}
+
+/*
+Kind returns the signer type, or when it is not set the type of the given settings: keystore with a keystore file,
+remote with a remote URL and the raw key otherwise
+*/
+func (s SignerConfig) Kind() string {
+	switch {
+	case s.Type != "":
+		return s.Type
+	case s.KeystoreFile != "":
+		return "keystore"
+	case s.RemoteURL != "":
+		return "remote"
+	}
+
+	return "key"
+}
+
+type TxConfig struct {
+	Confirmations   uint64        `yaml:"confirmations" env:"TX_CONFIRMATIONS"`
+	PollInterval    time.Duration `yaml:"pollInterval" env:"TX_POLL_INTERVAL"`
+	StuckAfter      time.Duration `yaml:"stuckAfter" env:"TX_STUCK_AFTER"`
+	FeeBumpPercent  int           `yaml:"feeBumpPercent" env:"TX_FEE_BUMP_PERCENT"`
+	MaxGasPrice     string        `yaml:"maxGasPrice" env:"TX_MAX_GAS_PRICE"`
+	MaxRebroadcasts int           `yaml:"maxRebroadcasts" env:"TX_MAX_REBROADCASTS"`
+	// GasMarginPercent is added to the estimated gas of the writes
+	GasMarginPercent  uint64  `yaml:"gasMarginPercent" env:"TX_GAS_MARGIN_PERCENT"`
+	FeeHistoryBlocks  uint64  `yaml:"feeHistoryBlocks" env:"TX_FEE_HISTORY_BLOCKS"`
+	FeePercentile     float64 `yaml:"feePercentile" env:"TX_FEE_PERCENTILE"`
+	BaseFeeMultiplier float64 `yaml:"baseFeeMultiplier" env:"TX_BASE_FEE_MULTIPLIER"`
+	MinTip            string  `yaml:"minTip" env:"TX_MIN_TIP"`
+	MaxTip            string  `yaml:"maxTip" env:"TX_MAX_TIP"`
+	// Legacy sends legacy transactions even if the chain supports dynamic fees
+	Legacy bool `yaml:"legacy" env:"TX_LEGACY"`
+}
+
+type DuneConfig struct {
+	URL        string        `yaml:"url" env:"DUNE_API_URL"`
+	Key        string        `yaml:"key" env:"DUNE_KEY"`
+	Timeout    time.Duration `yaml:"timeout" env:"DUNE_TIMEOUT"`
+	MaxRetries int           `yaml:"maxRetries" env:"DUNE_MAX_RETRIES"`
+	// PageSize of the paginated results, zero leaves it to the API
+	PageSize     int           `yaml:"pageSize" env:"DUNE_PAGE_SIZE"`
+	PollInterval time.Duration `yaml:"pollInterval" env:"DUNE_POLL_INTERVAL"`
+	// AVSQueryID is our saved query for the AVS stats, zero uses the eigenlayer/avs-stats endpoint
+	AVSQueryID int `yaml:"avsQueryId" env:"DUNE_AVS_QUERY_ID"`
+}
+
+type MetadataConfig struct {
+	// memory or redis
+	Cache         string        `yaml:"cache" env:"METADATA_CACHE"`
+	CacheLocation string        `yaml:"cacheLocation" env:"METADATA_CACHE_LOCATION"`
+	CachePassword string        `yaml:"cachePassword" env:"METADATA_CACHE_PASSWORD"`
+	OperatorTTL   time.Duration `yaml:"operatorTTL" env:"METADATA_OPERATOR_TTL"`
+	AVSTTL        time.Duration `yaml:"avsTTL" env:"METADATA_AVS_TTL"`
+	// NegativeTTL of the unknown addresses
+	NegativeTTL time.Duration `yaml:"negativeTTL" env:"METADATA_NEGATIVE_TTL"`
+	// RPC of the node the on-chain metadata URIs are read from, empty disables the on-chain provider
+	RPC               string `yaml:"rpc" env:"METADATA_RPC"`
+	DelegationManager string `yaml:"delegationManager" env:"METADATA_DELEGATION_MANAGER"`
+	AVSDirectory      string `yaml:"avsDirectory" env:"METADATA_AVS_DIRECTORY"`
+	// FromBlock is the deployment block of the DelegationManager and the AVSDirectory, the search stops there
+	FromBlock uint64 `yaml:"fromBlock" env:"METADATA_FROM_BLOCK"`
+	// LogRange is the number of blocks per eth_getLogs call, halved while the node refuses the range
+	LogRange    uint64 `yaml:"logRange" env:"METADATA_LOG_RANGE"`
+	IPFSGateway string `yaml:"ipfsGateway" env:"METADATA_IPFS_GATEWAY"`
+	// OverrideFile maps the addresses to names by hand, asked after the other providers
+	OverrideFile string `yaml:"overrideFile" env:"METADATA_OVERRIDE_FILE"`
+}
+
+type HandlersConfig struct {
+	Enabled        []string          `yaml:"enabled" env:"HANDLERS"`
+	OutputChannels map[string]string `yaml:"outputChannels" env:"HANDLER_OUTPUT_CHANNELS"`
+	Contracts      map[string]string `yaml:"contracts" env:"HANDLER_CONTRACTS"`
+}
+
+type DedupConfig struct {
+	Store     string        `yaml:"store" env:"DEDUP_STORE"`
+	Location  string        `yaml:"location" env:"DEDUP_LOCATION"`
+	Password  string        `yaml:"password" env:"DEDUP_PASSWORD"`
+	Retention time.Duration `yaml:"retention" env:"DEDUP_RETENTION"`
+	// ClaimTTL is the longest processing of a log, the claim of a crashed replica expires after it
+	ClaimTTL time.Duration `yaml:"claimTTL" env:"DEDUP_CLAIM_TTL"`
+}
+
+type ReorgConfig struct {
+	RPC                 string        `yaml:"rpc" env:"REORG_RPC"`
+	Depth               uint64        `yaml:"depth" env:"REORG_DEPTH"`
+	CheckInterval       time.Duration `yaml:"checkInterval" env:"REORG_CHECK_INTERVAL"`
+	CompensationChannel string        `yaml:"compensationChannel" env:"REORG_COMPENSATION_CHANNEL"`
+}
+
+type DeferredConfig struct {
+	QueueFile     string        `yaml:"queueFile" env:"DEFERRED_QUEUE_FILE"`
+	MinDelay      time.Duration `yaml:"minDelay" env:"DEFERRED_MIN_DELAY"`
+	MaxDelay      time.Duration `yaml:"maxDelay" env:"DEFERRED_MAX_DELAY"`
+	MaxAge        time.Duration `yaml:"maxAge" env:"DEFERRED_MAX_AGE"`
+	CheckInterval time.Duration `yaml:"checkInterval" env:"DEFERRED_CHECK_INTERVAL"`
+}
+
+type ScoringConfig struct {
+	Channel        string            `yaml:"channel" env:"SCORING_CHANNEL"`
+	Interval       time.Duration     `yaml:"interval" env:"SCORING_INTERVAL"`
+	Weights        map[string]string `yaml:"weights" env:"SCORING_WEIGHTS"`
+	VelocityBlocks uint64            `yaml:"velocityBlocks" env:"SCORING_VELOCITY_BLOCKS"`
+	// Entities without a registration within this many blocks are dropped from the scoring
+	RetentionBlocks uint64 `yaml:"retentionBlocks" env:"SCORING_RETENTION_BLOCKS"`
+}
+
+type SnapshotsConfig struct {
+	File                string        `yaml:"file" env:"SNAPSHOT_FILE"`
+	Retention           time.Duration `yaml:"retention" env:"SNAPSHOT_RETENTION"`
+	CompactAfter        time.Duration `yaml:"compactAfter" env:"SNAPSHOT_COMPACT_AFTER"`
+	CompactResolution   time.Duration `yaml:"compactResolution" env:"SNAPSHOT_COMPACT_RESOLUTION"`
+	MaintenanceInterval time.Duration `yaml:"maintenanceInterval" env:"SNAPSHOT_MAINTENANCE_INTERVAL"`
+}
+
+type IndexConfig struct {
+	// File of the registry index and the write journal, empty disables both
+	File          string `yaml:"file" env:"REGISTRY_INDEX_FILE"`
+	LogRange      uint64 `yaml:"logRange" env:"REGISTRY_INDEX_LOG_RANGE"`
+	Confirmations uint64 `yaml:"confirmations" env:"REGISTRY_INDEX_CONFIRMATIONS"`
+	// ReconcileInterval of the tracker, 0 disables the reconciliation in the tracker
+	ReconcileInterval time.Duration `yaml:"reconcileInterval" env:"REGISTRY_RECONCILE_INTERVAL"`
+	Resubmit          bool          `yaml:"resubmit" env:"REGISTRY_RECONCILE_RESUBMIT"`
+}
+
+type LimitsConfig struct {
+	WorkerCount     int           `yaml:"workerCount" env:"WORKER_COUNT"`
+	WorkerQueueSize int           `yaml:"workerQueueSize" env:"WORKER_QUEUE_SIZE"`
+	ShutdownTimeout time.Duration `yaml:"shutdownTimeout" env:"SHUTDOWN_TIMEOUT"`
+}
+
+type BackfillConfig struct {
+	RPC string `yaml:"rpc" env:"BACKFILL_RPC"`
+}
+
+type MetricsConfig struct {
+	Addr string `yaml:"addr" env:"METRICS_ADDR"`
+}
+
+/*
+Default returns the configuration with the default values of the tracker
+*/
+func Default() Config {
+	return Config{
+		Kafka:  KafkaConfig{GroupID: "centralisedEx"},
+		Source: StreamConfig{Type: "kafka", MaxLineSize: 16 * 1024 * 1024},
+		Sink:   SinkConfig{Type: "kafka"},
+		Chain: ChainConfig{
+			ID:            1,
+			Confirmations: 12,
+			MaxRange:      1000,
+			PollInterval:  12 * time.Second,
+			CursorFile:    "chain.cursor",
+		},
+		Registry: RegistryConfig{BatchSize: 1, BatchWait: 5 * time.Second},
+		Tx: TxConfig{Confirmations: 3, PollInterval: 2 * time.Second, StuckAfter: 3 * time.Minute, FeeBumpPercent: 20,
+			MaxRebroadcasts: 5, GasMarginPercent: 20, FeeHistoryBlocks: 10, FeePercentile: 50, BaseFeeMultiplier: 2},
+		Dune: DuneConfig{URL: "https://api.dune.com/api/v1", Timeout: 30 * time.Second, MaxRetries: 3,
+			PollInterval: 2 * time.Second},
+		Metadata: MetadataConfig{
+			Cache:             "memory",
+			OperatorTTL:       time.Hour,
+			AVSTTL:            6 * time.Hour,
+			NegativeTTL:       5 * time.Minute,
+			DelegationManager: "0x39053D51B77DC0d36036Fc1fCc8Cb819df8Ef37A",
+			AVSDirectory:      "0x135DDa560e946695d6f155dACaFC6f1F25C1F5AF",
+			FromBlock:         17445563,
+			LogRange:          50000,
+			IPFSGateway:       "https://ipfs.io/ipfs/",
+		},
+		Dedup: DedupConfig{Store: "memory", Retention: 7 * 24 * time.Hour, ClaimTTL: 30 * time.Minute},
+		Reorg: ReorgConfig{Depth: 128, CheckInterval: 15 * time.Second},
+		Deferred: DeferredConfig{QueueFile: "deferred.queue", MinDelay: 5 * time.Minute, MaxDelay: 6 * time.Hour,
+			MaxAge: 7 * 24 * time.Hour, CheckInterval: time.Minute},
+		Scoring: ScoringConfig{Interval: time.Hour, VelocityBlocks: 7200, RetentionBlocks: 216000},
+		Snapshots: SnapshotsConfig{File: "snapshots.db", Retention: 90 * 24 * time.Hour, CompactAfter: 7 * 24 * time.Hour,
+			CompactResolution: time.Hour, MaintenanceInterval: time.Hour},
+		Index:  IndexConfig{File: "registry.db", LogRange: 2000, Confirmations: 3, ReconcileInterval: 10 * time.Minute},
+		Limits: LimitsConfig{WorkerCount: 8, WorkerQueueSize: 100, ShutdownTimeout: 30 * time.Second},
+	}
+}
+
+/*
+Load reads the configuration: the defaults are overwritten by the file at path (skipped if path is empty) and then by
+the env map and the process environment. The result is validated, all the problems are returned together
+*/
+func Load(path string, envMap map[string]string) (Config, error) {
+	cfg, rErr := read(path, envMap)
+	if rErr != nil {
+		return cfg, rErr
+	}
+
+	return cfg, cfg.Validate()
+}
+
+/*
+LoadOnly reads the configuration like Load, but validates only the given settings or sections, see ValidateOnly
+*/
+func LoadOnly(path string, envMap map[string]string, settings ...string) (Config, error) {
+	cfg, rErr := read(path, envMap)
+	if rErr != nil {
+		return cfg, rErr
+	}
+
+	return cfg, cfg.ValidateOnly(settings...)
+}
+
+/*
+read reads the defaults, the file and the env map without validating them
+*/
+func read(path string, envMap map[string]string) (Config, error) {
+	cfg := Default()
+	cfg.File = path
+
+	if path != "" {
+		content, rErr := os.ReadFile(path)
+		if rErr != nil {
+			return cfg, errors.New("cannot read the config file: " + rErr.Error())
+		}
+
+		decoder := yaml.NewDecoder(bytes.NewReader(content))
+		// Misspelled keys would otherwise be silently ignored
+		decoder.KnownFields(true)
+
+		if dErr := decoder.Decode(&cfg); dErr != nil && !errors.Is(dErr, io.EOF) {
+			return cfg, errors.New("invalid config file " + path + ": " + dErr.Error())
+		}
+	}
+
+	if oErr := applyEnv(&cfg, envMap); oErr != nil {
+		return cfg, oErr
+	}
+
+	return cfg, nil
+}
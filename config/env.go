@@ -0,0 +1,147 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+/*
+envFields calls fn for every setting of the config that has an env tag
+*/
+func envFields(value reflect.Value, fn func(field reflect.Value, key string, separator string) error) error {
+	valueType := value.Type()
+
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Field(i)
+		structField := valueType.Field(i)
+
+		if field.Kind() == reflect.Struct {
+			if err := envFields(field, fn); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		key := structField.Tag.Get("env")
+
+		if key == "" {
+			continue
+		}
+
+		separator := structField.Tag.Get("sep")
+
+		if separator == "" {
+			separator = ","
+		}
+
+		if err := fn(field, key, separator); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+/*
+applyEnv overrides the settings that are present in the env map or in the process environment
+*/
+func applyEnv(cfg *Config, envMap map[string]string) error {
+	return envFields(reflect.ValueOf(cfg).Elem(), func(field reflect.Value, key string, separator string) error {
+		raw, isOk := envMap[key]
+
+		if !isOk || raw == "" {
+			raw = os.Getenv(key)
+		}
+
+		if raw == "" {
+			return nil
+		}
+
+		if sErr := setField(field, raw, separator); sErr != nil {
+			return errors.New("invalid value for " + key + ": " + sErr.Error())
+		}
+
+		return nil
+	})
+}
+
+/*
+setField parses the raw env value into the field
+*/
+func setField(field reflect.Value, raw string, separator string) error {
+	if field.Type() == durationType {
+		duration, pErr := time.ParseDuration(raw)
+		if pErr != nil {
+			return pErr
+		}
+
+		field.SetInt(int64(duration))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(raw)
+	case reflect.Int:
+		value, pErr := strconv.Atoi(raw)
+		if pErr != nil {
+			return pErr
+		}
+
+		field.SetInt(int64(value))
+	case reflect.Uint64:
+		value, pErr := strconv.ParseUint(raw, 10, 64)
+		if pErr != nil {
+			return pErr
+		}
+
+		field.SetUint(value)
+	case reflect.Float64:
+		value, pErr := strconv.ParseFloat(raw, 64)
+		if pErr != nil {
+			return pErr
+		}
+
+		field.SetFloat(value)
+	case reflect.Bool:
+		value, pErr := strconv.ParseBool(raw)
+		if pErr != nil {
+			return pErr
+		}
+
+		field.SetBool(value)
+	case reflect.Slice:
+		values := []string{}
+
+		for _, value := range strings.Split(raw, separator) {
+			values = append(values, strings.TrimSpace(value))
+		}
+
+		field.Set(reflect.ValueOf(values))
+	case reflect.Map:
+		values := map[string]string{}
+
+		for _, pair := range strings.Split(raw, separator) {
+			key, value, isOk := strings.Cut(pair, "=")
+
+			if !isOk {
+				return errors.New("expected key=value, got " + pair)
+			}
+
+			values[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+
+		field.Set(reflect.ValueOf(values))
+	default:
+		return errors.New("unsupported setting type " + field.Type().String())
+	}
+
+	return nil
+}
@@ -0,0 +1,258 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"gopkg.in/yaml.v3"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+/*
+SetFileValues writes the values, keyed by their setting (e.g. "registry.address"), into the YAML configuration file at
+path. The other settings and the comments of the file are kept, missing sections are added and a missing file is
+created
+*/
+func SetFileValues(path string, values map[string]string) error {
+	document := &yaml.Node{Kind: yaml.DocumentNode}
+
+	content, rErr := os.ReadFile(path)
+	if rErr != nil && !errors.Is(rErr, os.ErrNotExist) {
+		return rErr
+	}
+
+	if len(bytes.TrimSpace(content)) > 0 {
+		if uErr := yaml.Unmarshal(content, document); uErr != nil {
+			return errors.New("invalid config file " + path + ": " + uErr.Error())
+		}
+	}
+
+	settings := make([]string, 0, len(values))
+	for setting := range values {
+		settings = append(settings, setting)
+	}
+
+	sort.Strings(settings)
+
+	// Settings that are in the file are replaced in its text, so that its layout is kept as well
+	if edited, isEdited := replaceValues(content, document, settings, values); isEdited {
+		return os.WriteFile(path, edited, 0o644)
+	}
+
+	if len(document.Content) == 0 {
+		document.Content = []*yaml.Node{{Kind: yaml.MappingNode}}
+	}
+
+	for _, setting := range settings {
+		if sErr := setValue(document.Content[0], strings.Split(setting, "."), values[setting]); sErr != nil {
+			return errors.New(setting + ": " + sErr.Error())
+		}
+	}
+
+	buffer := new(bytes.Buffer)
+	encoder := yaml.NewEncoder(buffer)
+	encoder.SetIndent(2)
+
+	if eErr := encoder.Encode(document); eErr != nil {
+		return eErr
+	}
+
+	if cErr := encoder.Close(); cErr != nil {
+		return cErr
+	}
+
+	return os.WriteFile(path, buffer.Bytes(), 0o644)
+}
+
+/*
+replaceValues replaces the values of the settings in the text of the file. It fails when a setting is not a single
+line scalar of the file
+*/
+func replaceValues(content []byte, document *yaml.Node, settings []string, values map[string]string) ([]byte, bool) {
+	if len(document.Content) == 0 {
+		return nil, false
+	}
+
+	lines := strings.Split(string(content), "\n")
+
+	type replacement struct {
+		line, start, end int
+		value            string
+	}
+
+	var replacements []replacement
+
+	for _, setting := range settings {
+		node := findValue(document.Content[0], strings.Split(setting, "."))
+		if node == nil || node.Kind != yaml.ScalarNode || node.Line < 1 || node.Line > len(lines) ||
+			node.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0 {
+			return nil, false
+		}
+
+		line := []rune(lines[node.Line-1])
+		start := node.Column - 1
+
+		if start < 0 || start > len(line) {
+			return nil, false
+		}
+
+		end := scalarEnd(line, start)
+		if end < 0 {
+			return nil, false
+		}
+
+		value := values[setting]
+		if scalarStyle(value) == yaml.DoubleQuotedStyle {
+			value = strconv.Quote(value)
+		}
+
+		replacements = append(replacements, replacement{line: node.Line - 1, start: start, end: end, value: value})
+	}
+
+	// From the right, so that the columns of the other values of a line stay valid
+	sort.Slice(replacements, func(i, j int) bool {
+		return replacements[i].line < replacements[j].line ||
+			replacements[i].line == replacements[j].line && replacements[i].start > replacements[j].start
+	})
+
+	for _, r := range replacements {
+		line := []rune(lines[r.line])
+		rest := line[r.end:]
+
+		// An aligned comment keeps its column when the padding allows it
+		spaces := 0
+		for spaces < len(rest) && rest[spaces] == ' ' {
+			spaces++
+		}
+
+		if spaces > 0 && spaces < len(rest) && rest[spaces] == '#' {
+			padding := max(spaces-(len([]rune(r.value))-(r.end-r.start)), 1)
+			rest = append([]rune(strings.Repeat(" ", padding)), rest[spaces:]...)
+		}
+
+		lines[r.line] = string(line[:r.start]) + r.value + string(rest)
+	}
+
+	return []byte(strings.Join(lines, "\n")), true
+}
+
+/*
+findValue returns the value node at the key path of the mapping, nil when it is not there or in a flow mapping
+*/
+func findValue(mapping *yaml.Node, keys []string) *yaml.Node {
+	if mapping.Kind != yaml.MappingNode || mapping.Style&yaml.FlowStyle != 0 {
+		return nil
+	}
+
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == keys[0] {
+			if len(keys) == 1 {
+				return mapping.Content[i+1]
+			}
+
+			return findValue(mapping.Content[i+1], keys[1:])
+		}
+	}
+
+	return nil
+}
+
+/*
+scalarEnd returns the end of the scalar starting at start of the line, -1 when it does not end on the line
+*/
+func scalarEnd(line []rune, start int) int {
+	if start == len(line) {
+		return start
+	}
+
+	switch line[start] {
+	case '"':
+		for i := start + 1; i < len(line); i++ {
+			if line[i] == '\\' {
+				i++
+			} else if line[i] == '"' {
+				return i + 1
+			}
+		}
+
+		return -1
+	case '\'':
+		for i := start + 1; i < len(line); i++ {
+			if line[i] == '\'' {
+				if i+1 < len(line) && line[i+1] == '\'' {
+					i++
+					continue
+				}
+
+				return i + 1
+			}
+		}
+
+		return -1
+	}
+
+	// A plain scalar ends at a comment
+	end := len(line)
+	for i := start; i < len(line); i++ {
+		if line[i] == '#' && i > start && (line[i-1] == ' ' || line[i-1] == '\t') {
+			end = i
+			break
+		}
+	}
+
+	for end > start && (line[end-1] == ' ' || line[end-1] == '\t' || line[end-1] == '\r') {
+		end--
+	}
+
+	return end
+}
+
+/*
+setValue sets the scalar at the key path of the mapping, the missing mappings on the way are created
+*/
+func setValue(mapping *yaml.Node, keys []string, value string) error {
+	if mapping.Kind != yaml.MappingNode {
+		return errors.New("is not a mapping")
+	}
+
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value != keys[0] {
+			continue
+		}
+
+		if len(keys) > 1 {
+			return setValue(mapping.Content[i+1], keys[1:], value)
+		}
+
+		node := mapping.Content[i+1]
+		node.Kind, node.Tag, node.Value, node.Content, node.Style = yaml.ScalarNode, "", value, nil, scalarStyle(value)
+
+		return nil
+	}
+
+	key := &yaml.Node{Kind: yaml.ScalarNode, Value: keys[0]}
+
+	if len(keys) > 1 {
+		child := &yaml.Node{Kind: yaml.MappingNode}
+		mapping.Content = append(mapping.Content, key, child)
+
+		return setValue(child, keys[1:], value)
+	}
+
+	mapping.Content = append(mapping.Content, key, &yaml.Node{Kind: yaml.ScalarNode, Value: value, Style: scalarStyle(value)})
+
+	return nil
+}
+
+/*
+scalarStyle quotes the values that are not plain numbers, so that e.g. addresses are not read as hex integers
+*/
+func scalarStyle(value string) yaml.Style {
+	if _, pErr := strconv.ParseUint(value, 10, 64); pErr == nil {
+		return 0
+	}
+
+	return yaml.DoubleQuotedStyle
+}
@@ -0,0 +1,115 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSetFileValues(t *testing.T) {
+	const address = "0x00000000000000000000000000000000000000Ab"
+
+	tests := []struct {
+		name     string
+		content  *string
+		values   map[string]string
+		expected string
+	}{
+		{
+			name: "quoted scalar keeps the aligned comment",
+			content: ptr("registry:\n" +
+				"  address: \"\"                          # REGISTRY_ADDRESS\n" +
+				"  chainID: 1\n"),
+			values: map[string]string{"registry.address": address},
+			expected: "registry:\n" +
+				"  address: \"" + address + "\" # REGISTRY_ADDRESS\n" +
+				"  chainID: 1\n",
+		},
+		{
+			name: "plain scalars",
+			content: ptr("registry:\n" +
+				"  address: 0x1\n" +
+				"  deploymentBlock: 0\n"),
+			values: map[string]string{"registry.address": address, "registry.deploymentBlock": "19000000"},
+			expected: "registry:\n" +
+				"  address: \"" + address + "\"\n" +
+				"  deploymentBlock: 19000000\n",
+		},
+		{
+			name: "commented scalars",
+			content: ptr("# Registry settings\n" +
+				"registry:\n" +
+				"  address: 0x1 # the deployed registry\n" +
+				"  deploymentBlock: 0      # REGISTRY_DEPLOYMENT_BLOCK\n"),
+			values: map[string]string{"registry.address": address, "registry.deploymentBlock": "19000000"},
+			expected: "# Registry settings\n" +
+				"registry:\n" +
+				"  address: \"" + address + "\" # the deployed registry\n" +
+				"  deploymentBlock: 19000000 # REGISTRY_DEPLOYMENT_BLOCK\n",
+		},
+		{
+			name:    "missing section is added",
+			content: ptr("chain:\n  id: 1\n"),
+			values:  map[string]string{"registry.address": address, "registry.deploymentBlock": "5"},
+			expected: "chain:\n" +
+				"  id: 1\n" +
+				"registry:\n" +
+				"  address: \"" + address + "\"\n" +
+				"  deploymentBlock: 5\n",
+		},
+		{
+			name:   "absent file is created",
+			values: map[string]string{"registry.address": address, "registry.deploymentBlock": "5"},
+			expected: "registry:\n" +
+				"  address: \"" + address + "\"\n" +
+				"  deploymentBlock: 5\n",
+		},
+		{
+			name:     "flow mapping is re-encoded",
+			content:  ptr("registry: {address: \"\", chainID: 1}\n"),
+			values:   map[string]string{"registry.address": address},
+			expected: "registry: {address: \"" + address + "\", chainID: 1}\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.yaml")
+
+			if test.content != nil {
+				if wErr := os.WriteFile(path, []byte(*test.content), 0o644); wErr != nil {
+					t.Fatal(wErr)
+				}
+			}
+
+			if sErr := SetFileValues(path, test.values); sErr != nil {
+				t.Fatal(sErr)
+			}
+
+			written, rErr := os.ReadFile(path)
+			if rErr != nil {
+				t.Fatal(rErr)
+			}
+
+			if string(written) != test.expected {
+				t.Fatalf("expected\n%s\ngot\n%s", test.expected, written)
+			}
+		})
+	}
+}
+
+func TestSetFileValuesRejectsAScalarSection(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+
+	if wErr := os.WriteFile(path, []byte("registry: none\n"), 0o644); wErr != nil {
+		t.Fatal(wErr)
+	}
+
+	if sErr := SetFileValues(path, map[string]string{"registry.address": "0x1"}); sErr == nil {
+		t.Fatal("expected the scalar registry section to be rejected")
+	}
+}
+
+func ptr(value string) *string {
+	return &value
+}
@@ -0,0 +1,443 @@
+package config
+
+import (
+	"errors"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"math/big"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+)
+
+/*
+Problem is an invalid setting found by Validate
+*/
+type Problem struct {
+	Setting string
+	Key     string
+	Message string
+}
+
+func (p Problem) Error() string {
+	return p.Setting + " (" + p.Key + "): " + p.Message
+}
+
+/*
+ValidateOnly checks the configuration like Validate, but returns only the problems of the given settings or sections
+(e.g. "signer" or "registry.rpc"), for the commands that use a part of the configuration
+*/
+func (c Config) ValidateOnly(settings ...string) error {
+	var problems []error
+
+	for _, problem := range c.problems() {
+		for _, setting := range settings {
+			if problem.Setting == setting || strings.HasPrefix(problem.Setting, setting+".") {
+				problems = append(problems, problem)
+				break
+			}
+		}
+	}
+
+	return errors.Join(problems...)
+}
+
+/*
+Validate checks the configuration and returns every problem found, each naming the setting and its env variable
+*/
+func (c Config) Validate() error {
+	var problems []error
+
+	for _, problem := range c.problems() {
+		problems = append(problems, problem)
+	}
+
+	return errors.Join(problems...)
+}
+
+func (c Config) problems() []Problem {
+	var problems []Problem
+
+	problem := func(setting string, key string, message string) {
+		problems = append(problems, Problem{Setting: setting, Key: key, Message: message})
+	}
+
+	sourceType := strings.ToLower(c.Source.Type)
+	sinkType := strings.ToLower(c.Sink.Type)
+
+	// Kafka
+	if (sourceType == "kafka" || sinkType == "kafka") && c.Kafka.URI == "" {
+		problem("kafka.uri", "KAFKA_URI", "is required when kafka is used as source or sink")
+	}
+
+	if len(c.Kafka.ListenChannels) == 0 {
+		problem("kafka.listenChannels", "KAFKA_LISTEN_CHANNEL", "at least one channel is required")
+	}
+
+	if len(c.Kafka.OutputChannels) != len(c.Kafka.ListenChannels) {
+		problem("kafka.outputChannels", "KAFKA_OUTPUT_CHANNEL", "one output channel is required per listen channel")
+	}
+
+	if len(c.Kafka.DeadLetterChannels) > 0 && len(c.Kafka.DeadLetterChannels) != len(c.Kafka.ListenChannels) {
+		problem("kafka.deadLetterChannels", "KAFKA_DEAD_LETTER_CHANNEL", "one dead-letter channel is required per listen channel")
+	}
+
+	// Source and sink
+	switch sourceType {
+	case "kafka", "stdin":
+	case "file", "webhook", "chain":
+		if c.Source.Location == "" {
+			problem("source.location", "SOURCE_LOCATION", "is required for the "+sourceType+" source")
+		}
+	default:
+		problem("source.type", "SOURCE_TYPE", "unknown source type "+c.Source.Type)
+	}
+
+	if c.Source.MaxLineSize <= 0 {
+		problem("source.maxLineSize", "SOURCE_MAX_LINE_SIZE", "must be positive")
+	}
+
+	switch sinkType {
+	case "kafka", "stdout":
+	case "file":
+		if c.Sink.Location == "" {
+			problem("sink.location", "SINK_LOCATION", "is required for the file sink")
+		}
+	default:
+		problem("sink.type", "SINK_TYPE", "unknown sink type "+c.Sink.Type)
+	}
+
+	// Chain
+	if c.Chain.ID == 0 {
+		problem("chain.id", "CHAIN_ID", "must be set")
+	}
+
+	if c.Chain.MaxRange == 0 {
+		problem("chain.maxRange", "CHAIN_MAX_RANGE", "must be positive")
+	}
+
+	if c.Chain.PollInterval <= 0 {
+		problem("chain.pollInterval", "CHAIN_POLL_INTERVAL", "must be positive")
+	}
+
+	for _, address := range c.Chain.Addresses {
+		if !common.IsHexAddress(address) {
+			problem("chain.addresses", "CHAIN_ADDRESSES", "invalid address "+address)
+		}
+	}
+
+	// Registry, there is no default node so that the writes never go to an unintended chain
+	if c.Registry.RPC == "" {
+		problem("registry.rpc", "REGISTRY_RPC", "is required to read and write the registry")
+	} else if !isURL(c.Registry.RPC) {
+		problem("registry.rpc", "REGISTRY_RPC", "must be an absolute http(s) or ws(s) URL")
+	}
+
+	if !common.IsHexAddress(c.Registry.Address) || common.HexToAddress(c.Registry.Address) == (common.Address{}) {
+		problem("registry.address", "REGISTRY_ADDRESS", "must be the address of the deployed registry contract")
+	}
+
+	// Signer
+	signerType := c.Signer.Kind()
+
+	switch signerType {
+	case "keystore":
+		if _, sErr := os.Stat(c.Signer.KeystoreFile); sErr != nil {
+			problem("signer.keystoreFile", "KEYSTORE_FILE", "must be an encrypted keystore file: "+sErr.Error())
+		}
+
+		if _, sErr := os.Stat(c.Signer.PassphraseFile); sErr != nil {
+			problem("signer.passphraseFile", "KEYSTORE_PASSPHRASE_FILE", "must be the file of the keystore passphrase: "+
+				sErr.Error())
+		}
+	case "remote":
+		if c.Signer.RemoteURL == "" {
+			problem("signer.remoteUrl", "REMOTE_SIGNER_URL", "is required for the remote signer")
+		}
+
+		if c.Signer.Address != "" && !common.IsHexAddress(c.Signer.Address) {
+			problem("signer.address", "SIGNER_ADDRESS", "must be an address")
+		}
+	case "key":
+		// The key itself is never part of the message
+		if c.Registry.PrivateKey == "" {
+			problem("registry.privateKey", "PRV_KEY", "is required to sign the registry writes without a keystore "+
+				"or remote signer")
+		} else if _, kErr := crypto.HexToECDSA(strings.TrimPrefix(c.Registry.PrivateKey, "0x")); kErr != nil {
+			problem("registry.privateKey", "PRV_KEY", "must be a 32 byte hex encoded secp256k1 key")
+		}
+	default:
+		problem("signer.type", "SIGNER_TYPE", "must be keystore, remote or key")
+	}
+
+	if c.Registry.BatchSize < 1 {
+		problem("registry.batchSize", "REGISTRY_BATCH_SIZE", "must be at least 1")
+	}
+
+	if c.Registry.BatchSize > 1 && c.Registry.BatchWait <= 0 {
+		problem("registry.batchWait", "REGISTRY_BATCH_WAIT", "must be positive when the writes are batched")
+	}
+
+	// Transactions
+	if c.Tx.Confirmations == 0 {
+		problem("transactions.confirmations", "TX_CONFIRMATIONS", "must be positive")
+	}
+
+	if c.Tx.PollInterval <= 0 {
+		problem("transactions.pollInterval", "TX_POLL_INTERVAL", "must be positive")
+	}
+
+	if c.Tx.StuckAfter <= 0 {
+		problem("transactions.stuckAfter", "TX_STUCK_AFTER", "must be positive")
+	}
+
+	if c.Tx.FeeBumpPercent < 10 {
+		problem("transactions.feeBumpPercent", "TX_FEE_BUMP_PERCENT", "must be at least 10, nodes reject smaller bumps")
+	}
+
+	if c.Tx.MaxRebroadcasts <= 0 {
+		problem("transactions.maxRebroadcasts", "TX_MAX_REBROADCASTS", "must be positive")
+	}
+
+	wei := []struct {
+		setting string
+		env     string
+		value   string
+	}{
+		{"transactions.maxGasPrice", "TX_MAX_GAS_PRICE", c.Tx.MaxGasPrice},
+		{"transactions.minTip", "TX_MIN_TIP", c.Tx.MinTip},
+		{"transactions.maxTip", "TX_MAX_TIP", c.Tx.MaxTip},
+	}
+
+	for _, amount := range wei {
+		if amount.value == "" {
+			continue
+		}
+
+		if parsed, isOk := new(big.Int).SetString(amount.value, 10); !isOk || parsed.Sign() <= 0 {
+			problem(amount.setting, amount.env, "must be a positive amount in wei")
+		}
+	}
+
+	if c.Tx.FeeHistoryBlocks == 0 || c.Tx.FeeHistoryBlocks > 1024 {
+		problem("transactions.feeHistoryBlocks", "TX_FEE_HISTORY_BLOCKS", "must be between 1 and 1024")
+	}
+
+	if c.Tx.FeePercentile <= 0 || c.Tx.FeePercentile > 100 {
+		problem("transactions.feePercentile", "TX_FEE_PERCENTILE", "must be above 0 and at most 100")
+	}
+
+	if c.Tx.BaseFeeMultiplier < 1 {
+		problem("transactions.baseFeeMultiplier", "TX_BASE_FEE_MULTIPLIER", "must be at least 1")
+	}
+
+	// Dune
+	if c.Dune.Key == "" {
+		problem("dune.key", "DUNE_KEY", "is required")
+	}
+
+	if !isURL(c.Dune.URL) {
+		problem("dune.url", "DUNE_API_URL", "must be an absolute http(s) URL")
+	}
+
+	if c.Dune.Timeout <= 0 {
+		problem("dune.timeout", "DUNE_TIMEOUT", "must be positive")
+	}
+
+	if c.Dune.MaxRetries < 0 {
+		problem("dune.maxRetries", "DUNE_MAX_RETRIES", "must not be negative")
+	}
+
+	if c.Dune.PageSize < 0 {
+		problem("dune.pageSize", "DUNE_PAGE_SIZE", "must not be negative")
+	}
+
+	if c.Dune.PollInterval <= 0 {
+		problem("dune.pollInterval", "DUNE_POLL_INTERVAL", "must be positive")
+	}
+
+	if c.Dune.AVSQueryID < 0 {
+		problem("dune.avsQueryId", "DUNE_AVS_QUERY_ID", "must not be negative")
+	}
+
+	// Metadata
+	switch strings.ToLower(c.Metadata.Cache) {
+	case "memory":
+	case "redis":
+		if c.Metadata.CacheLocation == "" {
+			problem("metadata.cacheLocation", "METADATA_CACHE_LOCATION", "is required for the redis cache")
+		}
+	default:
+		problem("metadata.cache", "METADATA_CACHE", "unknown metadata cache "+c.Metadata.Cache)
+	}
+
+	if c.Metadata.OperatorTTL <= 0 {
+		problem("metadata.operatorTTL", "METADATA_OPERATOR_TTL", "must be positive")
+	}
+
+	if c.Metadata.AVSTTL <= 0 {
+		problem("metadata.avsTTL", "METADATA_AVS_TTL", "must be positive")
+	}
+
+	if c.Metadata.NegativeTTL <= 0 {
+		problem("metadata.negativeTTL", "METADATA_NEGATIVE_TTL", "must be positive")
+	}
+
+	if c.Metadata.RPC != "" && !isURL(c.Metadata.RPC) {
+		problem("metadata.rpc", "METADATA_RPC", "must be an absolute http(s) or ws(s) URL")
+	}
+
+	if c.Metadata.RPC != "" && c.Metadata.LogRange == 0 {
+		problem("metadata.logRange", "METADATA_LOG_RANGE", "must be positive")
+	}
+
+	if !common.IsHexAddress(c.Metadata.DelegationManager) {
+		problem("metadata.delegationManager", "METADATA_DELEGATION_MANAGER", "invalid address "+c.Metadata.DelegationManager)
+	}
+
+	if !common.IsHexAddress(c.Metadata.AVSDirectory) {
+		problem("metadata.avsDirectory", "METADATA_AVS_DIRECTORY", "invalid address "+c.Metadata.AVSDirectory)
+	}
+
+	if !isURL(c.Metadata.IPFSGateway) {
+		problem("metadata.ipfsGateway", "METADATA_IPFS_GATEWAY", "must be an absolute http(s) URL")
+	}
+
+	if c.Metadata.OverrideFile != "" {
+		if _, sErr := os.Stat(c.Metadata.OverrideFile); sErr != nil {
+			problem("metadata.overrideFile", "METADATA_OVERRIDE_FILE", sErr.Error())
+		}
+	}
+
+	// Handlers
+	for name, address := range c.Handlers.Contracts {
+		if !common.IsHexAddress(address) {
+			problem("handlers.contracts", "HANDLER_CONTRACTS", "invalid address "+address+" for "+name)
+		}
+	}
+
+	// Dedup
+	switch strings.ToLower(c.Dedup.Store) {
+	case "memory":
+	case "file", "redis":
+		if c.Dedup.Location == "" {
+			problem("dedup.location", "DEDUP_LOCATION", "is required for the "+c.Dedup.Store+" store")
+		}
+	default:
+		problem("dedup.store", "DEDUP_STORE", "unknown dedup store "+c.Dedup.Store)
+	}
+
+	if c.Dedup.Retention <= 0 {
+		problem("dedup.retention", "DEDUP_RETENTION", "must be positive")
+	}
+
+	if c.Dedup.ClaimTTL <= 0 {
+		problem("dedup.claimTTL", "DEDUP_CLAIM_TTL", "must be positive")
+	}
+
+	// Reorg
+	if c.Reorg.RPC != "" && !isURL(c.Reorg.RPC) {
+		problem("reorg.rpc", "REORG_RPC", "must be an absolute http(s) or ws(s) URL")
+	}
+
+	if c.Reorg.CheckInterval <= 0 {
+		problem("reorg.checkInterval", "REORG_CHECK_INTERVAL", "must be positive")
+	}
+
+	// Deferred
+	if c.Deferred.MinDelay <= 0 {
+		problem("deferred.minDelay", "DEFERRED_MIN_DELAY", "must be positive")
+	}
+
+	if c.Deferred.MaxDelay < c.Deferred.MinDelay {
+		problem("deferred.maxDelay", "DEFERRED_MAX_DELAY", "must not be less than DEFERRED_MIN_DELAY")
+	}
+
+	if c.Deferred.MaxAge <= 0 {
+		problem("deferred.maxAge", "DEFERRED_MAX_AGE", "must be positive")
+	}
+
+	if c.Deferred.CheckInterval <= 0 {
+		problem("deferred.checkInterval", "DEFERRED_CHECK_INTERVAL", "must be positive")
+	}
+
+	// Scoring
+	if c.Scoring.Interval <= 0 {
+		problem("scoring.interval", "SCORING_INTERVAL", "must be positive")
+	}
+
+	if c.Scoring.VelocityBlocks == 0 {
+		problem("scoring.velocityBlocks", "SCORING_VELOCITY_BLOCKS", "must be positive")
+	}
+
+	if c.Scoring.RetentionBlocks < c.Scoring.VelocityBlocks {
+		problem("scoring.retentionBlocks", "SCORING_RETENTION_BLOCKS", "must not be below scoring.velocityBlocks")
+	}
+
+	for component, weight := range c.Scoring.Weights {
+		if value, pErr := strconv.ParseFloat(weight, 64); pErr != nil || value < 0 {
+			problem("scoring.weights", "SCORING_WEIGHTS", "weight of "+component+" must be a non-negative number")
+		}
+	}
+
+	// Snapshots
+	if c.Snapshots.Retention < 0 {
+		problem("snapshots.retention", "SNAPSHOT_RETENTION", "must not be negative, 0 keeps every snapshot")
+	}
+
+	if c.Snapshots.CompactAfter < 0 {
+		problem("snapshots.compactAfter", "SNAPSHOT_COMPACT_AFTER", "must not be negative, 0 disables the compaction")
+	}
+
+	if c.Snapshots.CompactAfter > 0 && c.Snapshots.CompactResolution <= 0 {
+		problem("snapshots.compactResolution", "SNAPSHOT_COMPACT_RESOLUTION", "must be positive")
+	}
+
+	if c.Snapshots.File != "" && c.Snapshots.MaintenanceInterval <= 0 {
+		problem("snapshots.maintenanceInterval", "SNAPSHOT_MAINTENANCE_INTERVAL", "must be positive")
+	}
+
+	// Registry index
+	if c.Index.File != "" && c.Index.LogRange == 0 {
+		problem("registryIndex.logRange", "REGISTRY_INDEX_LOG_RANGE", "must be positive")
+	}
+
+	if c.Index.ReconcileInterval < 0 {
+		problem("registryIndex.reconcileInterval", "REGISTRY_RECONCILE_INTERVAL",
+			"must not be negative, 0 disables the reconciliation")
+	}
+
+	// Limits
+	if c.Limits.WorkerCount <= 0 {
+		problem("limits.workerCount", "WORKER_COUNT", "must be positive")
+	}
+
+	if c.Limits.WorkerQueueSize <= 0 {
+		problem("limits.workerQueueSize", "WORKER_QUEUE_SIZE", "must be positive")
+	}
+
+	if c.Limits.ShutdownTimeout <= 0 {
+		problem("limits.shutdownTimeout", "SHUTDOWN_TIMEOUT", "must be positive")
+	}
+
+	return problems
+}
+
+/*
+isURL returns true if the value is an absolute http(s) or ws(s) URL
+*/
+func isURL(value string) bool {
+	parsed, pErr := url.Parse(value)
+
+	if pErr != nil || parsed.Host == "" {
+		return false
+	}
+
+	switch parsed.Scheme {
+	case "http", "https", "ws", "wss":
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,40 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateRequiresTheRegistryRPC(t *testing.T) {
+	tests := []struct {
+		name     string
+		rpc      string
+		expected string
+	}{
+		{name: "default", rpc: "", expected: "registry.rpc (REGISTRY_RPC): is required"},
+		{name: "relative", rpc: "eth.example.org/rpc", expected: "registry.rpc (REGISTRY_RPC): must be an absolute"},
+		{name: "https", rpc: "https://eth.example.org/rpc"},
+		{name: "websocket", rpc: "wss://eth.example.org/ws"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cfg := Default()
+			cfg.Registry.RPC = test.rpc
+
+			vErr := cfg.ValidateOnly("registry.rpc")
+
+			if test.expected == "" {
+				if vErr != nil {
+					t.Fatal("expected the RPC to be accepted, got ", vErr)
+				}
+
+				return
+			}
+
+			if vErr == nil || !strings.HasPrefix(vErr.Error(), test.expected) {
+				t.Fatalf("expected %q, got %v", test.expected, vErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package consumer
+
+import (
+	"github.com/confluentinc/confluent-kafka-go/kafka"
+)
+
+/*
+NewManualCommitConsumer creates a kafka consumer subscribed to the given topics with auto commit disabled. Offsets of
+the consumed messages have to be committed by the caller after the processing is completed
+*/
+func NewManualCommitConsumer(kafkaURI string, topics []string, groupID string) (*kafka.Consumer, error) {
+	c, cErr := kafka.NewConsumer(&kafka.ConfigMap{
+		"bootstrap.servers":  kafkaURI,
+		"group.id":           groupID,
+		"auto.offset.reset":  "earliest",
+		"enable.auto.commit": false,
+	})
+
+	if cErr != nil {
+		return nil, cErr
+	}
+
+	sErr := c.SubscribeTopics(topics, nil)
+
+	if sErr != nil {
+		c.Close()
+		return nil, sErr
+	}
+
+	return c, nil
+}
+
+/*
+NewProducer creates a kafka producer connected to the given broker
+*/
+func NewProducer(kafkaURI string) (*kafka.Producer, error) {
+	return kafka.NewProducer(&kafka.ConfigMap{"bootstrap.servers": kafkaURI})
+}
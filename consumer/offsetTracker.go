@@ -0,0 +1,128 @@
+package consumer
+
+import (
+	"sync"
+
+	"github.com/confluentinc/confluent-kafka-go/kafka"
+)
+
+type partitionKey struct {
+	topic     string
+	partition int32
+}
+
+type partitionState struct {
+	pending   map[kafka.Offset]bool
+	committed kafka.Offset
+}
+
+/*
+OffsetTracker keeps track of the in-flight messages of every assigned partition. Since messages are processed
+concurrently they can finish in any order, so only the offset right after the lowest still pending message is safe to
+commit. Committing that way gives at-least-once delivery: anything that was not fully processed is read again after a
+restart
+*/
+type OffsetTracker struct {
+	mu         sync.Mutex
+	partitions map[partitionKey]*partitionState
+}
+
+/*
+NewOffsetTracker constructor for the OffsetTracker object
+*/
+func NewOffsetTracker() *OffsetTracker {
+	return &OffsetTracker{partitions: map[partitionKey]*partitionState{}}
+}
+
+/*
+Track registers the given message as in-flight. It has to be called before the message is handed to a processor
+*/
+func (oT *OffsetTracker) Track(tp kafka.TopicPartition) {
+	oT.mu.Lock()
+	defer oT.mu.Unlock()
+
+	key := partitionKey{topic: *tp.Topic, partition: tp.Partition}
+
+	state, isOk := oT.partitions[key]
+
+	if !isOk {
+		state = &partitionState{pending: map[kafka.Offset]bool{}, committed: kafka.OffsetInvalid}
+		oT.partitions[key] = state
+	}
+
+	// false -> still processing
+	state.pending[tp.Offset] = false
+}
+
+/*
+Done marks the given message as fully processed. If this unblocks the partition it returns the TopicPartition which
+should be committed (offset of the next message to be read) and true, else it returns false
+*/
+func (oT *OffsetTracker) Done(tp kafka.TopicPartition) (kafka.TopicPartition, bool) {
+	oT.mu.Lock()
+	defer oT.mu.Unlock()
+
+	key := partitionKey{topic: *tp.Topic, partition: tp.Partition}
+
+	state, isOk := oT.partitions[key]
+
+	if !isOk {
+		return kafka.TopicPartition{}, false
+	}
+
+	if _, isTracked := state.pending[tp.Offset]; !isTracked {
+		return kafka.TopicPartition{}, false
+	}
+
+	state.pending[tp.Offset] = true
+
+	// Find the lowest offset that is still being processed
+	lowestPending := kafka.OffsetInvalid
+	highestDone := kafka.OffsetInvalid
+
+	for offset, isDone := range state.pending {
+		if !isDone && (lowestPending == kafka.OffsetInvalid || offset < lowestPending) {
+			lowestPending = offset
+		}
+	}
+
+	// Remove every finished offset below the lowest pending one, those are safe to commit
+	for offset, isDone := range state.pending {
+		if isDone && (lowestPending == kafka.OffsetInvalid || offset < lowestPending) {
+			if offset > highestDone {
+				highestDone = offset
+			}
+			delete(state.pending, offset)
+		}
+	}
+
+	if highestDone == kafka.OffsetInvalid || highestDone+1 <= state.committed {
+		return kafka.TopicPartition{}, false
+	}
+
+	state.committed = highestDone + 1
+
+	topic := key.topic
+
+	return kafka.TopicPartition{Topic: &topic, Partition: key.partition, Offset: state.committed}, true
+}
+
+/*
+Pending returns the total number of in-flight messages over all partitions
+*/
+func (oT *OffsetTracker) Pending() int {
+	oT.mu.Lock()
+	defer oT.mu.Unlock()
+
+	total := 0
+
+	for _, state := range oT.partitions {
+		for _, isDone := range state.pending {
+			if !isDone {
+				total++
+			}
+		}
+	}
+
+	return total
+}
@@ -0,0 +1,64 @@
+package consumer
+
+import (
+	"github.com/confluentinc/confluent-kafka-go/kafka"
+	"testing"
+)
+
+func partition(topic string, index int32, offset kafka.Offset) kafka.TopicPartition {
+	return kafka.TopicPartition{Topic: &topic, Partition: index, Offset: offset}
+}
+
+func TestOffsetTrackerCommitsAfterTheLowestPendingMessage(t *testing.T) {
+	tracker := NewOffsetTracker()
+
+	for offset := kafka.Offset(10); offset <= 12; offset++ {
+		tracker.Track(partition("events", 0, offset))
+	}
+
+	tracker.Track(partition("events", 1, 5))
+
+	// The message 10 is still processed, nothing can be committed yet
+	if _, isCommittable := tracker.Done(partition("events", 0, 11)); isCommittable {
+		t.Fatal("committed past the pending offset 10")
+	}
+
+	commit, isCommittable := tracker.Done(partition("events", 0, 10))
+	if !isCommittable || commit.Offset != 12 || commit.Partition != 0 || *commit.Topic != "events" {
+		t.Fatalf("expected events[0]@12 to be committed, got %v (%v)", commit, isCommittable)
+	}
+
+	if pending := tracker.Pending(); pending != 2 {
+		t.Fatalf("expected 2 pending messages, got %d", pending)
+	}
+
+	// The partitions are tracked independently
+	if commit, isCommittable = tracker.Done(partition("events", 1, 5)); !isCommittable || commit.Offset != 6 {
+		t.Fatalf("expected events[1]@6 to be committed, got %v (%v)", commit, isCommittable)
+	}
+
+	if commit, isCommittable = tracker.Done(partition("events", 0, 12)); !isCommittable || commit.Offset != 13 {
+		t.Fatalf("expected events[0]@13 to be committed, got %v (%v)", commit, isCommittable)
+	}
+
+	if pending := tracker.Pending(); pending != 0 {
+		t.Fatalf("expected no pending message, got %d", pending)
+	}
+}
+
+func TestOffsetTrackerIgnoresUntrackedMessages(t *testing.T) {
+	tracker := NewOffsetTracker()
+	tracker.Track(partition("events", 0, 1))
+
+	if _, isCommittable := tracker.Done(partition("events", 0, 2)); isCommittable {
+		t.Fatal("committed an untracked offset")
+	}
+
+	if _, isCommittable := tracker.Done(partition("other", 0, 1)); isCommittable {
+		t.Fatal("committed an offset of an untracked partition")
+	}
+
+	if pending := tracker.Pending(); pending != 1 {
+		t.Fatalf("expected the tracked message to stay pending, got %d", pending)
+	}
+}
@@ -0,0 +1,158 @@
+package consumer
+
+import (
+	"context"
+	"eigenlayer_hack/metrics"
+	"errors"
+	"hash/fnv"
+	"strconv"
+	"sync"
+	"sync/atomic"
+)
+
+/*
+WorkerPool runs the submitted jobs on a fixed number of workers. Every worker owns a bounded queue and jobs are
+assigned to the workers by hashing their key, so the jobs with the same key (e.g. same operator) are executed one after
+another in submission order while the different keys are processed in parallel
+*/
+type WorkerPool struct {
+	queues  []chan func()
+	depths  []int64
+	total   int64
+	wg      sync.WaitGroup
+	closeMu sync.RWMutex
+	closed  bool
+}
+
+var ErrPoolClosed = errors.New("worker pool is closed")
+
+/*
+NewWorkerPool constructor for the WorkerPool object, starts workerCount workers each having a queue of queueSize jobs
+*/
+func NewWorkerPool(workerCount int, queueSize int) *WorkerPool {
+	if workerCount <= 0 {
+		workerCount = 1
+	}
+
+	if queueSize <= 0 {
+		queueSize = 1
+	}
+
+	wp := &WorkerPool{
+		queues: make([]chan func(), workerCount),
+		depths: make([]int64, workerCount),
+	}
+
+	for i := range wp.queues {
+		wp.queues[i] = make(chan func(), queueSize)
+		metrics.WorkerQueueDepthPerWorker.Add(strconv.Itoa(i), 0)
+
+		wp.wg.Add(1)
+		go wp.work(i)
+	}
+
+	return wp
+}
+
+func (wp *WorkerPool) work(idx int) {
+	defer wp.wg.Done()
+
+	for job := range wp.queues[idx] {
+		wp.updateDepth(idx, -1)
+		job()
+	}
+}
+
+func (wp *WorkerPool) indexOf(key string) int {
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(key))
+
+	return int(hash.Sum32() % uint32(len(wp.queues)))
+}
+
+func (wp *WorkerPool) updateDepth(idx int, delta int64) {
+	atomic.AddInt64(&wp.depths[idx], delta)
+	atomic.AddInt64(&wp.total, delta)
+
+	metrics.WorkerQueueDepthPerWorker.Add(strconv.Itoa(idx), delta)
+	metrics.WorkerQueueDepth.Add(delta)
+}
+
+/*
+TrySubmit queues the job on the worker that owns the key without blocking, returns false if that queue is full
+*/
+func (wp *WorkerPool) TrySubmit(key string, job func()) (bool, error) {
+	wp.closeMu.RLock()
+	defer wp.closeMu.RUnlock()
+
+	if wp.closed {
+		return false, ErrPoolClosed
+	}
+
+	idx := wp.indexOf(key)
+
+	select {
+	case wp.queues[idx] <- job:
+		wp.updateDepth(idx, 1)
+		return true, nil
+	default:
+		return false, nil
+	}
+}
+
+/*
+Submit queues the job on the worker that owns the key, blocks until there is space in the queue or the context is done
+*/
+func (wp *WorkerPool) Submit(ctx context.Context, key string, job func()) error {
+	wp.closeMu.RLock()
+	defer wp.closeMu.RUnlock()
+
+	if wp.closed {
+		return ErrPoolClosed
+	}
+
+	idx := wp.indexOf(key)
+
+	select {
+	case wp.queues[idx] <- job:
+		wp.updateDepth(idx, 1)
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+/*
+HasRoom returns true when the queue of the worker that owns the key is at most half full
+*/
+func (wp *WorkerPool) HasRoom(key string) bool {
+	idx := wp.indexOf(key)
+
+	return atomic.LoadInt64(&wp.depths[idx])*2 <= int64(cap(wp.queues[idx]))
+}
+
+/*
+Depth returns the total number of jobs waiting in the queues
+*/
+func (wp *WorkerPool) Depth() int64 {
+	return atomic.LoadInt64(&wp.total)
+}
+
+/*
+Close stops accepting new jobs and waits until the already queued jobs are executed
+*/
+func (wp *WorkerPool) Close() {
+	wp.closeMu.Lock()
+
+	if !wp.closed {
+		wp.closed = true
+
+		for _, queue := range wp.queues {
+			close(queue)
+		}
+	}
+
+	wp.closeMu.Unlock()
+
+	wp.wg.Wait()
+}
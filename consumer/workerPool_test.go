@@ -0,0 +1,97 @@
+package consumer
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWorkerPoolRunsTheJobsOfAKeyInOrder(t *testing.T) {
+	pool := NewWorkerPool(4, 100)
+
+	var mu sync.Mutex
+	order := map[string][]int{}
+
+	for index := 0; index < 50; index++ {
+		key := "operator-" + strconv.Itoa(index%3)
+		job := index
+
+		if sErr := pool.Submit(context.Background(), key, func() {
+			mu.Lock()
+			defer mu.Unlock()
+
+			order[key] = append(order[key], job)
+		}); sErr != nil {
+			t.Fatal(sErr)
+		}
+	}
+
+	// Close waits for the queued jobs
+	pool.Close()
+
+	for key, jobs := range order {
+		for index := 1; index < len(jobs); index++ {
+			if jobs[index] < jobs[index-1] {
+				t.Fatalf("jobs of %s ran out of order: %v", key, jobs)
+			}
+		}
+	}
+
+	if len(order) != 3 {
+		t.Fatalf("expected the jobs of 3 keys, got %d", len(order))
+	}
+}
+
+func TestWorkerPoolReportsAFullQueue(t *testing.T) {
+	pool := NewWorkerPool(1, 1)
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+
+	// The worker is busy with the first job, the second one waits in the queue
+	if sErr := pool.Submit(context.Background(), "a", func() {
+		close(started)
+		<-release
+	}); sErr != nil {
+		t.Fatal(sErr)
+	}
+
+	<-started
+
+	if isSubmitted, sErr := pool.TrySubmit("a", func() {}); !isSubmitted || sErr != nil {
+		t.Fatalf("expected the job to be queued, got %v (%v)", isSubmitted, sErr)
+	}
+
+	if isSubmitted, _ := pool.TrySubmit("a", func() {}); isSubmitted {
+		t.Fatal("expected the full queue to refuse the job")
+	}
+
+	if pool.Depth() != 1 {
+		t.Fatalf("expected a queue depth of 1, got %d", pool.Depth())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if sErr := pool.Submit(ctx, "a", func() {}); !errors.Is(sErr, context.DeadlineExceeded) {
+		t.Fatalf("expected Submit to give up with the context, got %v", sErr)
+	}
+
+	close(release)
+	pool.Close()
+
+	if pool.Depth() != 0 {
+		t.Fatalf("expected the queues to be drained, got a depth of %d", pool.Depth())
+	}
+
+	if sErr := pool.Submit(context.Background(), "a", func() {}); !errors.Is(sErr, ErrPoolClosed) {
+		t.Fatalf("expected ErrPoolClosed, got %v", sErr)
+	}
+
+	if _, sErr := pool.TrySubmit("a", func() {}); !errors.Is(sErr, ErrPoolClosed) {
+		t.Fatalf("expected ErrPoolClosed, got %v", sErr)
+	}
+}
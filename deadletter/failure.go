@@ -0,0 +1,52 @@
+package deadletter
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Processing stages that can fail, they are written to the StageHeader of the dead-lettered message
+const (
+	StageUnmarshal  = "unmarshal"
+	StageDecode     = "decode"
+	StageEnrichment = "enrichment"
+	StageWrite      = "write"
+	StagePublish    = "publish"
+	StageDedup      = "dedup"
+)
+
+/*
+Failure is an error that carries the processing stage it happened in
+*/
+type Failure struct {
+	Stage string
+	Err   error
+}
+
+/*
+NewFailure wraps the given error with the stage information
+*/
+func NewFailure(stage string, err error) *Failure {
+	return &Failure{Stage: stage, Err: err}
+}
+
+func (f *Failure) Error() string {
+	return fmt.Sprintf("%s: %v", f.Stage, f.Err)
+}
+
+func (f *Failure) Unwrap() error {
+	return f.Err
+}
+
+/*
+StageOf returns the stage of the first Failure found in the error chain, "unknown" if there is none
+*/
+func StageOf(err error) string {
+	var failure *Failure
+
+	if errors.As(err, &failure) {
+		return failure.Stage
+	}
+
+	return "unknown"
+}
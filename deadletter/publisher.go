@@ -0,0 +1,84 @@
+package deadletter
+
+import (
+	"context"
+	"eigenlayer_hack/stream"
+	"strconv"
+	"time"
+)
+
+// Headers attached to the dead-lettered messages
+const (
+	StageHeader             = "dlq-stage"
+	ErrorHeader             = "dlq-error"
+	AttemptsHeader          = "dlq-attempts"
+	OriginalTopicHeader     = "dlq-original-topic"
+	OriginalPartitionHeader = "dlq-original-partition"
+	OriginalOffsetHeader    = "dlq-original-offset"
+	FailedAtHeader          = "dlq-failed-at"
+)
+
+/*
+Publisher forwards the failed messages of the listen channels to their dead-letter channels
+*/
+type Publisher struct {
+	sink stream.Sink
+	// listen channel -> dead-letter channel
+	channelMapping map[string]string
+}
+
+/*
+NewPublisher constructor for the Publisher object. channelMapping maps every listen channel to its dead-letter channel,
+messages of the channels that are not in the mapping are dropped as before
+*/
+func NewPublisher(sink stream.Sink, channelMapping map[string]string) *Publisher {
+	return &Publisher{sink: sink, channelMapping: channelMapping}
+}
+
+/*
+Attempts returns the number of times the message has been processed before, based on the AttemptsHeader
+*/
+func Attempts(msg *stream.Message) int {
+	attempts, err := strconv.Atoi(msg.Headers[AttemptsHeader])
+
+	if err != nil {
+		return 0
+	}
+
+	return attempts
+}
+
+/*
+Publish sends the failed message together with the failure metadata to the dead-letter channel of its channel and waits
+for the delivery. Returns false if no dead-letter channel is configured for the channel
+*/
+func (p *Publisher) Publish(ctx context.Context, msg *stream.Message, failure error) (bool, error) {
+	if p == nil {
+		return false, nil
+	}
+
+	dlqChannel, isOk := p.channelMapping[msg.Channel]
+
+	if !isOk || dlqChannel == "" {
+		return false, nil
+	}
+
+	dlqMessage := stream.NewMessage(dlqChannel, msg.Value)
+	dlqMessage.Key = msg.Key
+
+	// Keep the headers that are not related with a previous failure
+	for key, value := range msg.Headers {
+		dlqMessage.Headers[key] = value
+	}
+
+	dlqMessage.Headers[StageHeader] = StageOf(failure)
+	dlqMessage.Headers[ErrorHeader] = failure.Error()
+	dlqMessage.Headers[AttemptsHeader] = strconv.Itoa(Attempts(msg) + 1)
+	dlqMessage.Headers[OriginalTopicHeader] = msg.Channel
+	dlqMessage.Headers[OriginalPartitionHeader] = strconv.Itoa(int(msg.Partition))
+	dlqMessage.Headers[OriginalOffsetHeader] = strconv.FormatInt(msg.Offset, 10)
+	dlqMessage.Headers[FailedAtHeader] = time.Now().UTC().Format(time.RFC3339)
+
+	// Wait for the delivery, the original message is committed only after the message is safely stored
+	return true, p.sink.Send(ctx, dlqMessage)
+}
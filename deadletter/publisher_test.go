@@ -0,0 +1,73 @@
+package deadletter
+
+import (
+	"context"
+	"eigenlayer_hack/stream"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestPublishForwardsTheFailureToTheDeadLetterChannel(t *testing.T) {
+	failed := stream.NewMessage("events", []byte(`{"event":"OperatorRegistered"}`))
+	failed.Key = []byte("0xoperator")
+	failed.Partition = 2
+	failed.Offset = 41
+	failed.Headers["trace-id"] = "abc"
+	// Left by a previous failure, they are replaced
+	failed.Headers[StageHeader] = StageUnmarshal
+	failed.Headers[AttemptsHeader] = "1"
+
+	sink := stream.NewMemorySink()
+	publisher := NewPublisher(sink, map[string]string{"events": "events-dlq"})
+
+	isPublished, pErr := publisher.Publish(context.Background(), failed, fmt.Errorf("handling: %w",
+		NewFailure(StageDecode, errors.New("unknown signature"))))
+	if !isPublished || pErr != nil {
+		t.Fatalf("expected the message to be dead-lettered, got %v (%v)", isPublished, pErr)
+	}
+
+	messages := sink.Messages("events-dlq")
+
+	if len(messages) != 1 {
+		t.Fatalf("expected a single dead-lettered message, got %d", len(messages))
+	}
+
+	msg := messages[0]
+
+	if string(msg.Key) != "0xoperator" || string(msg.Value) != `{"event":"OperatorRegistered"}` {
+		t.Fatalf("unexpected dead-lettered message %s=%s", msg.Key, msg.Value)
+	}
+
+	expected := map[string]string{
+		"trace-id":              "abc",
+		StageHeader:             StageDecode,
+		ErrorHeader:             "handling: decode: unknown signature",
+		AttemptsHeader:          "2",
+		OriginalTopicHeader:     "events",
+		OriginalPartitionHeader: "2",
+		OriginalOffsetHeader:    "41",
+	}
+
+	for key, value := range expected {
+		if header := msg.Headers[key]; header != value {
+			t.Errorf("expected the header %s to be %q, got %q", key, value, header)
+		}
+	}
+
+	// The original message is left untouched
+	if failed.Headers[AttemptsHeader] != "1" {
+		t.Fatalf("expected the original headers to be kept, got %v", failed.Headers)
+	}
+}
+
+func TestPublishSkipsTheChannelsWithoutDeadLetterChannel(t *testing.T) {
+	// The sink is not used for the unmapped channels
+	publisher := NewPublisher(nil, map[string]string{"events": "events-dlq"})
+
+	isPublished, pErr := publisher.Publish(context.Background(), stream.NewMessage("other", nil),
+		errors.New("failed"))
+	if isPublished || pErr != nil {
+		t.Fatalf("expected the message to be dropped, got %v (%v)", isPublished, pErr)
+	}
+}
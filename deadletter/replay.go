@@ -0,0 +1,65 @@
+package deadletter
+
+import (
+	"context"
+	"eigenlayer_hack/stream"
+	"errors"
+	"solity/utils/logger"
+	"time"
+)
+
+/*
+Replay reads the messages of the dead-letter source and sends them back to their original channel, so that they go
+through the pipeline again. The failure metadata is kept on the message, thus the attempt count keeps increasing if the
+message fails again. Every replayed message is committed on the source, so it is not replayed twice. Returns when the
+context is cancelled, the source is exhausted or no message arrives for idleTimeout (0 means wait forever)
+*/
+func Replay(ctx context.Context, source stream.Source, sink stream.Sink, idleTimeout time.Duration) (replayed int, err error) {
+	for ctx.Err() == nil {
+		readCtx, cancel := ctx, context.CancelFunc(func() {})
+
+		if idleTimeout > 0 {
+			readCtx, cancel = context.WithTimeout(ctx, idleTimeout)
+		}
+
+		msg, rErr := source.Read(readCtx)
+		cancel()
+
+		if rErr != nil {
+			if errors.Is(rErr, stream.ErrClosed) || errors.Is(rErr, context.DeadlineExceeded) || ctx.Err() != nil {
+				return
+			}
+
+			err = rErr
+			return
+		}
+
+		originalTopic := msg.Headers[OriginalTopicHeader]
+
+		if originalTopic == "" {
+			logger.LogW("Dead-lettered message has no original topic, skipping: ", msg.Channel, " ", msg.Offset)
+			_ = source.Commit(msg)
+			continue
+		}
+
+		replayMessage := stream.NewMessage(originalTopic, msg.Value)
+		replayMessage.Key = msg.Key
+		replayMessage.Headers = msg.Headers
+
+		if sErr := sink.Send(ctx, replayMessage); sErr != nil {
+			err = sErr
+			return
+		}
+
+		if cErr := source.Commit(msg); cErr != nil {
+			err = cErr
+			return
+		}
+
+		logger.LogIf("Replayed message failed at [%s] to %s (attempts: %d)", msg.Headers[StageHeader], originalTopic,
+			Attempts(msg))
+		replayed++
+	}
+
+	return
+}
@@ -0,0 +1,71 @@
+package deadletter
+
+import (
+	"context"
+	"eigenlayer_hack/stream"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestReplaySendsTheMessagesBackToTheirOriginalChannel(t *testing.T) {
+	ctx := context.Background()
+	dlqSink := stream.NewMemorySink()
+
+	failed := stream.NewMessage("events", []byte("payload"))
+	failed.Offset = 7
+
+	if _, pErr := NewPublisher(dlqSink, map[string]string{"events": "events-dlq"}).Publish(ctx, failed,
+		NewFailure(StageWrite, errors.New("reverted"))); pErr != nil {
+		t.Fatal(pErr)
+	}
+
+	source := stream.NewMemorySource(2)
+
+	if pErr := source.Push(ctx, dlqSink.Messages("events-dlq")[0]); pErr != nil {
+		t.Fatal(pErr)
+	}
+
+	// Without the original topic the message cannot be replayed, it is skipped
+	if pErr := source.Push(ctx, stream.NewMessage("events-dlq", []byte("orphan"))); pErr != nil {
+		t.Fatal(pErr)
+	}
+
+	_ = source.Close()
+
+	sink := stream.NewMemorySink()
+
+	replayed, rErr := Replay(ctx, source, sink, time.Second)
+	if rErr != nil {
+		t.Fatal(rErr)
+	}
+
+	if replayed != 1 {
+		t.Fatalf("expected a single replayed message, got %d", replayed)
+	}
+
+	// Both messages are committed, the skipped one too, so they are not read again
+	if committed := source.Committed(); len(committed) != 2 {
+		t.Fatalf("expected the 2 dead-lettered messages to be committed, got %d", len(committed))
+	}
+
+	messages := sink.Messages("events")
+
+	if len(messages) != 1 || string(messages[0].Value) != "payload" {
+		t.Fatalf("expected the payload to be replayed, got %v", messages)
+	}
+
+	// The failure metadata is kept, a new failure counts the next attempt
+	if stage := messages[0].Headers[StageHeader]; stage != StageWrite || Attempts(messages[0]) != 1 {
+		t.Fatalf("expected the failure metadata of the write stage, got %s after %d attempts", stage,
+			Attempts(messages[0]))
+	}
+}
+
+func TestReplayStopsAfterTheIdleTimeout(t *testing.T) {
+	replayed, rErr := Replay(context.Background(), stream.NewMemorySource(1), stream.NewMemorySink(),
+		20*time.Millisecond)
+	if rErr != nil || replayed != 0 {
+		t.Fatalf("expected the idle replay to stop without messages, got %d (%v)", replayed, rErr)
+	}
+}
@@ -0,0 +1,165 @@
+package dedup
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"time"
+)
+
+type fileEntry struct {
+	Key    string `json:"key"`
+	Record Record `json:"record"`
+}
+
+/*
+FileStore is a Store that keeps the records in memory and appends every change to a JSON lines file, the file is read
+back on start so the records survive restarts. The file is compacted when it has grown to twice the live records
+*/
+type FileStore struct {
+	*MemoryStore
+	path    string
+	file    *os.File
+	entries int
+}
+
+/*
+NewFileStore constructor for the FileStore object, loads the existing records of the file at the given path
+*/
+func NewFileStore(path string, retention time.Duration) (*FileStore, error) {
+	if path == "" {
+		return nil, errors.New("dedup file path is empty")
+	}
+
+	fS := &FileStore{MemoryStore: NewMemoryStore(retention), path: path}
+
+	if lErr := fS.load(); lErr != nil {
+		return nil, lErr
+	}
+
+	// Start with a compacted file
+	if cErr := fS.compact(); cErr != nil {
+		return nil, cErr
+	}
+
+	return fS, nil
+}
+
+func (fS *FileStore) load() error {
+	file, oErr := os.Open(fS.path)
+
+	if errors.Is(oErr, os.ErrNotExist) {
+		return nil
+	}
+
+	if oErr != nil {
+		return oErr
+	}
+
+	defer file.Close()
+
+	now := time.Now()
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		entry := fileEntry{}
+
+		// A partially written last line is skipped
+		if uErr := json.Unmarshal(scanner.Bytes(), &entry); uErr != nil {
+			continue
+		}
+
+		if !fS.expired(entry.Record, now) {
+			fS.records[entry.Key] = entry.Record
+		}
+	}
+
+	return scanner.Err()
+}
+
+/*
+compact rewrites the file with only the live records
+*/
+func (fS *FileStore) compact() error {
+	fS.prune(time.Now())
+
+	tmpPath := fS.path + ".tmp"
+	tmpFile, cErr := os.Create(tmpPath)
+
+	if cErr != nil {
+		return cErr
+	}
+
+	writer := bufio.NewWriter(tmpFile)
+	encoder := json.NewEncoder(writer)
+
+	for key, record := range fS.records {
+		if eErr := encoder.Encode(fileEntry{Key: key, Record: record}); eErr != nil {
+			tmpFile.Close()
+			return eErr
+		}
+	}
+
+	if fErr := writer.Flush(); fErr != nil {
+		tmpFile.Close()
+		return fErr
+	}
+
+	if cErr := tmpFile.Close(); cErr != nil {
+		return cErr
+	}
+
+	if fS.file != nil {
+		_ = fS.file.Close()
+	}
+
+	if rErr := os.Rename(tmpPath, fS.path); rErr != nil {
+		return rErr
+	}
+
+	file, oErr := os.OpenFile(fS.path, os.O_APPEND|os.O_WRONLY, 0o644)
+
+	if oErr != nil {
+		return oErr
+	}
+
+	fS.file = file
+	fS.entries = len(fS.records)
+
+	return nil
+}
+
+func (fS *FileStore) Put(_ context.Context, key Key, outcome Outcome) error {
+	fS.mu.Lock()
+	defer fS.mu.Unlock()
+
+	record := Record{Outcome: outcome, UpdatedAt: time.Now()}
+
+	line, mErr := json.Marshal(fileEntry{Key: key.String(), Record: record})
+
+	if mErr != nil {
+		return mErr
+	}
+
+	if _, wErr := fS.file.Write(append(line, '\n')); wErr != nil {
+		return wErr
+	}
+
+	fS.put(key.String(), record)
+	fS.entries++
+
+	if fS.entries > 2*len(fS.records)+1000 {
+		return fS.compact()
+	}
+
+	return nil
+}
+
+func (fS *FileStore) Close() error {
+	fS.mu.Lock()
+	defer fS.mu.Unlock()
+
+	return fS.file.Close()
+}
@@ -0,0 +1,108 @@
+package dedup
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+/*
+MemoryStore is a Store that keeps the records in the process memory, the records are lost on restart
+*/
+type MemoryStore struct {
+	mu      sync.Mutex
+	records map[string]Record
+	// claims holds the expiry of the claimed keys
+	claims    map[string]time.Time
+	retention time.Duration
+	lastPrune time.Time
+}
+
+/*
+NewMemoryStore constructor for the MemoryStore object, a retention <= 0 keeps the records forever
+*/
+func NewMemoryStore(retention time.Duration) *MemoryStore {
+	return &MemoryStore{records: map[string]Record{}, claims: map[string]time.Time{}, retention: retention,
+		lastPrune: time.Now()}
+}
+
+func (mS *MemoryStore) expired(record Record, now time.Time) bool {
+	return mS.retention > 0 && now.Sub(record.UpdatedAt) > mS.retention
+}
+
+func (mS *MemoryStore) Get(_ context.Context, key Key) (Record, bool, error) {
+	mS.mu.Lock()
+	defer mS.mu.Unlock()
+
+	record, isOk := mS.records[key.String()]
+
+	if !isOk || mS.expired(record, time.Now()) {
+		return Record{}, false, nil
+	}
+
+	return record, true, nil
+}
+
+func (mS *MemoryStore) Put(_ context.Context, key Key, outcome Outcome) error {
+	mS.mu.Lock()
+	defer mS.mu.Unlock()
+
+	mS.put(key.String(), Record{Outcome: outcome, UpdatedAt: time.Now()})
+
+	return nil
+}
+
+func (mS *MemoryStore) Claim(_ context.Context, key Key, ttl time.Duration) (bool, error) {
+	mS.mu.Lock()
+	defer mS.mu.Unlock()
+
+	now := time.Now()
+
+	if expiry, isClaimed := mS.claims[key.String()]; isClaimed && now.Before(expiry) {
+		return false, nil
+	}
+
+	mS.claims[key.String()] = now.Add(ttl)
+
+	return true, nil
+}
+
+func (mS *MemoryStore) Release(_ context.Context, key Key) error {
+	mS.mu.Lock()
+	defer mS.mu.Unlock()
+
+	delete(mS.claims, key.String())
+
+	return nil
+}
+
+func (mS *MemoryStore) put(key string, record Record) {
+	mS.records[key] = record
+
+	// Prune the expired records from time to time so that the map does not grow forever
+	now := time.Now()
+
+	if mS.retention > 0 && now.Sub(mS.lastPrune) > mS.retention/10 {
+		mS.prune(now)
+	}
+}
+
+func (mS *MemoryStore) prune(now time.Time) {
+	for key, record := range mS.records {
+		if mS.expired(record, now) {
+			delete(mS.records, key)
+		}
+	}
+
+	for key, expiry := range mS.claims {
+		if now.After(expiry) {
+			delete(mS.claims, key)
+		}
+	}
+
+	mS.lastPrune = now
+}
+
+func (mS *MemoryStore) Close() error {
+	return nil
+}
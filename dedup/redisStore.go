@@ -0,0 +1,105 @@
+package dedup
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/go-redis/redis/v8"
+	"os"
+	"time"
+)
+
+const (
+	redisKeyPrefix   = "eigenlayer:dedup:"
+	redisClaimPrefix = "eigenlayer:dedup:claim:"
+)
+
+// releaseScript deletes the claim only if it is still held by the owner, an expired claim may have been taken over
+var releaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+
+/*
+RedisStore is a Store backed by redis, so that the records are shared by every replica. The retention is applied with
+the expiration of the redis keys
+*/
+type RedisStore struct {
+	client    *redis.Client
+	retention time.Duration
+	// owner identifies the claims of this store
+	owner string
+}
+
+/*
+NewRedisStore constructor for the RedisStore object, checks the connection before returning
+*/
+func NewRedisStore(addr string, password string, retention time.Duration) (*RedisStore, error) {
+	client := redis.NewClient(&redis.Options{Addr: addr, Password: password})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if pErr := client.Ping(ctx).Err(); pErr != nil {
+		_ = client.Close()
+		return nil, pErr
+	}
+
+	hostname, _ := os.Hostname()
+
+	return &RedisStore{client: client, retention: retention,
+		owner: fmt.Sprintf("%s-%d-%d", hostname, os.Getpid(), time.Now().UnixNano())}, nil
+}
+
+func (rS *RedisStore) Get(ctx context.Context, key Key) (Record, bool, error) {
+	value, gErr := rS.client.Get(ctx, redisKeyPrefix+key.String()).Bytes()
+
+	if gErr == redis.Nil {
+		return Record{}, false, nil
+	}
+
+	if gErr != nil {
+		return Record{}, false, gErr
+	}
+
+	record := Record{}
+
+	if uErr := json.Unmarshal(value, &record); uErr != nil {
+		return Record{}, false, uErr
+	}
+
+	return record, true, nil
+}
+
+func (rS *RedisStore) Put(ctx context.Context, key Key, outcome Outcome) error {
+	value, mErr := json.Marshal(Record{Outcome: outcome, UpdatedAt: time.Now()})
+
+	if mErr != nil {
+		return mErr
+	}
+
+	// 0 expiration keeps the key forever
+	expiration := rS.retention
+
+	if expiration < 0 {
+		expiration = 0
+	}
+
+	return rS.client.Set(ctx, redisKeyPrefix+key.String(), value, expiration).Err()
+}
+
+/*
+Claim sets the in-progress marker of the key with SET NX, so that of the replicas racing for a key only one wins
+*/
+func (rS *RedisStore) Claim(ctx context.Context, key Key, ttl time.Duration) (bool, error) {
+	return rS.client.SetNX(ctx, redisClaimPrefix+key.String(), rS.owner, ttl).Result()
+}
+
+func (rS *RedisStore) Release(ctx context.Context, key Key) error {
+	return releaseScript.Run(ctx, rS.client, []string{redisClaimPrefix + key.String()}, rS.owner).Err()
+}
+
+func (rS *RedisStore) Close() error {
+	return rS.client.Close()
+}
@@ -0,0 +1,91 @@
+package dedup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"strings"
+	"time"
+)
+
+// Outcome of the processing of a single event log
+type Outcome string
+
+const (
+	// OutcomeWritten the event is written to the registry contract but it is not published yet
+	OutcomeWritten Outcome = "written"
+	// OutcomeCompleted the event is written and published, it must not be processed again
+	OutcomeCompleted Outcome = "completed"
+	// OutcomeFailed the processing has failed, the event can be processed again (e.g. after a dead-letter replay)
+	OutcomeFailed Outcome = "failed"
+	// OutcomeRetracted the block of the event has been orphaned, the event is processed again if it is re-included
+	OutcomeRetracted Outcome = "retracted"
+)
+
+/*
+Key identifies a single event log over all chains
+*/
+type Key struct {
+	ChainID  uint64
+	TxHash   common.Hash
+	LogIndex uint
+}
+
+func (k Key) String() string {
+	return fmt.Sprintf("%d:%s:%d", k.ChainID, strings.ToLower(k.TxHash.Hex()), k.LogIndex)
+}
+
+/*
+Record is the stored state of a processed event log
+*/
+type Record struct {
+	Outcome   Outcome   `json:"outcome"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+/*
+Store keeps the processing outcome of the event logs, so that redelivered or restarted messages do not cause duplicate
+contract writes. Records older than the retention of the store are forgotten
+*/
+type Store interface {
+	/*
+		Get returns the record of the given key and true, false if the key has not been recorded (or expired)
+	*/
+	Get(ctx context.Context, key Key) (Record, bool, error)
+	/*
+		Put records the outcome of the given key
+	*/
+	Put(ctx context.Context, key Key, outcome Outcome) error
+	/*
+		Claim marks the key as in progress for up to ttl, so that only one replica processes it at a time. Returns
+		false while another holder has the claim. The outcome has to be read after the claim, it may have been
+		recorded by the previous holder
+	*/
+	Claim(ctx context.Context, key Key, ttl time.Duration) (bool, error)
+	/*
+		Release ends the own claim of the key once its outcome is recorded
+	*/
+	Release(ctx context.Context, key Key) error
+	/*
+		Close releases the resources of the store
+	*/
+	Close() error
+}
+
+/*
+NewStore creates the store of the given kind: "memory", "file" (location is the file path) or "redis" (location is
+the redis address)
+*/
+func NewStore(kind string, location string, password string, retention time.Duration) (Store, error) {
+	switch strings.ToLower(kind) {
+	case "", "memory":
+		return NewMemoryStore(retention), nil
+	case "file":
+		return NewFileStore(location, retention)
+	case "redis":
+		return NewRedisStore(location, password, retention)
+	default:
+		return nil, errors.New("unknown dedup store kind: " + kind)
+	}
+}
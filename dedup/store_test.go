@@ -0,0 +1,197 @@
+package dedup
+
+import (
+	"context"
+	"github.com/ethereum/go-ethereum/common"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func testKey(logIndex uint) Key {
+	return Key{ChainID: 1, TxHash: common.HexToHash("0xAB"), LogIndex: logIndex}
+}
+
+func TestStoresRecordTheLatestOutcome(t *testing.T) {
+	stores := map[string]func(t *testing.T) Store{
+		"memory": func(t *testing.T) Store {
+			return NewMemoryStore(time.Hour)
+		},
+		"file": func(t *testing.T) Store {
+			store, nErr := NewFileStore(filepath.Join(t.TempDir(), "dedup.jsonl"), time.Hour)
+			if nErr != nil {
+				t.Fatal(nErr)
+			}
+
+			return store
+		},
+	}
+
+	for name, newStore := range stores {
+		t.Run(name, func(t *testing.T) {
+			store := newStore(t)
+			defer store.Close()
+
+			ctx := context.Background()
+
+			if _, isRecorded, _ := store.Get(ctx, testKey(0)); isRecorded {
+				t.Fatal("expected an unknown key")
+			}
+
+			for _, outcome := range []Outcome{OutcomeWritten, OutcomeCompleted} {
+				if pErr := store.Put(ctx, testKey(0), outcome); pErr != nil {
+					t.Fatal(pErr)
+				}
+			}
+
+			record, isRecorded, gErr := store.Get(ctx, testKey(0))
+			if gErr != nil || !isRecorded || record.Outcome != OutcomeCompleted {
+				t.Fatalf("expected the completed outcome, got %+v %v (%v)", record, isRecorded, gErr)
+			}
+
+			// The log index is part of the key
+			if _, isRecorded, _ = store.Get(ctx, testKey(1)); isRecorded {
+				t.Fatal("expected the other log of the transaction to be unknown")
+			}
+		})
+	}
+}
+
+func TestMemoryStoreForgetsExpiredRecords(t *testing.T) {
+	store := NewMemoryStore(20 * time.Millisecond)
+	ctx := context.Background()
+
+	if pErr := store.Put(ctx, testKey(0), OutcomeCompleted); pErr != nil {
+		t.Fatal(pErr)
+	}
+
+	time.Sleep(40 * time.Millisecond)
+
+	if _, isRecorded, _ := store.Get(ctx, testKey(0)); isRecorded {
+		t.Fatal("expected the record to expire after the retention")
+	}
+}
+
+func TestFileStoreKeepsTheRecordsOverRestarts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dedup.jsonl")
+	ctx := context.Background()
+
+	store, nErr := NewFileStore(path, time.Hour)
+	if nErr != nil {
+		t.Fatal(nErr)
+	}
+
+	if pErr := store.Put(ctx, testKey(0), OutcomeWritten); pErr != nil {
+		t.Fatal(pErr)
+	}
+
+	if pErr := store.Put(ctx, testKey(0), OutcomeCompleted); pErr != nil {
+		t.Fatal(pErr)
+	}
+
+	if pErr := store.Put(ctx, testKey(1), OutcomeFailed); pErr != nil {
+		t.Fatal(pErr)
+	}
+
+	if cErr := store.Close(); cErr != nil {
+		t.Fatal(cErr)
+	}
+
+	// A crash in the middle of an append leaves a partial line
+	file, oErr := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o644)
+	if oErr != nil {
+		t.Fatal(oErr)
+	}
+
+	if _, wErr := file.WriteString(`{"key":"1:0x`); wErr != nil {
+		t.Fatal(wErr)
+	}
+	_ = file.Close()
+
+	reopened, nErr := NewFileStore(path, time.Hour)
+	if nErr != nil {
+		t.Fatal(nErr)
+	}
+	defer reopened.Close()
+
+	expected := map[Key]Outcome{testKey(0): OutcomeCompleted, testKey(1): OutcomeFailed}
+
+	for key, outcome := range expected {
+		record, isRecorded, gErr := reopened.Get(ctx, key)
+		if gErr != nil || !isRecorded || record.Outcome != outcome {
+			t.Fatalf("expected %s to be %s after the restart, got %+v %v (%v)", key, outcome, record, isRecorded, gErr)
+		}
+	}
+}
+
+func TestStoresGrantOneClaimAtATime(t *testing.T) {
+	stores := map[string]func(t *testing.T) Store{
+		"memory": func(t *testing.T) Store {
+			return NewMemoryStore(time.Hour)
+		},
+		"file": func(t *testing.T) Store {
+			store, nErr := NewFileStore(filepath.Join(t.TempDir(), "dedup.jsonl"), time.Hour)
+			if nErr != nil {
+				t.Fatal(nErr)
+			}
+
+			return store
+		},
+	}
+
+	for name, newStore := range stores {
+		t.Run(name, func(t *testing.T) {
+			store := newStore(t)
+			defer store.Close()
+
+			ctx := context.Background()
+
+			if isClaimed, cErr := store.Claim(ctx, testKey(0), time.Minute); cErr != nil || !isClaimed {
+				t.Fatalf("expected the first claim to be granted, got %v (%v)", isClaimed, cErr)
+			}
+
+			if isClaimed, _ := store.Claim(ctx, testKey(0), time.Minute); isClaimed {
+				t.Fatal("expected the held claim to be refused")
+			}
+
+			if isClaimed, _ := store.Claim(ctx, testKey(1), time.Minute); !isClaimed {
+				t.Fatal("expected the claim of the other log to be granted")
+			}
+
+			if rErr := store.Release(ctx, testKey(0)); rErr != nil {
+				t.Fatal(rErr)
+			}
+
+			if isClaimed, _ := store.Claim(ctx, testKey(0), time.Minute); !isClaimed {
+				t.Fatal("expected the released claim to be granted again")
+			}
+		})
+	}
+}
+
+func TestMemoryStoreExpiresTheAbandonedClaims(t *testing.T) {
+	store := NewMemoryStore(time.Hour)
+	ctx := context.Background()
+
+	if isClaimed, _ := store.Claim(ctx, testKey(0), 20*time.Millisecond); !isClaimed {
+		t.Fatal("expected the first claim to be granted")
+	}
+
+	time.Sleep(40 * time.Millisecond)
+
+	// The holder crashed without releasing, the claim is granted again after its ttl
+	if isClaimed, _ := store.Claim(ctx, testKey(0), time.Minute); !isClaimed {
+		t.Fatal("expected the expired claim to be granted again")
+	}
+}
+
+func TestNewStoreRejectsUnknownKinds(t *testing.T) {
+	if _, nErr := NewStore("postgres", "", "", time.Hour); nErr == nil {
+		t.Fatal("expected the unknown kind to be rejected")
+	}
+
+	if _, nErr := NewStore("file", "", "", time.Hour); nErr == nil {
+		t.Fatal("expected the file store without a path to be rejected")
+	}
+}
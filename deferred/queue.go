@@ -0,0 +1,336 @@
+package deferred
+
+import (
+	"context"
+	"eigenlayer_hack/dedup"
+	"eigenlayer_hack/metadata"
+	"eigenlayer_hack/metrics"
+	"encoding/json"
+	"errors"
+	"os"
+	"solity/utils/logger"
+	"sort"
+	"sync"
+	"time"
+)
+
+/*
+Entity is a looked up entity whose metadata was not known when the event was processed. Field is the payload field
+the name is written to
+*/
+type Entity struct {
+	Kind    metadata.Kind `json:"kind"`
+	Address string        `json:"address"`
+	Field   string        `json:"field"`
+}
+
+/*
+Registration is the registry write of the event, done once the names are known
+*/
+type Registration struct {
+	AvsAddress      string `json:"avsAddress"`
+	OperatorAddress string `json:"operatorAddress"`
+	AvsName         string `json:"avsName"`
+	OperatorName    string `json:"operatorName"`
+	// Written is set once the registry write has succeeded, so it is not repeated if the publishing fails
+	Written bool `json:"written"`
+}
+
+/*
+Item is an event whose enrichment is incomplete
+*/
+type Item struct {
+	Key           dedup.Key       `json:"key"`
+	EventType     string          `json:"eventType"`
+	OutputChannel string          `json:"outputChannel"`
+	Payload       json.RawMessage `json:"payload"`
+	Unresolved    []Entity        `json:"unresolved"`
+	Registration  *Registration   `json:"registration,omitempty"`
+	EnqueuedAt    time.Time       `json:"enqueuedAt"`
+	NextAttemptAt time.Time       `json:"nextAttemptAt"`
+	Attempts      int             `json:"attempts"`
+	LastError     string          `json:"lastError,omitempty"`
+}
+
+/*
+Schedule holds the retry settings of the Queue. The delay before the nth retry is MinDelay * 2^(n-1) capped at
+MaxDelay, items older than MaxAge are dropped
+*/
+type Schedule struct {
+	MinDelay      time.Duration
+	MaxDelay      time.Duration
+	MaxAge        time.Duration
+	CheckInterval time.Duration
+}
+
+/*
+AttemptFunc re-attempts the enrichment of the item, returns true if the item is done and can be removed
+*/
+type AttemptFunc func(ctx context.Context, item Item) (bool, error)
+
+/*
+Queue keeps the events with incomplete enrichment and re-attempts them on an exponential schedule. With a path the
+queue is persisted to that file after every change, so it survives restarts and can be inspected
+*/
+type Queue struct {
+	mu       sync.Mutex
+	path     string
+	schedule Schedule
+	items    map[string]*Item
+}
+
+/*
+NewQueue constructor for the Queue object, loads the items stored at path. An empty path keeps the queue in memory
+*/
+func NewQueue(path string, schedule Schedule) (*Queue, error) {
+	if schedule.MinDelay <= 0 {
+		schedule.MinDelay = 5 * time.Minute
+	}
+
+	if schedule.MaxDelay < schedule.MinDelay {
+		schedule.MaxDelay = 6 * time.Hour
+	}
+
+	if schedule.MaxAge <= 0 {
+		schedule.MaxAge = 7 * 24 * time.Hour
+	}
+
+	if schedule.CheckInterval <= 0 {
+		schedule.CheckInterval = time.Minute
+	}
+
+	q := &Queue{path: path, schedule: schedule, items: map[string]*Item{}}
+
+	if path != "" {
+		items, rErr := ReadItems(path)
+		if rErr != nil {
+			return nil, rErr
+		}
+
+		for i := range items {
+			q.items[items[i].Key.String()] = &items[i]
+		}
+	}
+
+	metrics.DeferredQueueSize.Set(int64(len(q.items)))
+
+	return q, nil
+}
+
+/*
+ReadItems returns the items stored in the queue file, ordered by their next attempt
+*/
+func ReadItems(path string) ([]Item, error) {
+	content, rErr := os.ReadFile(path)
+
+	if errors.Is(rErr, os.ErrNotExist) {
+		return []Item{}, nil
+	}
+
+	if rErr != nil {
+		return nil, rErr
+	}
+
+	items := []Item{}
+
+	if uErr := json.Unmarshal(content, &items); uErr != nil {
+		return nil, uErr
+	}
+
+	sortItems(items)
+
+	return items, nil
+}
+
+/*
+Enqueue adds the item, its first attempt is scheduled after MinDelay. An item with the same key is replaced
+*/
+func (q *Queue) Enqueue(item Item) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := time.Now()
+	item.EnqueuedAt = now
+	item.NextAttemptAt = now.Add(q.schedule.MinDelay)
+	item.Attempts = 0
+
+	q.items[item.Key.String()] = &item
+
+	logger.LogI("Enrichment of ", item.Key.String(), " is deferred, next attempt at ", item.NextAttemptAt.Format(time.RFC3339))
+
+	return q.save()
+}
+
+/*
+Items returns a copy of the queued items, ordered by their next attempt
+*/
+func (q *Queue) Items() []Item {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	items := make([]Item, 0, len(q.items))
+
+	for _, item := range q.items {
+		items = append(items, *item)
+	}
+
+	sortItems(items)
+
+	return items
+}
+
+/*
+MarkWritten records that the registry write of the item has succeeded
+*/
+func (q *Queue) MarkWritten(key dedup.Key) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	item, isOk := q.items[key.String()]
+
+	if !isOk || item.Registration == nil {
+		return nil
+	}
+
+	item.Registration.Written = true
+
+	return q.save()
+}
+
+/*
+Run re-attempts the due items every CheckInterval until the context is cancelled
+*/
+func (q *Queue) Run(ctx context.Context, attempt AttemptFunc) {
+	if q == nil {
+		return
+	}
+
+	ticker := time.NewTicker(q.schedule.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			q.ProcessDue(ctx, attempt)
+		}
+	}
+}
+
+/*
+ProcessDue re-attempts the items whose next attempt is due. Done items are removed, failed ones are rescheduled and
+the ones older than MaxAge are dropped
+*/
+func (q *Queue) ProcessDue(ctx context.Context, attempt AttemptFunc) {
+	now := time.Now()
+
+	for _, item := range q.Items() {
+		if ctx.Err() != nil {
+			return
+		}
+
+		if item.NextAttemptAt.After(now) {
+			// The items are ordered by their next attempt
+			return
+		}
+
+		done, aErr := attempt(ctx, item)
+
+		if done {
+			metrics.DeferredResolved.Add(1)
+			q.update(item.Key, func(items map[string]*Item) { delete(items, item.Key.String()) })
+			continue
+		}
+
+		if now.Sub(item.EnqueuedAt) > q.schedule.MaxAge {
+			metrics.DeferredExpired.Add(1)
+			logger.LogW("Enrichment of ", item.Key.String(), " is given up after ", item.Attempts+1, " attempts")
+			q.update(item.Key, func(items map[string]*Item) { delete(items, item.Key.String()) })
+			continue
+		}
+
+		q.update(item.Key, func(items map[string]*Item) {
+			stored, isOk := items[item.Key.String()]
+
+			if !isOk {
+				return
+			}
+
+			stored.Attempts++
+			stored.NextAttemptAt = time.Now().Add(q.delay(stored.Attempts))
+			stored.LastError = ""
+
+			if aErr != nil {
+				stored.LastError = aErr.Error()
+			}
+		})
+	}
+}
+
+/*
+delay returns the wait time after the given number of attempts
+*/
+func (q *Queue) delay(attempts int) time.Duration {
+	delay := q.schedule.MinDelay
+
+	for i := 1; i < attempts && delay < q.schedule.MaxDelay; i++ {
+		delay *= 2
+	}
+
+	return min(delay, q.schedule.MaxDelay)
+}
+
+/*
+update applies the change to the items and persists the queue
+*/
+func (q *Queue) update(key dedup.Key, change func(items map[string]*Item)) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	change(q.items)
+
+	if sErr := q.save(); sErr != nil {
+		logger.LogW("Error while saving the deferred queue after ", key.String(), ": ", sErr)
+	}
+}
+
+/*
+save writes the queue to its file, the caller holds the lock
+*/
+func (q *Queue) save() error {
+	metrics.DeferredQueueSize.Set(int64(len(q.items)))
+
+	if q.path == "" {
+		return nil
+	}
+
+	items := make([]Item, 0, len(q.items))
+
+	for _, item := range q.items {
+		items = append(items, *item)
+	}
+
+	sortItems(items)
+
+	content, mErr := json.MarshalIndent(items, "", "  ")
+	if mErr != nil {
+		return mErr
+	}
+
+	// Write to a temporary file first so that a crash does not leave a half written queue
+	tmpPath := q.path + ".tmp"
+
+	if wErr := os.WriteFile(tmpPath, content, 0o644); wErr != nil {
+		return wErr
+	}
+
+	return os.Rename(tmpPath, q.path)
+}
+
+/*
+sortItems orders the items by their next attempt
+*/
+func sortItems(items []Item) {
+	sort.Slice(items, func(i, j int) bool { return items[i].NextAttemptAt.Before(items[j].NextAttemptAt) })
+}
@@ -0,0 +1,161 @@
+package deferred
+
+import (
+	"context"
+	"eigenlayer_hack/dedup"
+	"eigenlayer_hack/metadata"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func testItem(logIndex uint) Item {
+	return Item{
+		Key:        dedup.Key{ChainID: 1, LogIndex: logIndex},
+		EventType:  "OperatorAVSRegistrationStatusUpdated",
+		Payload:    json.RawMessage(`{"avsName":""}`),
+		Unresolved: []Entity{{Kind: metadata.KindAVS, Address: "0xavs", Field: "avsName"}},
+	}
+}
+
+func TestQueueKeepsTheItemsOverRestarts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "deferred.json")
+
+	queue, nErr := NewQueue(path, Schedule{})
+	if nErr != nil {
+		t.Fatal(nErr)
+	}
+
+	registered := testItem(1)
+	registered.Registration = &Registration{AvsAddress: "0xavs", OperatorAddress: "0xoperator"}
+
+	for _, item := range []Item{testItem(0), registered} {
+		if eErr := queue.Enqueue(item); eErr != nil {
+			t.Fatal(eErr)
+		}
+	}
+
+	if mErr := queue.MarkWritten(registered.Key); mErr != nil {
+		t.Fatal(mErr)
+	}
+
+	if _, sErr := os.Stat(path + ".tmp"); !errors.Is(sErr, os.ErrNotExist) {
+		t.Fatalf("expected the temporary file to be renamed, got %v", sErr)
+	}
+
+	reopened, nErr := NewQueue(path, Schedule{})
+	if nErr != nil {
+		t.Fatal(nErr)
+	}
+
+	items := reopened.Items()
+
+	if len(items) != 2 {
+		t.Fatalf("expected the 2 items after the restart, got %d", len(items))
+	}
+
+	for _, item := range items {
+		payload := map[string]string{}
+
+		_ = json.Unmarshal(item.Payload, &payload)
+
+		if _, isOk := payload["avsName"]; !isOk || len(item.Unresolved) != 1 {
+			t.Fatalf("unexpected item after the restart %+v", item)
+		}
+
+		if item.Key == registered.Key && (item.Registration == nil || !item.Registration.Written) {
+			t.Fatalf("expected the registry write to be remembered, got %+v", item.Registration)
+		}
+	}
+
+	// The inspection reads the same file
+	stored, rErr := ReadItems(path)
+	if rErr != nil || len(stored) != 2 {
+		t.Fatalf("expected the stored items, got %d (%v)", len(stored), rErr)
+	}
+}
+
+func TestQueueProcessesTheDueItems(t *testing.T) {
+	queue, nErr := NewQueue("", Schedule{MinDelay: time.Millisecond, MaxDelay: time.Hour, MaxAge: time.Hour})
+	if nErr != nil {
+		t.Fatal(nErr)
+	}
+
+	for logIndex := uint(0); logIndex < 2; logIndex++ {
+		if eErr := queue.Enqueue(testItem(logIndex)); eErr != nil {
+			t.Fatal(eErr)
+		}
+	}
+
+	time.Sleep(5 * time.Millisecond)
+
+	attempted := 0
+
+	queue.ProcessDue(context.Background(), func(_ context.Context, item Item) (bool, error) {
+		attempted++
+
+		// The first item is resolved, the second one is still unknown
+		if item.Key.LogIndex == 0 {
+			return true, nil
+		}
+
+		return false, errors.New("avs name is unknown")
+	})
+
+	if attempted != 2 {
+		t.Fatalf("expected both items to be attempted, got %d", attempted)
+	}
+
+	items := queue.Items()
+
+	if len(items) != 1 || items[0].Key.LogIndex != 1 {
+		t.Fatalf("expected only the unresolved item to stay, got %+v", items)
+	}
+
+	if items[0].Attempts != 1 || items[0].LastError != "avs name is unknown" {
+		t.Fatalf("expected the failed attempt to be recorded, got %+v", items[0])
+	}
+
+	// The retry is not due yet
+	queue.ProcessDue(context.Background(), func(context.Context, Item) (bool, error) {
+		t.Fatal("attempted an item before its next attempt")
+		return false, nil
+	})
+}
+
+func TestQueueDropsTheItemsOlderThanMaxAge(t *testing.T) {
+	queue, nErr := NewQueue("", Schedule{MinDelay: time.Millisecond, MaxDelay: time.Hour, MaxAge: 5 * time.Millisecond})
+	if nErr != nil {
+		t.Fatal(nErr)
+	}
+
+	if eErr := queue.Enqueue(testItem(0)); eErr != nil {
+		t.Fatal(eErr)
+	}
+
+	time.Sleep(10 * time.Millisecond)
+
+	queue.ProcessDue(context.Background(), func(context.Context, Item) (bool, error) { return false, nil })
+
+	if items := queue.Items(); len(items) != 0 {
+		t.Fatalf("expected the expired item to be dropped, got %+v", items)
+	}
+}
+
+func TestQueueDelayDoublesUpToMaxDelay(t *testing.T) {
+	queue, nErr := NewQueue("", Schedule{MinDelay: time.Minute, MaxDelay: 5 * time.Minute})
+	if nErr != nil {
+		t.Fatal(nErr)
+	}
+
+	expected := map[int]time.Duration{1: time.Minute, 2: 2 * time.Minute, 3: 4 * time.Minute, 4: 5 * time.Minute, 10: 5 * time.Minute}
+
+	for attempts, delay := range expected {
+		if actual := queue.delay(attempts); actual != delay {
+			t.Errorf("expected a delay of %s after %d attempts, got %s", delay, attempts, actual)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"eigenlayer_hack/config"
+	"eigenlayer_hack/deferred"
+	"encoding/json"
+	"flag"
+	"os"
+	"solity/utils/logger"
+)
+
+/*
+runDeferredInspect prints the events waiting in the deferred enrichment queue as JSON, with their unknown entities,
+attempt counts and next attempt times. It only reads the queue file, so it can run next to the tracker
+*/
+func runDeferredInspect(cfg config.Config, args []string) {
+	flags := flag.NewFlagSet("deferred", flag.ExitOnError)
+	file := flags.String("file", cfg.Deferred.QueueFile,
+		"path of the deferred enrichment queue file")
+	_ = flags.Parse(args)
+
+	items, rErr := deferred.ReadItems(*file)
+	if rErr != nil {
+		logger.LogE("Error while reading the deferred queue: ", rErr)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+
+	if eErr := encoder.Encode(items); eErr != nil {
+		logger.LogE("Error while printing the deferred queue: ", eErr)
+	}
+}
@@ -29,10 +29,18 @@ var (
 	_ = abi.ConvertType
 )
 
+// RegisteryEventInput is an auto generated low-level Go binding around an user-defined struct.
+type RegisteryEventInput struct {
+	AvsName         string
+	OperatorName    string
+	AvsAddress      common.Address
+	OperatorAddress common.Address
+}
+
 // RegisteryMetaData contains all meta data concerning the Registery contract.
 var RegisteryMetaData = &bind.MetaData{
-	ABI: "[{\"inputs\":[{\"internalType\":\"string\",\"name\":\"avsName\",\"type\":\"string\"},{\"internalType\":\"string\",\"name\":\"operatorName\",\"type\":\"string\"},{\"internalType\":\"address\",\"name\":\"avsAddress\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"operatorAddress\",\"type\":\"address\"}],\"name\":\"registerEvent\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"name\":\"events\",\"outputs\":[{\"internalType\":\"string\",\"name\":\"avsName\",\"type\":\"string\"},{\"internalType\":\"string\",\"name\":\"operatorName\",\"type\":\"string\"},{\"internalType\":\"address\",\"name\":\"avsAddress\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"operatorAddress\",\"type\":\"address\"}],\"stateMutability\":\"view\",\"type\":\"function\"}]",
-	Bin: "0x6080604052348015600e575f80fd5b506109858061001c5f395ff3fe608060405234801561000f575f80fd5b5060043610610034575f3560e01c80630b7914301461003857806370bb43e41461006b575b5f80fd5b610052600480360381019061004d9190610400565b610087565b60405161006294939291906104da565b60405180910390f35b610085600480360381019061008091906105b6565b61020b565b005b5f8181548110610095575f80fd5b905f5260205f2090600402015f91509050805f0180546100b490610686565b80601f01602080910402602001604051908101604052809291908181526020018280546100e090610686565b801561012b5780601f106101025761010080835404028352916020019161012b565b820191905f5260205f20905b81548152906001019060200180831161010e57829003601f168201915b50505050509080600101805461014090610686565b80601f016020809104026020016040519081016040528092919081815260200182805461016c90610686565b80156101b75780601f1061018e576101008083540402835291602001916101b7565b820191905f5260205f20905b81548152906001019060200180831161019a57829003601f168201915b505050505090806002015f9054906101000a900473ffffffffffffffffffffffffffffffffffffffff1690806003015f9054906101000a900473ffffffffffffffffffffffffffffffffffffffff16905084565b5f604051806080016040528088888080601f0160208091040260200160405190810160405280939291908181526020018383808284375f81840152601f19601f82011690508083019250505050505050815260200186868080601f0160208091040260200160405190810160405280939291908181526020018383808284375f81840152601f19601f8201169050808301925050505050505081526020018473ffffffffffffffffffffffffffffffffffffffff1681526020018373ffffffffffffffffffffffffffffffffffffffff16815250908060018154018082558091505060019003905f5260205f2090600402015f909190919091505f820151815f0190816103189190610880565b50602082015181600101908161032e9190610880565b506040820151816002015f6101000a81548173ffffffffffffffffffffffffffffffffffffffff021916908373ffffffffffffffffffffffffffffffffffffffff1602179055506060820151816003015f6101000a81548173ffffffffffffffffffffffffffffffffffffffff021916908373ffffffffffffffffffffffffffffffffffffffff1602179055505050505050505050565b5f80fd5b5f80fd5b5f819050919050565b6103df816103cd565b81146103e9575f80fd5b50565b5f813590506103fa816103d6565b92915050565b5f60208284031215610415576104146103c5565b5b5f610422848285016103ec565b91505092915050565b5f81519050919050565b5f82825260208201905092915050565b8281835e5f83830152505050565b5f601f19601f8301169050919050565b5f61046d8261042b565b6104778185610435565b9350610487818560208601610445565b61049081610453565b840191505092915050565b5f73ffffffffffffffffffffffffffffffffffffffff82169050919050565b5f6104c48261049b565b9050919050565b6104d4816104ba565b82525050565b5f6080820190508181035f8301526104f28187610463565b905081810360208301526105068186610463565b905061051560408301856104cb565b61052260608301846104cb565b95945050505050565b5f80fd5b5f80fd5b5f80fd5b5f8083601f84011261054c5761054b61052b565b5b8235905067ffffffffffffffff8111156105695761056861052f565b5b60208301915083600182028301111561058557610584610533565b5b9250929050565b610595816104ba565b811461059f575f80fd5b50565b5f813590506105b08161058c565b92915050565b5f805f805f80608087890312156105d0576105cf6103c5565b5b5f87013567ffffffffffffffff8111156105ed576105ec6103c9565b5b6105f989828a01610537565b9650965050602087013567ffffffffffffffff81111561061c5761061b6103c9565b5b61062889828a01610537565b9450945050604061063b89828a016105a2565b925050606061064c89828a016105a2565b9150509295509295509295565b7f4e487b71000000000000000000000000000000000000000000000000000000005f52602260045260245ffd5b5f600282049050600182168061069d57607f821691505b6020821081036106b0576106af610659565b5b50919050565b7f4e487b71000000000000000000000000000000000000000000000000000000005f52604160045260245ffd5b5f819050815f5260205f209050919050565b5f6020601f8301049050919050565b5f82821b905092915050565b5f6008830261073f7fffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff82610704565b6107498683610704565b95508019841693508086168417925050509392505050565b5f819050919050565b5f61078461077f61077a846103cd565b610761565b6103cd565b9050919050565b5f819050919050565b61079d8361076a565b6107b16107a98261078b565b848454610710565b825550505050565b5f90565b6107c56107b9565b6107d0818484610794565b505050565b5b818110156107f3576107e85f826107bd565b6001810190506107d6565b5050565b601f82111561083857610809816106e3565b610812846106f5565b81016020851015610821578190505b61083561082d856106f5565b8301826107d5565b50505b505050565b5f82821c905092915050565b5f6108585f198460080261083d565b1980831691505092915050565b5f6108708383610849565b9150826002028217905092915050565b6108898261042b565b67ffffffffffffffff8111156108a2576108a16106b6565b5b6108ac8254610686565b6108b78282856107f7565b5f60209050601f8311600181146108e8575f84156108d6578287015190505b6108e08582610865565b865550610947565b601f1984166108f6866106e3565b5f5b8281101561091d578489015182556001820191506020850194506020810190506108f8565b8683101561093a5784890151610936601f891682610849565b8355505b6001600288020188555050505b50505050505056fea26469706673582212204a5bcc02f0f0094e0b6c607f15db168104528914f5a95eae139ab350a4614b9164736f6c634300081a0033",
+	ABI: "[{\"inputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"constructor\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"avsAddress\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"operatorAddress\",\"type\":\"address\"}],\"name\":\"DuplicateEvent\",\"type\":\"error\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"caller\",\"type\":\"address\"}],\"name\":\"NotOwner\",\"type\":\"error\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"caller\",\"type\":\"address\"}],\"name\":\"NotWriter\",\"type\":\"error\"},{\"inputs\":[],\"name\":\"ZeroAddress\",\"type\":\"error\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"id\",\"type\":\"uint256\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"avsAddress\",\"type\":\"address\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"operatorAddress\",\"type\":\"address\"},{\"indexed\":false,\"internalType\":\"string\",\"name\":\"avsName\",\"type\":\"string\"},{\"indexed\":false,\"internalType\":\"string\",\"name\":\"operatorName\",\"type\":\"string\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"timestamp\",\"type\":\"uint256\"}],\"name\":\"EventRegistered\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"address\",\"name\":\"previousOwner\",\"type\":\"address\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"newOwner\",\"type\":\"address\"}],\"name\":\"OwnershipTransferred\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"address\",\"name\":\"writer\",\"type\":\"address\"}],\"name\":\"WriterAdded\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"address\",\"name\":\"writer\",\"type\":\"address\"}],\"name\":\"WriterRemoved\",\"type\":\"event\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"writer\",\"type\":\"address\"}],\"name\":\"addWriter\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"eventCount\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"name\":\"events\",\"outputs\":[{\"internalType\":\"string\",\"name\":\"avsName\",\"type\":\"string\"},{\"internalType\":\"string\",\"name\":\"operatorName\",\"type\":\"string\"},{\"internalType\":\"address\",\"name\":\"avsAddress\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"operatorAddress\",\"type\":\"address\"},{\"internalType\":\"uint256\",\"name\":\"timestamp\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"avsAddress\",\"type\":\"address\"}],\"name\":\"eventsByAVS\",\"outputs\":[{\"internalType\":\"uint256[]\",\"name\":\"\",\"type\":\"uint256[]\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"operatorAddress\",\"type\":\"address\"}],\"name\":\"eventsByOperator\",\"outputs\":[{\"internalType\":\"uint256[]\",\"name\":\"\",\"type\":\"uint256[]\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"avsAddress\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"operatorAddress\",\"type\":\"address\"}],\"name\":\"isRegistered\",\"outputs\":[{\"internalType\":\"bool\",\"name\":\"\",\"type\":\"bool\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"owner\",\"outputs\":[{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"string\",\"name\":\"avsName\",\"type\":\"string\"},{\"internalType\":\"string\",\"name\":\"operatorName\",\"type\":\"string\"},{\"internalType\":\"address\",\"name\":\"avsAddress\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"operatorAddress\",\"type\":\"address\"}],\"name\":\"registerEvent\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"components\":[{\"internalType\":\"string\",\"name\":\"avsName\",\"type\":\"string\"},{\"internalType\":\"string\",\"name\":\"operatorName\",\"type\":\"string\"},{\"internalType\":\"address\",\"name\":\"avsAddress\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"operatorAddress\",\"type\":\"address\"}],\"internalType\":\"structRegistery.EventInput[]\",\"name\":\"batch\",\"type\":\"tuple[]\"}],\"name\":\"registerEvents\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"bytes32\",\"name\":\"\",\"type\":\"bytes32\"}],\"name\":\"registered\",\"outputs\":[{\"internalType\":\"bool\",\"name\":\"\",\"type\":\"bool\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"writer\",\"type\":\"address\"}],\"name\":\"removeWriter\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"newOwner\",\"type\":\"address\"}],\"name\":\"transferOwnership\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"}],\"name\":\"writers\",\"outputs\":[{\"internalType\":\"bool\",\"name\":\"\",\"type\":\"bool\"}],\"stateMutability\":\"view\",\"type\":\"function\"}]",
+	Bin: "0x608060405234801561000f575f5ffd5b503360015f6101000a81548173ffffffffffffffffffffffffffffffffffffffff021916908373ffffffffffffffffffffffffffffffffffffffff160217905550600160025f3373ffffffffffffffffffffffffffffffffffffffff1673ffffffffffffffffffffffffffffffffffffffff1681526020019081526020015f205f6101000a81548160ff0219169083151502179055503373ffffffffffffffffffffffffffffffffffffffff165f73ffffffffffffffffffffffffffffffffffffffff167f8be0079c531659141344cd1fd0a4f28419497f9722a3daafe3b4186f6b6457e060405160405180910390a33373ffffffffffffffffffffffffffffffffffffffff167f6ff3aa2ea7b53070f6d9d07a445d338d89e8edef44250ffa8be19f53910d4a2e60405160405180910390a2611ae88061014f5f395ff3fe608060405234801561000f575f5ffd5b50600436106100cd575f3560e01c80638da5cb5b1161008a578063da2824a811610064578063da2824a814610239578063ee299b1514610255578063f2fde38b14610271578063f4b8a1371461028d576100cd565b80638da5cb5b146101bb5780638e7e80a2146101d9578063d3da927f14610209576100cd565b80630b791430146100d157806317d72775146101055780635356dddc146101355780635524d5481461015157806370bb43e41461018157806371be2e4a1461019d575b5f5ffd5b6100eb60048036038101906100e691906110b8565b6102bd565b6040516100fc9594939291906111a1565b60405180910390f35b61011f600480360381019061011a919061122a565b610447565b60405161012c919061130c565b60405180910390f35b61014f600480360381019061014a919061122a565b6104da565b005b61016b6004803603810190610166919061135f565b610605565b60405161017891906113a4565b60405180910390f35b61019b6004803603810190610196919061141e565b610622565b005b6101a56106c3565b6040516101b291906114c1565b60405180910390f35b6101c36106ce565b6040516101d091906114da565b60405180910390f35b6101f360048036038101906101ee919061122a565b6106f3565b60405161020091906113a4565b60405180910390f35b610223600480360381019061021e91906114f3565b610710565b60405161023091906113a4565b60405180910390f35b610253600480360381019061024e919061122a565b61075f565b005b61026f600480360381019061026a9190611586565b6108f0565b005b61028b6004803603810190610286919061122a565b610a7a565b005b6102a760048036038101906102a2919061122a565b610c2e565b6040516102b4919061130c565b60405180910390f35b5f81815481106102cb575f80fd5b905f5260205f2090600502015f91509050805f0180546102ea906115fe565b80601f0160208091040260200160405190810160405280929190818152602001828054610316906115fe565b80156103615780601f1061033857610100808354040283529160200191610361565b820191905f5260205f20905b81548152906001019060200180831161034457829003601f168201915b505050505090806001018054610376906115fe565b80601f01602080910402602001604051908101604052809291908181526020018280546103a2906115fe565b80156103ed5780601f106103c4576101008083540402835291602001916103ed565b820191905f5260205f20905b8154815290600101906020018083116103d057829003601f168201915b505050505090806002015f9054906101000a900473ffffffffffffffffffffffffffffffffffffffff1690806003015f9054906101000a900473ffffffffffffffffffffffffffffffffffffffff16908060040154905085565b606060045f8373ffffffffffffffffffffffffffffffffffffffff1673ffffffffffffffffffffffffffffffffffffffff1681526020019081526020015f208054806020026020016040519081016040528092919081815260200182805480156104ce57602002820191905f5260205f20905b8154815260200190600101908083116104ba575b50505050509050919050565b60015f9054906101000a900473ffffffffffffffffffffffffffffffffffffffff1673ffffffffffffffffffffffffffffffffffffffff163373ffffffffffffffffffffffffffffffffffffffff161461056b57336040517f245aecd300000000000000000000000000000000000000000000000000000000815260040161056291906114da565b60405180910390fd5b5f60025f8373ffffffffffffffffffffffffffffffffffffffff1673ffffffffffffffffffffffffffffffffffffffff1681526020019081526020015f205f6101000a81548160ff0219169083151502179055508073ffffffffffffffffffffffffffffffffffffffff167f86e5bbceda94081c32220d685f37cc4e3ea7bb0be2dfbf0cb703579505a5390e60405160405180910390a250565b6003602052805f5260405f205f915054906101000a900460ff1681565b60025f3373ffffffffffffffffffffffffffffffffffffffff1673ffffffffffffffffffffffffffffffffffffffff1681526020019081526020015f205f9054906101000a900460ff166106ad57336040517f5d94d23c0000000000000000000000000000000000000000000000000000000081526004016106a491906114da565b60405180910390fd5b6106bb868686868686610cc1565b505050505050565b5f5f80549050905090565b60015f9054906101000a900473ffffffffffffffffffffffffffffffffffffffff1681565b6002602052805f5260405f205f915054906101000a900460ff1681565b5f60035f8484604051602001610727929190611673565b6040516020818303038152906040528051906020012081526020019081526020015f205f9054906101000a900460ff16905092915050565b60015f9054906101000a900473ffffffffffffffffffffffffffffffffffffffff1673ffffffffffffffffffffffffffffffffffffffff163373ffffffffffffffffffffffffffffffffffffffff16146107f057336040517f245aecd30000000000000000000000000000000000000000000000000000000081526004016107e791906114da565b60405180910390fd5b5f73ffffffffffffffffffffffffffffffffffffffff168173ffffffffffffffffffffffffffffffffffffffff1603610855576040517fd92e233d00000000000000000000000000000000000000000000000000000000815260040160405180910390fd5b600160025f8373ffffffffffffffffffffffffffffffffffffffff1673ffffffffffffffffffffffffffffffffffffffff1681526020019081526020015f205f6101000a81548160ff0219169083151502179055508073ffffffffffffffffffffffffffffffffffffffff167f6ff3aa2ea7b53070f6d9d07a445d338d89e8edef44250ffa8be19f53910d4a2e60405160405180910390a250565b60025f3373ffffffffffffffffffffffffffffffffffffffff1673ffffffffffffffffffffffffffffffffffffffff1681526020019081526020015f205f9054906101000a900460ff1661097b57336040517f5d94d23c00000000000000000000000000000000000000000000000000000000815260040161097291906114da565b60405180910390fd5b5f5f90505b82829050811015610a7557610a688383838181106109a1576109a061169e565b5b90506020028101906109b391906116d7565b805f01906109c191906116fe565b8585858181106109d4576109d361169e565b5b90506020028101906109e691906116d7565b80602001906109f591906116fe565b878787818110610a0857610a0761169e565b5b9050602002810190610a1a91906116d7565b6040016020810190610a2c919061122a565b888888818110610a3f57610a3e61169e565b5b9050602002810190610a5191906116d7565b6060016020810190610a63919061122a565b610cc1565b8080600101915050610980565b505050565b60015f9054906101000a900473ffffffffffffffffffffffffffffffffffffffff1673ffffffffffffffffffffffffffffffffffffffff163373ffffffffffffffffffffffffffffffffffffffff1614610b0b57336040517f245aecd3000000000000000000000000000000000000000000000000000000008152600401610b0291906114da565b60405180910390fd5b5f73ffffffffffffffffffffffffffffffffffffffff168173ffffffffffffffffffffffffffffffffffffffff1603610b70576040517fd92e233d00000000000000000000000000000000000000000000000000000000815260040160405180910390fd5b8073ffffffffffffffffffffffffffffffffffffffff1660015f9054906101000a900473ffffffffffffffffffffffffffffffffffffffff1673ffffffffffffffffffffffffffffffffffffffff167f8be0079c531659141344cd1fd0a4f28419497f9722a3daafe3b4186f6b6457e060405160405180910390a38060015f6101000a81548173ffffffffffffffffffffffffffffffffffffffff021916908373ffffffffffffffffffffffffffffffffffffffff16021790555050565b606060055f8373ffffffffffffffffffffffffffffffffffffffff1673ffffffffffffffffffffffffffffffffffffffff1681526020019081526020015f20805480602002602001604051908101604052809291908181526020018280548015610cb557602002820191905f5260205f20905b815481526020019060010190808311610ca1575b50505050509050919050565b5f8282604051602001610cd5929190611673565b60405160208183030381529060405280519060200120905060035f8281526020019081526020015f205f9054906101000a900460ff1615610d4f5782826040517fe65c700c000000000000000000000000000000000000000000000000000000008152600401610d46929190611760565b60405180910390fd5b600160035f8381526020019081526020015f205f6101000a81548160ff0219169083151502179055505f5f8054905090505f6040518060a001604052808a8a8080601f0160208091040260200160405190810160405280939291908181526020018383808284375f81840152601f19601f82011690508083019250505050505050815260200188888080601f0160208091040260200160405190810160405280939291908181526020018383808284375f81840152601f19601f8201169050808301925050505050505081526020018673ffffffffffffffffffffffffffffffffffffffff1681526020018573ffffffffffffffffffffffffffffffffffffffff16815260200142815250908060018154018082558091505060019003905f5260205f2090600502015f909190919091505f820151815f019081610e939190611954565b506020820151816001019081610ea99190611954565b506040820151816002015f6101000a81548173ffffffffffffffffffffffffffffffffffffffff021916908373ffffffffffffffffffffffffffffffffffffffff1602179055506060820151816003015f6101000a81548173ffffffffffffffffffffffffffffffffffffffff021916908373ffffffffffffffffffffffffffffffffffffffff16021790555060808201518160040155505060045f8573ffffffffffffffffffffffffffffffffffffffff1673ffffffffffffffffffffffffffffffffffffffff1681526020019081526020015f2081908060018154018082558091505060019003905f5260205f20015f909190919091505560055f8473ffffffffffffffffffffffffffffffffffffffff1673ffffffffffffffffffffffffffffffffffffffff1681526020019081526020015f2081908060018154018082558091505060019003905f5260205f20015f90919091909150558273ffffffffffffffffffffffffffffffffffffffff168473ffffffffffffffffffffffffffffffffffffffff167fd3002fc2df65080c6b3c96f6a886760e60773ae82ee1a2b349b213fd0f1f3175838b8b8b8b4260405161106b96959493929190611a5d565b60405180910390a35050505050505050565b5f5ffd5b5f5ffd5b5f819050919050565b61109781611085565b81146110a1575f5ffd5b50565b5f813590506110b28161108e565b92915050565b5f602082840312156110cd576110cc61107d565b5b5f6110da848285016110a4565b91505092915050565b5f81519050919050565b5f82825260208201905092915050565b8281835e5f83830152505050565b5f601f19601f8301169050919050565b5f611125826110e3565b61112f81856110ed565b935061113f8185602086016110fd565b6111488161110b565b840191505092915050565b5f73ffffffffffffffffffffffffffffffffffffffff82169050919050565b5f61117c82611153565b9050919050565b61118c81611172565b82525050565b61119b81611085565b82525050565b5f60a0820190508181035f8301526111b9818861111b565b905081810360208301526111cd818761111b565b90506111dc6040830186611183565b6111e96060830185611183565b6111f66080830184611192565b9695505050505050565b61120981611172565b8114611213575f5ffd5b50565b5f8135905061122481611200565b92915050565b5f6020828403121561123f5761123e61107d565b5b5f61124c84828501611216565b91505092915050565b5f81519050919050565b5f82825260208201905092915050565b5f819050602082019050919050565b61128781611085565b82525050565b5f611298838361127e565b60208301905092915050565b5f602082019050919050565b5f6112ba82611255565b6112c4818561125f565b93506112cf8361126f565b805f5b838110156112ff5781516112e6888261128d565b97506112f1836112a4565b9250506001810190506112d2565b5085935050505092915050565b5f6020820190508181035f83015261132481846112b0565b905092915050565b5f819050919050565b61133e8161132c565b8114611348575f5ffd5b50565b5f8135905061135981611335565b92915050565b5f602082840312156113745761137361107d565b5b5f6113818482850161134b565b91505092915050565b5f8115159050919050565b61139e8161138a565b82525050565b5f6020820190506113b75f830184611395565b92915050565b5f5ffd5b5f5ffd5b5f5ffd5b5f5f83601f8401126113de576113dd6113bd565b5b8235905067ffffffffffffffff8111156113fb576113fa6113c1565b5b602083019150836001820283011115611417576114166113c5565b5b9250929050565b5f5f5f5f5f5f608087890312156114385761143761107d565b5b5f87013567ffffffffffffffff81111561145557611454611081565b5b61146189828a016113c9565b9650965050602087013567ffffffffffffffff81111561148457611483611081565b5b61149089828a016113c9565b945094505060406114a389828a01611216565b92505060606114b489828a01611216565b9150509295509295509295565b5f6020820190506114d45f830184611192565b92915050565b5f6020820190506114ed5f830184611183565b92915050565b5f5f604083850312156115095761150861107d565b5b5f61151685828601611216565b925050602061152785828601611216565b9150509250929050565b5f5f83601f840112611546576115456113bd565b5b8235905067ffffffffffffffff811115611563576115626113c1565b5b60208301915083602082028301111561157f5761157e6113c5565b5b9250929050565b5f5f6020838503121561159c5761159b61107d565b5b5f83013567ffffffffffffffff8111156115b9576115b8611081565b5b6115c585828601611531565b92509250509250929050565b7f4e487b71000000000000000000000000000000000000000000000000000000005f52602260045260245ffd5b5f600282049050600182168061161557607f821691505b602082108103611628576116276115d1565b5b50919050565b5f8160601b9050919050565b5f6116448261162e565b9050919050565b5f6116558261163a565b9050919050565b61166d61166882611172565b61164b565b82525050565b5f61167e828561165c565b60148201915061168e828461165c565b6014820191508190509392505050565b7f4e487b71000000000000000000000000000000000000000000000000000000005f52603260045260245ffd5b5f5ffd5b5f5ffd5b5f5ffd5b5f823560016080038336030381126116f2576116f16116cb565b5b80830191505092915050565b5f5f8335600160200384360303811261171a576117196116cb565b5b80840192508235915067ffffffffffffffff82111561173c5761173b6116cf565b5b602083019250600182023603831315611758576117576116d3565b5b509250929050565b5f6040820190506117735f830185611183565b6117806020830184611183565b9392505050565b7f4e487b71000000000000000000000000000000000000000000000000000000005f52604160045260245ffd5b5f819050815f5260205f209050919050565b5f6020601f8301049050919050565b5f82821b905092915050565b5f600883026118107fffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff826117d5565b61181a86836117d5565b95508019841693508086168417925050509392505050565b5f819050919050565b5f61185561185061184b84611085565b611832565b611085565b9050919050565b5f819050919050565b61186e8361183b565b61188261187a8261185c565b8484546117e1565b825550505050565b5f5f905090565b61189961188a565b6118a4818484611865565b505050565b5b818110156118c7576118bc5f82611891565b6001810190506118aa565b5050565b601f82111561190c576118dd816117b4565b6118e6846117c6565b810160208510156118f5578190505b611909611901856117c6565b8301826118a9565b50505b505050565b5f82821c905092915050565b5f61192c5f1984600802611911565b1980831691505092915050565b5f611944838361191d565b9150826002028217905092915050565b61195d826110e3565b67ffffffffffffffff81111561197657611975611787565b5b61198082546115fe565b61198b8282856118cb565b5f60209050601f8311600181146119bc575f84156119aa578287015190505b6119b48582611939565b865550611a1b565b601f1984166119ca866117b4565b5f5b828110156119f1578489015182556001820191506020850194506020810190506119cc565b86831015611a0e5784890151611a0a601f89168261191d565b8355505b6001600288020188555050505b505050505050565b828183375f83830152505050565b5f611a3c83856110ed565b9350611a49838584611a23565b611a528361110b565b840190509392505050565b5f608082019050611a705f830189611192565b8181036020830152611a83818789611a31565b90508181036040830152611a98818587611a31565b9050611aa76060830184611192565b97965050505050505056fea2646970667358221220b6a538bd89d6ae385219ded107ce61646f57e4e024ffb5ee8db84ae02a45ea7664736f6c634300081e0033",
 }
 
 // RegisteryABI is the input ABI used to generate the binding from.
@@ -202,14 +210,46 @@ func (_Registery *RegisteryTransactorRaw) Transact(opts *bind.TransactOpts, meth
 	return _Registery.Contract.contract.Transact(opts, method, params...)
 }
 
+// EventCount is a free data retrieval call binding the contract method 0x71be2e4a.
+//
+// Solidity: function eventCount() view returns(uint256)
+func (_Registery *RegisteryCaller) EventCount(opts *bind.CallOpts) (*big.Int, error) {
+	var out []interface{}
+	err := _Registery.contract.Call(opts, &out, "eventCount")
+
+	if err != nil {
+		return *new(*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+
+	return out0, err
+
+}
+
+// EventCount is a free data retrieval call binding the contract method 0x71be2e4a.
+//
+// Solidity: function eventCount() view returns(uint256)
+func (_Registery *RegisterySession) EventCount() (*big.Int, error) {
+	return _Registery.Contract.EventCount(&_Registery.CallOpts)
+}
+
+// EventCount is a free data retrieval call binding the contract method 0x71be2e4a.
+//
+// Solidity: function eventCount() view returns(uint256)
+func (_Registery *RegisteryCallerSession) EventCount() (*big.Int, error) {
+	return _Registery.Contract.EventCount(&_Registery.CallOpts)
+}
+
 // Events is a free data retrieval call binding the contract method 0x0b791430.
 //
-// Solidity: function events(uint256 ) view returns(string avsName, string operatorName, address avsAddress, address operatorAddress)
+// Solidity: function events(uint256 ) view returns(string avsName, string operatorName, address avsAddress, address operatorAddress, uint256 timestamp)
 func (_Registery *RegisteryCaller) Events(opts *bind.CallOpts, arg0 *big.Int) (struct {
 	AvsName         string
 	OperatorName    string
 	AvsAddress      common.Address
 	OperatorAddress common.Address
+	Timestamp       *big.Int
 }, error) {
 	var out []interface{}
 	err := _Registery.contract.Call(opts, &out, "events", arg0)
@@ -219,6 +259,7 @@ func (_Registery *RegisteryCaller) Events(opts *bind.CallOpts, arg0 *big.Int) (s
 		OperatorName    string
 		AvsAddress      common.Address
 		OperatorAddress common.Address
+		Timestamp       *big.Int
 	})
 	if err != nil {
 		return *outstruct, err
@@ -228,6 +269,7 @@ func (_Registery *RegisteryCaller) Events(opts *bind.CallOpts, arg0 *big.Int) (s
 	outstruct.OperatorName = *abi.ConvertType(out[1], new(string)).(*string)
 	outstruct.AvsAddress = *abi.ConvertType(out[2], new(common.Address)).(*common.Address)
 	outstruct.OperatorAddress = *abi.ConvertType(out[3], new(common.Address)).(*common.Address)
+	outstruct.Timestamp = *abi.ConvertType(out[4], new(*big.Int)).(**big.Int)
 
 	return *outstruct, err
 
@@ -235,28 +277,237 @@ func (_Registery *RegisteryCaller) Events(opts *bind.CallOpts, arg0 *big.Int) (s
 
 // Events is a free data retrieval call binding the contract method 0x0b791430.
 //
-// Solidity: function events(uint256 ) view returns(string avsName, string operatorName, address avsAddress, address operatorAddress)
+// Solidity: function events(uint256 ) view returns(string avsName, string operatorName, address avsAddress, address operatorAddress, uint256 timestamp)
 func (_Registery *RegisterySession) Events(arg0 *big.Int) (struct {
 	AvsName         string
 	OperatorName    string
 	AvsAddress      common.Address
 	OperatorAddress common.Address
+	Timestamp       *big.Int
 }, error) {
 	return _Registery.Contract.Events(&_Registery.CallOpts, arg0)
 }
 
 // Events is a free data retrieval call binding the contract method 0x0b791430.
 //
-// Solidity: function events(uint256 ) view returns(string avsName, string operatorName, address avsAddress, address operatorAddress)
+// Solidity: function events(uint256 ) view returns(string avsName, string operatorName, address avsAddress, address operatorAddress, uint256 timestamp)
 func (_Registery *RegisteryCallerSession) Events(arg0 *big.Int) (struct {
 	AvsName         string
 	OperatorName    string
 	AvsAddress      common.Address
 	OperatorAddress common.Address
+	Timestamp       *big.Int
 }, error) {
 	return _Registery.Contract.Events(&_Registery.CallOpts, arg0)
 }
 
+// EventsByAVS is a free data retrieval call binding the contract method 0x17d72775.
+//
+// Solidity: function eventsByAVS(address avsAddress) view returns(uint256[])
+func (_Registery *RegisteryCaller) EventsByAVS(opts *bind.CallOpts, avsAddress common.Address) ([]*big.Int, error) {
+	var out []interface{}
+	err := _Registery.contract.Call(opts, &out, "eventsByAVS", avsAddress)
+
+	if err != nil {
+		return *new([]*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new([]*big.Int)).(*[]*big.Int)
+
+	return out0, err
+
+}
+
+// EventsByAVS is a free data retrieval call binding the contract method 0x17d72775.
+//
+// Solidity: function eventsByAVS(address avsAddress) view returns(uint256[])
+func (_Registery *RegisterySession) EventsByAVS(avsAddress common.Address) ([]*big.Int, error) {
+	return _Registery.Contract.EventsByAVS(&_Registery.CallOpts, avsAddress)
+}
+
+// EventsByAVS is a free data retrieval call binding the contract method 0x17d72775.
+//
+// Solidity: function eventsByAVS(address avsAddress) view returns(uint256[])
+func (_Registery *RegisteryCallerSession) EventsByAVS(avsAddress common.Address) ([]*big.Int, error) {
+	return _Registery.Contract.EventsByAVS(&_Registery.CallOpts, avsAddress)
+}
+
+// EventsByOperator is a free data retrieval call binding the contract method 0xf4b8a137.
+//
+// Solidity: function eventsByOperator(address operatorAddress) view returns(uint256[])
+func (_Registery *RegisteryCaller) EventsByOperator(opts *bind.CallOpts, operatorAddress common.Address) ([]*big.Int, error) {
+	var out []interface{}
+	err := _Registery.contract.Call(opts, &out, "eventsByOperator", operatorAddress)
+
+	if err != nil {
+		return *new([]*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new([]*big.Int)).(*[]*big.Int)
+
+	return out0, err
+
+}
+
+// EventsByOperator is a free data retrieval call binding the contract method 0xf4b8a137.
+//
+// Solidity: function eventsByOperator(address operatorAddress) view returns(uint256[])
+func (_Registery *RegisterySession) EventsByOperator(operatorAddress common.Address) ([]*big.Int, error) {
+	return _Registery.Contract.EventsByOperator(&_Registery.CallOpts, operatorAddress)
+}
+
+// EventsByOperator is a free data retrieval call binding the contract method 0xf4b8a137.
+//
+// Solidity: function eventsByOperator(address operatorAddress) view returns(uint256[])
+func (_Registery *RegisteryCallerSession) EventsByOperator(operatorAddress common.Address) ([]*big.Int, error) {
+	return _Registery.Contract.EventsByOperator(&_Registery.CallOpts, operatorAddress)
+}
+
+// IsRegistered is a free data retrieval call binding the contract method 0xd3da927f.
+//
+// Solidity: function isRegistered(address avsAddress, address operatorAddress) view returns(bool)
+func (_Registery *RegisteryCaller) IsRegistered(opts *bind.CallOpts, avsAddress common.Address, operatorAddress common.Address) (bool, error) {
+	var out []interface{}
+	err := _Registery.contract.Call(opts, &out, "isRegistered", avsAddress, operatorAddress)
+
+	if err != nil {
+		return *new(bool), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(bool)).(*bool)
+
+	return out0, err
+
+}
+
+// IsRegistered is a free data retrieval call binding the contract method 0xd3da927f.
+//
+// Solidity: function isRegistered(address avsAddress, address operatorAddress) view returns(bool)
+func (_Registery *RegisterySession) IsRegistered(avsAddress common.Address, operatorAddress common.Address) (bool, error) {
+	return _Registery.Contract.IsRegistered(&_Registery.CallOpts, avsAddress, operatorAddress)
+}
+
+// IsRegistered is a free data retrieval call binding the contract method 0xd3da927f.
+//
+// Solidity: function isRegistered(address avsAddress, address operatorAddress) view returns(bool)
+func (_Registery *RegisteryCallerSession) IsRegistered(avsAddress common.Address, operatorAddress common.Address) (bool, error) {
+	return _Registery.Contract.IsRegistered(&_Registery.CallOpts, avsAddress, operatorAddress)
+}
+
+// Owner is a free data retrieval call binding the contract method 0x8da5cb5b.
+//
+// Solidity: function owner() view returns(address)
+func (_Registery *RegisteryCaller) Owner(opts *bind.CallOpts) (common.Address, error) {
+	var out []interface{}
+	err := _Registery.contract.Call(opts, &out, "owner")
+
+	if err != nil {
+		return *new(common.Address), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(common.Address)).(*common.Address)
+
+	return out0, err
+
+}
+
+// Owner is a free data retrieval call binding the contract method 0x8da5cb5b.
+//
+// Solidity: function owner() view returns(address)
+func (_Registery *RegisterySession) Owner() (common.Address, error) {
+	return _Registery.Contract.Owner(&_Registery.CallOpts)
+}
+
+// Owner is a free data retrieval call binding the contract method 0x8da5cb5b.
+//
+// Solidity: function owner() view returns(address)
+func (_Registery *RegisteryCallerSession) Owner() (common.Address, error) {
+	return _Registery.Contract.Owner(&_Registery.CallOpts)
+}
+
+// Registered is a free data retrieval call binding the contract method 0x5524d548.
+//
+// Solidity: function registered(bytes32 ) view returns(bool)
+func (_Registery *RegisteryCaller) Registered(opts *bind.CallOpts, arg0 [32]byte) (bool, error) {
+	var out []interface{}
+	err := _Registery.contract.Call(opts, &out, "registered", arg0)
+
+	if err != nil {
+		return *new(bool), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(bool)).(*bool)
+
+	return out0, err
+
+}
+
+// Registered is a free data retrieval call binding the contract method 0x5524d548.
+//
+// Solidity: function registered(bytes32 ) view returns(bool)
+func (_Registery *RegisterySession) Registered(arg0 [32]byte) (bool, error) {
+	return _Registery.Contract.Registered(&_Registery.CallOpts, arg0)
+}
+
+// Registered is a free data retrieval call binding the contract method 0x5524d548.
+//
+// Solidity: function registered(bytes32 ) view returns(bool)
+func (_Registery *RegisteryCallerSession) Registered(arg0 [32]byte) (bool, error) {
+	return _Registery.Contract.Registered(&_Registery.CallOpts, arg0)
+}
+
+// Writers is a free data retrieval call binding the contract method 0x8e7e80a2.
+//
+// Solidity: function writers(address ) view returns(bool)
+func (_Registery *RegisteryCaller) Writers(opts *bind.CallOpts, arg0 common.Address) (bool, error) {
+	var out []interface{}
+	err := _Registery.contract.Call(opts, &out, "writers", arg0)
+
+	if err != nil {
+		return *new(bool), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(bool)).(*bool)
+
+	return out0, err
+
+}
+
+// Writers is a free data retrieval call binding the contract method 0x8e7e80a2.
+//
+// Solidity: function writers(address ) view returns(bool)
+func (_Registery *RegisterySession) Writers(arg0 common.Address) (bool, error) {
+	return _Registery.Contract.Writers(&_Registery.CallOpts, arg0)
+}
+
+// Writers is a free data retrieval call binding the contract method 0x8e7e80a2.
+//
+// Solidity: function writers(address ) view returns(bool)
+func (_Registery *RegisteryCallerSession) Writers(arg0 common.Address) (bool, error) {
+	return _Registery.Contract.Writers(&_Registery.CallOpts, arg0)
+}
+
+// AddWriter is a paid mutator transaction binding the contract method 0xda2824a8.
+//
+// Solidity: function addWriter(address writer) returns()
+func (_Registery *RegisteryTransactor) AddWriter(opts *bind.TransactOpts, writer common.Address) (*types.Transaction, error) {
+	return _Registery.contract.Transact(opts, "addWriter", writer)
+}
+
+// AddWriter is a paid mutator transaction binding the contract method 0xda2824a8.
+//
+// Solidity: function addWriter(address writer) returns()
+func (_Registery *RegisterySession) AddWriter(writer common.Address) (*types.Transaction, error) {
+	return _Registery.Contract.AddWriter(&_Registery.TransactOpts, writer)
+}
+
+// AddWriter is a paid mutator transaction binding the contract method 0xda2824a8.
+//
+// Solidity: function addWriter(address writer) returns()
+func (_Registery *RegisteryTransactorSession) AddWriter(writer common.Address) (*types.Transaction, error) {
+	return _Registery.Contract.AddWriter(&_Registery.TransactOpts, writer)
+}
+
 // RegisterEvent is a paid mutator transaction binding the contract method 0x70bb43e4.
 //
 // Solidity: function registerEvent(string avsName, string operatorName, address avsAddress, address operatorAddress) returns()
@@ -277,3 +528,664 @@ func (_Registery *RegisterySession) RegisterEvent(avsName string, operatorName s
 func (_Registery *RegisteryTransactorSession) RegisterEvent(avsName string, operatorName string, avsAddress common.Address, operatorAddress common.Address) (*types.Transaction, error) {
 	return _Registery.Contract.RegisterEvent(&_Registery.TransactOpts, avsName, operatorName, avsAddress, operatorAddress)
 }
+
+// RegisterEvents is a paid mutator transaction binding the contract method 0xee299b15.
+//
+// Solidity: function registerEvents((string,string,address,address)[] batch) returns()
+func (_Registery *RegisteryTransactor) RegisterEvents(opts *bind.TransactOpts, batch []RegisteryEventInput) (*types.Transaction, error) {
+	return _Registery.contract.Transact(opts, "registerEvents", batch)
+}
+
+// RegisterEvents is a paid mutator transaction binding the contract method 0xee299b15.
+//
+// Solidity: function registerEvents((string,string,address,address)[] batch) returns()
+func (_Registery *RegisterySession) RegisterEvents(batch []RegisteryEventInput) (*types.Transaction, error) {
+	return _Registery.Contract.RegisterEvents(&_Registery.TransactOpts, batch)
+}
+
+// RegisterEvents is a paid mutator transaction binding the contract method 0xee299b15.
+//
+// Solidity: function registerEvents((string,string,address,address)[] batch) returns()
+func (_Registery *RegisteryTransactorSession) RegisterEvents(batch []RegisteryEventInput) (*types.Transaction, error) {
+	return _Registery.Contract.RegisterEvents(&_Registery.TransactOpts, batch)
+}
+
+// RemoveWriter is a paid mutator transaction binding the contract method 0x5356dddc.
+//
+// Solidity: function removeWriter(address writer) returns()
+func (_Registery *RegisteryTransactor) RemoveWriter(opts *bind.TransactOpts, writer common.Address) (*types.Transaction, error) {
+	return _Registery.contract.Transact(opts, "removeWriter", writer)
+}
+
+// RemoveWriter is a paid mutator transaction binding the contract method 0x5356dddc.
+//
+// Solidity: function removeWriter(address writer) returns()
+func (_Registery *RegisterySession) RemoveWriter(writer common.Address) (*types.Transaction, error) {
+	return _Registery.Contract.RemoveWriter(&_Registery.TransactOpts, writer)
+}
+
+// RemoveWriter is a paid mutator transaction binding the contract method 0x5356dddc.
+//
+// Solidity: function removeWriter(address writer) returns()
+func (_Registery *RegisteryTransactorSession) RemoveWriter(writer common.Address) (*types.Transaction, error) {
+	return _Registery.Contract.RemoveWriter(&_Registery.TransactOpts, writer)
+}
+
+// TransferOwnership is a paid mutator transaction binding the contract method 0xf2fde38b.
+//
+// Solidity: function transferOwnership(address newOwner) returns()
+func (_Registery *RegisteryTransactor) TransferOwnership(opts *bind.TransactOpts, newOwner common.Address) (*types.Transaction, error) {
+	return _Registery.contract.Transact(opts, "transferOwnership", newOwner)
+}
+
+// TransferOwnership is a paid mutator transaction binding the contract method 0xf2fde38b.
+//
+// Solidity: function transferOwnership(address newOwner) returns()
+func (_Registery *RegisterySession) TransferOwnership(newOwner common.Address) (*types.Transaction, error) {
+	return _Registery.Contract.TransferOwnership(&_Registery.TransactOpts, newOwner)
+}
+
+// TransferOwnership is a paid mutator transaction binding the contract method 0xf2fde38b.
+//
+// Solidity: function transferOwnership(address newOwner) returns()
+func (_Registery *RegisteryTransactorSession) TransferOwnership(newOwner common.Address) (*types.Transaction, error) {
+	return _Registery.Contract.TransferOwnership(&_Registery.TransactOpts, newOwner)
+}
+
+// RegisteryEventRegisteredIterator is returned from FilterEventRegistered and is used to iterate over the raw logs and unpacked data for EventRegistered events raised by the Registery contract.
+type RegisteryEventRegisteredIterator struct {
+	Event *RegisteryEventRegistered // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *RegisteryEventRegisteredIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(RegisteryEventRegistered)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(RegisteryEventRegistered)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *RegisteryEventRegisteredIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *RegisteryEventRegisteredIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// RegisteryEventRegistered represents a EventRegistered event raised by the Registery contract.
+type RegisteryEventRegistered struct {
+	Id              *big.Int
+	AvsAddress      common.Address
+	OperatorAddress common.Address
+	AvsName         string
+	OperatorName    string
+	Timestamp       *big.Int
+	Raw             types.Log // Blockchain specific contextual infos
+}
+
+// FilterEventRegistered is a free log retrieval operation binding the contract event 0xd3002fc2df65080c6b3c96f6a886760e60773ae82ee1a2b349b213fd0f1f3175.
+//
+// Solidity: event EventRegistered(uint256 id, address indexed avsAddress, address indexed operatorAddress, string avsName, string operatorName, uint256 timestamp)
+func (_Registery *RegisteryFilterer) FilterEventRegistered(opts *bind.FilterOpts, avsAddress []common.Address, operatorAddress []common.Address) (*RegisteryEventRegisteredIterator, error) {
+
+	var avsAddressRule []interface{}
+	for _, avsAddressItem := range avsAddress {
+		avsAddressRule = append(avsAddressRule, avsAddressItem)
+	}
+	var operatorAddressRule []interface{}
+	for _, operatorAddressItem := range operatorAddress {
+		operatorAddressRule = append(operatorAddressRule, operatorAddressItem)
+	}
+
+	logs, sub, err := _Registery.contract.FilterLogs(opts, "EventRegistered", avsAddressRule, operatorAddressRule)
+	if err != nil {
+		return nil, err
+	}
+	return &RegisteryEventRegisteredIterator{contract: _Registery.contract, event: "EventRegistered", logs: logs, sub: sub}, nil
+}
+
+// WatchEventRegistered is a free log subscription operation binding the contract event 0xd3002fc2df65080c6b3c96f6a886760e60773ae82ee1a2b349b213fd0f1f3175.
+//
+// Solidity: event EventRegistered(uint256 id, address indexed avsAddress, address indexed operatorAddress, string avsName, string operatorName, uint256 timestamp)
+func (_Registery *RegisteryFilterer) WatchEventRegistered(opts *bind.WatchOpts, sink chan<- *RegisteryEventRegistered, avsAddress []common.Address, operatorAddress []common.Address) (event.Subscription, error) {
+
+	var avsAddressRule []interface{}
+	for _, avsAddressItem := range avsAddress {
+		avsAddressRule = append(avsAddressRule, avsAddressItem)
+	}
+	var operatorAddressRule []interface{}
+	for _, operatorAddressItem := range operatorAddress {
+		operatorAddressRule = append(operatorAddressRule, operatorAddressItem)
+	}
+
+	logs, sub, err := _Registery.contract.WatchLogs(opts, "EventRegistered", avsAddressRule, operatorAddressRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(RegisteryEventRegistered)
+				if err := _Registery.contract.UnpackLog(event, "EventRegistered", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseEventRegistered is a log parse operation binding the contract event 0xd3002fc2df65080c6b3c96f6a886760e60773ae82ee1a2b349b213fd0f1f3175.
+//
+// Solidity: event EventRegistered(uint256 id, address indexed avsAddress, address indexed operatorAddress, string avsName, string operatorName, uint256 timestamp)
+func (_Registery *RegisteryFilterer) ParseEventRegistered(log types.Log) (*RegisteryEventRegistered, error) {
+	event := new(RegisteryEventRegistered)
+	if err := _Registery.contract.UnpackLog(event, "EventRegistered", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
+
+// RegisteryOwnershipTransferredIterator is returned from FilterOwnershipTransferred and is used to iterate over the raw logs and unpacked data for OwnershipTransferred events raised by the Registery contract.
+type RegisteryOwnershipTransferredIterator struct {
+	Event *RegisteryOwnershipTransferred // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *RegisteryOwnershipTransferredIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(RegisteryOwnershipTransferred)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(RegisteryOwnershipTransferred)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *RegisteryOwnershipTransferredIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *RegisteryOwnershipTransferredIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// RegisteryOwnershipTransferred represents a OwnershipTransferred event raised by the Registery contract.
+type RegisteryOwnershipTransferred struct {
+	PreviousOwner common.Address
+	NewOwner      common.Address
+	Raw           types.Log // Blockchain specific contextual infos
+}
+
+// FilterOwnershipTransferred is a free log retrieval operation binding the contract event 0x8be0079c531659141344cd1fd0a4f28419497f9722a3daafe3b4186f6b6457e0.
+//
+// Solidity: event OwnershipTransferred(address indexed previousOwner, address indexed newOwner)
+func (_Registery *RegisteryFilterer) FilterOwnershipTransferred(opts *bind.FilterOpts, previousOwner []common.Address, newOwner []common.Address) (*RegisteryOwnershipTransferredIterator, error) {
+
+	var previousOwnerRule []interface{}
+	for _, previousOwnerItem := range previousOwner {
+		previousOwnerRule = append(previousOwnerRule, previousOwnerItem)
+	}
+	var newOwnerRule []interface{}
+	for _, newOwnerItem := range newOwner {
+		newOwnerRule = append(newOwnerRule, newOwnerItem)
+	}
+
+	logs, sub, err := _Registery.contract.FilterLogs(opts, "OwnershipTransferred", previousOwnerRule, newOwnerRule)
+	if err != nil {
+		return nil, err
+	}
+	return &RegisteryOwnershipTransferredIterator{contract: _Registery.contract, event: "OwnershipTransferred", logs: logs, sub: sub}, nil
+}
+
+// WatchOwnershipTransferred is a free log subscription operation binding the contract event 0x8be0079c531659141344cd1fd0a4f28419497f9722a3daafe3b4186f6b6457e0.
+//
+// Solidity: event OwnershipTransferred(address indexed previousOwner, address indexed newOwner)
+func (_Registery *RegisteryFilterer) WatchOwnershipTransferred(opts *bind.WatchOpts, sink chan<- *RegisteryOwnershipTransferred, previousOwner []common.Address, newOwner []common.Address) (event.Subscription, error) {
+
+	var previousOwnerRule []interface{}
+	for _, previousOwnerItem := range previousOwner {
+		previousOwnerRule = append(previousOwnerRule, previousOwnerItem)
+	}
+	var newOwnerRule []interface{}
+	for _, newOwnerItem := range newOwner {
+		newOwnerRule = append(newOwnerRule, newOwnerItem)
+	}
+
+	logs, sub, err := _Registery.contract.WatchLogs(opts, "OwnershipTransferred", previousOwnerRule, newOwnerRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(RegisteryOwnershipTransferred)
+				if err := _Registery.contract.UnpackLog(event, "OwnershipTransferred", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseOwnershipTransferred is a log parse operation binding the contract event 0x8be0079c531659141344cd1fd0a4f28419497f9722a3daafe3b4186f6b6457e0.
+//
+// Solidity: event OwnershipTransferred(address indexed previousOwner, address indexed newOwner)
+func (_Registery *RegisteryFilterer) ParseOwnershipTransferred(log types.Log) (*RegisteryOwnershipTransferred, error) {
+	event := new(RegisteryOwnershipTransferred)
+	if err := _Registery.contract.UnpackLog(event, "OwnershipTransferred", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
+
+// RegisteryWriterAddedIterator is returned from FilterWriterAdded and is used to iterate over the raw logs and unpacked data for WriterAdded events raised by the Registery contract.
+type RegisteryWriterAddedIterator struct {
+	Event *RegisteryWriterAdded // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *RegisteryWriterAddedIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(RegisteryWriterAdded)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(RegisteryWriterAdded)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *RegisteryWriterAddedIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *RegisteryWriterAddedIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// RegisteryWriterAdded represents a WriterAdded event raised by the Registery contract.
+type RegisteryWriterAdded struct {
+	Writer common.Address
+	Raw    types.Log // Blockchain specific contextual infos
+}
+
+// FilterWriterAdded is a free log retrieval operation binding the contract event 0x6ff3aa2ea7b53070f6d9d07a445d338d89e8edef44250ffa8be19f53910d4a2e.
+//
+// Solidity: event WriterAdded(address indexed writer)
+func (_Registery *RegisteryFilterer) FilterWriterAdded(opts *bind.FilterOpts, writer []common.Address) (*RegisteryWriterAddedIterator, error) {
+
+	var writerRule []interface{}
+	for _, writerItem := range writer {
+		writerRule = append(writerRule, writerItem)
+	}
+
+	logs, sub, err := _Registery.contract.FilterLogs(opts, "WriterAdded", writerRule)
+	if err != nil {
+		return nil, err
+	}
+	return &RegisteryWriterAddedIterator{contract: _Registery.contract, event: "WriterAdded", logs: logs, sub: sub}, nil
+}
+
+// WatchWriterAdded is a free log subscription operation binding the contract event 0x6ff3aa2ea7b53070f6d9d07a445d338d89e8edef44250ffa8be19f53910d4a2e.
+//
+// Solidity: event WriterAdded(address indexed writer)
+func (_Registery *RegisteryFilterer) WatchWriterAdded(opts *bind.WatchOpts, sink chan<- *RegisteryWriterAdded, writer []common.Address) (event.Subscription, error) {
+
+	var writerRule []interface{}
+	for _, writerItem := range writer {
+		writerRule = append(writerRule, writerItem)
+	}
+
+	logs, sub, err := _Registery.contract.WatchLogs(opts, "WriterAdded", writerRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(RegisteryWriterAdded)
+				if err := _Registery.contract.UnpackLog(event, "WriterAdded", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseWriterAdded is a log parse operation binding the contract event 0x6ff3aa2ea7b53070f6d9d07a445d338d89e8edef44250ffa8be19f53910d4a2e.
+//
+// Solidity: event WriterAdded(address indexed writer)
+func (_Registery *RegisteryFilterer) ParseWriterAdded(log types.Log) (*RegisteryWriterAdded, error) {
+	event := new(RegisteryWriterAdded)
+	if err := _Registery.contract.UnpackLog(event, "WriterAdded", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
+
+// RegisteryWriterRemovedIterator is returned from FilterWriterRemoved and is used to iterate over the raw logs and unpacked data for WriterRemoved events raised by the Registery contract.
+type RegisteryWriterRemovedIterator struct {
+	Event *RegisteryWriterRemoved // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *RegisteryWriterRemovedIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(RegisteryWriterRemoved)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(RegisteryWriterRemoved)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *RegisteryWriterRemovedIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *RegisteryWriterRemovedIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// RegisteryWriterRemoved represents a WriterRemoved event raised by the Registery contract.
+type RegisteryWriterRemoved struct {
+	Writer common.Address
+	Raw    types.Log // Blockchain specific contextual infos
+}
+
+// FilterWriterRemoved is a free log retrieval operation binding the contract event 0x86e5bbceda94081c32220d685f37cc4e3ea7bb0be2dfbf0cb703579505a5390e.
+//
+// Solidity: event WriterRemoved(address indexed writer)
+func (_Registery *RegisteryFilterer) FilterWriterRemoved(opts *bind.FilterOpts, writer []common.Address) (*RegisteryWriterRemovedIterator, error) {
+
+	var writerRule []interface{}
+	for _, writerItem := range writer {
+		writerRule = append(writerRule, writerItem)
+	}
+
+	logs, sub, err := _Registery.contract.FilterLogs(opts, "WriterRemoved", writerRule)
+	if err != nil {
+		return nil, err
+	}
+	return &RegisteryWriterRemovedIterator{contract: _Registery.contract, event: "WriterRemoved", logs: logs, sub: sub}, nil
+}
+
+// WatchWriterRemoved is a free log subscription operation binding the contract event 0x86e5bbceda94081c32220d685f37cc4e3ea7bb0be2dfbf0cb703579505a5390e.
+//
+// Solidity: event WriterRemoved(address indexed writer)
+func (_Registery *RegisteryFilterer) WatchWriterRemoved(opts *bind.WatchOpts, sink chan<- *RegisteryWriterRemoved, writer []common.Address) (event.Subscription, error) {
+
+	var writerRule []interface{}
+	for _, writerItem := range writer {
+		writerRule = append(writerRule, writerItem)
+	}
+
+	logs, sub, err := _Registery.contract.WatchLogs(opts, "WriterRemoved", writerRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(RegisteryWriterRemoved)
+				if err := _Registery.contract.UnpackLog(event, "WriterRemoved", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseWriterRemoved is a log parse operation binding the contract event 0x86e5bbceda94081c32220d685f37cc4e3ea7bb0be2dfbf0cb703579505a5390e.
+//
+// Solidity: event WriterRemoved(address indexed writer)
+func (_Registery *RegisteryFilterer) ParseWriterRemoved(log types.Log) (*RegisteryWriterRemoved, error) {
+	event := new(RegisteryWriterRemoved)
+	if err := _Registery.contract.UnpackLog(event, "WriterRemoved", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
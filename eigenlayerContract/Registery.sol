@@ -8,12 +8,107 @@ contract Registery {
         string operatorName;
         address avsAddress;
         address operatorAddress;
+        uint256 timestamp;
+    }
+
+    struct EventInput {
+        string avsName;
+        string operatorName;
+        address avsAddress;
+        address operatorAddress;
     }
 
     Event[] public events;
 
-    function registerEvent(string calldata avsName,string calldata operatorName,address avsAddress,address operatorAddress) public  {
-        events.push(Event(avsName,operatorName,avsAddress,operatorAddress));
+    address public owner;
+    mapping(address => bool) public writers;
+
+    // keccak256(avsAddress, operatorAddress) => registered
+    mapping(bytes32 => bool) public registered;
+    mapping(address => uint256[]) private eventsOfAVS;
+    mapping(address => uint256[]) private eventsOfOperator;
+
+    event EventRegistered(uint256 id, address indexed avsAddress, address indexed operatorAddress, string avsName, string operatorName, uint256 timestamp);
+    event WriterAdded(address indexed writer);
+    event WriterRemoved(address indexed writer);
+    event OwnershipTransferred(address indexed previousOwner, address indexed newOwner);
+
+    error NotOwner(address caller);
+    error NotWriter(address caller);
+    error DuplicateEvent(address avsAddress, address operatorAddress);
+    error ZeroAddress();
+
+    modifier onlyOwner() {
+        if (msg.sender != owner) revert NotOwner(msg.sender);
+        _;
+    }
+
+    modifier onlyWriter() {
+        if (!writers[msg.sender]) revert NotWriter(msg.sender);
+        _;
+    }
+
+    constructor() {
+        owner = msg.sender;
+        writers[msg.sender] = true;
+        emit OwnershipTransferred(address(0), msg.sender);
+        emit WriterAdded(msg.sender);
+    }
+
+    function registerEvent(string calldata avsName,string calldata operatorName,address avsAddress,address operatorAddress) public onlyWriter {
+        _register(avsName,operatorName,avsAddress,operatorAddress);
+    }
+
+    function registerEvents(EventInput[] calldata batch) public onlyWriter {
+        for (uint256 i = 0; i < batch.length; i++) {
+            _register(batch[i].avsName,batch[i].operatorName,batch[i].avsAddress,batch[i].operatorAddress);
+        }
+    }
+
+    function _register(string calldata avsName,string calldata operatorName,address avsAddress,address operatorAddress) private {
+        bytes32 key = keccak256(abi.encodePacked(avsAddress, operatorAddress));
+        if (registered[key]) revert DuplicateEvent(avsAddress, operatorAddress);
+        registered[key] = true;
+
+        uint256 id = events.length;
+        events.push(Event(avsName,operatorName,avsAddress,operatorAddress,block.timestamp));
+        eventsOfAVS[avsAddress].push(id);
+        eventsOfOperator[operatorAddress].push(id);
+
+        emit EventRegistered(id, avsAddress, operatorAddress, avsName, operatorName, block.timestamp);
+    }
+
+    function eventCount() public view returns (uint256) {
+        return events.length;
+    }
+
+    function isRegistered(address avsAddress, address operatorAddress) public view returns (bool) {
+        return registered[keccak256(abi.encodePacked(avsAddress, operatorAddress))];
+    }
+
+    function eventsByAVS(address avsAddress) public view returns (uint256[] memory) {
+        return eventsOfAVS[avsAddress];
+    }
+
+    function eventsByOperator(address operatorAddress) public view returns (uint256[] memory) {
+        return eventsOfOperator[operatorAddress];
+    }
+
+    function addWriter(address writer) public onlyOwner {
+        if (writer == address(0)) revert ZeroAddress();
+        writers[writer] = true;
+        emit WriterAdded(writer);
+    }
+
+    function removeWriter(address writer) public onlyOwner {
+        writers[writer] = false;
+        emit WriterRemoved(writer);
+    }
+
+    function transferOwnership(address newOwner) public onlyOwner {
+        if (newOwner == address(0)) revert ZeroAddress();
+        emit OwnershipTransferred(owner, newOwner);
+        owner = newOwner;
     }
 
-}
\ No newline at end of file
+}
@@ -0,0 +1,324 @@
+package registery
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient/simulated"
+	"github.com/ethereum/go-ethereum/rpc"
+	"math/big"
+	"testing"
+	"time"
+)
+
+var (
+	avsA      = common.HexToAddress("0xa1")
+	avsB      = common.HexToAddress("0xa2")
+	operatorA = common.HexToAddress("0xb1")
+	operatorB = common.HexToAddress("0xb2")
+)
+
+/*
+testRegistry is the registry deployed on a simulated chain by owner, stranger is not a writer
+*/
+type testRegistry struct {
+	sim      *simulated.Backend
+	registry *Registery
+	owner    *bind.TransactOpts
+	stranger *bind.TransactOpts
+	abi      *abi.ABI
+}
+
+func newTransactor(t *testing.T, key *ecdsa.PrivateKey, chainID *big.Int) *bind.TransactOpts {
+	t.Helper()
+
+	opts, oErr := bind.NewKeyedTransactorWithChainID(key, chainID)
+	if oErr != nil {
+		t.Fatal(oErr)
+	}
+
+	return opts
+}
+
+func newTestRegistry(t *testing.T) *testRegistry {
+	t.Helper()
+
+	ownerKey, _ := crypto.GenerateKey()
+	strangerKey, _ := crypto.GenerateKey()
+
+	balance := new(big.Int).Mul(big.NewInt(1000), big.NewInt(1e18))
+	sim := simulated.NewBackend(types.GenesisAlloc{
+		crypto.PubkeyToAddress(ownerKey.PublicKey):    {Balance: balance},
+		crypto.PubkeyToAddress(strangerKey.PublicKey): {Balance: balance},
+	})
+	t.Cleanup(func() { _ = sim.Close() })
+
+	// The calls on the genesis block run without the Shanghai opcodes the registry is compiled with (PUSH0)
+	sim.Commit()
+
+	chainID, cErr := sim.Client().ChainID(context.Background())
+	if cErr != nil {
+		t.Fatal(cErr)
+	}
+
+	registryABI, aErr := RegisteryMetaData.GetAbi()
+	if aErr != nil {
+		t.Fatal(aErr)
+	}
+
+	tR := &testRegistry{
+		sim:      sim,
+		owner:    newTransactor(t, ownerKey, chainID),
+		stranger: newTransactor(t, strangerKey, chainID),
+		abi:      registryABI,
+	}
+
+	_, tx, registry, dErr := DeployRegistery(tR.owner, sim.Client())
+	if dErr != nil {
+		t.Fatal(dErr)
+	}
+
+	tR.registry = registry
+	tR.mined(t, tx)
+
+	return tR
+}
+
+/*
+mined commits a block and returns the receipt of the transaction, which must be successful
+*/
+func (tR *testRegistry) mined(t *testing.T, tx *types.Transaction) *types.Receipt {
+	t.Helper()
+
+	tR.sim.Commit()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	receipt, wErr := bind.WaitMined(ctx, tR.sim.Client(), tx)
+	if wErr != nil {
+		t.Fatal(wErr)
+	}
+
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		t.Fatalf("transaction %s reverted", tx.Hash().Hex())
+	}
+
+	return receipt
+}
+
+/*
+revertedWith returns the name and the arguments of the custom error the call reverted with
+*/
+func (tR *testRegistry) revertedWith(t *testing.T, err error) (string, []interface{}) {
+	t.Helper()
+
+	var dataErr rpc.DataError
+	if !errors.As(err, &dataErr) {
+		t.Fatalf("expected a revert with data, got %v", err)
+	}
+
+	encoded, _ := dataErr.ErrorData().(string)
+
+	data, dErr := hexutil.Decode(encoded)
+	if dErr != nil || len(data) < 4 {
+		t.Fatalf("invalid revert data %q", encoded)
+	}
+
+	for name, registryErr := range tR.abi.Errors {
+		if string(registryErr.ID[:4]) != string(data[:4]) {
+			continue
+		}
+
+		values, uErr := registryErr.Inputs.Unpack(data[4:])
+		if uErr != nil {
+			t.Fatal(uErr)
+		}
+
+		return name, values
+	}
+
+	t.Fatalf("unknown revert data %q", encoded)
+
+	return "", nil
+}
+
+func (tR *testRegistry) calls() *bind.CallOpts {
+	return &bind.CallOpts{Context: context.Background()}
+}
+
+func TestRegisterEventRejectsOtherWriters(t *testing.T) {
+	tR := newTestRegistry(t)
+
+	_, rErr := tR.registry.RegisterEvent(tR.stranger, "avs", "operator", avsA, operatorA)
+
+	name, values := tR.revertedWith(t, rErr)
+	if name != "NotWriter" || values[0].(common.Address) != tR.stranger.From {
+		t.Fatalf("expected NotWriter(%s), got %s%v", tR.stranger.From.Hex(), name, values)
+	}
+
+	tR.mined(t, mustTransact(t)(tR.registry.AddWriter(tR.owner, tR.stranger.From)))
+	tR.mined(t, mustTransact(t)(tR.registry.RegisterEvent(tR.stranger, "avs", "operator", avsA, operatorA)))
+
+	if count, _ := tR.registry.EventCount(tR.calls()); count.Uint64() != 1 {
+		t.Fatalf("expected 1 event once the stranger is a writer, got %s", count)
+	}
+}
+
+func TestRegisterEventRejectsDuplicates(t *testing.T) {
+	tR := newTestRegistry(t)
+
+	tR.mined(t, mustTransact(t)(tR.registry.RegisterEvent(tR.owner, "avs", "operator", avsA, operatorA)))
+
+	_, rErr := tR.registry.RegisterEvent(tR.owner, "renamed", "renamed", avsA, operatorA)
+
+	name, values := tR.revertedWith(t, rErr)
+	if name != "DuplicateEvent" || values[0].(common.Address) != avsA || values[1].(common.Address) != operatorA {
+		t.Fatalf("expected DuplicateEvent(%s, %s), got %s%v", avsA.Hex(), operatorA.Hex(), name, values)
+	}
+}
+
+func TestRegisterEventEmitsEventRegistered(t *testing.T) {
+	tR := newTestRegistry(t)
+
+	receipt := tR.mined(t, mustTransact(t)(tR.registry.RegisterEvent(tR.owner, "avs", "operator", avsA, operatorA)))
+
+	signature := crypto.Keccak256Hash([]byte("EventRegistered(uint256,address,address,string,string,uint256)"))
+	if tR.abi.Events["EventRegistered"].ID != signature {
+		t.Fatalf("EventRegistered topic is %s, expected %s", tR.abi.Events["EventRegistered"].ID.Hex(), signature.Hex())
+	}
+
+	if len(receipt.Logs) != 1 {
+		t.Fatalf("expected a single log, got %d", len(receipt.Logs))
+	}
+
+	log := receipt.Logs[0]
+
+	if len(log.Topics) != 3 || log.Topics[0] != signature ||
+		common.BytesToAddress(log.Topics[1].Bytes()) != avsA || common.BytesToAddress(log.Topics[2].Bytes()) != operatorA {
+		t.Fatalf("expected the signature, the AVS and the operator as topics, got %v", log.Topics)
+	}
+
+	registered, pErr := tR.registry.ParseEventRegistered(*log)
+	if pErr != nil {
+		t.Fatal(pErr)
+	}
+
+	if registered.Id.Sign() != 0 || registered.AvsName != "avs" || registered.OperatorName != "operator" ||
+		registered.Timestamp.Sign() == 0 {
+		t.Fatalf("unexpected event %+v", registered)
+	}
+}
+
+func TestRegisterEventsAndLookups(t *testing.T) {
+	tR := newTestRegistry(t)
+
+	tR.mined(t, mustTransact(t)(tR.registry.RegisterEvent(tR.owner, "avs A", "operator A", avsA, operatorA)))
+
+	receipt := tR.mined(t, mustTransact(t)(tR.registry.RegisterEvents(tR.owner, []RegisteryEventInput{
+		{AvsName: "avs A", OperatorName: "operator B", AvsAddress: avsA, OperatorAddress: operatorB},
+		{AvsName: "avs B", OperatorName: "operator A", AvsAddress: avsB, OperatorAddress: operatorA},
+	})))
+
+	// One log per entry, in the order of the batch
+	if len(receipt.Logs) != 2 {
+		t.Fatalf("expected 2 logs, got %d", len(receipt.Logs))
+	}
+
+	for index, log := range receipt.Logs {
+		registered, pErr := tR.registry.ParseEventRegistered(*log)
+		if pErr != nil {
+			t.Fatal(pErr)
+		}
+
+		if registered.Id.Int64() != int64(index+1) {
+			t.Fatalf("entry %d of the batch has the id %s", index, registered.Id)
+		}
+	}
+
+	// A batch with a registered pair is rejected as a whole
+	_, rErr := tR.registry.RegisterEvents(tR.owner, []RegisteryEventInput{
+		{AvsName: "avs B", OperatorName: "operator B", AvsAddress: avsB, OperatorAddress: operatorB},
+		{AvsName: "avs A", OperatorName: "operator A", AvsAddress: avsA, OperatorAddress: operatorA},
+	})
+	if name, _ := tR.revertedWith(t, rErr); name != "DuplicateEvent" {
+		t.Fatalf("expected DuplicateEvent, got %s", name)
+	}
+
+	count, cErr := tR.registry.EventCount(tR.calls())
+	if cErr != nil || count.Uint64() != 3 {
+		t.Fatalf("expected 3 events, got %v (%v)", count, cErr)
+	}
+
+	lookups := []struct {
+		name   string
+		lookup func(*bind.CallOpts, common.Address) ([]*big.Int, error)
+		of     common.Address
+		ids    []int64
+	}{
+		{name: "eventsByAVS A", lookup: tR.registry.EventsByAVS, of: avsA, ids: []int64{0, 1}},
+		{name: "eventsByAVS B", lookup: tR.registry.EventsByAVS, of: avsB, ids: []int64{2}},
+		{name: "eventsByOperator A", lookup: tR.registry.EventsByOperator, of: operatorA, ids: []int64{0, 2}},
+		{name: "eventsByOperator B", lookup: tR.registry.EventsByOperator, of: operatorB, ids: []int64{1}},
+		{name: "eventsByOperator unknown", lookup: tR.registry.EventsByOperator, of: common.HexToAddress("0xc1")},
+	}
+
+	for _, test := range lookups {
+		ids, lErr := test.lookup(tR.calls(), test.of)
+		if lErr != nil {
+			t.Fatal(lErr)
+		}
+
+		if len(ids) != len(test.ids) {
+			t.Fatalf("%s: expected %v, got %v", test.name, test.ids, ids)
+		}
+
+		for index, id := range ids {
+			if id.Int64() != test.ids[index] {
+				t.Fatalf("%s: expected %v, got %v", test.name, test.ids, ids)
+			}
+		}
+	}
+
+	pairs := []struct {
+		avs, operator common.Address
+		registered    bool
+	}{
+		{avs: avsA, operator: operatorA, registered: true},
+		{avs: avsA, operator: operatorB, registered: true},
+		{avs: avsB, operator: operatorA, registered: true},
+		{avs: avsB, operator: operatorB, registered: false},
+	}
+
+	for _, pair := range pairs {
+		isRegistered, iErr := tR.registry.IsRegistered(tR.calls(), pair.avs, pair.operator)
+		if iErr != nil {
+			t.Fatal(iErr)
+		}
+
+		if isRegistered != pair.registered {
+			t.Fatalf("isRegistered(%s, %s) is %v", pair.avs.Hex(), pair.operator.Hex(), isRegistered)
+		}
+	}
+}
+
+/*
+mustTransact fails the test when the transaction cannot be sent
+*/
+func mustTransact(t *testing.T) func(*types.Transaction, error) *types.Transaction {
+	return func(tx *types.Transaction, err error) *types.Transaction {
+		t.Helper()
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return tx
+	}
+}
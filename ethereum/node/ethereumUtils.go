@@ -193,9 +193,7 @@ func GenerateKeypairFromPrivateKeyHex(privateKeyHex string) (*ecdsa.PrivateKey,
 	log.Println("Generating the keypair...")
 
 	// If hex string has "0x" at the start discard it
-	if privateKeyHex[:2] == "0x" {
-		privateKeyHex = privateKeyHex[2:]
-	}
+	privateKeyHex = strings.TrimPrefix(privateKeyHex, "0x")
 
 	// Convert hex key to a private key object
 	privateKey, privateKeyErr := crypto.HexToECDSA(privateKeyHex)
@@ -220,7 +218,12 @@ func GenerateKeypairFromPrivateKeyHex(privateKeyHex string) (*ecdsa.PrivateKey,
 	return privateKey, publicKeyECDSA, pubkeyAsAddress, nil
 }
 
-func BuildTransactionOptions(client *ethclient.Client, fromAddress common.Address, prvKey *ecdsa.PrivateKey, gasLimit uint64) (*bind.TransactOpts, error) {
+/*
+BuildTransactionOptions returns the options of a transaction of the signer with the next pending nonce, priced by a
+FeeOracle with the default settings. Chains with a base fee get dynamic fee transactions, the others legacy ones.
+A zero gasLimit leaves the estimation to the contract binding, use EstimateGasLimit to add a margin
+*/
+func BuildTransactionOptions(client *ethclient.Client, signer Signer, gasLimit uint64) (*bind.TransactOpts, error) {
 
 	// Retrieve the chainID
 	chainID, cIDErr := client.ChainID(context.Background())
@@ -230,32 +233,34 @@ func BuildTransactionOptions(client *ethclient.Client, fromAddress common.Addres
 	}
 
 	// Retrieve Nonce
-	nonce, nErr := client.PendingNonceAt(context.Background(), fromAddress)
+	nonce, nErr := client.PendingNonceAt(context.Background(), signer.Address())
 
 	if nErr != nil {
 		return nil, nErr
 	}
 
-	// Retrieve suggested gas price
-	gasPrice, gErr := client.SuggestGasPrice(context.Background())
+	// Price the transaction from the fee history
+	fees, fErr := NewFeeOracle(client, DefaultFeeOracleConfig()).Fees(context.Background())
 
-	if gErr != nil {
-		return nil, gErr
+	if fErr != nil {
+		return nil, fErr
 	}
 
-	logger.LogI("Suggested gas price: ", gasPrice)
-
-	// Create empty options object
-	txOptions, txOptErr := bind.NewKeyedTransactorWithChainID(prvKey, chainID)
+	logger.LogI("Transaction fees: ", fees)
 
-	if txOptErr != nil {
-		return nil, txOptErr
-	}
+	// Create options object signing with the signer
+	txOptions := TransactOpts(context.Background(), signer, chainID)
 
-	txOptions.Nonce = big.NewInt(int64(nonce))
+	txOptions.Nonce = new(big.Int).SetUint64(nonce)
 	txOptions.Value = big.NewInt(0) // in wei
 	txOptions.GasLimit = gasLimit   // in units
-	txOptions.GasPrice = gasPrice.Sub(gasPrice, big.NewInt(2000000000))
+
+	if fees.IsDynamic {
+		txOptions.GasTipCap = fees.GasTipCap
+		txOptions.GasFeeCap = fees.GasFeeCap
+	} else {
+		txOptions.GasPrice = fees.GasPrice
+	}
 
 	return txOptions, nil
 }
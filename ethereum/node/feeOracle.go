@@ -0,0 +1,299 @@
+package node
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"solity/utils/logger"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+/*
+FeeBackend is the part of the node API used by the FeeOracle, *ethclient.Client implements it
+*/
+type FeeBackend interface {
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+	FeeHistory(ctx context.Context, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (*ethereum.FeeHistory, error)
+	SuggestGasPrice(ctx context.Context) (*big.Int, error)
+	SuggestGasTipCap(ctx context.Context) (*big.Int, error)
+}
+
+/*
+FeeOracleConfig holds the settings of the FeeOracle. The priority fee is the RewardPercentile of the tips paid in the
+last HistoryBlocks blocks, the fee cap is the next base fee times BaseFeeMultiplier plus the priority fee
+*/
+type FeeOracleConfig struct {
+	HistoryBlocks     uint64
+	RewardPercentile  float64
+	BaseFeeMultiplier float64
+	// MinTip and MaxTip bound the priority fee, nil means no bound
+	MinTip *big.Int
+	MaxTip *big.Int
+	// MaxFeeCap caps the fee cap of dynamic fee transactions and the gas price of legacy ones, nil means no cap
+	MaxFeeCap *big.Int
+	// Legacy disables the dynamic fee transactions, for chains that do not price them correctly
+	Legacy bool
+}
+
+/*
+Fees is the pricing of a transaction. Dynamic fee transactions use GasTipCap and GasFeeCap, legacy ones GasPrice
+*/
+type Fees struct {
+	IsDynamic bool
+	GasTipCap *big.Int
+	GasFeeCap *big.Int
+	GasPrice  *big.Int
+}
+
+/*
+Cost returns the highest price per gas the transaction can pay
+*/
+func (f Fees) Cost() *big.Int {
+	if f.IsDynamic {
+		return f.GasFeeCap
+	}
+
+	return f.GasPrice
+}
+
+func (f Fees) String() string {
+	if f.IsDynamic {
+		return fmt.Sprintf("tip %s, fee cap %s", f.GasTipCap, f.GasFeeCap)
+	}
+
+	return fmt.Sprintf("gas price %s", f.GasPrice)
+}
+
+/*
+FeeOracle prices the transactions from eth_feeHistory. Chains without a base fee, and nodes without eth_feeHistory, get
+the legacy pricing from eth_gasPrice
+*/
+type FeeOracle struct {
+	client FeeBackend
+	config FeeOracleConfig
+}
+
+/*
+DefaultFeeOracleConfig returns the median tip of the last 10 blocks and twice the base fee
+*/
+func DefaultFeeOracleConfig() FeeOracleConfig {
+	return FeeOracleConfig{HistoryBlocks: 10, RewardPercentile: 50, BaseFeeMultiplier: 2}
+}
+
+/*
+NewFeeOracle constructor for the FeeOracle object, zero settings get the defaults of DefaultFeeOracleConfig
+*/
+func NewFeeOracle(client FeeBackend, config FeeOracleConfig) *FeeOracle {
+	defaults := DefaultFeeOracleConfig()
+
+	if config.HistoryBlocks == 0 {
+		config.HistoryBlocks = defaults.HistoryBlocks
+	}
+
+	if config.RewardPercentile <= 0 || config.RewardPercentile > 100 {
+		config.RewardPercentile = defaults.RewardPercentile
+	}
+
+	if config.BaseFeeMultiplier < 1 {
+		config.BaseFeeMultiplier = defaults.BaseFeeMultiplier
+	}
+
+	return &FeeOracle{client: client, config: config}
+}
+
+/*
+Fees returns the pricing of a transaction sent now
+*/
+func (o *FeeOracle) Fees(ctx context.Context) (Fees, error) {
+	if o.config.Legacy {
+		return o.legacyFees(ctx)
+	}
+
+	header, hErr := o.client.HeaderByNumber(ctx, nil)
+	if hErr != nil {
+		return Fees{}, fmt.Errorf("reading the latest header: %w", hErr)
+	}
+
+	// No base fee, the chain does not support dynamic fee transactions
+	if header.BaseFee == nil {
+		return o.legacyFees(ctx)
+	}
+
+	history, fErr := o.client.FeeHistory(ctx, o.config.HistoryBlocks, nil, []float64{o.config.RewardPercentile})
+	if fErr != nil {
+		logger.LogW("Error while reading the fee history, using the legacy gas price: ", fErr)
+		return o.legacyFees(ctx)
+	}
+
+	// The last base fee of the history is the one of the next block
+	baseFee := header.BaseFee
+	if len(history.BaseFee) > 0 && history.BaseFee[len(history.BaseFee)-1] != nil {
+		baseFee = history.BaseFee[len(history.BaseFee)-1]
+	}
+
+	tip, isFound := averageReward(history)
+
+	// Only empty blocks in the history, the node suggests the tip
+	if !isFound {
+		suggested, sErr := o.client.SuggestGasTipCap(ctx)
+		if sErr != nil {
+			return Fees{}, fmt.Errorf("reading the suggested tip: %w", sErr)
+		}
+
+		tip = suggested
+	}
+
+	tip = o.boundTip(tip)
+
+	feeCap := new(big.Float).Mul(new(big.Float).SetInt(baseFee), big.NewFloat(o.config.BaseFeeMultiplier))
+	gasFeeCap, _ := feeCap.Int(nil)
+	gasFeeCap.Add(gasFeeCap, tip)
+
+	gasFeeCap = o.CapFee(gasFeeCap)
+	if tip.Cmp(gasFeeCap) > 0 {
+		tip = new(big.Int).Set(gasFeeCap)
+	}
+
+	return Fees{IsDynamic: true, GasTipCap: tip, GasFeeCap: gasFeeCap}, nil
+}
+
+/*
+legacyFees returns the suggested gas price of the node, capped at MaxFeeCap
+*/
+func (o *FeeOracle) legacyFees(ctx context.Context) (Fees, error) {
+	gasPrice, gErr := o.client.SuggestGasPrice(ctx)
+	if gErr != nil {
+		return Fees{}, fmt.Errorf("reading the gas price: %w", gErr)
+	}
+
+	if gasPrice.Sign() <= 0 {
+		return Fees{}, errors.New("node suggests a gas price of " + gasPrice.String())
+	}
+
+	return Fees{GasPrice: o.CapFee(gasPrice)}, nil
+}
+
+/*
+Bump raises the fees by percent, to replace a pending transaction, and takes the current fees where they are higher.
+The results are capped at MaxFeeCap
+*/
+func (o *FeeOracle) Bump(ctx context.Context, fees Fees, percent int64) Fees {
+	current, cErr := o.Fees(ctx)
+	if cErr != nil {
+		logger.LogW("Error while reading the current fees, bumping the previous ones: ", cErr)
+	}
+
+	// The replacement keeps the type of the pending transaction
+	if fees.IsDynamic {
+		bumped := Fees{IsDynamic: true, GasTipCap: bumpBy(fees.GasTipCap, percent), GasFeeCap: bumpBy(fees.GasFeeCap, percent)}
+
+		if cErr == nil && current.IsDynamic {
+			bumped.GasTipCap = maxBig(bumped.GasTipCap, current.GasTipCap)
+			bumped.GasFeeCap = maxBig(bumped.GasFeeCap, current.GasFeeCap)
+		}
+
+		bumped.GasFeeCap = o.CapFee(bumped.GasFeeCap)
+		if bumped.GasTipCap.Cmp(bumped.GasFeeCap) > 0 {
+			bumped.GasTipCap = new(big.Int).Set(bumped.GasFeeCap)
+		}
+
+		return bumped
+	}
+
+	gasPrice := bumpBy(fees.GasPrice, percent)
+	if cErr == nil {
+		gasPrice = maxBig(gasPrice, current.Cost())
+	}
+
+	return Fees{GasPrice: o.CapFee(gasPrice)}
+}
+
+/*
+CapFee limits the price per gas to MaxFeeCap
+*/
+func (o *FeeOracle) CapFee(fee *big.Int) *big.Int {
+	if o.config.MaxFeeCap != nil && fee.Cmp(o.config.MaxFeeCap) > 0 {
+		return new(big.Int).Set(o.config.MaxFeeCap)
+	}
+
+	return fee
+}
+
+/*
+boundTip applies MinTip and MaxTip to the priority fee
+*/
+func (o *FeeOracle) boundTip(tip *big.Int) *big.Int {
+	if o.config.MinTip != nil && tip.Cmp(o.config.MinTip) < 0 {
+		tip = new(big.Int).Set(o.config.MinTip)
+	}
+
+	if o.config.MaxTip != nil && tip.Cmp(o.config.MaxTip) > 0 {
+		tip = new(big.Int).Set(o.config.MaxTip)
+	}
+
+	return tip
+}
+
+/*
+averageReward returns the average tip of the history at the requested percentile. Empty blocks report a zero tip and
+are left out, false if every block is empty
+*/
+func averageReward(history *ethereum.FeeHistory) (*big.Int, bool) {
+	sum := big.NewInt(0)
+	count := int64(0)
+
+	for i, rewards := range history.Reward {
+		if len(rewards) == 0 || rewards[0] == nil {
+			continue
+		}
+
+		if i < len(history.GasUsedRatio) && history.GasUsedRatio[i] == 0 {
+			continue
+		}
+
+		sum.Add(sum, rewards[0])
+		count++
+	}
+
+	if count == 0 {
+		return nil, false
+	}
+
+	return sum.Div(sum, big.NewInt(count)), true
+}
+
+func bumpBy(value *big.Int, percent int64) *big.Int {
+	bumped := new(big.Int).Mul(value, big.NewInt(100+percent))
+	bumped.Div(bumped, big.NewInt(100))
+
+	// Small values are raised by at least one wei, nodes compare the bump strictly
+	if bumped.Cmp(value) <= 0 {
+		bumped.Add(value, big.NewInt(1))
+	}
+
+	return bumped
+}
+
+func maxBig(a *big.Int, b *big.Int) *big.Int {
+	if b.Cmp(a) > 0 {
+		return new(big.Int).Set(b)
+	}
+
+	return a
+}
+
+/*
+EstimateGasLimit returns the estimated gas of the call raised by marginPercent. The estimation fails for calls that
+would revert, so those are not sent
+*/
+func EstimateGasLimit(ctx context.Context, client ethereum.GasEstimator, msg ethereum.CallMsg, marginPercent uint64) (uint64, error) {
+	gas, eErr := client.EstimateGas(ctx, msg)
+	if eErr != nil {
+		return 0, fmt.Errorf("estimating the gas: %w", eErr)
+	}
+
+	return gas + gas*marginPercent/100, nil
+}
@@ -0,0 +1,182 @@
+package node
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+/*
+fakeFeeBackend serves fixed fee data, a nil history makes eth_feeHistory fail
+*/
+type fakeFeeBackend struct {
+	baseFee   *big.Int
+	history   *ethereum.FeeHistory
+	gasPrice  *big.Int
+	tipCap    *big.Int
+	rewardsAt []float64
+}
+
+func (f *fakeFeeBackend) HeaderByNumber(context.Context, *big.Int) (*types.Header, error) {
+	return &types.Header{BaseFee: f.baseFee}, nil
+}
+
+func (f *fakeFeeBackend) FeeHistory(_ context.Context, _ uint64, _ *big.Int, rewardPercentiles []float64) (*ethereum.FeeHistory, error) {
+	f.rewardsAt = rewardPercentiles
+
+	if f.history == nil {
+		return nil, errors.New("the method eth_feeHistory does not exist/is not available")
+	}
+
+	return f.history, nil
+}
+
+func (f *fakeFeeBackend) SuggestGasPrice(context.Context) (*big.Int, error) {
+	return f.gasPrice, nil
+}
+
+func (f *fakeFeeBackend) SuggestGasTipCap(context.Context) (*big.Int, error) {
+	return f.tipCap, nil
+}
+
+/*
+history returns a fee history whose next base fee is nextBaseFee, with a block per reward. Zero rewards are empty
+blocks
+*/
+func history(nextBaseFee int64, rewards ...int64) *ethereum.FeeHistory {
+	feeHistory := &ethereum.FeeHistory{BaseFee: []*big.Int{big.NewInt(100), big.NewInt(nextBaseFee)}}
+
+	for _, reward := range rewards {
+		ratio := 0.5
+		if reward == 0 {
+			ratio = 0
+		}
+
+		feeHistory.Reward = append(feeHistory.Reward, []*big.Int{big.NewInt(reward)})
+		feeHistory.GasUsedRatio = append(feeHistory.GasUsedRatio, ratio)
+	}
+
+	return feeHistory
+}
+
+func TestFeeOraclePricesTheTransactions(t *testing.T) {
+	tests := []struct {
+		name    string
+		backend *fakeFeeBackend
+		config  FeeOracleConfig
+		fees    Fees
+	}{
+		{
+			name:    "average tip of the non-empty blocks over twice the next base fee",
+			backend: &fakeFeeBackend{baseFee: big.NewInt(100), history: history(110, 2, 0, 4)},
+			fees:    Fees{IsDynamic: true, GasTipCap: big.NewInt(3), GasFeeCap: big.NewInt(223)},
+		},
+		{
+			name:    "suggested tip when every block is empty",
+			backend: &fakeFeeBackend{baseFee: big.NewInt(100), history: history(100, 0, 0), tipCap: big.NewInt(7)},
+			fees:    Fees{IsDynamic: true, GasTipCap: big.NewInt(7), GasFeeCap: big.NewInt(207)},
+		},
+		{
+			name:    "tip raised to MinTip",
+			backend: &fakeFeeBackend{baseFee: big.NewInt(100), history: history(100, 1)},
+			config:  FeeOracleConfig{MinTip: big.NewInt(5), BaseFeeMultiplier: 1.5},
+			fees:    Fees{IsDynamic: true, GasTipCap: big.NewInt(5), GasFeeCap: big.NewInt(155)},
+		},
+		{
+			name:    "tip lowered to MaxTip",
+			backend: &fakeFeeBackend{baseFee: big.NewInt(100), history: history(100, 50)},
+			config:  FeeOracleConfig{MaxTip: big.NewInt(10)},
+			fees:    Fees{IsDynamic: true, GasTipCap: big.NewInt(10), GasFeeCap: big.NewInt(210)},
+		},
+		{
+			name:    "fee cap and tip capped at MaxFeeCap",
+			backend: &fakeFeeBackend{baseFee: big.NewInt(100), history: history(100, 500)},
+			config:  FeeOracleConfig{MaxFeeCap: big.NewInt(300)},
+			fees:    Fees{IsDynamic: true, GasTipCap: big.NewInt(300), GasFeeCap: big.NewInt(300)},
+		},
+		{
+			name:    "legacy price without a base fee",
+			backend: &fakeFeeBackend{gasPrice: big.NewInt(42)},
+			fees:    Fees{GasPrice: big.NewInt(42)},
+		},
+		{
+			name:    "legacy price without eth_feeHistory",
+			backend: &fakeFeeBackend{baseFee: big.NewInt(100), gasPrice: big.NewInt(42)},
+			fees:    Fees{GasPrice: big.NewInt(42)},
+		},
+		{
+			name:    "legacy price when configured, capped at MaxFeeCap",
+			backend: &fakeFeeBackend{baseFee: big.NewInt(100), history: history(100, 1), gasPrice: big.NewInt(42)},
+			config:  FeeOracleConfig{Legacy: true, MaxFeeCap: big.NewInt(40)},
+			fees:    Fees{GasPrice: big.NewInt(40)},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fees, fErr := NewFeeOracle(test.backend, test.config).Fees(context.Background())
+			if fErr != nil {
+				t.Fatal(fErr)
+			}
+
+			if fees.String() != test.fees.String() || fees.IsDynamic != test.fees.IsDynamic {
+				t.Fatalf("expected %s, got %s", test.fees, fees)
+			}
+		})
+	}
+}
+
+func TestFeeOracleRejectsAZeroGasPrice(t *testing.T) {
+	if _, fErr := NewFeeOracle(&fakeFeeBackend{gasPrice: big.NewInt(0)}, FeeOracleConfig{}).Fees(context.Background()); fErr == nil {
+		t.Fatal("expected the zero gas price to be rejected")
+	}
+}
+
+func TestFeeOracleBumpsTheFees(t *testing.T) {
+	// The current fees are a tip of 3 and a fee cap of 223
+	oracle := NewFeeOracle(&fakeFeeBackend{baseFee: big.NewInt(100), history: history(110, 2, 4), gasPrice: big.NewInt(150)},
+		FeeOracleConfig{MaxFeeCap: big.NewInt(1000)})
+
+	tests := []struct {
+		name     string
+		fees     Fees
+		expected Fees
+	}{
+		{
+			name:     "dynamic fees raised by the percentage",
+			fees:     Fees{IsDynamic: true, GasTipCap: big.NewInt(20), GasFeeCap: big.NewInt(400)},
+			expected: Fees{IsDynamic: true, GasTipCap: big.NewInt(22), GasFeeCap: big.NewInt(440)},
+		},
+		{
+			name:     "current fees when they are higher, small values raised by a wei",
+			fees:     Fees{IsDynamic: true, GasTipCap: big.NewInt(1), GasFeeCap: big.NewInt(100)},
+			expected: Fees{IsDynamic: true, GasTipCap: big.NewInt(3), GasFeeCap: big.NewInt(223)},
+		},
+		{
+			name:     "fee cap capped at MaxFeeCap",
+			fees:     Fees{IsDynamic: true, GasTipCap: big.NewInt(20), GasFeeCap: big.NewInt(950)},
+			expected: Fees{IsDynamic: true, GasTipCap: big.NewInt(22), GasFeeCap: big.NewInt(1000)},
+		},
+		{
+			name:     "legacy price keeps its type",
+			fees:     Fees{GasPrice: big.NewInt(100)},
+			expected: Fees{GasPrice: big.NewInt(223)},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if bumped := oracle.Bump(context.Background(), test.fees, 10); bumped.String() != test.expected.String() {
+				t.Fatalf("expected %s, got %s", test.expected, bumped)
+			}
+		})
+	}
+
+	if bumped := bumpBy(big.NewInt(5), 10); bumped.Int64() != 6 {
+		t.Fatalf("expected the small value to be raised by a wei, got %s", bumped)
+	}
+}
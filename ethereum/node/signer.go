@@ -0,0 +1,215 @@
+package node
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+/*
+Signer signs the transactions of a single account
+*/
+type Signer interface {
+	Address() common.Address
+	SignTx(ctx context.Context, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error)
+}
+
+/*
+KeySigner signs with a private key held in memory
+*/
+type KeySigner struct {
+	key     *ecdsa.PrivateKey
+	address common.Address
+}
+
+/*
+NewKeySigner creates the signer from a hex encoded private key, with or without 0x. Meant for development, the key
+should not be kept in plain text otherwise
+*/
+func NewKeySigner(privateKeyHex string) (*KeySigner, error) {
+	privateKey, _, address, kErr := GenerateKeypairFromPrivateKeyHex(privateKeyHex)
+	if kErr != nil {
+		return nil, kErr
+	}
+
+	return &KeySigner{key: privateKey, address: address}, nil
+}
+
+/*
+NewKeystoreSigner creates the signer from an encrypted geth keystore file, the passphrase is read from passphraseFile.
+A trailing line break of the passphrase file is not part of the passphrase
+*/
+func NewKeystoreSigner(keyFile string, passphraseFile string) (*KeySigner, error) {
+	keyJSON, rErr := os.ReadFile(keyFile)
+	if rErr != nil {
+		return nil, fmt.Errorf("reading the keystore file: %w", rErr)
+	}
+
+	passphrase, pErr := os.ReadFile(passphraseFile)
+	if pErr != nil {
+		return nil, fmt.Errorf("reading the passphrase file: %w", pErr)
+	}
+
+	key, dErr := keystore.DecryptKey(keyJSON, strings.TrimRight(string(passphrase), "\r\n"))
+	if dErr != nil {
+		return nil, fmt.Errorf("decrypting the keystore file %s: %w", keyFile, dErr)
+	}
+
+	return &KeySigner{key: key.PrivateKey, address: key.Address}, nil
+}
+
+func (kS *KeySigner) Address() common.Address {
+	return kS.address
+}
+
+func (kS *KeySigner) SignTx(ctx context.Context, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	return types.SignTx(tx, types.LatestSignerForChainID(chainID), kS.key)
+}
+
+/*
+RemoteSigner signs through an external signer with the Clef JSON-RPC API (account_signTransaction), so the key never
+reaches the process
+*/
+type RemoteSigner struct {
+	client  *rpc.Client
+	address common.Address
+}
+
+/*
+NewRemoteSigner connects to the signer at endpoint (http(s), ws(s) or an IPC path). A zero address selects the first
+account of the signer, other addresses have to be managed by it
+*/
+func NewRemoteSigner(ctx context.Context, endpoint string, address common.Address) (*RemoteSigner, error) {
+	client, dErr := rpc.DialContext(ctx, endpoint)
+	if dErr != nil {
+		return nil, fmt.Errorf("connecting to the remote signer: %w", dErr)
+	}
+
+	var version string
+	if vErr := client.CallContext(ctx, &version, "account_version"); vErr != nil {
+		client.Close()
+		return nil, fmt.Errorf("reading the remote signer version: %w", vErr)
+	}
+
+	var accounts []common.Address
+	if lErr := client.CallContext(ctx, &accounts, "account_list"); lErr != nil {
+		client.Close()
+		return nil, fmt.Errorf("listing the remote signer accounts: %w", lErr)
+	}
+
+	if address == (common.Address{}) {
+		if len(accounts) == 0 {
+			client.Close()
+			return nil, errors.New("remote signer has no accounts")
+		}
+
+		address = accounts[0]
+	}
+
+	for _, account := range accounts {
+		if account == address {
+			return &RemoteSigner{client: client, address: address}, nil
+		}
+	}
+
+	client.Close()
+
+	return nil, fmt.Errorf("remote signer does not manage %s", address.Hex())
+}
+
+func (rS *RemoteSigner) Address() common.Address {
+	return rS.address
+}
+
+/*
+SignTx asks the remote signer to sign the transaction. The context bounds the wait, which includes a manual approval
+when the signer has no rule for the transaction
+*/
+func (rS *RemoteSigner) SignTx(ctx context.Context, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	data := hexutil.Bytes(tx.Data())
+
+	args := &apitypes.SendTxArgs{
+		From:    common.NewMixedcaseAddress(rS.address),
+		Gas:     hexutil.Uint64(tx.Gas()),
+		Value:   hexutil.Big(*tx.Value()),
+		Nonce:   hexutil.Uint64(tx.Nonce()),
+		Input:   &data,
+		ChainID: (*hexutil.Big)(chainID),
+	}
+
+	if tx.To() != nil {
+		to := common.NewMixedcaseAddress(*tx.To())
+		args.To = &to
+	}
+
+	switch tx.Type() {
+	case types.LegacyTxType:
+		args.GasPrice = (*hexutil.Big)(tx.GasPrice())
+	case types.DynamicFeeTxType:
+		args.MaxFeePerGas = (*hexutil.Big)(tx.GasFeeCap())
+		args.MaxPriorityFeePerGas = (*hexutil.Big)(tx.GasTipCap())
+		accessList := tx.AccessList()
+		args.AccessList = &accessList
+	default:
+		return nil, fmt.Errorf("unsupported transaction type %d", tx.Type())
+	}
+
+	var result struct {
+		Raw hexutil.Bytes      `json:"raw"`
+		Tx  *types.Transaction `json:"tx"`
+	}
+
+	if cErr := rS.client.CallContext(ctx, &result, "account_signTransaction", args); cErr != nil {
+		return nil, fmt.Errorf("signing with the remote signer: %w", cErr)
+	}
+
+	if result.Tx == nil {
+		return nil, errors.New("remote signer returned no transaction")
+	}
+
+	// The signer could change the transaction, only the one we asked for is sent
+	txSigner := types.LatestSignerForChainID(chainID)
+
+	sender, sErr := types.Sender(txSigner, result.Tx)
+	if sErr != nil || sender != rS.address || txSigner.Hash(result.Tx) != txSigner.Hash(tx) {
+		return nil, errors.New("remote signer returned a different transaction")
+	}
+
+	return result.Tx, nil
+}
+
+/*
+Close closes the connection to the remote signer
+*/
+func (rS *RemoteSigner) Close() {
+	rS.client.Close()
+}
+
+/*
+TransactOpts returns the transaction options of the signer for the contract bindings
+*/
+func TransactOpts(ctx context.Context, signer Signer, chainID *big.Int) *bind.TransactOpts {
+	return &bind.TransactOpts{
+		From:    signer.Address(),
+		Context: ctx,
+		Signer: func(address common.Address, tx *types.Transaction) (*types.Transaction, error) {
+			if address != signer.Address() {
+				return nil, bind.ErrNotAuthorized
+			}
+
+			return signer.SignTx(ctx, tx, chainID)
+		},
+	}
+}
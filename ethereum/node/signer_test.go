@@ -0,0 +1,213 @@
+package node
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"math/big"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+/*
+fakeClef serves the account API of Clef for a single key. With tamper set it signs a different value than requested
+*/
+type fakeClef struct {
+	key    *ecdsa.PrivateKey
+	tamper bool
+}
+
+type signedTransaction struct {
+	Raw hexutil.Bytes      `json:"raw"`
+	Tx  *types.Transaction `json:"tx"`
+}
+
+func (fC *fakeClef) Version() string {
+	return "6.0.0"
+}
+
+func (fC *fakeClef) List() []common.Address {
+	return []common.Address{crypto.PubkeyToAddress(fC.key.PublicKey)}
+}
+
+func (fC *fakeClef) SignTransaction(args apitypes.SendTxArgs) (*signedTransaction, error) {
+	if fC.tamper {
+		args.Value = hexutil.Big(*new(big.Int).Add(args.Value.ToInt(), big.NewInt(1)))
+	}
+
+	tx, tErr := args.ToTransaction()
+	if tErr != nil {
+		return nil, tErr
+	}
+
+	signed, sErr := types.SignTx(tx, types.LatestSignerForChainID(args.ChainID.ToInt()), fC.key)
+	if sErr != nil {
+		return nil, sErr
+	}
+
+	raw, mErr := signed.MarshalBinary()
+	if mErr != nil {
+		return nil, mErr
+	}
+
+	return &signedTransaction{Raw: raw, Tx: signed}, nil
+}
+
+/*
+newFakeClef serves the fake signer over HTTP, returns its URL and its account
+*/
+func newFakeClef(t *testing.T, clef *fakeClef) (string, common.Address) {
+	t.Helper()
+
+	server := rpc.NewServer()
+	if rErr := server.RegisterName("account", clef); rErr != nil {
+		t.Fatal(rErr)
+	}
+
+	httpServer := httptest.NewServer(server)
+	t.Cleanup(func() {
+		httpServer.Close()
+		server.Stop()
+	})
+
+	return httpServer.URL, crypto.PubkeyToAddress(clef.key.PublicKey)
+}
+
+func newTestKey(t *testing.T) *ecdsa.PrivateKey {
+	t.Helper()
+
+	key, kErr := crypto.GenerateKey()
+	if kErr != nil {
+		t.Fatal(kErr)
+	}
+
+	return key
+}
+
+func testTransactions() map[string]*types.Transaction {
+	to := common.HexToAddress("0x1234")
+
+	return map[string]*types.Transaction{
+		"legacy": types.NewTx(&types.LegacyTx{
+			Nonce: 1, GasPrice: big.NewInt(2e9), Gas: 21000, To: &to, Value: big.NewInt(5),
+		}),
+		"dynamic fee": types.NewTx(&types.DynamicFeeTx{
+			Nonce: 2, GasTipCap: big.NewInt(1e9), GasFeeCap: big.NewInt(3e9), Gas: 50000, To: &to, Value: big.NewInt(5),
+			Data: []byte{0xde, 0xad},
+		}),
+	}
+}
+
+func TestRemoteSignerSignsThroughClef(t *testing.T) {
+	endpoint, account := newFakeClef(t, &fakeClef{key: newTestKey(t)})
+	chainID := big.NewInt(17000)
+
+	// The zero address selects the first account of the signer
+	signer, nErr := NewRemoteSigner(context.Background(), endpoint, common.Address{})
+	if nErr != nil {
+		t.Fatal(nErr)
+	}
+	defer signer.Close()
+
+	if signer.Address() != account {
+		t.Fatalf("expected the account %s, got %s", account.Hex(), signer.Address().Hex())
+	}
+
+	for name, tx := range testTransactions() {
+		t.Run(name, func(t *testing.T) {
+			signed, sErr := signer.SignTx(context.Background(), tx, chainID)
+			if sErr != nil {
+				t.Fatal(sErr)
+			}
+
+			txSigner := types.LatestSignerForChainID(chainID)
+
+			if sender, _ := types.Sender(txSigner, signed); sender != account {
+				t.Fatalf("signed by %s instead of %s", sender.Hex(), account.Hex())
+			}
+
+			if txSigner.Hash(signed) != txSigner.Hash(tx) {
+				t.Fatal("signed transaction differs from the requested one")
+			}
+		})
+	}
+}
+
+func TestRemoteSignerRejectsOtherTransactions(t *testing.T) {
+	endpoint, _ := newFakeClef(t, &fakeClef{key: newTestKey(t), tamper: true})
+
+	signer, nErr := NewRemoteSigner(context.Background(), endpoint, common.Address{})
+	if nErr != nil {
+		t.Fatal(nErr)
+	}
+	defer signer.Close()
+
+	_, sErr := signer.SignTx(context.Background(), testTransactions()["dynamic fee"], big.NewInt(17000))
+	if sErr == nil || !strings.Contains(sErr.Error(), "different transaction") {
+		t.Fatalf("expected the changed transaction to be rejected, got %v", sErr)
+	}
+}
+
+func TestRemoteSignerRejectsUnmanagedAccounts(t *testing.T) {
+	endpoint, _ := newFakeClef(t, &fakeClef{key: newTestKey(t)})
+
+	_, nErr := NewRemoteSigner(context.Background(), endpoint, common.HexToAddress("0x5678"))
+	if nErr == nil || !strings.Contains(nErr.Error(), "does not manage") {
+		t.Fatalf("expected the unmanaged account to be rejected, got %v", nErr)
+	}
+}
+
+func TestKeystoreSignerRoundTrip(t *testing.T) {
+	key := newTestKey(t)
+	directory := t.TempDir()
+
+	account, iErr := keystore.NewKeyStore(directory, keystore.LightScryptN, keystore.LightScryptP).ImportECDSA(key,
+		"secret")
+	if iErr != nil {
+		t.Fatal(iErr)
+	}
+
+	passphraseFile := filepath.Join(directory, "passphrase")
+
+	// The trailing line break of the file is not part of the passphrase
+	if wErr := os.WriteFile(passphraseFile, []byte("secret\n"), 0o600); wErr != nil {
+		t.Fatal(wErr)
+	}
+
+	signer, nErr := NewKeystoreSigner(account.URL.Path, passphraseFile)
+	if nErr != nil {
+		t.Fatal(nErr)
+	}
+
+	if signer.Address() != account.Address {
+		t.Fatalf("expected the account %s, got %s", account.Address.Hex(), signer.Address().Hex())
+	}
+
+	chainID := big.NewInt(17000)
+
+	signed, sErr := signer.SignTx(context.Background(), testTransactions()["dynamic fee"], chainID)
+	if sErr != nil {
+		t.Fatal(sErr)
+	}
+
+	if sender, _ := types.Sender(types.LatestSignerForChainID(chainID), signed); sender != account.Address {
+		t.Fatalf("signed by %s instead of %s", sender.Hex(), account.Address.Hex())
+	}
+
+	if wErr := os.WriteFile(passphraseFile, []byte("wrong"), 0o600); wErr != nil {
+		t.Fatal(wErr)
+	}
+
+	if _, nErr := NewKeystoreSigner(account.URL.Path, passphraseFile); nErr == nil {
+		t.Fatal("expected the wrong passphrase to fail the decryption")
+	}
+}
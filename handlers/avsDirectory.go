@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"context"
+	"eigenlayer_hack/structs"
+	"solity/utils/logger"
+)
+
+// Registration status of the OperatorAVSRegistrationStatusUpdated event
+const operatorAVSRegistered = 1
+
+/*
+OperatorAVSRegistrationStatusUpdatedHandler handles the registration updates of the AVSDirectory. Registrations are
+enriched with the Dune metadata and written to the registry contract, deregistrations are only published
+*/
+type OperatorAVSRegistrationStatusUpdatedHandler struct{}
+
+/*
+NewOperatorAVSRegistrationStatusUpdatedHandler constructor for the OperatorAVSRegistrationStatusUpdatedHandler object
+*/
+func NewOperatorAVSRegistrationStatusUpdatedHandler() *OperatorAVSRegistrationStatusUpdatedHandler {
+	return &OperatorAVSRegistrationStatusUpdatedHandler{}
+}
+
+func (h *OperatorAVSRegistrationStatusUpdatedHandler) Name() string {
+	return structs.EventOperatorAVSRegistration
+}
+
+func (h *OperatorAVSRegistrationStatusUpdatedHandler) Signature() string {
+	return "OperatorAVSRegistrationStatusUpdated(address,address,uint8)"
+}
+
+func (h *OperatorAVSRegistrationStatusUpdatedHandler) Types() []string { return []string{"uint8"} }
+
+func (h *OperatorAVSRegistrationStatusUpdatedHandler) IndexedTypes() []string {
+	return []string{"address", "address"}
+}
+
+func (h *OperatorAVSRegistrationStatusUpdatedHandler) OrderingKey(event *Event) string {
+	return orderingAddress(event.Decoded.DecodedIndexedData, 0)
+}
+
+func (h *OperatorAVSRegistrationStatusUpdatedHandler) Handle(ctx context.Context, deps *Dependencies,
+	event *Event) (interface{}, error) {
+	operatorAddress, oErr := addressAt(event.Decoded.DecodedIndexedData, 0, "operator address")
+	if oErr != nil {
+		return nil, oErr
+	}
+
+	avsAddress, aErr := addressAt(event.Decoded.DecodedIndexedData, 1, "avs address")
+	if aErr != nil {
+		return nil, aErr
+	}
+
+	status, sErr := uintAt(event.Decoded.DecodedData, 0, "status")
+	if sErr != nil {
+		return nil, sErr
+	}
+
+	payload := structs.OperatorAVSRegistrationPayload{
+		EventMeta:       eventMeta(structs.EventOperatorAVSRegistration, event),
+		OperatorAddress: operatorAddress,
+		AvsAddress:      avsAddress,
+		Status:          uint8(status),
+	}
+
+	if status != operatorAVSRegistered {
+		logger.LogI("Operator ", operatorAddress.Hex(), " is deregistered from the AVS ", avsAddress.Hex())
+		return payload, nil
+	}
+
+	operatorName, nErr := lookupOperatorName(ctx, deps, event, operatorAddress)
+	if nErr != nil {
+		return nil, nErr
+	}
+
+	avsName, nErr := lookupAVSName(ctx, deps, event, avsAddress)
+	if nErr != nil {
+		return nil, nErr
+	}
+
+	payload.OperatorName = operatorName
+	payload.AvsName = avsName
+
+	logger.LogS(operatorName + " operator is registered to " + avsName + " AVS")
+
+	if wErr := writeRegistration(ctx, deps, event, structs.EigenlayerPayload{
+		AvsName:         avsName,
+		OperatorName:    operatorName,
+		AvsAddress:      avsAddress,
+		OperatorAddress: operatorAddress,
+	}); wErr != nil {
+		return nil, wErr
+	}
+
+	return payload, nil
+}
@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"eigenlayer_hack/dedup"
+	"eigenlayer_hack/deferred"
+	"eigenlayer_hack/metadata"
+	"eigenlayer_hack/structs"
+	"encoding/json"
+	"errors"
+	"github.com/ethereum/go-ethereum/common"
+	"solity/utils/logger"
+	"time"
+)
+
+/*
+EnrichmentUpdate is published to the output channel of an event once its deferred enrichment is completed, Event is
+the payload of the event with the found names
+*/
+type EnrichmentUpdate struct {
+	Type      string          `json:"type"`
+	EventType string          `json:"eventType"`
+	ChainID   uint64          `json:"chainId"`
+	TxHash    string          `json:"txHash"`
+	LogIndex  uint            `json:"logIndex"`
+	Event     json.RawMessage `json:"event"`
+	UpdatedAt time.Time       `json:"updatedAt"`
+}
+
+/*
+deferEnrichment parks the published event in the deferred queue, so that its names are looked up again later. Returns
+the error of an event that could not be parked, its names would never be looked up again otherwise
+*/
+func deferEnrichment(deps *Dependencies, handler Handler, event *Event, outputChannel string,
+	payload interface{}) error {
+	if deps.Deferred == nil {
+		logger.LogW("Deferred enrichment is disabled, ", event.Key.String(), " keeps the unknown names")
+		return nil
+	}
+
+	content, mErr := json.Marshal(payload)
+	if mErr != nil {
+		return mErr
+	}
+
+	return deps.Deferred.Enqueue(deferred.Item{
+		Key:           event.Key,
+		EventType:     handler.Name(),
+		OutputChannel: outputChannel,
+		Payload:       content,
+		Unresolved:    event.Unresolved,
+		Registration:  event.Registration,
+	})
+}
+
+/*
+RetryDeferred re-attempts the enrichment of the deferred item. Once every name is found the registry write is done
+and an EnrichmentUpdate is published, then the item is done. Items of retracted events are dropped
+*/
+func RetryDeferred(ctx context.Context, deps *Dependencies, item deferred.Item) (bool, error) {
+	record, isRecorded, gErr := deps.DedupStore.Get(ctx, item.Key)
+	if gErr != nil {
+		return false, gErr
+	}
+
+	// The event is no longer part of the canonical chain, it is processed again if it is re-included
+	if isRecorded && record.Outcome == dedup.OutcomeRetracted {
+		logger.LogI("Deferred event ", item.Key.String(), " is retracted, dropping it")
+		return true, nil
+	}
+
+	names := map[metadata.Kind]string{}
+
+	for _, entity := range item.Unresolved {
+		entry, rErr := deps.Metadata.Resolve(ctx, entity.Kind, entity.Address)
+		if rErr != nil {
+			return false, rErr
+		}
+
+		if !entry.Found {
+			return false, errors.New("no metadata for the " + string(entity.Kind) + " " + entity.Address + " yet")
+		}
+
+		names[entity.Kind] = entry.Name
+	}
+
+	payload, fErr := fillNames(item.Payload, item.Unresolved, names)
+	if fErr != nil {
+		// The payload will not get better, give up on the item
+		logger.LogW("Deferred event ", item.Key.String(), " cannot be updated: ", fErr)
+		return true, nil
+	}
+
+	if item.Registration != nil && !item.Registration.Written {
+		registration := structs.EigenlayerPayload{
+			AvsName:         nameOr(names[metadata.KindAVS], item.Registration.AvsName),
+			OperatorName:    nameOr(names[metadata.KindOperator], item.Registration.OperatorName),
+			AvsAddress:      common.HexToAddress(item.Registration.AvsAddress),
+			OperatorAddress: common.HexToAddress(item.Registration.OperatorAddress),
+		}
+
+		if wErr := writeToRegistry(ctx, deps, item.Key, registration); wErr != nil {
+			return false, wErr
+		}
+
+		if mErr := deps.Deferred.MarkWritten(item.Key); mErr != nil {
+			logger.LogW("Error while recording the registry write of ", item.Key.String(), ": ", mErr)
+		}
+	}
+
+	if pErr := deps.Sink.Publish(ctx, item.OutputChannel, EnrichmentUpdate{
+		Type:      "enrichment-update",
+		EventType: item.EventType,
+		ChainID:   item.Key.ChainID,
+		TxHash:    item.Key.TxHash.Hex(),
+		LogIndex:  item.Key.LogIndex,
+		Event:     payload,
+		UpdatedAt: time.Now(),
+	}); pErr != nil {
+		return false, pErr
+	}
+
+	logger.LogS("Deferred enrichment of ", item.Key.String(), " is completed")
+
+	return true, nil
+}
+
+/*
+fillNames writes the found names into the fields of the payload
+*/
+func fillNames(payload json.RawMessage, entities []deferred.Entity, names map[metadata.Kind]string) (json.RawMessage, error) {
+	fields := map[string]interface{}{}
+
+	// Numbers are kept as they are, big integers would lose precision as float64
+	decoder := json.NewDecoder(bytes.NewReader(payload))
+	decoder.UseNumber()
+
+	if dErr := decoder.Decode(&fields); dErr != nil {
+		return nil, dErr
+	}
+
+	for _, entity := range entities {
+		fields[entity.Field] = names[entity.Kind]
+	}
+
+	return json.Marshal(fields)
+}
+
+/*
+nameOr returns the name, or the fallback if the name is empty
+*/
+func nameOr(name string, fallback string) string {
+	if name == "" {
+		return fallback
+	}
+
+	return name
+}
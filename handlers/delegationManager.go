@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"context"
+	"eigenlayer_hack/structs"
+	"solity/utils/logger"
+)
+
+/*
+OperatorRegisteredHandler handles the operator registrations of the DelegationManager. The OperatorDetails tuple is a
+static struct, so it is decoded as its flattened fields
+*/
+type OperatorRegisteredHandler struct{}
+
+/*
+NewOperatorRegisteredHandler constructor for the OperatorRegisteredHandler object
+*/
+func NewOperatorRegisteredHandler() *OperatorRegisteredHandler {
+	return &OperatorRegisteredHandler{}
+}
+
+func (h *OperatorRegisteredHandler) Name() string { return structs.EventOperatorRegistered }
+
+func (h *OperatorRegisteredHandler) Signature() string {
+	return "OperatorRegistered(address,(address,address,uint32))"
+}
+
+func (h *OperatorRegisteredHandler) Types() []string { return []string{"address", "address", "uint32"} }
+
+func (h *OperatorRegisteredHandler) IndexedTypes() []string { return []string{"address"} }
+
+func (h *OperatorRegisteredHandler) OrderingKey(event *Event) string {
+	return orderingAddress(event.Decoded.DecodedIndexedData, 0)
+}
+
+func (h *OperatorRegisteredHandler) Handle(ctx context.Context, deps *Dependencies, event *Event) (interface{}, error) {
+	operatorAddress, oErr := addressAt(event.Decoded.DecodedIndexedData, 0, "operator address")
+	if oErr != nil {
+		return nil, oErr
+	}
+
+	earningsReceiver, eErr := addressAt(event.Decoded.DecodedData, 0, "earnings receiver")
+	if eErr != nil {
+		return nil, eErr
+	}
+
+	delegationApprover, dErr := addressAt(event.Decoded.DecodedData, 1, "delegation approver")
+	if dErr != nil {
+		return nil, dErr
+	}
+
+	optOutWindow, wErr := uintAt(event.Decoded.DecodedData, 2, "staker opt-out window")
+	if wErr != nil {
+		return nil, wErr
+	}
+
+	// A new operator is not necessarily known by Dune yet, the name is optional
+	operatorName, nErr := lookupOperatorName(ctx, deps, event, operatorAddress)
+	if nErr != nil {
+		logger.LogW(nErr)
+	}
+
+	return structs.OperatorRegisteredPayload{
+		EventMeta:                eventMeta(structs.EventOperatorRegistered, event),
+		OperatorAddress:          operatorAddress,
+		OperatorName:             operatorName,
+		EarningsReceiver:         earningsReceiver,
+		DelegationApprover:       delegationApprover,
+		StakerOptOutWindowBlocks: uint32(optOutWindow),
+	}, nil
+}
+
+/*
+StakerDelegationHandler handles the StakerDelegated and StakerUndelegated events of the DelegationManager
+*/
+type StakerDelegationHandler struct {
+	eventType string
+}
+
+/*
+NewStakerDelegatedHandler constructor for the StakerDelegated handler
+*/
+func NewStakerDelegatedHandler() *StakerDelegationHandler {
+	return &StakerDelegationHandler{eventType: structs.EventStakerDelegated}
+}
+
+/*
+NewStakerUndelegatedHandler constructor for the StakerUndelegated handler
+*/
+func NewStakerUndelegatedHandler() *StakerDelegationHandler {
+	return &StakerDelegationHandler{eventType: structs.EventStakerUndelegated}
+}
+
+func (h *StakerDelegationHandler) Name() string { return h.eventType }
+
+func (h *StakerDelegationHandler) Signature() string { return h.eventType + "(address,address)" }
+
+func (h *StakerDelegationHandler) Types() []string { return []string{} }
+
+func (h *StakerDelegationHandler) IndexedTypes() []string { return []string{"address", "address"} }
+
+func (h *StakerDelegationHandler) OrderingKey(event *Event) string {
+	// Ordered by the operator, so the delegations of an operator are published in order
+	return orderingAddress(event.Decoded.DecodedIndexedData, 1)
+}
+
+func (h *StakerDelegationHandler) Handle(_ context.Context, _ *Dependencies, event *Event) (interface{}, error) {
+	stakerAddress, sErr := addressAt(event.Decoded.DecodedIndexedData, 0, "staker address")
+	if sErr != nil {
+		return nil, sErr
+	}
+
+	operatorAddress, oErr := addressAt(event.Decoded.DecodedIndexedData, 1, "operator address")
+	if oErr != nil {
+		return nil, oErr
+	}
+
+	return structs.StakerDelegationPayload{
+		EventMeta:       eventMeta(h.eventType, event),
+		StakerAddress:   stakerAddress,
+		OperatorAddress: operatorAddress,
+	}, nil
+}
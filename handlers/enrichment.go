@@ -0,0 +1,201 @@
+package handlers
+
+import (
+	"context"
+	"eigenlayer_hack/deadletter"
+	"eigenlayer_hack/dedup"
+	"eigenlayer_hack/deferred"
+	"eigenlayer_hack/metadata"
+	"eigenlayer_hack/registryindex"
+	"eigenlayer_hack/structs"
+	"eigenlayer_hack/utils"
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"solity/utils/evm/evmStructs"
+	"solity/utils/logger"
+	"strings"
+)
+
+// Payload fields of the looked up names
+const (
+	operatorNameField = "OperatorName"
+	avsNameField      = "AvsName"
+)
+
+/*
+lookupOperatorName returns the name of the operator from the metadata sources
+*/
+func lookupOperatorName(ctx context.Context, deps *Dependencies, event *Event, operatorAddress common.Address) (string, error) {
+	return lookupName(ctx, deps, event, metadata.KindOperator, operatorAddress, operatorNameField)
+}
+
+/*
+lookupAVSName returns the name of the AVS from the metadata sources
+*/
+func lookupAVSName(ctx context.Context, deps *Dependencies, event *Event, avsAddress common.Address) (string, error) {
+	return lookupName(ctx, deps, event, metadata.KindAVS, avsAddress, avsNameField)
+}
+
+/*
+lookupName resolves the name of the entity, entities none of the metadata providers know are named "unknown" and are
+recorded in the event, so that its enrichment is re-attempted later
+*/
+func lookupName(ctx context.Context, deps *Dependencies, event *Event, kind metadata.Kind, entityAddress common.Address,
+	field string) (string, error) {
+	address := strings.ToLower(entityAddress.Hex())
+
+	entry, rErr := deps.Metadata.Resolve(ctx, kind, address)
+	if rErr != nil {
+		return "", deadletter.NewFailure(deadletter.StageEnrichment, rErr)
+	}
+
+	if !entry.Found {
+		logger.LogW("No metadata is found for the ", string(kind), " ", address)
+		event.Unresolved = append(event.Unresolved, deferred.Entity{Kind: kind, Address: address, Field: field})
+
+		return metadata.UnknownName, nil
+	}
+
+	return entry.Name, nil
+}
+
+/*
+writeRegistration writes the operator - AVS pair to the registry contract, unless it is already written for this event.
+If a name is unknown the write is deferred until the name is found
+*/
+func writeRegistration(ctx context.Context, deps *Dependencies, event *Event, payload structs.EigenlayerPayload) error {
+	// Both the AVS and the operator gain a registration for their velocity
+	deps.Scoring.ObserveRegistration(metadata.KindAVS, payload.AvsAddress.Hex(), event.Key.String(), event.Log.BlockNumber)
+	deps.Scoring.ObserveRegistration(metadata.KindOperator, payload.OperatorAddress.Hex(), event.Key.String(),
+		event.Log.BlockNumber)
+
+	if len(event.Unresolved) > 0 {
+		event.Registration = &deferred.Registration{
+			AvsAddress:      payload.AvsAddress.Hex(),
+			OperatorAddress: payload.OperatorAddress.Hex(),
+			AvsName:         payload.AvsName,
+			OperatorName:    payload.OperatorName,
+		}
+
+		return nil
+	}
+
+	return WriteOnce(ctx, deps, event.Key, func() error {
+		return writeToRegistry(ctx, deps, event.Key, payload)
+	})
+}
+
+/*
+writeToRegistry sends the registration and waits for the final status of the transaction. Failed, reverted, replaced
+or stuck transactions are returned as errors with their status. The confirmed writes are journaled for the
+reconciliation with the registry
+*/
+func writeToRegistry(ctx context.Context, deps *Dependencies, key dedup.Key, payload structs.EigenlayerPayload) error {
+	result, wErr := deps.RegistryWriter.Write(ctx, payload)
+
+	// Written before, e.g. by a previous run or a replay
+	if errors.Is(wErr, utils.ErrAlreadyRegistered) {
+		logger.LogI("Registration of ", payload.OperatorAddress.Hex(), " to ", payload.AvsAddress.Hex(),
+			" is already in the registry")
+
+		write := registryindex.NewWrite(key.String(), payload, utils.WriteResult{})
+		write.AlreadyRegistered = true
+		journalWrite(deps, write)
+
+		return nil
+	}
+
+	if wErr != nil {
+		if result.Status == "" {
+			return wErr
+		}
+
+		return fmt.Errorf("registry write is %s (nonce %d, %d attempts): %w", result.Status, result.Nonce,
+			result.Attempts, wErr)
+	}
+
+	journalWrite(deps, registryindex.NewWrite(key.String(), payload, result))
+
+	if result.BatchSize > 1 {
+		logger.LogS("Registration of ", payload.OperatorAddress.Hex(), " to ", payload.AvsAddress.Hex(),
+			" is confirmed in ", result.Hash.Hex(), " as entry ", result.BatchIndex, " of ", result.BatchSize)
+
+		return nil
+	}
+
+	logger.LogS("Registration of ", payload.OperatorAddress.Hex(), " to ", payload.AvsAddress.Hex(), " is confirmed in ",
+		result.Hash.Hex())
+
+	return nil
+}
+
+/*
+journalWrite records the write in the journal of the registry index. The write is done, so a failed record is only
+logged, the reconciliation reports the entry as unexpected then
+*/
+func journalWrite(deps *Dependencies, write registryindex.Write) {
+	if jErr := deps.RegistryIndex.RecordWrite(write); jErr != nil {
+		logger.LogW("Error while journaling the registry write of ", write.Key, ": ", jErr)
+	}
+}
+
+/*
+eventMeta returns the common fields of the payloads
+*/
+func eventMeta(eventType string, event *Event) structs.EventMeta {
+	return structs.EventMeta{
+		EventType:   eventType,
+		TxHash:      event.Log.TxHash,
+		BlockNumber: event.Log.BlockNumber,
+		LogIndex:    event.Log.Index,
+	}
+}
+
+/*
+addressAt returns the address at the given index of the decoded values
+*/
+func addressAt(values []evmStructs.DecodeOutput, index int, name string) (common.Address, error) {
+	if index >= len(values) {
+		return common.Address{}, deadletter.NewFailure(deadletter.StageDecode, errors.New(name+" is missing in the log"))
+	}
+
+	address, aErr := values[index].AsAddress()
+	if aErr != nil {
+		return common.Address{}, deadletter.NewFailure(deadletter.StageDecode, aErr)
+	}
+
+	return address, nil
+}
+
+/*
+uintAt returns the unsigned integer at the given index of the decoded values
+*/
+func uintAt(values []evmStructs.DecodeOutput, index int, name string) (uint64, error) {
+	if index >= len(values) {
+		return 0, deadletter.NewFailure(deadletter.StageDecode, errors.New(name+" is missing in the log"))
+	}
+
+	value, iErr := values[index].AsInt()
+	if iErr != nil {
+		return 0, deadletter.NewFailure(deadletter.StageDecode, iErr)
+	}
+
+	if !value.IsUint64() {
+		return 0, deadletter.NewFailure(deadletter.StageDecode, errors.New(name+" is out of range"))
+	}
+
+	return value.Uint64(), nil
+}
+
+/*
+orderingAddress returns the lower case hex of the address at the given index, empty if it cannot be decoded
+*/
+func orderingAddress(values []evmStructs.DecodeOutput, index int) string {
+	address, aErr := addressAt(values, index, "")
+	if aErr != nil {
+		return ""
+	}
+
+	return strings.ToLower(address.Hex())
+}
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"context"
+	"eigenlayer_hack/structs"
+	"solity/utils/logger"
+)
+
+/*
+OperatorSubscribedHandler handles the OperatorSubscribed event of the AVS contracts: the operator and the AVS are
+enriched with the Dune metadata and written to the registry contract
+*/
+type OperatorSubscribedHandler struct{}
+
+/*
+NewOperatorSubscribedHandler constructor for the OperatorSubscribedHandler object
+*/
+func NewOperatorSubscribedHandler() *OperatorSubscribedHandler {
+	return &OperatorSubscribedHandler{}
+}
+
+func (h *OperatorSubscribedHandler) Name() string { return "OperatorSubscribed" }
+
+func (h *OperatorSubscribedHandler) Signature() string { return "OperatorSubscribed(address,uint32)" }
+
+func (h *OperatorSubscribedHandler) Types() []string { return []string{} }
+
+func (h *OperatorSubscribedHandler) IndexedTypes() []string { return []string{"address", "uint32"} }
+
+func (h *OperatorSubscribedHandler) OrderingKey(event *Event) string {
+	return orderingAddress(event.Decoded.DecodedIndexedData, 0)
+}
+
+func (h *OperatorSubscribedHandler) Handle(ctx context.Context, deps *Dependencies, event *Event) (interface{}, error) {
+	operatorAddress, aErr := addressAt(event.Decoded.DecodedIndexedData, 0, "operator address")
+	if aErr != nil {
+		return nil, aErr
+	}
+
+	// The event is emitted by the AVS contract
+	avsAddress := event.Log.Address
+
+	operatorName, oErr := lookupOperatorName(ctx, deps, event, operatorAddress)
+	if oErr != nil {
+		return nil, oErr
+	}
+
+	avsName, nErr := lookupAVSName(ctx, deps, event, avsAddress)
+	if nErr != nil {
+		return nil, nErr
+	}
+
+	logger.LogS(operatorName + " operator is registered to " + avsName + " AVS")
+	payload := structs.EigenlayerPayload{
+		AvsName:         avsName,
+		AvsAddress:      avsAddress,
+		OperatorAddress: operatorAddress,
+		OperatorName:    operatorName,
+	}
+
+	if wErr := writeRegistration(ctx, deps, event, payload); wErr != nil {
+		return nil, wErr
+	}
+
+	return payload, nil
+}
@@ -0,0 +1,282 @@
+package handlers
+
+import (
+	"context"
+	"eigenlayer_hack/deadletter"
+	"eigenlayer_hack/dedup"
+	"eigenlayer_hack/reorg"
+	"errors"
+	"github.com/ethereum/go-ethereum/core/types"
+	"solity/schemas"
+	evmUtils "solity/utils/evm"
+	"solity/utils/logger"
+	"strings"
+	"time"
+)
+
+// Intervals of the claim re-checks while another replica processes the same log
+const (
+	claimRetryInterval    = 50 * time.Millisecond
+	maxClaimRetryInterval = 2 * time.Second
+)
+
+/*
+ExtractOrderingKey returns the key used to order the processing of the given message, it is the ordering key of the
+first handled event (e.g. the operator address). If no handled event is present the transaction hash is used
+*/
+func (r *Registry) ExtractOrderingKey(message schemas.SolityETHCompleteTransactionMessage) string {
+	rcptInfo := new(types.Receipt)
+
+	if err := rcptInfo.UnmarshalJSON(message.ReceiptData); err != nil {
+		return ""
+	}
+
+	keeper := r.SignatureKeeper()
+
+	for _, eventLog := range rcptInfo.Logs {
+		registration, isOk := r.Lookup(eventLog)
+
+		if !isOk {
+			continue
+		}
+
+		decodedLog, err := evmUtils.DecodeLog(eventLog, keeper)
+
+		if err != nil {
+			continue
+		}
+
+		if key := registration.Handler.OrderingKey(&Event{Log: eventLog, Decoded: decodedLog, Receipt: rcptInfo}); key != "" {
+			return strings.ToLower(key)
+		}
+	}
+
+	return rcptInfo.TxHash.Hex()
+}
+
+/*
+ProcessTransaction dispatches every log of the given transaction to the handler registered for its event and publishes
+the produced payloads. The supplied context is checked between the logs, once it is cancelled the remaining logs are
+not processed. Failing logs do not stop the processing of the other logs, their errors are returned as
+deadletter.Failure objects carrying the failed stage. The outcome of every log is recorded in the dedup store, so
+redelivered logs are not handled again
+*/
+func (r *Registry) ProcessTransaction(ctx context.Context, message schemas.SolityETHCompleteTransactionMessage,
+	deps *Dependencies, outputChannel string) error {
+	// This service expects types.Receipt format
+	rcptInfo := new(types.Receipt)
+	txInfo := new(types.Transaction)
+	fMErr := rcptInfo.UnmarshalJSON(message.ReceiptData)
+	err := txInfo.UnmarshalJSON(message.TransactionData)
+
+	if err != nil {
+		logger.LogW("Error while un-marshalling the txInfo data: ", err)
+		return deadletter.NewFailure(deadletter.StageUnmarshal, err)
+	}
+
+	// If the message is malformed skip the message
+	if fMErr != nil {
+		logger.LogW("Error while un-marshalling the rcpt data: ", fMErr)
+		return deadletter.NewFailure(deadletter.StageUnmarshal, fMErr)
+	}
+
+	// Check the status of the transaction, if it has failed do not include it
+	if rcptInfo.Status != 1 {
+		logger.LogWf("The tx [%s] has failed, skipping the processing for this tx!", rcptInfo.TxHash.Hex())
+		return nil
+	}
+
+	var failures []error
+
+	for _, eventLog := range rcptInfo.Logs {
+		// Stop processing if the shutdown deadline has been reached
+		if ctx.Err() != nil {
+			logger.LogWf("Processing of the tx [%s] is aborted: %v", rcptInfo.TxHash.Hex(), ctx.Err())
+			return ctx.Err()
+		}
+
+		// Skip the logs of the events that we are not interested in
+		registration, isOk := r.Lookup(eventLog)
+
+		if !isOk {
+			continue
+		}
+
+		dedupKey := dedup.Key{ChainID: txInfo.ChainId().Uint64(), TxHash: rcptInfo.TxHash, LogIndex: eventLog.Index}
+
+		// The log is no longer part of the canonical chain, retract it if it has been processed
+		if eventLog.Removed {
+			if !deps.ReorgMonitor.RetractRemoved(ctx, dedupKey) {
+				logger.LogW("Removed log ", dedupKey.String(), " was not processed before, skipping")
+			}
+
+			continue
+		}
+
+		channel := registration.OutputChannel
+
+		if channel == "" {
+			channel = outputChannel
+		}
+
+		event := &Event{Log: eventLog, Receipt: rcptInfo, Transaction: txInfo, Key: dedupKey}
+
+		payload, pErr := r.processLog(ctx, deps, registration.Handler, event, channel)
+
+		if pErr != nil {
+			logger.LogW(registration.Handler.Name(), ": ", pErr)
+			failures = append(failures, pErr)
+			continue
+		}
+
+		if payload != nil {
+			deps.ReorgMonitor.Record(ctx, reorg.ProcessedEvent{
+				Key:           dedupKey,
+				BlockNumber:   eventLog.BlockNumber,
+				BlockHash:     eventLog.BlockHash,
+				OutputChannel: channel,
+				Payload:       payload,
+			})
+		}
+	}
+
+	return errors.Join(failures...)
+}
+
+/*
+processLog decodes the log, runs its handler and publishes the payload. Logs that are already completed are skipped.
+Returns the published payload, nil if the log was skipped
+*/
+func (r *Registry) processLog(ctx context.Context, deps *Dependencies, handler Handler, event *Event,
+	outputChannel string) (published interface{}, err error) {
+	// Only one replica processes the log at a time, the outcome is read under the claim
+	if cErr := claim(ctx, deps, event.Key); cErr != nil {
+		if ctx.Err() != nil {
+			return nil, cErr
+		}
+
+		return nil, deadletter.NewFailure(deadletter.StageDedup, cErr)
+	}
+
+	// Released after the outcome is recorded, also when the processing is aborted
+	defer func() {
+		if rErr := deps.DedupStore.Release(context.WithoutCancel(ctx), event.Key); rErr != nil {
+			logger.LogW("Error while releasing the claim of ", event.Key.String(), ": ", rErr)
+		}
+	}()
+
+	record, isRecorded, gErr := deps.DedupStore.Get(ctx, event.Key)
+	if gErr != nil {
+		return nil, deadletter.NewFailure(deadletter.StageDedup, gErr)
+	}
+
+	if isRecorded && record.Outcome == dedup.OutcomeCompleted {
+		logger.LogI("Log ", event.Key.String(), " is already processed, skipping")
+		return nil, nil
+	}
+
+	// Record the failure, a written log stays as written so that it is not written again on retry
+	defer func() {
+		if err == nil || ctx.Err() != nil {
+			return
+		}
+
+		if record, isRecorded, _ := deps.DedupStore.Get(ctx, event.Key); isRecorded && record.Outcome == dedup.OutcomeWritten {
+			return
+		}
+
+		if pErr := deps.DedupStore.Put(ctx, event.Key, dedup.OutcomeFailed); pErr != nil {
+			logger.LogW("Error while recording the failure of ", event.Key.String(), ": ", pErr)
+		}
+	}()
+
+	decodedLog, dErr := evmUtils.DecodeLog(event.Log, r.SignatureKeeper())
+	if dErr != nil {
+		return nil, deadletter.NewFailure(deadletter.StageDecode, dErr)
+	}
+
+	event.Decoded = decodedLog
+
+	payload, hErr := handler.Handle(ctx, deps, event)
+	if hErr != nil {
+		return nil, hErr
+	}
+
+	if pErr := deps.Sink.Publish(ctx, outputChannel, payload); pErr != nil {
+		return nil, deadletter.NewFailure(deadletter.StagePublish, pErr)
+	}
+
+	// Parked before the log is completed, so that a failed enqueue fails the message and is retried with it
+	if len(event.Unresolved) > 0 {
+		if dErr := deferEnrichment(deps, handler, event, outputChannel, payload); dErr != nil {
+			return nil, deadletter.NewFailure(deadletter.StageEnrichment, dErr)
+		}
+	}
+
+	if pErr := deps.DedupStore.Put(ctx, event.Key, dedup.OutcomeCompleted); pErr != nil {
+		return nil, deadletter.NewFailure(deadletter.StageDedup, pErr)
+	}
+
+	return payload, nil
+}
+
+/*
+claim waits until the claim of the log is granted. Another replica holding it is processing the same log, once it has
+recorded the outcome (or crashed and its claim has expired) the log is claimed and its outcome read again, so a log
+that was completed meanwhile is skipped instead of being dead-lettered
+*/
+func claim(ctx context.Context, deps *Dependencies, key dedup.Key) error {
+	wait := claimRetryInterval
+	isWaiting := false
+
+	for {
+		isClaimed, cErr := deps.DedupStore.Claim(ctx, key, deps.ClaimTTL)
+		if cErr != nil {
+			return cErr
+		}
+
+		if isClaimed {
+			return nil
+		}
+
+		if !isWaiting {
+			logger.LogI("Log ", key.String(), " is being processed by another replica, waiting for its claim")
+			isWaiting = true
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+
+		wait = min(wait*2, maxClaimRetryInterval)
+	}
+}
+
+/*
+WriteOnce runs the given contract write unless the dedup store shows that the event has already been written, and
+records the write. It is used by the handlers so that a retried event is not written to the contract twice
+*/
+func WriteOnce(ctx context.Context, deps *Dependencies, key dedup.Key, write func() error) error {
+	// Check right before the write, another replica may have processed the log in the meantime
+	record, isRecorded, gErr := deps.DedupStore.Get(ctx, key)
+	if gErr != nil {
+		return deadletter.NewFailure(deadletter.StageDedup, gErr)
+	}
+
+	if isRecorded && (record.Outcome == dedup.OutcomeWritten || record.Outcome == dedup.OutcomeCompleted) {
+		logger.LogI("Log ", key.String(), " is already written to the contract, skipping the write")
+		return nil
+	}
+
+	if wErr := write(); wErr != nil {
+		return deadletter.NewFailure(deadletter.StageWrite, wErr)
+	}
+
+	if pErr := deps.DedupStore.Put(ctx, key, dedup.OutcomeWritten); pErr != nil {
+		return deadletter.NewFailure(deadletter.StageDedup, pErr)
+	}
+
+	return nil
+}
@@ -0,0 +1,221 @@
+package handlers
+
+import (
+	"context"
+	"eigenlayer_hack/deadletter"
+	"eigenlayer_hack/dedup"
+	"eigenlayer_hack/deferred"
+	"eigenlayer_hack/metadata"
+	"eigenlayer_hack/stream"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+/*
+countingHandler handles TestEvent(address,uint256), Handle counts its calls, waits for release when it is set and
+reports the unresolved entities
+*/
+type countingHandler struct {
+	release    chan struct{}
+	started    chan struct{}
+	unresolved []deferred.Entity
+	calls      int32
+}
+
+func (h *countingHandler) Name() string              { return "TestEvent" }
+func (h *countingHandler) Signature() string         { return "TestEvent(address,uint256)" }
+func (h *countingHandler) Types() []string           { return []string{"uint256"} }
+func (h *countingHandler) IndexedTypes() []string    { return []string{"address"} }
+func (h *countingHandler) OrderingKey(*Event) string { return "" }
+
+func (h *countingHandler) Handle(ctx context.Context, _ *Dependencies, event *Event) (interface{}, error) {
+	if atomic.AddInt32(&h.calls, 1) == 1 && h.started != nil {
+		close(h.started)
+	}
+
+	if h.release != nil {
+		select {
+		case <-h.release:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	event.Unresolved = h.unresolved
+
+	return map[string]string{"tx": event.Log.TxHash.Hex()}, nil
+}
+
+/*
+replica is a processing replica with its own registry and sink, sharing the dedup store of the other replicas
+*/
+type replica struct {
+	registry *Registry
+	deps     *Dependencies
+	sink     *stream.MemorySink
+}
+
+func newReplica(t *testing.T, handler Handler, store dedup.Store) *replica {
+	t.Helper()
+
+	registry := NewRegistry()
+	if rErr := registry.Register(handler, "", common.Address{}); rErr != nil {
+		t.Fatal(rErr)
+	}
+
+	sink := stream.NewMemorySink()
+
+	return &replica{
+		registry: registry,
+		deps:     &Dependencies{Sink: sink, DedupStore: store, ClaimTTL: time.Minute},
+		sink:     sink,
+	}
+}
+
+func testEvent(handler Handler) *Event {
+	txHash := common.HexToHash("0xAB")
+
+	return &Event{
+		Log: &types.Log{
+			Address: common.HexToAddress("0x1"),
+			Topics:  []common.Hash{Topic(handler), common.HexToHash("0x2")},
+			Data:    common.LeftPadBytes([]byte{1}, 32),
+			TxHash:  txHash,
+		},
+		Key: dedup.Key{ChainID: 1, TxHash: txHash, LogIndex: 0},
+	}
+}
+
+func TestProcessLogSkipsTheLogCompletedByAnotherReplica(t *testing.T) {
+	handler := &countingHandler{release: make(chan struct{}), started: make(chan struct{})}
+	store := dedup.NewMemoryStore(time.Hour)
+
+	first := newReplica(t, handler, store)
+	second := newReplica(t, handler, store)
+
+	ctx := context.Background()
+	firstDone := make(chan error, 1)
+
+	go func() {
+		_, pErr := first.registry.processLog(ctx, first.deps, handler, testEvent(handler), "registrations")
+		firstDone <- pErr
+	}()
+
+	// The first replica holds the claim while its handler runs
+	<-handler.started
+
+	secondDone := make(chan error, 1)
+
+	go func() {
+		payload, pErr := second.registry.processLog(ctx, second.deps, handler, testEvent(handler), "registrations")
+		if payload != nil {
+			t.Error("expected the second replica to skip the log, it published ", payload)
+		}
+		secondDone <- pErr
+	}()
+
+	select {
+	case pErr := <-secondDone:
+		t.Fatal("expected the second replica to wait for the claim, it returned ", pErr)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	close(handler.release)
+
+	for name, done := range map[string]chan error{"first": firstDone, "second": secondDone} {
+		select {
+		case pErr := <-done:
+			if pErr != nil {
+				t.Fatalf("expected the %s replica to succeed, got %v", name, pErr)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("the %s replica did not finish", name)
+		}
+	}
+
+	if calls := atomic.LoadInt32(&handler.calls); calls != 1 {
+		t.Fatalf("expected the log to be handled once, got %d", calls)
+	}
+
+	published := len(first.sink.Messages("registrations")) + len(second.sink.Messages("registrations"))
+	if published != 1 {
+		t.Fatalf("expected a single publication, got %d", published)
+	}
+}
+
+func TestProcessLogGivesUpTheClaimWaitWhenTheContextIsDone(t *testing.T) {
+	handler := &countingHandler{}
+	store := dedup.NewMemoryStore(time.Hour)
+	waiting := newReplica(t, handler, store)
+
+	event := testEvent(handler)
+
+	// Another replica holds the claim and does not finish in time
+	if isClaimed, _ := store.Claim(context.Background(), event.Key, time.Minute); !isClaimed {
+		t.Fatal("expected the claim to be granted")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	// The message is left uncommitted by the tracker, not dead-lettered
+	_, pErr := waiting.registry.processLog(ctx, waiting.deps, handler, event, "registrations")
+	if pErr != context.DeadlineExceeded {
+		t.Fatal("expected the deadline error, got ", pErr)
+	}
+
+	if calls := atomic.LoadInt32(&handler.calls); calls != 0 {
+		t.Fatalf("expected the log not to be handled, got %d calls", calls)
+	}
+}
+
+func TestProcessLogFailsWhenTheEnrichmentCannotBeDeferred(t *testing.T) {
+	handler := &countingHandler{unresolved: []deferred.Entity{{Kind: metadata.KindOperator, Address: "0xb0",
+		Field: "operatorName"}}}
+	store := dedup.NewMemoryStore(time.Hour)
+	processing := newReplica(t, handler, store)
+	ctx := context.Background()
+
+	// The queue file cannot be written, its directory does not exist
+	broken, nErr := deferred.NewQueue(filepath.Join(t.TempDir(), "missing", "deferred.json"), deferred.Schedule{})
+	if nErr != nil {
+		t.Fatal(nErr)
+	}
+
+	processing.deps.Deferred = broken
+
+	_, pErr := processing.registry.processLog(ctx, processing.deps, handler, testEvent(handler), "registrations")
+	if pErr == nil || deadletter.StageOf(pErr) != deadletter.StageEnrichment {
+		t.Fatal("expected the enrichment failure, got ", pErr)
+	}
+
+	event := testEvent(handler)
+
+	// The log is not completed, the retried message is processed and deferred again
+	if record, _, _ := store.Get(ctx, event.Key); record.Outcome == dedup.OutcomeCompleted {
+		t.Fatal("expected the log not to be completed")
+	}
+
+	queue, nErr := deferred.NewQueue(filepath.Join(t.TempDir(), "deferred.json"), deferred.Schedule{})
+	if nErr != nil {
+		t.Fatal(nErr)
+	}
+
+	processing.deps.Deferred = queue
+
+	if _, pErr = processing.registry.processLog(ctx, processing.deps, handler, event, "registrations"); pErr != nil {
+		t.Fatal(pErr)
+	}
+
+	if items := queue.Items(); len(items) != 1 || items[0].Key != event.Key {
+		t.Fatalf("expected the deferred event, got %+v", items)
+	}
+
+	if record, _, _ := store.Get(ctx, event.Key); record.Outcome != dedup.OutcomeCompleted {
+		t.Fatalf("expected the log to be completed, got %+v", record)
+	}
+}
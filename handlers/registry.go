@@ -0,0 +1,295 @@
+package handlers
+
+import (
+	"context"
+	"eigenlayer_hack/dedup"
+	"eigenlayer_hack/deferred"
+	"eigenlayer_hack/metadata"
+	"eigenlayer_hack/registryindex"
+	"eigenlayer_hack/reorg"
+	"eigenlayer_hack/scoring"
+	"eigenlayer_hack/stream"
+	"eigenlayer_hack/utils"
+	"errors"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"solity/utils/evm/evmStructs"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+/*
+Event is a single decoded log handed to a Handler
+*/
+type Event struct {
+	Log         *types.Log
+	Decoded     evmStructs.DecodedLog
+	Receipt     *types.Receipt
+	Transaction *types.Transaction
+	Key         dedup.Key
+	// Unresolved are the entities whose metadata was not found, set by the handler
+	Unresolved []deferred.Entity
+	// Registration is the registry write deferred until the unresolved names are found, set by the handler
+	Registration *deferred.Registration
+}
+
+/*
+Dependencies are the services shared by every handler
+*/
+type Dependencies struct {
+	Sink       stream.Sink
+	DedupStore dedup.Store
+	// ClaimTTL is the longest time a log is claimed by its processing, see dedup.Store.Claim
+	ClaimTTL     time.Duration
+	ReorgMonitor *reorg.Monitor
+	Metadata     *metadata.CachingResolver
+	// Deferred queues the events with unresolved metadata, nil disables the re-attempts
+	Deferred *deferred.Queue
+	// Scoring counts the registrations for the popularity scores, nil disables the scoring
+	Scoring *scoring.Engine
+	// RegistryWriter sends the registrations to the registry contract
+	RegistryWriter *utils.RegistryWriter
+	// RegistryIndex journals the confirmed registry writes for the reconciliation, nil disables the journal
+	RegistryIndex *registryindex.Store
+}
+
+/*
+Handler processes the logs of a single event type and produces its typed payload
+*/
+type Handler interface {
+	/*
+		Name identifies the handler in the configuration
+	*/
+	Name() string
+	/*
+		Signature is the canonical event signature the topic0 is derived from, e.g. "StakerDelegated(address,address)"
+	*/
+	Signature() string
+	/*
+		Types are the types of the non-indexed event parameters, used to decode the log data
+	*/
+	Types() []string
+	/*
+		IndexedTypes are the types of the indexed event parameters, used to decode the log topics
+	*/
+	IndexedTypes() []string
+	/*
+		OrderingKey returns the key that orders the processing of the events, e.g. the operator address
+	*/
+	OrderingKey(event *Event) string
+	/*
+		Handle enriches the event and performs its side effects, returns the payload to be published. Errors should be
+		deadletter.Failure objects carrying the failed stage
+	*/
+	Handle(ctx context.Context, deps *Dependencies, event *Event) (interface{}, error)
+}
+
+/*
+Registration binds a handler to its output channel and optionally to a contract
+*/
+type Registration struct {
+	Handler Handler
+	// OutputChannel of the payloads, empty means the output channel mapped to the listen channel
+	OutputChannel string
+	// Contract limits the handler to the logs of a single contract, zero address means every contract
+	Contract common.Address
+	Topic    common.Hash
+}
+
+/*
+Registry maps the event signatures to their handlers
+*/
+type Registry struct {
+	mu            sync.RWMutex
+	registrations map[common.Hash]Registration
+	keeper        evmStructs.SignatureKeeper
+}
+
+/*
+NewRegistry constructor for the Registry object
+*/
+func NewRegistry() *Registry {
+	return &Registry{registrations: map[common.Hash]Registration{}, keeper: evmStructs.NewSignatureKeeper()}
+}
+
+/*
+Topic returns the topic0 of the handler's event
+*/
+func Topic(handler Handler) common.Hash {
+	return crypto.Keccak256Hash([]byte(handler.Signature()))
+}
+
+/*
+Register adds the handler to the registry, a signature can only have a single handler
+*/
+func (r *Registry) Register(handler Handler, outputChannel string, contract common.Address) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	topic := Topic(handler)
+
+	if existing, isOk := r.registrations[topic]; isOk {
+		return errors.New("signature " + handler.Signature() + " is already handled by " + existing.Handler.Name())
+	}
+
+	r.registrations[topic] = Registration{
+		Handler:       handler,
+		OutputChannel: outputChannel,
+		Contract:      contract,
+		Topic:         topic,
+	}
+
+	r.keeper.AddHash(topic.Hex(), handler.Types(), handler.IndexedTypes())
+
+	return nil
+}
+
+/*
+Lookup returns the registration of the given log, false if no handler is registered for it
+*/
+func (r *Registry) Lookup(eventLog *types.Log) (Registration, bool) {
+	if len(eventLog.Topics) == 0 {
+		return Registration{}, false
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	registration, isOk := r.registrations[eventLog.Topics[0]]
+
+	if !isOk {
+		return Registration{}, false
+	}
+
+	if registration.Contract != (common.Address{}) && registration.Contract != eventLog.Address {
+		return Registration{}, false
+	}
+
+	return registration, true
+}
+
+/*
+SignatureKeeper returns the keeper holding the decoding information of every registered event
+*/
+func (r *Registry) SignatureKeeper() evmStructs.SignatureKeeper {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.keeper
+}
+
+/*
+Topics returns the topic0 values of the registered events
+*/
+func (r *Registry) Topics() []common.Hash {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	topics := []common.Hash{}
+
+	for topic := range r.registrations {
+		topics = append(topics, topic)
+	}
+
+	sort.Slice(topics, func(i, j int) bool { return topics[i].Hex() < topics[j].Hex() })
+
+	return topics
+}
+
+/*
+Contracts returns the contracts the handlers are limited to, empty if any handler accepts every contract
+*/
+func (r *Registry) Contracts() []common.Address {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	contracts := []common.Address{}
+	seen := map[common.Address]bool{}
+
+	for _, registration := range r.registrations {
+		if registration.Contract == (common.Address{}) {
+			return []common.Address{}
+		}
+
+		if !seen[registration.Contract] {
+			seen[registration.Contract] = true
+			contracts = append(contracts, registration.Contract)
+		}
+	}
+
+	return contracts
+}
+
+/*
+Names returns the names of the registered handlers
+*/
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := []string{}
+
+	for _, registration := range r.registrations {
+		names = append(names, registration.Handler.Name())
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+/*
+BuiltinHandlers returns the handlers shipped with the tracker
+*/
+func BuiltinHandlers() []Handler {
+	return []Handler{
+		NewOperatorSubscribedHandler(),
+		NewOperatorAVSRegistrationStatusUpdatedHandler(),
+		NewOperatorRegisteredHandler(),
+		NewStakerDelegatedHandler(),
+		NewStakerUndelegatedHandler(),
+		NewDepositHandler(),
+	}
+}
+
+/*
+NewRegistryFromConfig registers the enabled builtin handlers. enabled is the list of handler names, empty enables every
+handler. outputChannels and contracts map the handler names to their output channel and contract
+*/
+func NewRegistryFromConfig(enabled []string, outputChannels map[string]string, contracts map[string]string) (*Registry, error) {
+	registry := NewRegistry()
+	isEnabled := map[string]bool{}
+
+	for _, name := range enabled {
+		isEnabled[strings.ToLower(name)] = true
+	}
+
+	for _, handler := range BuiltinHandlers() {
+		if len(isEnabled) > 0 && !isEnabled[strings.ToLower(handler.Name())] {
+			continue
+		}
+
+		contract := common.Address{}
+
+		if address, isOk := contracts[handler.Name()]; isOk && address != "" {
+			if !common.IsHexAddress(address) {
+				return nil, errors.New("invalid contract address for " + handler.Name() + ": " + address)
+			}
+
+			contract = common.HexToAddress(address)
+		}
+
+		if rErr := registry.Register(handler, outputChannels[handler.Name()], contract); rErr != nil {
+			return nil, rErr
+		}
+	}
+
+	if len(registry.registrations) == 0 {
+		return nil, errors.New("no handler is enabled")
+	}
+
+	return registry, nil
+}
@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"eigenlayer_hack/structs"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"testing"
+)
+
+func TestRegistryRejectsASecondHandlerOfASignature(t *testing.T) {
+	registry := NewRegistry()
+
+	if rErr := registry.Register(NewDepositHandler(), "", common.Address{}); rErr != nil {
+		t.Fatal(rErr)
+	}
+
+	if rErr := registry.Register(NewDepositHandler(), "deposits", common.Address{}); rErr == nil {
+		t.Fatal("expected the duplicate signature to be rejected")
+	}
+}
+
+func TestRegistryLookupHonoursTheContract(t *testing.T) {
+	delegationManager := common.HexToAddress("0x39053D51B77DC0d36036Fc1fCc8Cb819df8Ef37A")
+	registry := NewRegistry()
+
+	if rErr := registry.Register(NewStakerDelegatedHandler(), "delegations", delegationManager); rErr != nil {
+		t.Fatal(rErr)
+	}
+
+	topic := Topic(NewStakerDelegatedHandler())
+
+	registration, isOk := registry.Lookup(&types.Log{Address: delegationManager, Topics: []common.Hash{topic}})
+	if !isOk || registration.OutputChannel != "delegations" || registration.Topic != topic {
+		t.Fatalf("expected the StakerDelegated registration, got %+v (%v)", registration, isOk)
+	}
+
+	tests := map[string]*types.Log{
+		"other contract": {Address: common.HexToAddress("0x01"), Topics: []common.Hash{topic}},
+		"other event":    {Address: delegationManager, Topics: []common.Hash{common.HexToHash("0x02")}},
+		"no topic":       {Address: delegationManager},
+	}
+
+	for name, eventLog := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, isOk := registry.Lookup(eventLog); isOk {
+				t.Fatal("expected no handler")
+			}
+		})
+	}
+
+	if contracts := registry.Contracts(); len(contracts) != 1 || contracts[0] != delegationManager {
+		t.Fatalf("expected the contract filter, got %v", contracts)
+	}
+}
+
+func TestNewRegistryFromConfig(t *testing.T) {
+	tests := []struct {
+		name      string
+		enabled   []string
+		contracts map[string]string
+		names     []string
+		isValid   bool
+	}{
+		{name: "every handler by default", names: builtinNames(), isValid: true},
+		{
+			name:    "enabled handlers only, case insensitive",
+			enabled: []string{"deposit", structs.EventStakerDelegated},
+			names:   []string{structs.EventDeposit, structs.EventStakerDelegated},
+			isValid: true,
+		},
+		{name: "unknown handlers only", enabled: []string{"Transfer"}},
+		{
+			name:      "invalid contract address",
+			contracts: map[string]string{structs.EventDeposit: "0xnot-an-address"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			registry, nErr := NewRegistryFromConfig(test.enabled, map[string]string{}, test.contracts)
+
+			if !test.isValid {
+				if nErr == nil {
+					t.Fatal("expected the configuration to be rejected")
+				}
+
+				return
+			}
+
+			if nErr != nil {
+				t.Fatal(nErr)
+			}
+
+			names := registry.Names()
+
+			if len(names) != len(test.names) {
+				t.Fatalf("expected the handlers %v, got %v", test.names, names)
+			}
+
+			for _, name := range test.names {
+				if _, isOk := registry.Lookup(&types.Log{Topics: []common.Hash{topicOf(t, name)}}); !isOk {
+					t.Fatalf("expected %s to be registered", name)
+				}
+			}
+
+			// Without a contract filter every contract is accepted
+			if contracts := registry.Contracts(); len(contracts) != 0 {
+				t.Fatalf("expected no contract filter, got %v", contracts)
+			}
+		})
+	}
+}
+
+func builtinNames() []string {
+	names := []string{}
+
+	for _, handler := range BuiltinHandlers() {
+		names = append(names, handler.Name())
+	}
+
+	return names
+}
+
+func topicOf(t *testing.T, name string) common.Hash {
+	t.Helper()
+
+	for _, handler := range BuiltinHandlers() {
+		if handler.Name() == name {
+			return Topic(handler)
+		}
+	}
+
+	t.Fatalf("no builtin handler named %s", name)
+
+	return common.Hash{}
+}
@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"context"
+	"eigenlayer_hack/deadletter"
+	"eigenlayer_hack/structs"
+	"errors"
+)
+
+/*
+DepositHandler handles the Deposit event of the StrategyManager
+*/
+type DepositHandler struct{}
+
+/*
+NewDepositHandler constructor for the DepositHandler object
+*/
+func NewDepositHandler() *DepositHandler {
+	return &DepositHandler{}
+}
+
+func (h *DepositHandler) Name() string { return structs.EventDeposit }
+
+func (h *DepositHandler) Signature() string { return "Deposit(address,address,address,uint256)" }
+
+func (h *DepositHandler) Types() []string {
+	return []string{"address", "address", "address", "uint256"}
+}
+
+func (h *DepositHandler) IndexedTypes() []string { return []string{} }
+
+func (h *DepositHandler) OrderingKey(event *Event) string {
+	return orderingAddress(event.Decoded.DecodedData, 0)
+}
+
+func (h *DepositHandler) Handle(_ context.Context, _ *Dependencies, event *Event) (interface{}, error) {
+	stakerAddress, sErr := addressAt(event.Decoded.DecodedData, 0, "staker address")
+	if sErr != nil {
+		return nil, sErr
+	}
+
+	tokenAddress, tErr := addressAt(event.Decoded.DecodedData, 1, "token address")
+	if tErr != nil {
+		return nil, tErr
+	}
+
+	strategyAddress, aErr := addressAt(event.Decoded.DecodedData, 2, "strategy address")
+	if aErr != nil {
+		return nil, aErr
+	}
+
+	if len(event.Decoded.DecodedData) < 4 {
+		return nil, deadletter.NewFailure(deadletter.StageDecode, errors.New("shares is missing in the log"))
+	}
+
+	shares, iErr := event.Decoded.DecodedData[3].AsInt()
+	if iErr != nil {
+		return nil, deadletter.NewFailure(deadletter.StageDecode, iErr)
+	}
+
+	return structs.DepositPayload{
+		EventMeta:       eventMeta(structs.EventDeposit, event),
+		StakerAddress:   stakerAddress,
+		TokenAddress:    tokenAddress,
+		StrategyAddress: strategyAddress,
+		Shares:          shares,
+	}, nil
+}
@@ -0,0 +1,55 @@
+package ingest
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"solity/schemas"
+)
+
+/*
+ChainReader is the part of the node API used by the ingestion, *ethclient.Client implements it
+*/
+type ChainReader interface {
+	BlockNumber(ctx context.Context) (uint64, error)
+	FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error)
+	TransactionByHash(ctx context.Context, hash common.Hash) (tx *types.Transaction, isPending bool, err error)
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
+}
+
+/*
+BuildTransactionMessage fetches the transaction and its receipt and packs them into the message format produced by the
+navigator, so that the ingested transactions go through the same processing path
+*/
+func BuildTransactionMessage(ctx context.Context, client ChainReader, txHash common.Hash) ([]byte, error) {
+	tx, _, tErr := client.TransactionByHash(ctx, txHash)
+
+	if tErr != nil {
+		return nil, tErr
+	}
+
+	rcpt, rErr := client.TransactionReceipt(ctx, txHash)
+
+	if rErr != nil {
+		return nil, rErr
+	}
+
+	txData, mErr := tx.MarshalJSON()
+
+	if mErr != nil {
+		return nil, mErr
+	}
+
+	rcptData, mErr := rcpt.MarshalJSON()
+
+	if mErr != nil {
+		return nil, mErr
+	}
+
+	return json.Marshal(schemas.SolityETHCompleteTransactionMessage{
+		TransactionData: txData,
+		ReceiptData:     rcptData,
+	})
+}
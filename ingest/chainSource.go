@@ -0,0 +1,419 @@
+package ingest
+
+import (
+	"context"
+	"eigenlayer_hack/stream"
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"math/big"
+	"solity/utils/logger"
+	"strings"
+	"sync"
+	"time"
+)
+
+/*
+ChainSourceConfig holds the settings of the ChainSource
+*/
+type ChainSourceConfig struct {
+	// Channel is the listen channel assigned to the ingested messages
+	Channel string
+	// Addresses limits the logs to the given contracts, empty means every contract
+	Addresses []common.Address
+	// Topics are the topic0 values (event signature hashes) to ingest
+	Topics []common.Hash
+	// StartBlock is used when the cursor is empty or behind it, 0 starts from the current head
+	StartBlock uint64
+	// EndBlock is the last block to scan, the source is exhausted after it. 0 follows the head forever
+	EndBlock uint64
+	// Confirmations is the number of blocks to stay behind the head
+	Confirmations uint64
+	PollInterval  time.Duration
+	// MaxRange is the maximum number of blocks requested with a single eth_getLogs call, the range is halved
+	// automatically when the node rejects a query for returning too many results
+	MaxRange uint64
+}
+
+/*
+ChainSource is a stream.Source that reads the relevant logs directly from a node by polling eth_getLogs. Every
+transaction that emitted a relevant log is delivered as a transaction message, the same format the navigator publishes.
+The cursor is advanced only up to the last block whose messages are all committed, so after a restart the ingestion
+continues from the first block that was not fully processed
+*/
+type ChainSource struct {
+	client ChainReader
+	cursor Cursor
+	config ChainSourceConfig
+
+	mu          sync.Mutex
+	initialized bool
+	chunkSize   uint64
+	nextBlock   uint64
+	scannedTo   uint64
+	hasScanned  bool
+	savedCursor uint64
+	isSaved     bool
+	queue       []*stream.Message
+	// block number -> number of uncommitted messages
+	pending map[uint64]int
+}
+
+/*
+NewChainSource constructor for the ChainSource object
+*/
+func NewChainSource(client ChainReader, cursor Cursor, config ChainSourceConfig) *ChainSource {
+	if config.PollInterval <= 0 {
+		config.PollInterval = 12 * time.Second
+	}
+
+	if config.MaxRange == 0 {
+		config.MaxRange = 1000
+	}
+
+	return &ChainSource{client: client, cursor: cursor, config: config, chunkSize: config.MaxRange, pending: map[uint64]int{}}
+}
+
+/*
+FilterQuery returns the eth_getLogs query of the source for the given block range
+*/
+func (cS *ChainSource) FilterQuery(from uint64, to uint64) ethereum.FilterQuery {
+	return ethereum.FilterQuery{
+		FromBlock: new(big.Int).SetUint64(from),
+		ToBlock:   new(big.Int).SetUint64(to),
+		Addresses: cS.config.Addresses,
+		Topics:    [][]common.Hash{cS.config.Topics},
+	}
+}
+
+/*
+initialize loads the cursor and sets the first block to scan
+*/
+func (cS *ChainSource) initialize(ctx context.Context) error {
+	savedBlock, isSaved, lErr := cS.cursor.Load()
+
+	if lErr != nil {
+		return lErr
+	}
+
+	nextBlock := cS.config.StartBlock
+
+	// A cursor outside the configured range (e.g. left from another range) is ignored
+	if isSaved && (savedBlock+1 < cS.config.StartBlock || cS.config.EndBlock > 0 && savedBlock > cS.config.EndBlock) {
+		logger.LogW("The cursor at the block ", savedBlock, " is outside of the range, it is ignored")
+		isSaved = false
+	}
+
+	switch {
+	case isSaved:
+		nextBlock = savedBlock + 1
+	case cS.config.StartBlock == 0:
+		head, hErr := cS.client.BlockNumber(ctx)
+
+		if hErr != nil {
+			return hErr
+		}
+
+		nextBlock = head
+	}
+
+	cS.mu.Lock()
+	defer cS.mu.Unlock()
+
+	cS.nextBlock = nextBlock
+	cS.savedCursor = savedBlock
+	cS.isSaved = isSaved
+	cS.initialized = true
+
+	logger.LogI("Chain ingestion starts from the block ", cS.nextBlock)
+
+	return nil
+}
+
+/*
+Read returns the next queued message and scans the next block range when the queue is empty. The node is queried
+without the lock, so the commits of the processed messages are not blocked by the RPCs. Read is called from a single
+goroutine, so the scan position is only changed by it
+*/
+func (cS *ChainSource) Read(ctx context.Context) (*stream.Message, error) {
+	for ctx.Err() == nil {
+		cS.mu.Lock()
+		initialized := cS.initialized
+		cS.mu.Unlock()
+
+		if !initialized {
+			if iErr := cS.initialize(ctx); iErr != nil {
+				logger.LogW("Error while initializing the chain source: ", iErr)
+				sleep(ctx, cS.config.PollInterval)
+				continue
+			}
+		}
+
+		cS.mu.Lock()
+
+		if len(cS.queue) > 0 {
+			msg := cS.queue[0]
+			cS.queue = cS.queue[1:]
+			cS.mu.Unlock()
+
+			return msg, nil
+		}
+
+		isDone := cS.isDone()
+		cS.mu.Unlock()
+
+		if isDone {
+			return nil, stream.ErrClosed
+		}
+
+		hasNewBlocks, pErr := cS.poll(ctx)
+
+		if errors.Is(pErr, stream.ErrFailed) {
+			return nil, pErr
+		}
+
+		if pErr != nil {
+			if ctx.Err() == nil {
+				logger.LogW("Error while polling the logs: ", pErr)
+			}
+
+			sleep(ctx, cS.config.PollInterval)
+			continue
+		}
+
+		if !hasNewBlocks {
+			sleep(ctx, cS.config.PollInterval)
+		}
+	}
+
+	return nil, ctx.Err()
+}
+
+/*
+poll scans the next block range and queues the messages of the found transactions, returns false if there is no new
+confirmed block to scan. The scan position is read and updated under the lock, the node is queried without it. A
+single block with too many results cannot be scanned at all, it fails the source with stream.ErrFailed
+*/
+func (cS *ChainSource) poll(ctx context.Context) (bool, error) {
+	cS.mu.Lock()
+	from, chunkSize := cS.nextBlock, cS.chunkSize
+	cS.mu.Unlock()
+
+	head, hErr := cS.client.BlockNumber(ctx)
+
+	if hErr != nil {
+		return false, hErr
+	}
+
+	if head < cS.config.Confirmations || head-cS.config.Confirmations < from {
+		return false, nil
+	}
+
+	confirmedHead := head - cS.config.Confirmations
+	to := from + chunkSize - 1
+
+	if to > confirmedHead {
+		to = confirmedHead
+	}
+
+	if cS.config.EndBlock > 0 && to > cS.config.EndBlock {
+		to = cS.config.EndBlock
+	}
+
+	logs, fErr := cS.client.FilterLogs(ctx, cS.FilterQuery(from, to))
+
+	if fErr != nil {
+		if !IsTooManyResultsError(fErr) {
+			return false, fErr
+		}
+
+		if chunkSize == 1 {
+			return false, fmt.Errorf("%w: the node returns too many results for the single block %d: %v",
+				stream.ErrFailed, from, fErr)
+		}
+
+		// Retry the same start with a smaller range
+		cS.mu.Lock()
+		cS.chunkSize = chunkSize / 2
+		cS.mu.Unlock()
+
+		logger.LogW("Too many results for the blocks ", from, "-", to, ", range is reduced to ", chunkSize/2)
+
+		return true, nil
+	}
+
+	messages, bErr := BuildMessages(ctx, cS.client, cS.config.Channel, logs)
+
+	if bErr != nil {
+		return false, bErr
+	}
+
+	cS.mu.Lock()
+	defer cS.mu.Unlock()
+
+	// Grow the range back after the successful queries
+	if cS.chunkSize < cS.config.MaxRange {
+		cS.chunkSize = min(cS.chunkSize*2, cS.config.MaxRange)
+	}
+
+	for _, msg := range messages {
+		cS.pending[uint64(msg.Offset)]++
+	}
+
+	cS.queue = append(cS.queue, messages...)
+	cS.scannedTo = to
+	cS.hasScanned = true
+	cS.nextBlock = to + 1
+
+	// Ranges without relevant logs move the cursor forward directly
+	cS.updateCursor()
+
+	return true, nil
+}
+
+/*
+isDone returns true if the EndBlock has been scanned. Has to be called with the lock held
+*/
+func (cS *ChainSource) isDone() bool {
+	return cS.config.EndBlock > 0 && cS.nextBlock > cS.config.EndBlock
+}
+
+/*
+Progress returns the next block to be scanned and the last block saved to the cursor
+*/
+func (cS *ChainSource) Progress() (nextBlock uint64, savedCursor uint64) {
+	cS.mu.Lock()
+	defer cS.mu.Unlock()
+
+	return cS.nextBlock, cS.savedCursor
+}
+
+/*
+IsTooManyResultsError returns true if the eth_getLogs error means that the queried range has to be reduced. Nodes and
+providers phrase this differently, so the common messages are matched
+*/
+func IsTooManyResultsError(err error) bool {
+	message := strings.ToLower(err.Error())
+
+	for _, pattern := range []string{
+		"too many results",
+		"query returned more than",
+		"log response size exceeded",
+		"response size exceeded",
+		"block range is too large",
+		"block range too large",
+		"exceed maximum block range",
+		"limit exceeded",
+		"query timeout exceeded",
+	} {
+		if strings.Contains(message, pattern) {
+			return true
+		}
+	}
+
+	return false
+}
+
+/*
+BuildMessages converts the logs into one transaction message per transaction, in the order of the logs. Removed logs
+are skipped. The block number is used as the offset and the transaction hash as the key of the messages
+*/
+func BuildMessages(ctx context.Context, client ChainReader, channel string, logs []types.Log) ([]*stream.Message, error) {
+	messages := []*stream.Message{}
+	seenTransactions := map[common.Hash]bool{}
+
+	for _, eventLog := range logs {
+		if eventLog.Removed || seenTransactions[eventLog.TxHash] {
+			continue
+		}
+
+		seenTransactions[eventLog.TxHash] = true
+
+		value, bErr := BuildTransactionMessage(ctx, client, eventLog.TxHash)
+
+		if bErr != nil {
+			return nil, bErr
+		}
+
+		msg := stream.NewMessage(channel, value)
+		msg.Key = []byte(eventLog.TxHash.Hex())
+		msg.Offset = int64(eventLog.BlockNumber)
+		msg.Headers["block-hash"] = eventLog.BlockHash.Hex()
+
+		messages = append(messages, msg)
+	}
+
+	return messages, nil
+}
+
+/*
+updateCursor saves the last block whose messages are all committed. Has to be called with the lock held
+*/
+func (cS *ChainSource) updateCursor() {
+	if !cS.hasScanned {
+		return
+	}
+
+	safeBlock := cS.scannedTo
+
+	for block := range cS.pending {
+		if block <= safeBlock {
+			// Nothing before the lowest pending block is guaranteed to be processed
+			if block == 0 {
+				return
+			}
+
+			safeBlock = block - 1
+		}
+	}
+
+	if cS.isSaved && safeBlock <= cS.savedCursor {
+		return
+	}
+
+	if sErr := cS.cursor.Save(safeBlock); sErr != nil {
+		logger.LogW("Error while saving the block cursor: ", sErr)
+		return
+	}
+
+	cS.savedCursor = safeBlock
+	cS.isSaved = true
+}
+
+func (cS *ChainSource) Commit(msg *stream.Message) error {
+	cS.mu.Lock()
+	defer cS.mu.Unlock()
+
+	block := uint64(msg.Offset)
+
+	if cS.pending[block] > 0 {
+		cS.pending[block]--
+
+		if cS.pending[block] == 0 {
+			delete(cS.pending, block)
+		}
+	}
+
+	cS.updateCursor()
+
+	return nil
+}
+
+func (cS *ChainSource) Close() error {
+	return nil
+}
+
+/*
+sleep waits for the given duration or until the context is done
+*/
+func sleep(ctx context.Context, duration time.Duration) {
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+}
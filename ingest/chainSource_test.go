@@ -0,0 +1,390 @@
+package ingest
+
+import (
+	"context"
+	"eigenlayer_hack/stream"
+	"encoding/json"
+	"errors"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+/*
+fakeChain is a ChainReader serving the logs of its blocks, filterLogs replaces the log query when it is set
+*/
+type fakeChain struct {
+	mu         sync.Mutex
+	head       uint64
+	logs       []types.Log
+	queries    [][2]uint64
+	filterLogs func(from uint64, to uint64) ([]types.Log, error)
+}
+
+func (fC *fakeChain) BlockNumber(context.Context) (uint64, error) {
+	return fC.head, nil
+}
+
+func (fC *fakeChain) FilterLogs(_ context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	from, to := q.FromBlock.Uint64(), q.ToBlock.Uint64()
+
+	fC.mu.Lock()
+	fC.queries = append(fC.queries, [2]uint64{from, to})
+	fC.mu.Unlock()
+
+	if fC.filterLogs != nil {
+		return fC.filterLogs(from, to)
+	}
+
+	ret := []types.Log{}
+
+	for _, eventLog := range fC.logs {
+		if eventLog.BlockNumber >= from && eventLog.BlockNumber <= to {
+			ret = append(ret, eventLog)
+		}
+	}
+
+	return ret, nil
+}
+
+func (fC *fakeChain) TransactionByHash(context.Context, common.Hash) (*types.Transaction, bool, error) {
+	return types.NewTx(&types.LegacyTx{GasPrice: big.NewInt(1), Gas: 21000, V: big.NewInt(27), R: big.NewInt(1),
+		S: big.NewInt(1)}), false, nil
+}
+
+func (fC *fakeChain) TransactionReceipt(_ context.Context, txHash common.Hash) (*types.Receipt, error) {
+	return &types.Receipt{Status: types.ReceiptStatusSuccessful, TxHash: txHash, Logs: []*types.Log{}}, nil
+}
+
+func (fC *fakeChain) Queries() [][2]uint64 {
+	fC.mu.Lock()
+	defer fC.mu.Unlock()
+
+	return append([][2]uint64{}, fC.queries...)
+}
+
+func TestChainSourceCommitsWhileReading(t *testing.T) {
+	release := make(chan struct{})
+	chain := &fakeChain{head: 20}
+	chain.filterLogs = func(from uint64, to uint64) ([]types.Log, error) {
+		if from == 10 {
+			return []types.Log{{BlockNumber: 10, TxHash: common.HexToHash("0x1")}}, nil
+		}
+
+		// The scan of the next range hangs until the commit is done
+		<-release
+
+		return []types.Log{}, nil
+	}
+
+	cursor := &MemoryCursor{}
+	source := NewChainSource(chain, cursor, ChainSourceConfig{StartBlock: 10, EndBlock: 11, MaxRange: 1,
+		PollInterval: time.Millisecond})
+
+	msg, rErr := source.Read(context.Background())
+	if rErr != nil {
+		t.Fatal(rErr)
+	}
+
+	reading := make(chan error, 1)
+	go func() {
+		_, err := source.Read(context.Background())
+		reading <- err
+	}()
+
+	// Let the second Read reach the hanging query
+	time.Sleep(50 * time.Millisecond)
+
+	committed := make(chan error, 1)
+	go func() {
+		committed <- source.Commit(msg)
+	}()
+
+	select {
+	case cErr := <-committed:
+		if cErr != nil {
+			t.Fatal(cErr)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Commit is blocked by the running Read")
+	}
+
+	if block, isSaved, _ := cursor.Load(); !isSaved || block != 10 {
+		t.Fatalf("expected the cursor at 10, got %d (saved %v)", block, isSaved)
+	}
+
+	close(release)
+
+	if err := <-reading; !errors.Is(err, stream.ErrClosed) {
+		t.Fatalf("expected the source to be exhausted, got %v", err)
+	}
+
+	if block, _, _ := cursor.Load(); block != 11 {
+		t.Fatalf("expected the cursor at 11, got %d", block)
+	}
+}
+
+func TestChainSourceFailsOnASingleBlockWithTooManyResults(t *testing.T) {
+	chain := &fakeChain{head: 100}
+	chain.filterLogs = func(uint64, uint64) ([]types.Log, error) {
+		return nil, errors.New("query returned more than 10000 results")
+	}
+
+	source := NewChainSource(chain, &MemoryCursor{}, ChainSourceConfig{StartBlock: 10, MaxRange: 8,
+		PollInterval: time.Millisecond})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, rErr := source.Read(ctx); !errors.Is(rErr, stream.ErrFailed) {
+		t.Fatalf("expected the source to fail, got %v", rErr)
+	}
+
+	expected := [][2]uint64{{10, 17}, {10, 13}, {10, 11}, {10, 10}}
+	queries := chain.Queries()
+
+	if len(queries) != len(expected) {
+		t.Fatalf("expected the queries %v, got %v", expected, queries)
+	}
+
+	for i := range expected {
+		if queries[i] != expected[i] {
+			t.Fatalf("expected the queries %v, got %v", expected, queries)
+		}
+	}
+}
+
+/*
+rpcNode is a JSON-RPC node serving a transaction with a single log at each of its log blocks. eth_getLogs refuses the
+ranges longer than maxRange like the providers do
+*/
+type rpcNode struct {
+	t        *testing.T
+	head     uint64
+	maxRange uint64
+
+	mu           sync.Mutex
+	logs         []types.Log
+	transactions map[common.Hash]json.RawMessage
+	receipts     map[common.Hash]json.RawMessage
+	served       [][2]uint64
+	rejected     int
+}
+
+func newRPCNode(t *testing.T, head uint64, maxRange uint64, logBlocks ...uint64) *rpcNode {
+	node := &rpcNode{t: t, head: head, maxRange: maxRange, transactions: map[common.Hash]json.RawMessage{},
+		receipts: map[common.Hash]json.RawMessage{}}
+
+	key, kErr := crypto.GenerateKey()
+	if kErr != nil {
+		t.Fatal(kErr)
+	}
+
+	signer := types.LatestSignerForChainID(big.NewInt(1))
+
+	for i, block := range logBlocks {
+		tx, sErr := types.SignNewTx(key, signer, &types.DynamicFeeTx{ChainID: big.NewInt(1), Nonce: uint64(i),
+			Gas: 21000, GasFeeCap: big.NewInt(1), GasTipCap: big.NewInt(1)})
+		if sErr != nil {
+			t.Fatal(sErr)
+		}
+
+		blockHash := common.BigToHash(new(big.Int).SetUint64(block))
+		eventLog := types.Log{Address: common.HexToAddress("0x1"), Topics: []common.Hash{common.HexToHash("0x2")},
+			Data: []byte{}, BlockNumber: block, TxHash: tx.Hash(), BlockHash: blockHash}
+		node.logs = append(node.logs, eventLog)
+
+		txData, _ := tx.MarshalJSON()
+		fields := map[string]interface{}{}
+		_ = json.Unmarshal(txData, &fields)
+		fields["blockHash"] = blockHash.Hex()
+		fields["blockNumber"] = hexutil.EncodeUint64(block)
+		fields["from"] = crypto.PubkeyToAddress(key.PublicKey).Hex()
+		node.transactions[tx.Hash()], _ = json.Marshal(fields)
+
+		receipt := &types.Receipt{Type: types.DynamicFeeTxType, Status: types.ReceiptStatusSuccessful,
+			TxHash: tx.Hash(), BlockHash: blockHash, BlockNumber: new(big.Int).SetUint64(block),
+			Logs: []*types.Log{&node.logs[len(node.logs)-1]}}
+		receipt.Bloom = types.CreateBloom(types.Receipts{receipt})
+		node.receipts[tx.Hash()], _ = receipt.MarshalJSON()
+	}
+
+	return node
+}
+
+func (rN *rpcNode) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	request := struct {
+		ID     json.RawMessage   `json:"id"`
+		Method string            `json:"method"`
+		Params []json.RawMessage `json:"params"`
+	}{}
+
+	if dErr := json.NewDecoder(r.Body).Decode(&request); dErr != nil {
+		rN.t.Error(dErr)
+		return
+	}
+
+	result, rpcErr := rN.call(request.Method, request.Params)
+	response := map[string]interface{}{"jsonrpc": "2.0", "id": request.ID}
+
+	if rpcErr != "" {
+		response["error"] = map[string]interface{}{"code": -32005, "message": rpcErr}
+	} else {
+		response["result"] = result
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+func (rN *rpcNode) call(method string, params []json.RawMessage) (interface{}, string) {
+	rN.mu.Lock()
+	defer rN.mu.Unlock()
+
+	switch method {
+	case "eth_blockNumber":
+		return hexutil.EncodeUint64(rN.head), ""
+	case "eth_getLogs":
+		filter := struct {
+			FromBlock hexutil.Uint64 `json:"fromBlock"`
+			ToBlock   hexutil.Uint64 `json:"toBlock"`
+		}{}
+		_ = json.Unmarshal(params[0], &filter)
+		from, to := uint64(filter.FromBlock), uint64(filter.ToBlock)
+
+		if to-from+1 > rN.maxRange {
+			rN.rejected++
+			return nil, "query returned more than 10000 results"
+		}
+
+		rN.served = append(rN.served, [2]uint64{from, to})
+		logs := []types.Log{}
+
+		for _, eventLog := range rN.logs {
+			if eventLog.BlockNumber >= from && eventLog.BlockNumber <= to {
+				logs = append(logs, eventLog)
+			}
+		}
+
+		return logs, ""
+	case "eth_getTransactionByHash", "eth_getTransactionReceipt":
+		var hash common.Hash
+		_ = json.Unmarshal(params[0], &hash)
+
+		if method == "eth_getTransactionByHash" {
+			return rN.transactions[hash], ""
+		}
+
+		return rN.receipts[hash], ""
+	}
+
+	rN.t.Error("unexpected method ", method)
+
+	return nil, "method not found"
+}
+
+func TestChainSourceHalvesCheckpointsAndResumes(t *testing.T) {
+	node := newRPCNode(t, 300, 16, 105, 150, 190)
+	server := httptest.NewServer(node)
+	defer server.Close()
+
+	client, dErr := ethclient.Dial(server.URL)
+	if dErr != nil {
+		t.Fatal(dErr)
+	}
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cursor := NewFileCursor(filepath.Join(t.TempDir(), "backfill.cursor"))
+	config := ChainSourceConfig{StartBlock: 100, EndBlock: 200, MaxRange: 64, PollInterval: time.Millisecond}
+
+	// The first run stops with the message of the block 150 in flight
+	source := NewChainSource(client, cursor, config)
+
+	first, rErr := source.Read(ctx)
+	if rErr != nil || first.Offset != 105 {
+		t.Fatalf("expected the message of the block 105, got %v (%v)", first, rErr)
+	}
+
+	if cErr := source.Commit(first); cErr != nil {
+		t.Fatal(cErr)
+	}
+
+	second, rErr := source.Read(ctx)
+	if rErr != nil || second.Offset != 150 {
+		t.Fatalf("expected the message of the block 150, got %v (%v)", second, rErr)
+	}
+
+	if block, _, _ := cursor.Load(); block != 149 {
+		t.Fatalf("expected the checkpoint before the uncommitted block 150, got %d", block)
+	}
+
+	// The restarted run continues from the checkpoint
+	source = NewChainSource(client, cursor, config)
+	offsets := []int64{}
+
+	for {
+		msg, rErr := source.Read(ctx)
+		if errors.Is(rErr, stream.ErrClosed) {
+			break
+		}
+
+		if rErr != nil {
+			t.Fatal(rErr)
+		}
+
+		offsets = append(offsets, msg.Offset)
+
+		if cErr := source.Commit(msg); cErr != nil {
+			t.Fatal(cErr)
+		}
+	}
+
+	if len(offsets) != 2 || offsets[0] != 150 || offsets[1] != 190 {
+		t.Fatalf("expected the blocks 150 and 190 after the restart, got %v", offsets)
+	}
+
+	if block, _, _ := cursor.Load(); block != 200 {
+		t.Fatalf("expected the checkpoint at the end of the range, got %d", block)
+	}
+
+	node.mu.Lock()
+	defer node.mu.Unlock()
+
+	if node.rejected == 0 {
+		t.Fatal("expected the node to reject the initial range")
+	}
+
+	for _, served := range node.served {
+		if served[1]-served[0]+1 > node.maxRange {
+			t.Fatalf("served range %v is longer than %d blocks", served, node.maxRange)
+		}
+	}
+}
+
+func TestChainSourceIgnoresACursorOutsideTheRange(t *testing.T) {
+	chain := &fakeChain{head: 300, logs: []types.Log{{BlockNumber: 105, TxHash: common.HexToHash("0x1")}}}
+
+	cursor := &MemoryCursor{}
+	_ = cursor.Save(200)
+
+	source := NewChainSource(chain, cursor, ChainSourceConfig{StartBlock: 100, EndBlock: 120, MaxRange: 100,
+		PollInterval: time.Millisecond})
+
+	msg, rErr := source.Read(context.Background())
+	if rErr != nil || msg.Offset != 105 {
+		t.Fatalf("expected the message of the block 105, got %v (%v)", msg, rErr)
+	}
+}
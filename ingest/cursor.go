@@ -0,0 +1,101 @@
+package ingest
+
+import (
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+/*
+Cursor persists the last block that is fully processed, so the ingestion continues where it left off after a restart
+*/
+type Cursor interface {
+	/*
+		Load returns the stored block number and true, false if nothing is stored yet
+	*/
+	Load() (uint64, bool, error)
+	/*
+		Save stores the given block number
+	*/
+	Save(block uint64) error
+}
+
+/*
+FileCursor is a Cursor that keeps the block number in a text file
+*/
+type FileCursor struct {
+	mu   sync.Mutex
+	path string
+}
+
+/*
+NewFileCursor constructor for the FileCursor object
+*/
+func NewFileCursor(path string) *FileCursor {
+	return &FileCursor{path: path}
+}
+
+func (fC *FileCursor) Load() (uint64, bool, error) {
+	fC.mu.Lock()
+	defer fC.mu.Unlock()
+
+	content, rErr := os.ReadFile(fC.path)
+
+	if errors.Is(rErr, os.ErrNotExist) {
+		return 0, false, nil
+	}
+
+	if rErr != nil {
+		return 0, false, rErr
+	}
+
+	block, pErr := strconv.ParseUint(strings.TrimSpace(string(content)), 10, 64)
+
+	if pErr != nil {
+		return 0, false, pErr
+	}
+
+	return block, true, nil
+}
+
+func (fC *FileCursor) Save(block uint64) error {
+	fC.mu.Lock()
+	defer fC.mu.Unlock()
+
+	// Write to a temporary file first so that a crash does not leave a half written cursor
+	tmpPath := fC.path + ".tmp"
+
+	if wErr := os.WriteFile(tmpPath, []byte(strconv.FormatUint(block, 10)+"\n"), 0o644); wErr != nil {
+		return wErr
+	}
+
+	return os.Rename(tmpPath, fC.path)
+}
+
+/*
+MemoryCursor is a Cursor that keeps the block number in memory
+*/
+type MemoryCursor struct {
+	mu      sync.Mutex
+	block   uint64
+	isSaved bool
+}
+
+func (mC *MemoryCursor) Load() (uint64, bool, error) {
+	mC.mu.Lock()
+	defer mC.mu.Unlock()
+
+	return mC.block, mC.isSaved, nil
+}
+
+func (mC *MemoryCursor) Save(block uint64) error {
+	mC.mu.Lock()
+	defer mC.mu.Unlock()
+
+	mC.block = block
+	mC.isSaved = true
+
+	return nil
+}
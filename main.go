@@ -2,73 +2,49 @@ package main
 
 import (
 	"eigenlayer_hack/utils"
-	"encoding/json"
-	"solity/schemas"
-	"solity/utils/evm/evmStructs"
-	kafkaUtils "solity/utils/kafka"
-	"solity/utils/logger"
-	"strings"
+	"os"
 )
 
 func main() {
-	envMap := utils.InitilializeEnvironment()
-
-	listenChannels := strings.Split(envMap["KAFKA_LISTEN_CHANNEL"], ":")
-	logger.LogI("Currently listening ", listenChannels)
-
-	outputChannels := strings.Split(envMap["KAFKA_OUTPUT_CHANNEL"], ":")
-	logger.LogI("Kafka out channel ", outputChannels)
-	if len(outputChannels) != len(listenChannels) {
-		logger.LogE("Kafka listen channels and output channels length miss-match")
-	}
-	//Listen Channels
-	channelMapping := map[string]string{}
-
-	for i, listenChannel := range listenChannels {
-		channelMapping[listenChannel] = outputChannels[i]
-	}
-	c, p, err := kafkaUtils.InitializeKafkaConsumerAndProducer(envMap["KAFKA_URI"], listenChannels, "centralisedEx",
-		nil, nil)
-	if err != nil {
-		logger.LogE("Error while initializing kafka: ", err)
+	// The registry commands validate only the settings they use, deploy runs before the registry address exists
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "deploy":
+			runRegistryDeploy(utils.InitializeEnvironmentFor("registry.rpc", "registry.chainId", "registry.privateKey", "signer",
+				"transactions"), os.Args[2:])
+			return
+		case "inspect":
+			runRegistryInspect(utils.InitializeEnvironmentFor("registry.rpc", "registry.address"), os.Args[2:])
+			return
+		case "dump":
+			runRegistryDump(utils.InitializeEnvironmentFor("registry.rpc", "registry.address"), os.Args[2:])
+			return
+		case "reconcile":
+			runRegistryReconcile(utils.InitializeEnvironmentFor("registry.rpc", "registry.address", "registryIndex"),
+				os.Args[2:])
+			return
+		}
 	}
 
-	defer c.Close()
-	defer p.Close()
-	operatorRegisterSignature := evmStructs.NewSignatureKeeper("OperatorSubscribed (indexed address operator, indexed uint32 chainID)")
-
-	// Start message reading loop
-	for {
-		// Read the message
-		msg, mErr := c.ReadMessage(-1)
-
-		if mErr == nil {
-			logger.LogIf("Topic is: %s", *msg.TopicPartition.Topic)
-
-			// This service expects schemas.SolityETHCompleteTransactionMessage format
-			receivedMessage := schemas.SolityETHCompleteTransactionMessage{}
-			rMErr := json.Unmarshal(msg.Value, &receivedMessage)
-			if rMErr != nil {
-				logger.LogW("Error while unmarshalling the message object : ", rMErr)
-				// Since this message cannot be unmarshalled, we skip the execution for this message
-				continue
-			}
-
-			theOutputChannel, isOk := channelMapping[*msg.TopicPartition.Topic]
-
-			if isOk {
-				// Process the message
-				go utils.CheckAVSMetadata(receivedMessage,
-					p,
-					&theOutputChannel,
-					&operatorRegisterSignature,
-					envMap,
-				)
-			}
-
-		} else {
-			// The client will automatically try to recover from all errors.
-			logger.LogWf("Consumer error: %v (%v)\n", mErr, msg)
+	cfg := utils.InitilializeEnvironment()
+
+	// The first argument selects the mode, without it the tracker is started
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "replay-dlq":
+			runReplay(cfg, os.Args[2:])
+			return
+		case "backfill":
+			runBackfill(cfg, os.Args[2:])
+			return
+		case "deferred":
+			runDeferredInspect(cfg, os.Args[2:])
+			return
+		case "snapshots":
+			runSnapshotQuery(cfg, os.Args[2:])
+			return
 		}
 	}
+
+	runTracker(cfg)
 }
@@ -0,0 +1,58 @@
+package metadata
+
+import (
+	"context"
+	"eigenlayer_hack/structs"
+	"errors"
+	"strings"
+	"time"
+)
+
+/*
+Entry is the cached metadata of an operator or an AVS. Found is false for the addresses the sources do not know, so
+that the unknown addresses are cached as well. Stats is the Dune stats row of the entities found on Dune
+*/
+type Entry struct {
+	Name        string
+	Website     string       `json:",omitempty"`
+	Description string       `json:",omitempty"`
+	Logo        string       `json:",omitempty"`
+	Twitter     string       `json:",omitempty"`
+	Stats       *structs.Row `json:",omitempty"`
+	Found       bool
+	Source      string
+	FetchedAt   time.Time
+}
+
+/*
+Cache stores the metadata entries until their TTL expires
+*/
+type Cache interface {
+	/*
+		Get returns the entry of the given key, false if it is missing or expired
+	*/
+	Get(ctx context.Context, key string) (Entry, bool, error)
+	/*
+		Set stores the entry for the given TTL
+	*/
+	Set(ctx context.Context, key string, entry Entry, ttl time.Duration) error
+	/*
+		Close releases the resources of the cache
+	*/
+	Close() error
+}
+
+/*
+NewCache creates the cache of the given kind: "memory" or "redis" (location is the redis address, shared by the
+replicas)
+*/
+func NewCache(kind string, location string, password string) (Cache, error) {
+	switch strings.ToLower(kind) {
+	case "", "memory":
+		return NewMemoryCache(), nil
+	case "redis":
+		return NewRedisCache(location, password)
+	default:
+		return nil, errors.New("unknown metadata cache kind: " + kind)
+	}
+}
@@ -0,0 +1,83 @@
+package metadata
+
+import (
+	"context"
+	"eigenlayer_hack/structs"
+	"eigenlayer_hack/utils"
+	"errors"
+	"solity/utils/logger"
+	"time"
+)
+
+/*
+StatsRecorder keeps the stats rows read from Dune, e.g. as time series
+*/
+type StatsRecorder interface {
+	RecordStats(kind Kind, address string, stats structs.Row, at time.Time) error
+}
+
+/*
+DuneProvider reads the names from the Dune EigenLayer stats, the stats row is kept in the entry for the scoring
+*/
+type DuneProvider struct {
+	client   *utils.DuneClient
+	recorder StatsRecorder
+}
+
+/*
+NewDuneProvider constructor for the DuneProvider object. The read stats rows are passed to the recorder, which can be
+nil
+*/
+func NewDuneProvider(client *utils.DuneClient, recorder StatsRecorder) *DuneProvider {
+	return &DuneProvider{client: client, recorder: recorder}
+}
+
+func (dP *DuneProvider) Name() string {
+	return "dune"
+}
+
+func (dP *DuneProvider) Lookup(ctx context.Context, kind Kind, address string) (Entry, error) {
+	switch kind {
+	case KindOperator:
+		resOp, dErr := dP.client.GetOperatorStats(ctx, address)
+		if dErr != nil {
+			return Entry{}, dErr
+		}
+
+		dP.record(kind, address, resOp.Result.Rows)
+
+		if len(resOp.Result.Rows) == 0 || resOp.Result.Rows[0].OperatorName() == "" {
+			return Entry{}, nil
+		}
+
+		return Entry{Name: resOp.Result.Rows[0].OperatorName(), Stats: &resOp.Result.Rows[0], Found: true}, nil
+	case KindAVS:
+		resAvs, dErr := dP.client.GetAVSStats(ctx, address)
+		if dErr != nil {
+			return Entry{}, dErr
+		}
+
+		dP.record(kind, address, resAvs.Result.Rows)
+
+		if len(resAvs.Result.Rows) == 0 || resAvs.Result.Rows[0].AVSName() == "" {
+			return Entry{}, nil
+		}
+
+		return Entry{Name: resAvs.Result.Rows[0].AVSName(), Stats: &resAvs.Result.Rows[0], Found: true}, nil
+	}
+
+	return Entry{}, errors.New("unknown metadata kind: " + string(kind))
+}
+
+/*
+record passes the stats row of the entity to the recorder, a failed recording does not fail the lookup
+*/
+func (dP *DuneProvider) record(kind Kind, address string, rows []structs.Row) {
+	if dP.recorder == nil || len(rows) == 0 {
+		return
+	}
+
+	if rErr := dP.recorder.RecordStats(kind, address, rows[0], time.Now().UTC()); rErr != nil {
+		logger.LogW("Error while recording the stats of the ", string(kind), " ", address, ": ", rErr)
+	}
+}
@@ -0,0 +1,69 @@
+package metadata
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Expired entries are pruned at most once per pruneInterval
+const pruneInterval = time.Minute
+
+type memoryEntry struct {
+	entry     Entry
+	expiresAt time.Time
+}
+
+/*
+MemoryCache is a Cache that keeps the entries in the process memory
+*/
+type MemoryCache struct {
+	mu        sync.Mutex
+	entries   map[string]memoryEntry
+	lastPrune time.Time
+}
+
+/*
+NewMemoryCache constructor for the MemoryCache object
+*/
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{entries: map[string]memoryEntry{}, lastPrune: time.Now()}
+}
+
+func (mC *MemoryCache) Get(_ context.Context, key string) (Entry, bool, error) {
+	mC.mu.Lock()
+	defer mC.mu.Unlock()
+
+	cached, isOk := mC.entries[key]
+
+	if !isOk || time.Now().After(cached.expiresAt) {
+		return Entry{}, false, nil
+	}
+
+	return cached.entry, true, nil
+}
+
+func (mC *MemoryCache) Set(_ context.Context, key string, entry Entry, ttl time.Duration) error {
+	mC.mu.Lock()
+	defer mC.mu.Unlock()
+
+	now := time.Now()
+	mC.entries[key] = memoryEntry{entry: entry, expiresAt: now.Add(ttl)}
+
+	// Prune the expired entries from time to time so that the map does not grow forever
+	if now.Sub(mC.lastPrune) > pruneInterval {
+		for key, cached := range mC.entries {
+			if now.After(cached.expiresAt) {
+				delete(mC.entries, key)
+			}
+		}
+
+		mC.lastPrune = now
+	}
+
+	return nil
+}
+
+func (mC *MemoryCache) Close() error {
+	return nil
+}
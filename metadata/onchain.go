@@ -0,0 +1,293 @@
+package metadata
+
+import (
+	"context"
+	"eigenlayer_hack/ingest"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Metadata documents larger than this are rejected
+const maxMetadataSize = 1 << 20
+
+// defaultLogRange is the number of blocks per eth_getLogs call when the LogRange is not set
+const defaultLogRange = 50000
+
+var (
+	// OperatorMetadataURIUpdated(address indexed operator, string metadataURI) of the DelegationManager
+	operatorMetadataURITopic = crypto.Keccak256Hash([]byte("OperatorMetadataURIUpdated(address,string)"))
+	// AVSMetadataURIUpdated(address indexed avs, string metadataURI) of the AVSDirectory
+	avsMetadataURITopic = crypto.Keccak256Hash([]byte("AVSMetadataURIUpdated(address,string)"))
+)
+
+/*
+LogFilterer is the part of the node API used by the OnChainProvider, *ethclient.Client implements it
+*/
+type LogFilterer interface {
+	BlockNumber(ctx context.Context) (uint64, error)
+	FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error)
+}
+
+/*
+OnChainProviderConfig holds the settings of the OnChainProvider
+*/
+type OnChainProviderConfig struct {
+	DelegationManager common.Address
+	AVSDirectory      common.Address
+	// FromBlock is the first block searched for the metadata URI updates, the deployment block of the contracts
+	FromBlock uint64
+	// LogRange is the number of blocks per eth_getLogs call (default 50000), the chain is searched backwards from the
+	// head. The range is halved while the node rejects a query for returning too many results
+	LogRange uint64
+	// IPFSGateway serves the ipfs:// URIs, e.g. https://ipfs.io/ipfs/
+	IPFSGateway string
+	Timeout     time.Duration
+}
+
+/*
+OnChainProvider reads the names from the metadata documents the operators and AVSs publish on-chain: the latest
+OperatorMetadataURIUpdated / AVSMetadataURIUpdated event points to a JSON document holding the name
+*/
+type OnChainProvider struct {
+	client     LogFilterer
+	config     OnChainProviderConfig
+	httpClient *http.Client
+}
+
+/*
+NewOnChainProvider constructor for the OnChainProvider object
+*/
+func NewOnChainProvider(client LogFilterer, config OnChainProviderConfig) *OnChainProvider {
+	if config.Timeout <= 0 {
+		config.Timeout = 10 * time.Second
+	}
+
+	if config.IPFSGateway == "" {
+		config.IPFSGateway = "https://ipfs.io/ipfs/"
+	}
+
+	if config.LogRange == 0 {
+		config.LogRange = defaultLogRange
+	}
+
+	return &OnChainProvider{client: client, config: config, httpClient: &http.Client{Timeout: config.Timeout}}
+}
+
+func (oP *OnChainProvider) Name() string {
+	return "onchain"
+}
+
+func (oP *OnChainProvider) Lookup(ctx context.Context, kind Kind, address string) (Entry, error) {
+	if !common.IsHexAddress(address) {
+		return Entry{}, errors.New("invalid address " + address)
+	}
+
+	var contract common.Address
+	var topic common.Hash
+
+	switch kind {
+	case KindOperator:
+		contract, topic = oP.config.DelegationManager, operatorMetadataURITopic
+	case KindAVS:
+		contract, topic = oP.config.AVSDirectory, avsMetadataURITopic
+	default:
+		return Entry{}, errors.New("unknown metadata kind: " + string(kind))
+	}
+
+	metadataURI, found, uErr := oP.latestMetadataURI(ctx, contract, topic, common.HexToAddress(address))
+	if uErr != nil || !found {
+		return Entry{}, uErr
+	}
+
+	document, fErr := oP.fetchDocument(ctx, metadataURI)
+	if fErr != nil {
+		return Entry{}, fErr
+	}
+
+	return document, nil
+}
+
+/*
+latestMetadataURI returns the URI of the latest metadata update of the entity, false if it has never published one
+*/
+func (oP *OnChainProvider) latestMetadataURI(ctx context.Context, contract common.Address, topic common.Hash,
+	entity common.Address) (string, bool, error) {
+	head, bErr := oP.client.BlockNumber(ctx)
+	if bErr != nil {
+		return "", false, bErr
+	}
+
+	to := head
+	logRange := oP.config.LogRange
+
+	for to >= oP.config.FromBlock {
+		from := oP.config.FromBlock
+
+		if to-oP.config.FromBlock >= logRange {
+			from = to - logRange + 1
+		}
+
+		logs, fErr := oP.client.FilterLogs(ctx, ethereum.FilterQuery{
+			FromBlock: new(big.Int).SetUint64(from),
+			ToBlock:   new(big.Int).SetUint64(to),
+			Addresses: []common.Address{contract},
+			Topics:    [][]common.Hash{{topic}, {common.BytesToHash(entity.Bytes())}},
+		})
+		if fErr != nil {
+			// Retry the same end with a smaller range, a single block that is refused cannot be searched
+			if ingest.IsTooManyResultsError(fErr) && to > from {
+				logRange = (to - from + 1) / 2
+				continue
+			}
+
+			return "", false, fErr
+		}
+
+		// Grow the range back after the successful queries
+		logRange = min(logRange*2, oP.config.LogRange)
+
+		// The logs are ordered, the last one that is still canonical is the current URI
+		for i := len(logs) - 1; i >= 0; i-- {
+			if logs[i].Removed {
+				continue
+			}
+
+			metadataURI, dErr := decodeMetadataURI(logs[i].Data)
+			if dErr != nil {
+				return "", false, dErr
+			}
+
+			return metadataURI, true, nil
+		}
+
+		if from == oP.config.FromBlock {
+			break
+		}
+
+		to = from - 1
+	}
+
+	return "", false, nil
+}
+
+/*
+decodeMetadataURI decodes the non-indexed string parameter of the metadata URI events
+*/
+func decodeMetadataURI(data []byte) (string, error) {
+	stringType, tErr := abi.NewType("string", "", nil)
+	if tErr != nil {
+		return "", tErr
+	}
+
+	values, uErr := abi.Arguments{{Type: stringType}}.Unpack(data)
+	if uErr != nil {
+		return "", uErr
+	}
+
+	metadataURI, isString := values[0].(string)
+	if !isString {
+		return "", errors.New("metadata URI is not a string")
+	}
+
+	return strings.TrimSpace(metadataURI), nil
+}
+
+/*
+metadataDocument is the metadata JSON published by the operators and the AVSs
+*/
+type metadataDocument struct {
+	Name        string `json:"name"`
+	Website     string `json:"website"`
+	Description string `json:"description"`
+	Logo        string `json:"logo"`
+	Twitter     string `json:"twitter"`
+}
+
+/*
+fetchDocument downloads and validates the metadata document. Documents without a usable name are reported as unknown
+*/
+func (oP *OnChainProvider) fetchDocument(ctx context.Context, metadataURI string) (Entry, error) {
+	documentURL, rErr := oP.resolveURI(metadataURI)
+	if rErr != nil {
+		// An unusable URI is the entity's fault, retrying does not help
+		return Entry{}, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, documentURL, nil)
+	if err != nil {
+		return Entry{}, err
+	}
+
+	resp, err := oP.httpClient.Do(req)
+	if err != nil {
+		return Entry{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusInternalServerError || resp.StatusCode == http.StatusTooManyRequests {
+		return Entry{}, fmt.Errorf("metadata document %s: status %d", documentURL, resp.StatusCode)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return Entry{}, nil
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxMetadataSize+1))
+	if err != nil {
+		return Entry{}, err
+	}
+
+	document := metadataDocument{}
+
+	if len(body) > maxMetadataSize || json.Unmarshal(body, &document) != nil {
+		return Entry{}, nil
+	}
+
+	name := strings.TrimSpace(document.Name)
+
+	if name == "" || len(name) > 256 || !utf8.ValidString(name) {
+		return Entry{}, nil
+	}
+
+	return Entry{
+		Name:        name,
+		Website:     document.Website,
+		Description: document.Description,
+		Logo:        document.Logo,
+		Twitter:     document.Twitter,
+		Found:       true,
+	}, nil
+}
+
+/*
+resolveURI returns the http(s) URL of the metadata URI, ipfs:// URIs are served by the gateway
+*/
+func (oP *OnChainProvider) resolveURI(metadataURI string) (string, error) {
+	if cid, isIPFS := strings.CutPrefix(metadataURI, "ipfs://"); isIPFS {
+		return strings.TrimRight(oP.config.IPFSGateway, "/") + "/" + strings.TrimLeft(cid, "/"), nil
+	}
+
+	parsed, pErr := url.Parse(metadataURI)
+	if pErr != nil {
+		return "", pErr
+	}
+
+	if (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return "", errors.New("unsupported metadata URI " + metadataURI)
+	}
+
+	return parsed.String(), nil
+}
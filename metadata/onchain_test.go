@@ -0,0 +1,70 @@
+package metadata
+
+import (
+	"context"
+	"errors"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"testing"
+)
+
+/*
+limitedNode refuses the log queries longer than maxRange and serves a single metadata URI update at uriBlock
+*/
+type limitedNode struct {
+	head     uint64
+	maxRange uint64
+	uriBlock uint64
+	data     []byte
+	queries  int
+}
+
+func (lN *limitedNode) BlockNumber(context.Context) (uint64, error) {
+	return lN.head, nil
+}
+
+func (lN *limitedNode) FilterLogs(_ context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	lN.queries++
+	from, to := q.FromBlock.Uint64(), q.ToBlock.Uint64()
+
+	if to-from+1 > lN.maxRange {
+		return nil, errors.New("query returned more than 10000 results")
+	}
+
+	if lN.uriBlock < from || lN.uriBlock > to {
+		return []types.Log{}, nil
+	}
+
+	return []types.Log{{BlockNumber: lN.uriBlock, Data: lN.data}}, nil
+}
+
+func TestLatestMetadataURIHalvesTheRefusedRanges(t *testing.T) {
+	stringType, _ := abi.NewType("string", "", nil)
+	data, pErr := abi.Arguments{{Type: stringType}}.Pack("https://example.com/operator.json")
+	if pErr != nil {
+		t.Fatal(pErr)
+	}
+
+	node := &limitedNode{head: 20000, maxRange: 1000, uriBlock: 12345, data: data}
+	provider := NewOnChainProvider(node, OnChainProviderConfig{FromBlock: 10000})
+
+	metadataURI, found, uErr := provider.latestMetadataURI(context.Background(), common.Address{}, common.Hash{},
+		common.HexToAddress("0x1"))
+	if uErr != nil {
+		t.Fatal(uErr)
+	}
+
+	if !found || metadataURI != "https://example.com/operator.json" {
+		t.Fatalf("expected the metadata URI, got %q (found %v)", metadataURI, found)
+	}
+
+	// A single refused block cannot be searched
+	node.maxRange = 0
+
+	if _, _, uErr := provider.latestMetadataURI(context.Background(), common.Address{}, common.Hash{},
+		common.HexToAddress("0x1")); uErr == nil {
+		t.Fatal("expected the refused block to fail the search")
+	}
+}
@@ -0,0 +1,110 @@
+package metadata
+
+import (
+	"context"
+	"gopkg.in/yaml.v3"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+/*
+overrideFile is the format of the override file (YAML or JSON):
+
+	operators:
+	  "0xabc...": "Operator name"
+	avs:
+	  "0xdef...": "AVS name"
+*/
+type overrideFile struct {
+	Operators map[string]string `yaml:"operators"`
+	AVS       map[string]string `yaml:"avs"`
+}
+
+/*
+OverrideProvider reads the names from a local file maintained by hand. The file is reloaded when it changes
+*/
+type OverrideProvider struct {
+	path    string
+	mu      sync.Mutex
+	modTime time.Time
+	names   map[Kind]map[string]string
+}
+
+/*
+NewOverrideProvider constructor for the OverrideProvider object, loads the file at path
+*/
+func NewOverrideProvider(path string) (*OverrideProvider, error) {
+	oP := &OverrideProvider{path: path}
+
+	if lErr := oP.reload(); lErr != nil {
+		return nil, lErr
+	}
+
+	return oP, nil
+}
+
+func (oP *OverrideProvider) Name() string {
+	return "override"
+}
+
+func (oP *OverrideProvider) Lookup(_ context.Context, kind Kind, address string) (Entry, error) {
+	oP.mu.Lock()
+	defer oP.mu.Unlock()
+
+	if rErr := oP.reload(); rErr != nil {
+		return Entry{}, rErr
+	}
+
+	name, isOk := oP.names[kind][strings.ToLower(address)]
+
+	if !isOk || name == "" {
+		return Entry{}, nil
+	}
+
+	return Entry{Name: name, Found: true}, nil
+}
+
+/*
+reload reads the file if it has changed since the last read
+*/
+func (oP *OverrideProvider) reload() error {
+	info, sErr := os.Stat(oP.path)
+	if sErr != nil {
+		return sErr
+	}
+
+	if info.ModTime().Equal(oP.modTime) {
+		return nil
+	}
+
+	content, rErr := os.ReadFile(oP.path)
+	if rErr != nil {
+		return rErr
+	}
+
+	overrides := overrideFile{}
+
+	if uErr := yaml.Unmarshal(content, &overrides); uErr != nil {
+		return uErr
+	}
+
+	oP.names = map[Kind]map[string]string{KindOperator: lowerKeys(overrides.Operators), KindAVS: lowerKeys(overrides.AVS)}
+	oP.modTime = info.ModTime()
+
+	return nil
+}
+
+/*
+lowerKeys returns the map with lower case keys, the addresses are case-insensitive
+*/
+func lowerKeys(values map[string]string) map[string]string {
+	ret := map[string]string{}
+
+	for key, value := range values {
+		ret[strings.ToLower(strings.TrimSpace(key))] = value
+	}
+
+	return ret
+}
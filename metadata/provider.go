@@ -0,0 +1,81 @@
+package metadata
+
+import (
+	"context"
+	"errors"
+	"solity/utils/logger"
+)
+
+// UnknownName is the name of the entities none of the providers know
+const UnknownName = "unknown"
+
+/*
+MetadataProvider looks up the metadata of operators and AVSs from a single source. Entities the source does not know
+are returned with Found false, errors mean that the source could not be asked
+*/
+type MetadataProvider interface {
+	/*
+		Name identifies the provider in the logs and in the Source of the entries
+	*/
+	Name() string
+	/*
+		Lookup returns the metadata of the given entity
+	*/
+	Lookup(ctx context.Context, kind Kind, address string) (Entry, error)
+}
+
+/*
+ProviderChain asks the providers in order and returns the first found entry
+*/
+type ProviderChain struct {
+	providers []MetadataProvider
+}
+
+/*
+NewProviderChain constructor for the ProviderChain object, nil providers are skipped
+*/
+func NewProviderChain(providers ...MetadataProvider) *ProviderChain {
+	chain := &ProviderChain{}
+
+	for _, provider := range providers {
+		if provider != nil {
+			chain.providers = append(chain.providers, provider)
+		}
+	}
+
+	return chain
+}
+
+func (pC *ProviderChain) Name() string {
+	return "chain"
+}
+
+/*
+Lookup returns the entry of the first provider that knows the entity. A failing provider does not stop the chain. If
+no provider knows the entity, the unknown entry is returned, unless a provider failed: then the entity may be known
+once the provider is back, so the errors are returned instead of caching it as unknown
+*/
+func (pC *ProviderChain) Lookup(ctx context.Context, kind Kind, address string) (Entry, error) {
+	var failures []error
+
+	for _, provider := range pC.providers {
+		entry, lErr := provider.Lookup(ctx, kind, address)
+
+		if lErr != nil {
+			logger.LogW("Metadata provider ", provider.Name(), " failed for the ", string(kind), " ", address, ": ", lErr)
+			failures = append(failures, errors.New(provider.Name()+": "+lErr.Error()))
+			continue
+		}
+
+		if entry.Found {
+			entry.Source = provider.Name()
+			return entry, nil
+		}
+	}
+
+	if len(failures) > 0 {
+		return Entry{}, errors.Join(failures...)
+	}
+
+	return Entry{Name: UnknownName, Found: false}, nil
+}
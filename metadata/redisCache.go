@@ -0,0 +1,69 @@
+package metadata
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/go-redis/redis/v8"
+	"time"
+)
+
+const redisKeyPrefix = "eigenlayer:metadata:"
+
+/*
+RedisCache is a Cache backed by redis, so that the looked up metadata is shared by every replica. The TTL is applied
+with the expiration of the redis keys
+*/
+type RedisCache struct {
+	client *redis.Client
+}
+
+/*
+NewRedisCache constructor for the RedisCache object, checks the connection before returning
+*/
+func NewRedisCache(addr string, password string) (*RedisCache, error) {
+	client := redis.NewClient(&redis.Options{Addr: addr, Password: password})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if pErr := client.Ping(ctx).Err(); pErr != nil {
+		_ = client.Close()
+		return nil, pErr
+	}
+
+	return &RedisCache{client: client}, nil
+}
+
+func (rC *RedisCache) Get(ctx context.Context, key string) (Entry, bool, error) {
+	value, gErr := rC.client.Get(ctx, redisKeyPrefix+key).Bytes()
+
+	if gErr == redis.Nil {
+		return Entry{}, false, nil
+	}
+
+	if gErr != nil {
+		return Entry{}, false, gErr
+	}
+
+	entry := Entry{}
+
+	if uErr := json.Unmarshal(value, &entry); uErr != nil {
+		return Entry{}, false, uErr
+	}
+
+	return entry, true, nil
+}
+
+func (rC *RedisCache) Set(ctx context.Context, key string, entry Entry, ttl time.Duration) error {
+	value, mErr := json.Marshal(entry)
+
+	if mErr != nil {
+		return mErr
+	}
+
+	return rC.client.Set(ctx, redisKeyPrefix+key, value, ttl).Err()
+}
+
+func (rC *RedisCache) Close() error {
+	return rC.client.Close()
+}
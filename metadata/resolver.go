@@ -0,0 +1,141 @@
+package metadata
+
+import (
+	"context"
+	"eigenlayer_hack/metrics"
+	"golang.org/x/sync/singleflight"
+	"solity/utils/logger"
+	"strings"
+	"time"
+)
+
+/*
+Kind is the kind of the entity the metadata belongs to
+*/
+type Kind string
+
+const (
+	KindOperator Kind = "operator"
+	KindAVS      Kind = "avs"
+)
+
+/*
+ResolverConfig holds the cache TTLs of the CachingResolver
+*/
+type ResolverConfig struct {
+	// TTL of the found entries per kind
+	TTLs map[Kind]time.Duration
+	// DefaultTTL is used for the kinds without a TTL
+	DefaultTTL time.Duration
+	// NegativeTTL of the entries that were not found, kept short so that new entities show up soon
+	NegativeTTL time.Duration
+}
+
+/*
+CachingResolver puts a cache in front of the metadata lookups. Concurrent lookups of the same entity are coalesced
+into a single call of the source
+*/
+type CachingResolver struct {
+	cache    Cache
+	provider MetadataProvider
+	config   ResolverConfig
+	group    singleflight.Group
+}
+
+/*
+NewCachingResolver constructor for the CachingResolver object
+*/
+func NewCachingResolver(cache Cache, provider MetadataProvider, config ResolverConfig) *CachingResolver {
+	if config.DefaultTTL <= 0 {
+		config.DefaultTTL = time.Hour
+	}
+
+	if config.NegativeTTL <= 0 {
+		config.NegativeTTL = 5 * time.Minute
+	}
+
+	return &CachingResolver{cache: cache, provider: provider, config: config}
+}
+
+/*
+cacheKey returns the cache key of the entity, the addresses are case-insensitive
+*/
+func cacheKey(kind Kind, address string) string {
+	return string(kind) + ":" + strings.ToLower(address)
+}
+
+/*
+Resolve returns the metadata of the given entity from the cache, or from the provider if it is not cached. Cache
+failures are logged and the provider is used, so an unavailable cache does not stop the processing
+*/
+func (r *CachingResolver) Resolve(ctx context.Context, kind Kind, address string) (Entry, error) {
+	key := cacheKey(kind, address)
+
+	entry, isCached, gErr := r.cache.Get(ctx, key)
+	if gErr != nil {
+		logger.LogW("Error while reading the metadata cache: ", gErr)
+	}
+
+	if isCached {
+		metrics.MetadataCacheHits.Add(string(kind), 1)
+		return entry, nil
+	}
+
+	metrics.MetadataCacheMisses.Add(string(kind), 1)
+
+	// Only the first caller runs the lookup, the others wait for its result
+	result := r.group.DoChan(key, func() (interface{}, error) {
+		// The lookup must not fail for the waiting callers when the first caller gives up
+		lookupCtx := context.WithoutCancel(ctx)
+
+		entry, lErr := r.provider.Lookup(lookupCtx, kind, address)
+		if lErr != nil {
+			return Entry{}, lErr
+		}
+
+		entry.FetchedAt = time.Now()
+
+		if sErr := r.cache.Set(lookupCtx, key, entry, r.ttl(kind, entry)); sErr != nil {
+			logger.LogW("Error while writing the metadata cache: ", sErr)
+		}
+
+		return entry, nil
+	})
+
+	select {
+	case <-ctx.Done():
+		return Entry{}, ctx.Err()
+	case res := <-result:
+		if res.Shared {
+			metrics.MetadataLookupsCoalesced.Add(1)
+		}
+
+		if res.Err != nil {
+			return Entry{}, res.Err
+		}
+
+		return res.Val.(Entry), nil
+	}
+}
+
+/*
+ttl returns the TTL of the entry
+*/
+func (r *CachingResolver) ttl(kind Kind, entry Entry) time.Duration {
+	if !entry.Found {
+		return r.config.NegativeTTL
+	}
+
+	if ttl, isOk := r.config.TTLs[kind]; isOk && ttl > 0 {
+		return ttl
+	}
+
+	return r.config.DefaultTTL
+}
+
+/*
+Close releases the cache
+*/
+func (r *CachingResolver) Close() error {
+	return r.cache.Close()
+}
@@ -0,0 +1,172 @@
+package metadata
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+/*
+fakeSource is a MetadataProvider answering the lookups with its entry, counting the calls. The lookups wait for release when it is set
+*/
+type fakeSource struct {
+	entry   Entry
+	err     error
+	release chan struct{}
+	calls   int32
+}
+
+func (f *fakeSource) Name() string { return "fake" }
+
+func (f *fakeSource) Lookup(ctx context.Context, kind Kind, address string) (Entry, error) {
+	atomic.AddInt32(&f.calls, 1)
+
+	if f.release != nil {
+		<-f.release
+	}
+
+	return f.entry, f.err
+}
+
+func (f *fakeSource) lookups() int {
+	return int(atomic.LoadInt32(&f.calls))
+}
+
+func TestCachingResolverCachesUntilTheTTL(t *testing.T) {
+	tests := []struct {
+		name  string
+		entry Entry
+	}{
+		{name: "found entries use the TTL of their kind", entry: Entry{Name: "EigenDA", Found: true}},
+		{name: "unknown entries use the negative TTL", entry: Entry{Found: false}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			source := &fakeSource{entry: test.entry}
+			resolver := NewCachingResolver(NewMemoryCache(), source, ResolverConfig{
+				TTLs:        map[Kind]time.Duration{KindAVS: 50 * time.Millisecond},
+				DefaultTTL:  time.Hour,
+				NegativeTTL: 50 * time.Millisecond,
+			})
+			ctx := context.Background()
+
+			for _, address := range []string{"0xAbC", "0xabc"} {
+				entry, rErr := resolver.Resolve(ctx, KindAVS, address)
+				if rErr != nil || entry.Name != test.entry.Name || entry.Found != test.entry.Found {
+					t.Fatalf("unexpected entry %+v (%v)", entry, rErr)
+				}
+			}
+
+			// The addresses are case-insensitive, the second call is served by the cache
+			if source.lookups() != 1 {
+				t.Fatalf("expected a single lookup, got %d", source.lookups())
+			}
+
+			time.Sleep(100 * time.Millisecond)
+
+			if _, rErr := resolver.Resolve(ctx, KindAVS, "0xabc"); rErr != nil {
+				t.Fatal(rErr)
+			}
+
+			if source.lookups() != 2 {
+				t.Fatalf("expected the expired entry to be looked up again, got %d lookups", source.lookups())
+			}
+
+			// Other kinds fall back to the default TTL
+			if ttl := resolver.ttl(KindOperator, Entry{Found: true}); ttl != time.Hour {
+				t.Fatalf("expected the default TTL, got %s", ttl)
+			}
+		})
+	}
+}
+
+func TestCachingResolverDoesNotCacheTheFailures(t *testing.T) {
+	source := &fakeSource{err: errors.New("dune: status 500")}
+	resolver := NewCachingResolver(NewMemoryCache(), source, ResolverConfig{})
+
+	for attempt := 0; attempt < 2; attempt++ {
+		if _, rErr := resolver.Resolve(context.Background(), KindOperator, "0x01"); rErr == nil {
+			t.Fatal("expected the lookup error")
+		}
+	}
+
+	if source.lookups() != 2 {
+		t.Fatalf("expected every call to reach the source, got %d lookups", source.lookups())
+	}
+}
+
+func TestCachingResolverCoalescesConcurrentLookups(t *testing.T) {
+	source := &fakeSource{entry: Entry{Name: "P2P.org", Found: true}, release: make(chan struct{})}
+	resolver := NewCachingResolver(NewMemoryCache(), source, ResolverConfig{})
+
+	var wg sync.WaitGroup
+	names := make(chan string, 10)
+
+	for caller := 0; caller < 10; caller++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			entry, rErr := resolver.Resolve(context.Background(), KindOperator, "0x01")
+			if rErr != nil {
+				t.Error(rErr)
+			}
+
+			names <- entry.Name
+		}()
+	}
+
+	// Give the callers the time to join the running lookup
+	time.Sleep(50 * time.Millisecond)
+	close(source.release)
+	wg.Wait()
+	close(names)
+
+	for name := range names {
+		if name != "P2P.org" {
+			t.Fatalf("expected every caller to get the entry, got %q", name)
+		}
+	}
+
+	if source.lookups() != 1 {
+		t.Fatalf("expected a single lookup, got %d", source.lookups())
+	}
+}
+
+func TestCachingResolverKeepsTheLookupOfAnAbandonedCaller(t *testing.T) {
+	source := &fakeSource{entry: Entry{Name: "EigenDA", Found: true}, release: make(chan struct{})}
+	resolver := NewCachingResolver(NewMemoryCache(), source, ResolverConfig{})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if _, rErr := resolver.Resolve(ctx, KindAVS, "0x01"); !errors.Is(rErr, context.DeadlineExceeded) {
+		t.Fatalf("expected the caller to give up, got %v", rErr)
+	}
+
+	close(source.release)
+
+	// The lookup completes in the background and fills the cache
+	deadline := time.Now().Add(time.Second)
+
+	for {
+		if _, isCached, _ := resolver.cache.Get(context.Background(), cacheKey(KindAVS, "0x01")); isCached {
+			break
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatal("expected the abandoned lookup to be cached")
+		}
+
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if source.lookups() != 1 {
+		t.Fatalf("expected a single lookup, got %d", source.lookups())
+	}
+}
@@ -0,0 +1,121 @@
+package metrics
+
+import (
+	"expvar"
+	"net/http"
+	"solity/utils/logger"
+)
+
+// Pipeline metrics, exported through expvar under /debug/vars
+var (
+	// WorkerQueueDepth is the total number of messages waiting in the worker queues
+	WorkerQueueDepth = expvar.NewInt("worker_queue_depth")
+	// WorkerQueueDepthPerWorker is the number of waiting messages per worker queue
+	WorkerQueueDepthPerWorker = expvar.NewMap("worker_queue_depth_per_worker")
+	// PartitionPauses is the number of times the consumption was paused because of a full worker queue
+	PartitionPauses = expvar.NewInt("partition_pauses")
+	// ProcessedMessages is the number of messages that completed the processing
+	ProcessedMessages = expvar.NewInt("processed_messages")
+	// DeadLetteredMessages is the number of messages forwarded to the dead-letter channels
+	DeadLetteredMessages = expvar.NewInt("dead_lettered_messages")
+	// ReorgsDetected is the number of detected chain reorganizations affecting the processed events
+	ReorgsDetected = expvar.NewInt("reorgs_detected")
+	// Retractions is the number of published retraction messages
+	Retractions = expvar.NewInt("retractions")
+	// MetadataCacheHits is the number of metadata lookups served by the cache per entity kind
+	MetadataCacheHits = expvar.NewMap("metadata_cache_hits")
+	// MetadataCacheMisses is the number of metadata lookups that missed the cache per entity kind
+	MetadataCacheMisses = expvar.NewMap("metadata_cache_misses")
+	// MetadataLookupsCoalesced is the number of lookups that shared the result of a concurrent identical lookup
+	MetadataLookupsCoalesced = expvar.NewInt("metadata_lookups_coalesced")
+	// DeferredQueueSize is the number of events waiting for their enrichment
+	DeferredQueueSize = expvar.NewInt("deferred_queue_size")
+	// DeferredResolved is the number of deferred events whose enrichment has been completed
+	DeferredResolved = expvar.NewInt("deferred_resolved")
+	// DeferredExpired is the number of deferred events dropped after the max age
+	DeferredExpired = expvar.NewInt("deferred_expired")
+	// ScoredEntities is the number of AVSs and operators in the popularity rankings
+	ScoredEntities = expvar.NewInt("scored_entities")
+	// ScoreChanges is the number of ranking moves found by the popularity scoring
+	ScoreChanges = expvar.NewInt("score_changes")
+	// TxSubmitted is the number of transactions accepted by the node, rebroadcasts not included
+	TxSubmitted = expvar.NewInt("tx_submitted")
+	// TxConfirmed is the number of transactions confirmed with a successful receipt
+	TxConfirmed = expvar.NewInt("tx_confirmed")
+	// TxFailed is the number of transactions that could not be sent, reverted, were replaced or got stuck
+	TxFailed = expvar.NewInt("tx_failed")
+	// TxRebroadcasts is the number of transactions sent again with a bumped fee
+	TxRebroadcasts = expvar.NewInt("tx_rebroadcasts")
+	// TxPending is the number of transactions waiting for their confirmations
+	TxPending = expvar.NewInt("tx_pending")
+	// RegistryBatches is the number of batches of registry writes
+	RegistryBatches = expvar.NewInt("registry_batches")
+	// RegistryIndexedEntries is the number of registry entries at the last reconciled block
+	RegistryIndexedEntries = expvar.NewInt("registry_indexed_entries")
+	// RegistryMissingWrites is the number of confirmed writes without a registry entry at the last reconciliation
+	RegistryMissingWrites = expvar.NewInt("registry_missing_writes")
+	// RegistryDuplicateEntries is the number of pairs with more than one registry entry at the last reconciliation
+	RegistryDuplicateEntries = expvar.NewInt("registry_duplicate_entries")
+	// RegistryMismatchedWrites is the number of writes whose entry differs at the last reconciliation
+	RegistryMismatchedWrites = expvar.NewInt("registry_mismatched_writes")
+	// RegistryResubmittedWrites is the number of missing writes sent again by the reconciliation
+	RegistryResubmittedWrites = expvar.NewInt("registry_resubmitted_writes")
+)
+
+// mux of the metrics server, other packages can add their endpoints with Handle
+var mux = http.NewServeMux()
+
+func init() {
+	mux.Handle("/debug/vars", expvar.Handler())
+	mux.Handle("/metrics", expvar.Handler())
+
+	// Ratio of the cache hits to all the metadata lookups
+	expvar.Publish("metadata_cache_hit_rate", expvar.Func(func() interface{} {
+		hits, misses := sumMap(MetadataCacheHits), sumMap(MetadataCacheMisses)
+
+		if hits+misses == 0 {
+			return 0.0
+		}
+
+		return float64(hits) / float64(hits+misses)
+	}))
+}
+
+/*
+sumMap returns the sum of the integer values of the map
+*/
+func sumMap(values *expvar.Map) int64 {
+	sum := int64(0)
+
+	values.Do(func(kv expvar.KeyValue) {
+		if value, isInt := kv.Value.(*expvar.Int); isInt {
+			sum += value.Value()
+		}
+	})
+
+	return sum
+}
+
+/*
+Handle adds an endpoint to the metrics server
+*/
+func Handle(pattern string, handler http.Handler) {
+	mux.Handle(pattern, handler)
+}
+
+/*
+Serve starts the metrics http server on the given address in a separate goroutine, does nothing if the address is empty
+*/
+func Serve(addr string) {
+	if addr == "" {
+		return
+	}
+
+	go func() {
+		logger.LogI("Serving metrics on ", addr)
+
+		if err := http.ListenAndServe(addr, mux); err != nil {
+			logger.LogW("Metrics server stopped: ", err)
+		}
+	}()
+}
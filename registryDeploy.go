@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"eigenlayer_hack/config"
+	registery "eigenlayer_hack/eigenlayerContract"
+	"eigenlayer_hack/ethereum/node"
+	"eigenlayer_hack/utils"
+	"flag"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"math/big"
+	"os"
+	"solity/utils/logger"
+	"time"
+)
+
+/*
+runRegistryDeploy deploys the registry contract with the configured signer to the chain of REGISTRY_RPC and records its
+address and deployment block in the config file. An already deployed registry is kept unless -force is given
+*/
+func runRegistryDeploy(cfg config.Config, args []string) {
+	configFile := cfg.File
+	if configFile == "" {
+		configFile = utils.DefaultConfigFile
+	}
+
+	flags := flag.NewFlagSet("deploy", flag.ExitOnError)
+	file := flags.String("config", configFile, "config file to record the registry address and deployment block in")
+	force := flags.Bool("force", false, "deploy even when the configured registry is already deployed")
+	timeout := flags.Duration("timeout", 5*time.Minute, "longest wait for the deployment to be mined")
+	_ = flags.Parse(args)
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	client, dErr := utils.DialChain(cfg.Registry.RPC, cfg.Registry.ChainID)
+	if dErr != nil {
+		logger.LogE("Error while connecting to the registry RPC: ", dErr)
+	}
+	defer client.Close()
+
+	current := common.HexToAddress(cfg.Registry.Address)
+	if current != (common.Address{}) && !*force {
+		code, cErr := client.CodeAt(ctx, current, nil)
+		if cErr != nil {
+			logger.LogE("Error while checking the configured registry: ", cErr)
+		}
+
+		if len(code) > 0 {
+			logger.LogE("The registry is already deployed at ", current.Hex(), ", use -force to deploy a new one")
+		}
+	}
+
+	signer, sErr := utils.NewSignerFromConfig(cfg.Signer, cfg.Registry.PrivateKey)
+	if sErr != nil {
+		logger.LogE("Error while creating the signer: ", sErr)
+	}
+	defer utils.CloseSigner(signer)
+
+	chainID, cErr := client.ChainID(ctx)
+	if cErr != nil {
+		logger.LogE("Error while reading the chain id: ", cErr)
+	}
+
+	txOptions, oErr := deployOptions(ctx, cfg.Tx, client, signer, chainID)
+	if oErr != nil {
+		logger.LogE("Error while preparing the deployment: ", oErr)
+	}
+
+	logger.LogI("Deploying the registry from ", signer.Address().Hex(), " on chain ", chainID)
+
+	address, tx, _, deployErr := registery.DeployRegistery(txOptions, client)
+	if deployErr != nil {
+		logger.LogE("Error while deploying the registry: ", deployErr)
+	}
+
+	logger.LogI("Deployment sent in ", tx.Hash().Hex(), ", waiting for it to be mined")
+
+	receipt, wErr := bind.WaitMined(ctx, client, tx)
+	if wErr != nil {
+		logger.LogE("Error while waiting for the deployment ", tx.Hash().Hex(), ": ", wErr)
+	}
+
+	if receipt.Status != 1 || receipt.ContractAddress != address {
+		logger.LogE("The deployment ", tx.Hash().Hex(), " failed")
+	}
+
+	logger.LogI("Registry deployed at ", address.Hex(), " in block ", receipt.BlockNumber)
+
+	if fErr := config.SetFileValues(*file, map[string]string{
+		"registry.address":         address.Hex(),
+		"registry.deploymentBlock": receipt.BlockNumber.String(),
+	}); fErr != nil {
+		logger.LogE("Error while recording the registry in ", *file, ", record it by hand: ", fErr)
+	}
+
+	logger.LogI("Recorded the registry in ", *file)
+
+	// The environment is applied over the file
+	for _, key := range []string{"REGISTRY_ADDRESS", "REGISTRY_DEPLOYMENT_BLOCK"} {
+		if _, isSet := os.LookupEnv(key); isSet {
+			logger.LogW(key, " is set in the environment and overrides the recorded value, unset it")
+		}
+	}
+}
+
+/*
+deployOptions returns the transaction options of the deployment, priced with the fee settings of the transactions and
+with the estimated gas raised by their gas margin
+*/
+func deployOptions(ctx context.Context, tx config.TxConfig, client *ethclient.Client, signer node.Signer,
+	chainID *big.Int) (*bind.TransactOpts, error) {
+	nonce, nErr := client.PendingNonceAt(ctx, signer.Address())
+	if nErr != nil {
+		return nil, nErr
+	}
+
+	fees, fErr := node.NewFeeOracle(client, utils.FeeOracleConfig(tx)).Fees(ctx)
+	if fErr != nil {
+		return nil, fErr
+	}
+
+	gasLimit, eErr := node.EstimateGasLimit(ctx, client, ethereum.CallMsg{
+		From: signer.Address(),
+		Data: hexutil.MustDecode(registery.RegisteryMetaData.Bin),
+	}, tx.GasMarginPercent)
+	if eErr != nil {
+		return nil, eErr
+	}
+
+	logger.LogI("Deployment fees: ", fees, ", gas limit ", gasLimit)
+
+	txOptions := node.TransactOpts(ctx, signer, chainID)
+	txOptions.Nonce = new(big.Int).SetUint64(nonce)
+	txOptions.GasLimit = gasLimit
+
+	if fees.IsDynamic {
+		txOptions.GasTipCap = fees.GasTipCap
+		txOptions.GasFeeCap = fees.GasFeeCap
+	} else {
+		txOptions.GasPrice = fees.GasPrice
+	}
+
+	return txOptions, nil
+}
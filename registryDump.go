@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"eigenlayer_hack/config"
+	"eigenlayer_hack/utils"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"io"
+	"math/big"
+	"os"
+	"solity/utils/logger"
+	"strconv"
+	"time"
+)
+
+/*
+runRegistryDump exports the entries of the registry at REGISTRY_ADDRESS as JSON or CSV. The entries are read at a
+single block, so the export is consistent while the tracker keeps writing
+*/
+func runRegistryDump(cfg config.Config, args []string) {
+	flags := flag.NewFlagSet("dump", flag.ExitOnError)
+	format := flags.String("format", "json", "export format: json or csv")
+	out := flags.String("out", "", "file to write the export to (empty is stdout)")
+	from := flags.Uint64("from", 0, "first entry id")
+	to := flags.Int64("to", -1, "last entry id (-1 is the last entry)")
+	block := flags.Uint64("block", 0, "block to read the entries at (0 is the latest)")
+	timeout := flags.Duration("timeout", 10*time.Minute, "longest time of the export")
+	_ = flags.Parse(args)
+
+	if *format != "json" && *format != "csv" {
+		logger.LogE("-format has to be json or csv")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	reader, rErr := utils.NewRegistryReaderFromConfig(cfg.Registry)
+	if rErr != nil {
+		logger.LogE("Error while connecting to the registry: ", rErr)
+	}
+	defer reader.Close()
+
+	if *block == 0 {
+		head, hErr := reader.Head(ctx)
+		if hErr != nil {
+			logger.LogE("Error while reading the latest block: ", hErr)
+		}
+
+		*block = head
+	}
+
+	atBlock := new(big.Int).SetUint64(*block)
+
+	count, cErr := reader.Count(ctx, atBlock)
+	if cErr != nil {
+		logger.LogE("Error while reading the registry: ", cErr)
+	}
+
+	end := count
+	if *to >= 0 && uint64(*to)+1 < end {
+		end = uint64(*to) + 1
+	}
+
+	var output io.Writer = os.Stdout
+
+	if *out != "" {
+		file, fErr := os.Create(*out)
+		if fErr != nil {
+			logger.LogE("Error while creating the export file: ", fErr)
+		}
+		defer file.Close()
+
+		output = file
+	}
+
+	var entries []utils.RegistryEntry
+
+	readErr := reader.Entries(ctx, atBlock, *from, end, func(entry utils.RegistryEntry) error {
+		entries = append(entries, entry)
+		return nil
+	})
+	if readErr != nil {
+		logger.LogE("Error while reading the entries: ", readErr)
+	}
+
+	var wErr error
+
+	if *format == "csv" {
+		wErr = writeEntriesCSV(output, entries)
+	} else {
+		encoder := json.NewEncoder(output)
+		encoder.SetIndent("", "  ")
+
+		if entries == nil {
+			entries = []utils.RegistryEntry{}
+		}
+
+		wErr = encoder.Encode(entries)
+	}
+
+	if wErr != nil {
+		logger.LogE("Error while writing the export: ", wErr)
+	}
+
+	if *out != "" {
+		logger.LogI("Exported ", len(entries), " entries of block ", *block, " to ", *out)
+	}
+}
+
+/*
+writeEntriesCSV writes the entries with a header row, the timestamps in RFC 3339
+*/
+func writeEntriesCSV(output io.Writer, entries []utils.RegistryEntry) error {
+	writer := csv.NewWriter(output)
+
+	if wErr := writer.Write([]string{"id", "avsName", "operatorName", "avsAddress", "operatorAddress", "timestamp"}); wErr != nil {
+		return wErr
+	}
+
+	for _, entry := range entries {
+		row := []string{
+			strconv.FormatUint(entry.ID, 10),
+			entry.AvsName,
+			entry.OperatorName,
+			entry.AvsAddress,
+			entry.OperatorAddress,
+			entry.Timestamp.Format(time.RFC3339),
+		}
+
+		if wErr := writer.Write(row); wErr != nil {
+			return wErr
+		}
+	}
+
+	writer.Flush()
+
+	return writer.Error()
+}
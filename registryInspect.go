@@ -0,0 +1,178 @@
+package main
+
+import (
+	"context"
+	"eigenlayer_hack/config"
+	"eigenlayer_hack/utils"
+	"encoding/json"
+	"flag"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"math/big"
+	"os"
+	"solity/utils/logger"
+	"time"
+)
+
+/*
+registryInfo is the state of the registry printed by inspect, the optional parts are set by their flags
+*/
+type registryInfo struct {
+	Address           string                `json:"address"`
+	ChainID           string                `json:"chainId"`
+	Block             uint64                `json:"block"`
+	DeploymentBlock   uint64                `json:"deploymentBlock"`
+	Owner             string                `json:"owner"`
+	EventCount        uint64                `json:"eventCount"`
+	Writer            *registryWriterInfo   `json:"writer,omitempty"`
+	Entry             *utils.RegistryEntry  `json:"entry,omitempty"`
+	EntriesOfAVS      []utils.RegistryEntry `json:"entriesOfAvs,omitempty"`
+	EntriesOfOperator []utils.RegistryEntry `json:"entriesOfOperator,omitempty"`
+	IsRegistered      *bool                 `json:"isRegistered,omitempty"`
+}
+
+type registryWriterInfo struct {
+	Address  string `json:"address"`
+	IsWriter bool   `json:"isWriter"`
+}
+
+/*
+runRegistryInspect prints the state of the registry at REGISTRY_ADDRESS as JSON: its owner and number of entries, and
+on request whether an account is a writer, an entry by id and the entries of an AVS or operator. With both -avs and
+-operator it tells whether the pair is registered
+*/
+func runRegistryInspect(cfg config.Config, args []string) {
+	flags := flag.NewFlagSet("inspect", flag.ExitOnError)
+	writer := flags.String("writer", "", "account to check the writer allowlist for")
+	id := flags.Int64("id", -1, "id of the entry to print")
+	avs := flags.String("avs", "", "AVS address to print the entries of")
+	operator := flags.String("operator", "", "operator address to print the entries of")
+	block := flags.Uint64("block", 0, "block to read the state at (0 is the latest)")
+	timeout := flags.Duration("timeout", time.Minute, "longest time of the reads")
+	_ = flags.Parse(args)
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	reader, rErr := utils.NewRegistryReaderFromConfig(cfg.Registry)
+	if rErr != nil {
+		logger.LogE("Error while connecting to the registry: ", rErr)
+	}
+	defer reader.Close()
+
+	info := registryInfo{Address: reader.Address().Hex(), Block: *block, DeploymentBlock: cfg.Registry.DeploymentBlock}
+
+	if info.Block == 0 {
+		head, hErr := reader.Head(ctx)
+		if hErr != nil {
+			logger.LogE("Error while reading the latest block: ", hErr)
+		}
+
+		info.Block = head
+	}
+
+	chainID, cErr := reader.Client().ChainID(ctx)
+	if cErr != nil {
+		logger.LogE("Error while reading the chain id: ", cErr)
+	}
+
+	info.ChainID = chainID.String()
+
+	atBlock := new(big.Int).SetUint64(info.Block)
+	opts := &bind.CallOpts{Context: ctx, BlockNumber: atBlock}
+
+	count, countErr := reader.Count(ctx, atBlock)
+	if countErr != nil {
+		logger.LogE("Error while reading the registry: ", countErr)
+	}
+
+	info.EventCount = count
+
+	owner, oErr := reader.Contract().Owner(opts)
+	if oErr != nil {
+		logger.LogE("Error while reading the registry owner: ", oErr)
+	}
+
+	info.Owner = owner.Hex()
+
+	if *writer != "" {
+		isWriter, wErr := reader.Contract().Writers(opts, parseAddress("-writer", *writer))
+		if wErr != nil {
+			logger.LogE("Error while reading the writer allowlist: ", wErr)
+		}
+
+		info.Writer = &registryWriterInfo{Address: common.HexToAddress(*writer).Hex(), IsWriter: isWriter}
+	}
+
+	if *id >= 0 {
+		entry, eErr := reader.Entry(ctx, atBlock, uint64(*id))
+		if eErr != nil {
+			logger.LogE("Error while reading the entry: ", eErr)
+		}
+
+		info.Entry = &entry
+	}
+
+	if *avs != "" {
+		ids, iErr := reader.Contract().EventsByAVS(opts, parseAddress("-avs", *avs))
+		if iErr != nil {
+			logger.LogE("Error while reading the entries of the AVS: ", iErr)
+		}
+
+		info.EntriesOfAVS = readEntries(ctx, reader, atBlock, ids)
+	}
+
+	if *operator != "" {
+		ids, iErr := reader.Contract().EventsByOperator(opts, parseAddress("-operator", *operator))
+		if iErr != nil {
+			logger.LogE("Error while reading the entries of the operator: ", iErr)
+		}
+
+		info.EntriesOfOperator = readEntries(ctx, reader, atBlock, ids)
+	}
+
+	if *avs != "" && *operator != "" {
+		isRegistered, iErr := reader.Contract().IsRegistered(opts, common.HexToAddress(*avs), common.HexToAddress(*operator))
+		if iErr != nil {
+			logger.LogE("Error while reading the registration of the pair: ", iErr)
+		}
+
+		info.IsRegistered = &isRegistered
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+
+	if eErr := encoder.Encode(info); eErr != nil {
+		logger.LogE("Error while printing the registry: ", eErr)
+	}
+}
+
+/*
+readEntries reads the entries with the ids, aborting on the first failed read
+*/
+func readEntries(ctx context.Context, reader *utils.RegistryReader, block *big.Int, ids []*big.Int) []utils.RegistryEntry {
+	entries := make([]utils.RegistryEntry, 0, len(ids))
+
+	for _, id := range ids {
+		entry, eErr := reader.Entry(ctx, block, id.Uint64())
+		if eErr != nil {
+			logger.LogE("Error while reading the entries: ", eErr)
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries
+}
+
+/*
+parseAddress returns the address given to the flag, aborting on an invalid one
+*/
+func parseAddress(flagName string, address string) common.Address {
+	if !common.IsHexAddress(address) {
+		logger.LogE(flagName, " has to be an address, got ", address)
+	}
+
+	return common.HexToAddress(address)
+}
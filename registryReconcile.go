@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"eigenlayer_hack/config"
+	"eigenlayer_hack/registryindex"
+	"eigenlayer_hack/utils"
+	"encoding/json"
+	"flag"
+	"os"
+	"solity/utils/logger"
+	"time"
+)
+
+/*
+runRegistryReconcile syncs the registry index and prints the reconciliation of the write journal with the registry
+as JSON. With -resubmit the missing writes are sent again. The index is locked by a running tracker, which reconciles
+every REGISTRY_RECONCILE_INTERVAL and serves its last report at /registry/reconciliation
+*/
+func runRegistryReconcile(cfg config.Config, args []string) {
+	flags := flag.NewFlagSet("reconcile", flag.ExitOnError)
+	file := flags.String("file", cfg.Index.File, "path of the registry index")
+	resubmit := flags.Bool("resubmit", false, "send the missing writes again")
+	timeout := flags.Duration("timeout", 30*time.Minute, "longest time of the sync and the resubmissions")
+	_ = flags.Parse(args)
+
+	if *file == "" {
+		logger.LogE("The registry index is disabled, give its file with -file")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	store, oErr := registryindex.Open(*file, 5*time.Second)
+	if oErr != nil {
+		logger.LogE("Error while opening the registry index, while the tracker runs use its "+
+			"/registry/reconciliation endpoint: ", oErr)
+	}
+	defer store.Close()
+
+	reader, rErr := utils.NewRegistryReaderFromConfig(cfg.Registry)
+	if rErr != nil {
+		logger.LogE("Error while connecting to the registry: ", rErr)
+	}
+	defer reader.Close()
+
+	var writer *utils.RegistryWriter
+
+	if *resubmit {
+		var wErr error
+
+		writer, wErr = utils.NewRegistryWriterFromConfig(cfg)
+		if wErr != nil {
+			logger.LogE("Error while initializing the registry writer: ", wErr)
+		}
+		defer writer.Close()
+	}
+
+	report, cErr := registryindex.NewReconciler(newIndexer(cfg, reader, store), store, writer).Check(ctx)
+	if cErr != nil {
+		logger.LogE("Error while reconciling the registry: ", cErr)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+
+	if eErr := encoder.Encode(report); eErr != nil {
+		logger.LogE("Error while printing the reconciliation: ", eErr)
+	}
+}
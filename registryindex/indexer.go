@@ -0,0 +1,170 @@
+package registryindex
+
+import (
+	"context"
+	"eigenlayer_hack/ingest"
+	"eigenlayer_hack/utils"
+	"fmt"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"math/big"
+	"solity/utils/logger"
+	"time"
+)
+
+/*
+Config holds the settings of the Indexer. The logs are read from DeploymentBlock on in ranges of LogRange blocks, up
+to Confirmations blocks behind the head, so that the index does not follow reorganizations of the latest blocks
+*/
+type Config struct {
+	DeploymentBlock uint64
+	LogRange        uint64
+	Confirmations   uint64
+}
+
+/*
+Indexer reads the entries of the registry into the Store. The entries come from the EventRegistered logs, which carry
+their transaction, and the contract state is checked against them: entries without a log are read from the state and
+entries that the state no longer has are deleted
+*/
+type Indexer struct {
+	reader *utils.RegistryReader
+	store  *Store
+	config Config
+}
+
+/*
+NewIndexer creates the indexer of the registry read by reader, a zero LogRange reads 2000 blocks per call
+*/
+func NewIndexer(reader *utils.RegistryReader, store *Store, config Config) *Indexer {
+	if config.LogRange == 0 {
+		config.LogRange = 2000
+	}
+
+	return &Indexer{reader: reader, store: store, config: config}
+}
+
+/*
+Sync reads the new logs and checks the index against the contract state at the last confirmed block
+*/
+func (i *Indexer) Sync(ctx context.Context) (Synced, error) {
+	head, hErr := i.reader.Head(ctx)
+	if hErr != nil {
+		return Synced{}, hErr
+	}
+
+	if head < i.config.Confirmations {
+		return Synced{}, fmt.Errorf("the chain has no confirmed block yet (head %d)", head)
+	}
+
+	target := head - i.config.Confirmations
+
+	from, isStarted, nErr := i.store.NextBlock()
+	if nErr != nil {
+		return Synced{}, nErr
+	}
+
+	if !isStarted {
+		from = i.config.DeploymentBlock
+	}
+
+	logRange := i.config.LogRange
+
+	for from <= target {
+		to := min(from+logRange-1, target)
+
+		entries, fErr := i.readLogs(ctx, from, to)
+
+		// Nodes limit the results of a call, smaller ranges are tried down to a single block. Other errors, e.g. an
+		// unreachable node, are not retried here
+		if fErr != nil && ingest.IsTooManyResultsError(fErr) && logRange > 1 {
+			logRange /= 2
+			continue
+		}
+
+		if fErr != nil {
+			return Synced{}, fErr
+		}
+
+		if pErr := i.store.PutEntries(entries, to+1); pErr != nil {
+			return Synced{}, pErr
+		}
+
+		from = to + 1
+
+		// The limit holds for a crowded span of blocks only, the range grows back after it
+		logRange = min(logRange*2, i.config.LogRange)
+	}
+
+	atBlock := new(big.Int).SetUint64(target)
+
+	count, cErr := i.reader.Count(ctx, atBlock)
+	if cErr != nil {
+		return Synced{}, cErr
+	}
+
+	missing, mErr := i.store.MissingIDs(count)
+	if mErr != nil {
+		return Synced{}, mErr
+	}
+
+	if len(missing) > 0 {
+		logger.LogW(len(missing), " registry entries have no EventRegistered log, reading them from the contract")
+
+		entries := make([]Entry, 0, len(missing))
+
+		for _, id := range missing {
+			entry, eErr := i.reader.Entry(ctx, atBlock, id)
+			if eErr != nil {
+				return Synced{}, eErr
+			}
+
+			entries = append(entries, Entry{RegistryEntry: entry})
+		}
+
+		if pErr := i.store.PutEntries(entries, target+1); pErr != nil {
+			return Synced{}, pErr
+		}
+	}
+
+	synced := Synced{Block: target, Count: count, SyncedAt: time.Now().UTC()}
+
+	return synced, i.store.SetSynced(synced)
+}
+
+/*
+readLogs returns the entries of the EventRegistered logs of the blocks from - to (both inclusive)
+*/
+func (i *Indexer) readLogs(ctx context.Context, from uint64, to uint64) ([]Entry, error) {
+	iterator, fErr := i.reader.Contract().FilterEventRegistered(&bind.FilterOpts{Start: from, End: &to, Context: ctx},
+		nil, nil)
+	if fErr != nil {
+		return nil, fmt.Errorf("reading the registry logs of the blocks %d-%d: %w", from, to, fErr)
+	}
+	defer iterator.Close()
+
+	var entries []Entry
+
+	for iterator.Next() {
+		event := iterator.Event
+
+		entries = append(entries, Entry{
+			RegistryEntry: utils.RegistryEntry{
+				ID:              event.Id.Uint64(),
+				AvsName:         event.AvsName,
+				OperatorName:    event.OperatorName,
+				AvsAddress:      event.AvsAddress.Hex(),
+				OperatorAddress: event.OperatorAddress.Hex(),
+				Timestamp:       time.Unix(event.Timestamp.Int64(), 0).UTC(),
+			},
+			TxHash:      event.Raw.TxHash.Hex(),
+			BlockNumber: event.Raw.BlockNumber,
+			LogIndex:    event.Raw.Index,
+		})
+	}
+
+	if iErr := iterator.Error(); iErr != nil {
+		return nil, fmt.Errorf("reading the registry logs of the blocks %d-%d: %w", from, to, iErr)
+	}
+
+	return entries, nil
+}
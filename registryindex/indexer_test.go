@@ -0,0 +1,349 @@
+package registryindex
+
+import (
+	"context"
+	registery "eigenlayer_hack/eigenlayerContract"
+	"eigenlayer_hack/utils"
+	"errors"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient/simulated"
+	"math/big"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+/*
+testChain is a simulated chain with the registry deployed by owner at deploymentBlock
+*/
+type testChain struct {
+	sim             *simulated.Backend
+	registry        *registery.Registery
+	address         common.Address
+	owner           *bind.TransactOpts
+	deploymentBlock uint64
+}
+
+func newTestChain(t *testing.T) *testChain {
+	t.Helper()
+
+	key, _ := crypto.GenerateKey()
+
+	sim := simulated.NewBackend(types.GenesisAlloc{
+		crypto.PubkeyToAddress(key.PublicKey): {Balance: new(big.Int).Mul(big.NewInt(1000), big.NewInt(1e18))},
+	})
+	t.Cleanup(func() { _ = sim.Close() })
+
+	// The calls on the genesis block run without the Shanghai opcodes the registry is compiled with (PUSH0)
+	sim.Commit()
+
+	chainID, cErr := sim.Client().ChainID(context.Background())
+	if cErr != nil {
+		t.Fatal(cErr)
+	}
+
+	owner, oErr := bind.NewKeyedTransactorWithChainID(key, chainID)
+	if oErr != nil {
+		t.Fatal(oErr)
+	}
+
+	address, tx, registry, dErr := registery.DeployRegistery(owner, sim.Client())
+	if dErr != nil {
+		t.Fatal(dErr)
+	}
+
+	chain := &testChain{sim: sim, registry: registry, address: address, owner: owner}
+	chain.deploymentBlock = chain.mined(t, tx)
+
+	return chain
+}
+
+/*
+mined commits a block with the transaction and returns its number, the transaction must be successful
+*/
+func (tC *testChain) mined(t *testing.T, tx *types.Transaction) uint64 {
+	t.Helper()
+
+	tC.sim.Commit()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	receipt, wErr := bind.WaitMined(ctx, tC.sim.Client(), tx)
+	if wErr != nil {
+		t.Fatal(wErr)
+	}
+
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		t.Fatalf("transaction %s reverted", tx.Hash().Hex())
+	}
+
+	return receipt.BlockNumber.Uint64()
+}
+
+/*
+register writes an entry in its own block and returns the block number
+*/
+func (tC *testChain) register(t *testing.T, index int64) uint64 {
+	t.Helper()
+
+	tx, rErr := tC.registry.RegisterEvent(tC.owner, "avs", "operator", common.BigToAddress(big.NewInt(0xa0+index)),
+		common.BigToAddress(big.NewInt(0xb0+index)))
+	if rErr != nil {
+		t.Fatal(rErr)
+	}
+
+	return tC.mined(t, tx)
+}
+
+/*
+newIndexer opens a store and the indexer of the chain's registry, read through backend
+*/
+func (tC *testChain) newIndexer(t *testing.T, backend utils.RegistryBackend, config Config) (*Indexer, *Store) {
+	t.Helper()
+
+	reader, rErr := utils.NewRegistryReader(backend, tC.address)
+	if rErr != nil {
+		t.Fatal(rErr)
+	}
+
+	store, oErr := Open(filepath.Join(t.TempDir(), "registry.db"), time.Second)
+	if oErr != nil {
+		t.Fatal(oErr)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+
+	return NewIndexer(reader, store, config), store
+}
+
+/*
+limitedBackend refuses the log queries over maxRange blocks like the nodes that limit the results of a call, and fails
+every log query with err when it is set. With crowdedUntil set, only the queries from the blocks up to it are limited
+*/
+type limitedBackend struct {
+	simulated.Client
+	maxRange     uint64
+	crowdedUntil uint64
+	err          error
+
+	mu sync.Mutex
+	// ranges are the queried block ranges
+	ranges [][2]uint64
+}
+
+func (lB *limitedBackend) FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+	from, to := query.FromBlock.Uint64(), query.ToBlock.Uint64()
+
+	lB.mu.Lock()
+	lB.ranges = append(lB.ranges, [2]uint64{from, to})
+	lB.mu.Unlock()
+
+	if lB.err != nil {
+		return nil, lB.err
+	}
+
+	if to-from+1 > lB.maxRange && (lB.crowdedUntil == 0 || from <= lB.crowdedUntil) {
+		return nil, errors.New("query returned more than 10000 results")
+	}
+
+	return lB.Client.FilterLogs(ctx, query)
+}
+
+func (lB *limitedBackend) queried() [][2]uint64 {
+	lB.mu.Lock()
+	defer lB.mu.Unlock()
+
+	return append([][2]uint64{}, lB.ranges...)
+}
+
+func indexedEntries(t *testing.T, store *Store) []Entry {
+	t.Helper()
+
+	entries := []Entry{}
+
+	if eErr := store.Entries(func(entry Entry) error {
+		entries = append(entries, entry)
+		return nil
+	}); eErr != nil {
+		t.Fatal(eErr)
+	}
+
+	return entries
+}
+
+func TestIndexerSyncsTheRegistryLogs(t *testing.T) {
+	chain := newTestChain(t)
+
+	blocks := []uint64{}
+	for index := int64(0); index < 3; index++ {
+		blocks = append(blocks, chain.register(t, index))
+	}
+
+	indexer, store := chain.newIndexer(t, chain.sim.Client(), Config{DeploymentBlock: chain.deploymentBlock, LogRange: 2})
+	ctx := context.Background()
+
+	synced, sErr := indexer.Sync(ctx)
+	if sErr != nil {
+		t.Fatal(sErr)
+	}
+
+	if synced.Count != 3 || synced.Block != blocks[2] {
+		t.Fatalf("expected the 3 entries up to block %d, got %+v", blocks[2], synced)
+	}
+
+	entries := indexedEntries(t, store)
+
+	for index, entry := range entries {
+		if entry.ID != uint64(index) || entry.BlockNumber != blocks[index] || entry.TxHash == "" {
+			t.Fatalf("expected entry %d from the log of block %d, got %+v", index, blocks[index], entry)
+		}
+	}
+
+	// The next sync only reads the new blocks
+	fourth := chain.register(t, 3)
+
+	if synced, sErr = indexer.Sync(ctx); sErr != nil || synced.Count != 4 || synced.Block != fourth {
+		t.Fatalf("expected the 4th entry to be indexed, got %+v (%v)", synced, sErr)
+	}
+
+	if next, _, _ := store.NextBlock(); next != fourth+1 {
+		t.Fatalf("expected the next read to start at block %d, got %d", fourth+1, next)
+	}
+
+	if entries = indexedEntries(t, store); len(entries) != 4 || entries[3].BlockNumber != fourth {
+		t.Fatalf("expected the 4th entry from its log, got %+v", entries)
+	}
+}
+
+func TestIndexerWaitsForTheConfirmations(t *testing.T) {
+	chain := newTestChain(t)
+	first := chain.register(t, 0)
+	chain.register(t, 1)
+
+	indexer, store := chain.newIndexer(t, chain.sim.Client(),
+		Config{DeploymentBlock: chain.deploymentBlock, Confirmations: 1})
+
+	synced, sErr := indexer.Sync(context.Background())
+	if sErr != nil {
+		t.Fatal(sErr)
+	}
+
+	// The latest block is not confirmed yet, neither its log nor its state is indexed
+	if synced.Count != 1 || synced.Block != first || len(indexedEntries(t, store)) != 1 {
+		t.Fatalf("expected only the confirmed entry, got %+v", synced)
+	}
+}
+
+func TestIndexerReadsTheEntriesWithoutLogFromTheState(t *testing.T) {
+	chain := newTestChain(t)
+	chain.register(t, 0)
+	second := chain.register(t, 1)
+
+	// The logs before the deployment block are not read, the entry is only in the contract state
+	indexer, store := chain.newIndexer(t, chain.sim.Client(), Config{DeploymentBlock: second})
+
+	synced, sErr := indexer.Sync(context.Background())
+	if sErr != nil || synced.Count != 2 {
+		t.Fatalf("expected the 2 entries, got %+v (%v)", synced, sErr)
+	}
+
+	entries := indexedEntries(t, store)
+
+	if len(entries) != 2 || entries[0].TxHash != "" || entries[0].AvsAddress != common.BigToAddress(big.NewInt(0xa0)).Hex() {
+		t.Fatalf("expected the first entry from the state, got %+v", entries)
+	}
+
+	if entries[1].TxHash == "" || entries[1].BlockNumber != second {
+		t.Fatalf("expected the second entry from its log, got %+v", entries[1])
+	}
+}
+
+func TestIndexerHalvesTheLogRangeWhenTheNodeReportsTooManyResults(t *testing.T) {
+	chain := newTestChain(t)
+
+	for index := int64(0); index < 3; index++ {
+		chain.register(t, index)
+	}
+
+	backend := &limitedBackend{Client: chain.sim.Client(), maxRange: 2}
+	indexer, store := chain.newIndexer(t, backend, Config{DeploymentBlock: chain.deploymentBlock, LogRange: 8})
+
+	synced, sErr := indexer.Sync(context.Background())
+	if sErr != nil || synced.Count != 3 {
+		t.Fatalf("expected the 3 entries, got %+v (%v)", synced, sErr)
+	}
+
+	// All entries are read from their logs, none of them from the state
+	for _, entry := range indexedEntries(t, store) {
+		if entry.TxHash == "" {
+			t.Fatalf("expected entry %d from its log, got %+v", entry.ID, entry)
+		}
+	}
+
+	ranges := backend.queried()
+	if ranges[0][1]-ranges[0][0]+1 != 4 {
+		t.Fatalf("expected the first query of 4 blocks (up to the head), got %v", ranges)
+	}
+
+	if last := ranges[len(ranges)-1]; last[1]-last[0]+1 > 2 {
+		t.Fatalf("expected the range to be halved down to 2 blocks, got %v", ranges)
+	}
+}
+
+func TestIndexerGrowsTheLogRangeBackAfterTheCrowdedBlocks(t *testing.T) {
+	chain := newTestChain(t)
+	registered := chain.register(t, 0)
+
+	for index := 0; index < 20; index++ {
+		chain.sim.Commit()
+	}
+
+	// Only the blocks up to the registration are crowded
+	backend := &limitedBackend{Client: chain.sim.Client(), maxRange: 2, crowdedUntil: registered}
+	indexer, _ := chain.newIndexer(t, backend, Config{DeploymentBlock: chain.deploymentBlock, LogRange: 8})
+
+	synced, sErr := indexer.Sync(context.Background())
+	if sErr != nil || synced.Count != 1 {
+		t.Fatalf("expected the entry, got %+v (%v)", synced, sErr)
+	}
+
+	ranges := backend.queried()
+
+	halved := false
+	for _, queried := range ranges {
+		size := queried[1] - queried[0] + 1
+
+		if size <= 2 {
+			halved = true
+		}
+
+		// After the crowded blocks the whole range is queried again
+		if halved && size == 8 {
+			return
+		}
+	}
+
+	t.Fatalf("expected the range to grow back to 8 blocks after the halving, got %v", ranges)
+}
+
+func TestIndexerStopsOnOtherLogErrors(t *testing.T) {
+	chain := newTestChain(t)
+	chain.register(t, 0)
+
+	backend := &limitedBackend{Client: chain.sim.Client(), err: errors.New("connection refused")}
+	indexer, _ := chain.newIndexer(t, backend, Config{DeploymentBlock: chain.deploymentBlock, LogRange: 8})
+
+	if _, sErr := indexer.Sync(context.Background()); sErr == nil {
+		t.Fatal("expected the sync to fail")
+	}
+
+	// The range is not halved for an error that does not depend on it
+	if ranges := backend.queried(); len(ranges) != 1 {
+		t.Fatalf("expected a single query, got %v", ranges)
+	}
+}
@@ -0,0 +1,353 @@
+package registryindex
+
+import (
+	"context"
+	"eigenlayer_hack/metrics"
+	"eigenlayer_hack/structs"
+	"eigenlayer_hack/utils"
+	"encoding/json"
+	"errors"
+	"github.com/ethereum/go-ethereum/common"
+	"net/http"
+	"solity/utils/logger"
+	"strconv"
+	"sync"
+	"time"
+)
+
+/*
+Mismatch is a write whose registry entry differs from it
+*/
+type Mismatch struct {
+	Write  Write   `json:"write"`
+	Entry  Entry   `json:"entry"`
+	Reason string  `json:"reason"`
+	Others []Entry `json:"others,omitempty"`
+}
+
+/*
+Duplicate is an operator - AVS pair with more than one registry entry
+*/
+type Duplicate struct {
+	AvsAddress      string  `json:"avsAddress"`
+	OperatorAddress string  `json:"operatorAddress"`
+	Entries         []Entry `json:"entries"`
+}
+
+/*
+Report is the result of a reconciliation of the journal of the writes with the indexed registry at Block. Missing
+writes have no registry entry, Pending writes are confirmed after Block and are not checked yet. Unexpected entries
+have no write in the journal, e.g. the entries of other writers or of writes before the journal
+*/
+type Report struct {
+	Block       uint64      `json:"block"`
+	Entries     int         `json:"entries"`
+	Writes      int         `json:"writes"`
+	Pending     int         `json:"pending"`
+	Missing     []Write     `json:"missing"`
+	Duplicates  []Duplicate `json:"duplicates"`
+	Mismatched  []Mismatch  `json:"mismatched"`
+	Unexpected  []Entry     `json:"unexpected"`
+	Resubmitted int         `json:"resubmitted"`
+	CheckedAt   time.Time   `json:"checkedAt"`
+}
+
+/*
+NewWrite returns the journal record of the confirmed write of the payload for the event key
+*/
+func NewWrite(key string, payload structs.EigenlayerPayload, result utils.WriteResult) Write {
+	write := Write{
+		Key:             key,
+		AvsName:         payload.AvsName,
+		OperatorName:    payload.OperatorName,
+		AvsAddress:      payload.AvsAddress.Hex(),
+		OperatorAddress: payload.OperatorAddress.Hex(),
+		WrittenAt:       time.Now().UTC(),
+	}
+
+	if result.Receipt != nil {
+		write.TxHash = result.Hash.Hex()
+		write.BlockNumber = result.Receipt.BlockNumber.Uint64()
+	}
+
+	if result.EventID != nil {
+		eventID := result.EventID.Uint64()
+		write.EventID = &eventID
+	}
+
+	return write
+}
+
+/*
+Reconcile compares the journal of the writes with the indexed entries of the last sync
+*/
+func (s *Store) Reconcile() (Report, error) {
+	synced, isSynced, sErr := s.Synced()
+	if sErr != nil {
+		return Report{}, sErr
+	}
+
+	if !isSynced {
+		return Report{}, errors.New("the registry is not indexed yet")
+	}
+
+	report := Report{
+		Block:      synced.Block,
+		Missing:    []Write{},
+		Duplicates: []Duplicate{},
+		Mismatched: []Mismatch{},
+		Unexpected: []Entry{},
+		CheckedAt:  time.Now().UTC(),
+	}
+
+	pairs := map[string][]Entry{}
+	var order []string
+
+	eErr := s.Entries(func(entry Entry) error {
+		key := pairKey(entry.AvsAddress, entry.OperatorAddress)
+		if _, isKnown := pairs[key]; !isKnown {
+			order = append(order, key)
+		}
+
+		pairs[key] = append(pairs[key], entry)
+		report.Entries++
+
+		return nil
+	})
+	if eErr != nil {
+		return Report{}, eErr
+	}
+
+	written := map[string]bool{}
+
+	wErr := s.Writes(func(write Write) error {
+		report.Writes++
+
+		key := pairKey(write.AvsAddress, write.OperatorAddress)
+		written[key] = true
+
+		if write.BlockNumber > synced.Block {
+			report.Pending++
+			return nil
+		}
+
+		entries := pairs[key]
+		if len(entries) == 0 {
+			report.Missing = append(report.Missing, write)
+			return nil
+		}
+
+		if mismatch, isMismatched := compare(write, entries); isMismatched {
+			report.Mismatched = append(report.Mismatched, mismatch)
+		}
+
+		return nil
+	})
+	if wErr != nil {
+		return Report{}, wErr
+	}
+
+	for _, key := range order {
+		entries := pairs[key]
+
+		if len(entries) > 1 {
+			report.Duplicates = append(report.Duplicates, Duplicate{
+				AvsAddress:      entries[0].AvsAddress,
+				OperatorAddress: entries[0].OperatorAddress,
+				Entries:         entries,
+			})
+		}
+
+		if !written[key] {
+			report.Unexpected = append(report.Unexpected, entries...)
+		}
+	}
+
+	return report, nil
+}
+
+/*
+compare checks the write against the entries of its pair: the entry of the write has to have the written names, and
+the id and transaction the write was confirmed with. Already registered writes are compared with the first entry
+*/
+func compare(write Write, entries []Entry) (Mismatch, bool) {
+	entry := entries[0]
+
+	if write.EventID != nil {
+		isFound := false
+
+		for _, candidate := range entries {
+			if candidate.ID == *write.EventID {
+				entry, isFound = candidate, true
+				break
+			}
+		}
+
+		if !isFound {
+			return Mismatch{Write: write, Entry: entry, Reason: "the entry " + strconv.FormatUint(*write.EventID, 10) +
+				" of the write belongs to another pair or does not exist", Others: entries[1:]}, true
+		}
+	}
+
+	if write.TxHash != "" && entry.TxHash != "" && !write.AlreadyRegistered &&
+		common.HexToHash(write.TxHash) != common.HexToHash(entry.TxHash) {
+		return Mismatch{Write: write, Entry: entry, Reason: "the entry is from the transaction " + entry.TxHash}, true
+	}
+
+	if write.AvsName != entry.AvsName || write.OperatorName != entry.OperatorName {
+		return Mismatch{Write: write, Entry: entry, Reason: "the names differ"}, true
+	}
+
+	return Mismatch{}, false
+}
+
+/*
+Reconciler syncs the index and reconciles it with the journal. With a writer the missing writes are sent again
+*/
+type Reconciler struct {
+	indexer *Indexer
+	store   *Store
+	writer  *utils.RegistryWriter
+
+	mu     sync.Mutex
+	report *Report
+}
+
+/*
+NewReconciler creates the reconciler, a nil writer only reports the missing writes
+*/
+func NewReconciler(indexer *Indexer, store *Store, writer *utils.RegistryWriter) *Reconciler {
+	return &Reconciler{indexer: indexer, store: store, writer: writer}
+}
+
+/*
+Check syncs the index, reconciles it and, with a writer, sends the missing writes again. The resubmitted writes are
+journaled with their new transaction and are checked by the next reconciliation
+*/
+func (r *Reconciler) Check(ctx context.Context) (Report, error) {
+	synced, sErr := r.indexer.Sync(ctx)
+	if sErr != nil {
+		return Report{}, sErr
+	}
+
+	report, rErr := r.store.Reconcile()
+	if rErr != nil {
+		return Report{}, rErr
+	}
+
+	metrics.RegistryIndexedEntries.Set(int64(synced.Count))
+	metrics.RegistryMissingWrites.Set(int64(len(report.Missing)))
+	metrics.RegistryDuplicateEntries.Set(int64(len(report.Duplicates)))
+	metrics.RegistryMismatchedWrites.Set(int64(len(report.Mismatched)))
+
+	if r.writer != nil {
+		report.Resubmitted = r.resubmit(ctx, report.Missing)
+	}
+
+	r.mu.Lock()
+	r.report = &report
+	r.mu.Unlock()
+
+	return report, nil
+}
+
+/*
+resubmit sends the missing writes again and returns the number of the confirmed ones
+*/
+func (r *Reconciler) resubmit(ctx context.Context, missing []Write) int {
+	resubmitted := 0
+
+	for _, write := range missing {
+		payload := structs.EigenlayerPayload{
+			AvsName:         write.AvsName,
+			OperatorName:    write.OperatorName,
+			AvsAddress:      common.HexToAddress(write.AvsAddress),
+			OperatorAddress: common.HexToAddress(write.OperatorAddress),
+		}
+
+		result, wErr := r.writer.Write(ctx, payload)
+
+		if errors.Is(wErr, utils.ErrAlreadyRegistered) {
+			// Registered after the sync, the next reconciliation finds the entry
+			logger.LogI("Missing write ", write.Key, " is already registered")
+			continue
+		}
+
+		if wErr != nil {
+			logger.LogW("Error while resubmitting the missing write ", write.Key, ": ", wErr)
+			continue
+		}
+
+		if jErr := r.store.RecordWrite(NewWrite(write.Key, payload, result)); jErr != nil {
+			logger.LogW("Error while journaling the resubmitted write ", write.Key, ": ", jErr)
+		}
+
+		logger.LogS("Missing write ", write.Key, " is resubmitted in ", result.Hash.Hex())
+		metrics.RegistryResubmittedWrites.Add(1)
+		resubmitted++
+	}
+
+	return resubmitted
+}
+
+/*
+Run reconciles at the start and every interval until the context is cancelled, the problems found are logged
+*/
+func (r *Reconciler) Run(ctx context.Context, interval time.Duration) {
+	if r == nil || interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		r.logCheck(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+/*
+logCheck runs a reconciliation and logs its outcome
+*/
+func (r *Reconciler) logCheck(ctx context.Context) {
+	report, cErr := r.Check(ctx)
+	if cErr != nil {
+		logger.LogW("Error while reconciling the registry: ", cErr)
+		return
+	}
+
+	if len(report.Missing)+len(report.Duplicates)+len(report.Mismatched) == 0 {
+		logger.LogI("Registry is reconciled at block ", report.Block, ": ", report.Writes, " writes, ", report.Entries,
+			" entries")
+		return
+	}
+
+	logger.LogW("Registry reconciliation at block ", report.Block, " found ", len(report.Missing), " missing writes (",
+		report.Resubmitted, " resubmitted), ", len(report.Duplicates), " duplicate pairs and ", len(report.Mismatched),
+		" mismatched writes")
+}
+
+/*
+Handler serves the report of the last reconciliation as JSON
+*/
+func (r *Reconciler) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		r.mu.Lock()
+		report := r.report
+		r.mu.Unlock()
+
+		if report == nil {
+			http.Error(w, "no reconciliation yet", http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(report)
+	})
+}
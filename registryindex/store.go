@@ -0,0 +1,276 @@
+package registryindex
+
+import (
+	"eigenlayer_hack/utils"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	bolt "go.etcd.io/bbolt"
+	"strings"
+	"time"
+)
+
+var (
+	// Entries of the registry keyed by their id
+	entriesBucket = []byte("entries")
+	// Journal of the confirmed writes keyed by the event key
+	writesBucket = []byte("writes")
+	// Progress of the indexer
+	metaBucket = []byte("meta")
+
+	nextBlockKey = []byte("nextBlock")
+	syncedKey    = []byte("synced")
+)
+
+/*
+Entry is an indexed entry of the registry. The transaction fields are set for the entries read from their
+EventRegistered log, entries read only from the contract state have none
+*/
+type Entry struct {
+	utils.RegistryEntry
+	TxHash      string `json:"txHash,omitempty"`
+	BlockNumber uint64 `json:"blockNumber,omitempty"`
+	LogIndex    uint   `json:"logIndex,omitempty"`
+}
+
+/*
+Write is a registry write the pipeline has confirmed. AlreadyRegistered writes were rejected by the registry because
+the pair is registered, they have no transaction
+*/
+type Write struct {
+	Key               string    `json:"key"`
+	AvsName           string    `json:"avsName"`
+	OperatorName      string    `json:"operatorName"`
+	AvsAddress        string    `json:"avsAddress"`
+	OperatorAddress   string    `json:"operatorAddress"`
+	TxHash            string    `json:"txHash,omitempty"`
+	BlockNumber       uint64    `json:"blockNumber,omitempty"`
+	EventID           *uint64   `json:"eventId,omitempty"`
+	AlreadyRegistered bool      `json:"alreadyRegistered,omitempty"`
+	WrittenAt         time.Time `json:"writtenAt"`
+}
+
+/*
+Synced is the state of the index after a sync: the entries up to Block are indexed, Count is the number of entries of
+the registry at Block
+*/
+type Synced struct {
+	Block    uint64    `json:"block"`
+	Count    uint64    `json:"count"`
+	SyncedAt time.Time `json:"syncedAt"`
+}
+
+/*
+Store keeps the index of the registry entries and the journal of the writes of the pipeline in a single bolt database
+file
+*/
+type Store struct {
+	db *bolt.DB
+}
+
+/*
+Open opens the store at path, the file is created if it does not exist. The file is locked while it is open, so this
+waits at most for timeout
+*/
+func Open(path string, timeout time.Duration) (*Store, error) {
+	db, oErr := bolt.Open(path, 0o644, &bolt.Options{Timeout: timeout})
+	if oErr != nil {
+		return nil, fmt.Errorf("opening the registry index %s: %w", path, oErr)
+	}
+
+	uErr := db.Update(func(tx *bolt.Tx) error {
+		for _, bucket := range [][]byte{entriesBucket, writesBucket, metaBucket} {
+			if _, cErr := tx.CreateBucketIfNotExists(bucket); cErr != nil {
+				return cErr
+			}
+		}
+
+		return nil
+	})
+	if uErr != nil {
+		_ = db.Close()
+		return nil, uErr
+	}
+
+	return &Store{db: db}, nil
+}
+
+/*
+Close closes the database file
+*/
+func (s *Store) Close() error {
+	if s == nil {
+		return nil
+	}
+
+	return s.db.Close()
+}
+
+func encodeID(id uint64) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, id)
+
+	return key
+}
+
+/*
+RecordWrite adds the write to the journal, a write of the same event replaces the former one
+*/
+func (s *Store) RecordWrite(write Write) error {
+	if s == nil {
+		return nil
+	}
+
+	encoded, mErr := json.Marshal(write)
+	if mErr != nil {
+		return mErr
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(writesBucket).Put([]byte(write.Key), encoded)
+	})
+}
+
+/*
+Writes calls fn for every write of the journal, ordered by the event key
+*/
+func (s *Store) Writes(fn func(write Write) error) error {
+	return s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(writesBucket).ForEach(func(key []byte, value []byte) error {
+			var write Write
+			if uErr := json.Unmarshal(value, &write); uErr != nil {
+				return fmt.Errorf("decoding the write %s: %w", key, uErr)
+			}
+
+			return fn(write)
+		})
+	})
+}
+
+/*
+PutEntries stores the entries read from the logs up to, not including, nextBlock, and records nextBlock as the start
+of the next read
+*/
+func (s *Store) PutEntries(entries []Entry, nextBlock uint64) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(entriesBucket)
+
+		for _, entry := range entries {
+			encoded, mErr := json.Marshal(entry)
+			if mErr != nil {
+				return mErr
+			}
+
+			if pErr := bucket.Put(encodeID(entry.ID), encoded); pErr != nil {
+				return pErr
+			}
+		}
+
+		return tx.Bucket(metaBucket).Put(nextBlockKey, encodeID(nextBlock))
+	})
+}
+
+/*
+NextBlock returns the first block that is not read yet, false before the first read
+*/
+func (s *Store) NextBlock() (uint64, bool, error) {
+	block, isSet := uint64(0), false
+
+	vErr := s.db.View(func(tx *bolt.Tx) error {
+		if value := tx.Bucket(metaBucket).Get(nextBlockKey); value != nil {
+			block, isSet = binary.BigEndian.Uint64(value), true
+		}
+
+		return nil
+	})
+
+	return block, isSet, vErr
+}
+
+/*
+MissingIDs returns the ids below count that have no entry
+*/
+func (s *Store) MissingIDs(count uint64) ([]uint64, error) {
+	var missing []uint64
+
+	vErr := s.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(entriesBucket)
+
+		for id := uint64(0); id < count; id++ {
+			if bucket.Get(encodeID(id)) == nil {
+				missing = append(missing, id)
+			}
+		}
+
+		return nil
+	})
+
+	return missing, vErr
+}
+
+/*
+SetSynced records the state of the sync. The entries from synced.Count on are deleted, they are no longer part of the
+registry at the synced block, e.g. after a reorganization
+*/
+func (s *Store) SetSynced(synced Synced) error {
+	encoded, mErr := json.Marshal(synced)
+	if mErr != nil {
+		return mErr
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		cursor := tx.Bucket(entriesBucket).Cursor()
+
+		for key, _ := cursor.Seek(encodeID(synced.Count)); key != nil; key, _ = cursor.Seek(encodeID(synced.Count)) {
+			if dErr := cursor.Delete(); dErr != nil {
+				return dErr
+			}
+		}
+
+		return tx.Bucket(metaBucket).Put(syncedKey, encoded)
+	})
+}
+
+/*
+Synced returns the state of the last sync, false before the first one
+*/
+func (s *Store) Synced() (Synced, bool, error) {
+	var synced Synced
+	isSynced := false
+
+	vErr := s.db.View(func(tx *bolt.Tx) error {
+		value := tx.Bucket(metaBucket).Get(syncedKey)
+		if value == nil {
+			return nil
+		}
+
+		isSynced = true
+
+		return json.Unmarshal(value, &synced)
+	})
+
+	return synced, isSynced, vErr
+}
+
+/*
+Entries calls fn for every indexed entry, ordered by id
+*/
+func (s *Store) Entries(fn func(entry Entry) error) error {
+	return s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(entriesBucket).ForEach(func(key []byte, value []byte) error {
+			var entry Entry
+			if uErr := json.Unmarshal(value, &entry); uErr != nil {
+				return fmt.Errorf("decoding the entry %d: %w", binary.BigEndian.Uint64(key), uErr)
+			}
+
+			return fn(entry)
+		})
+	})
+}
+
+/*
+pairKey identifies the operator - AVS pair of an entry or write
+*/
+func pairKey(avsAddress string, operatorAddress string) string {
+	return strings.ToLower(avsAddress) + "/" + strings.ToLower(operatorAddress)
+}
@@ -0,0 +1,508 @@
+package reorg
+
+import (
+	"context"
+	"eigenlayer_hack/dedup"
+	"eigenlayer_hack/metrics"
+	"eigenlayer_hack/stream"
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"math/big"
+	"solity/utils/logger"
+	"strings"
+	"sync"
+	"time"
+)
+
+/*
+HeaderReader is the part of the node API used by the Monitor, *ethclient.Client implements it
+*/
+type HeaderReader interface {
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+}
+
+/*
+ProcessedEvent is an event that has been written and published by the pipeline
+*/
+type ProcessedEvent struct {
+	Key           dedup.Key
+	BlockNumber   uint64
+	BlockHash     common.Hash
+	OutputChannel string
+	Payload       interface{}
+}
+
+/*
+Retraction is published to the output channel of an event when the block of the event is no longer canonical
+*/
+type Retraction struct {
+	Type               string      `json:"type"`
+	Reason             string      `json:"reason"`
+	ChainID            uint64      `json:"chainId"`
+	TxHash             string      `json:"txHash"`
+	LogIndex           uint        `json:"logIndex"`
+	BlockNumber        uint64      `json:"blockNumber"`
+	BlockHash          string      `json:"blockHash"`
+	CanonicalBlockHash string      `json:"canonicalBlockHash,omitempty"`
+	Event              interface{} `json:"event"`
+	RetractedAt        time.Time   `json:"retractedAt"`
+}
+
+/*
+Compensation is published to the compensation channel for every retracted event. It is advisory: the registry contract
+has no unregister function and the tracker does not consume the channel, the "unregister" action asks the operator of
+the registry to review the write of the event
+*/
+type Compensation struct {
+	Type   string      `json:"type"`
+	Action string      `json:"action"`
+	Reason string      `json:"reason"`
+	Event  interface{} `json:"event"`
+}
+
+type trackedBlock struct {
+	hash   common.Hash
+	events []ProcessedEvent
+}
+
+/*
+orphan is an event to be retracted, e.g. of a block that is found to be orphaned. It is retracted once the lock is
+released
+*/
+type orphan struct {
+	event         ProcessedEvent
+	reason        string
+	canonicalHash common.Hash
+}
+
+/*
+Monitor keeps the block hashes of the processed events for the last depth blocks and detects the reorganizations,
+either by the removed logs reported to it or by comparing the canonical chain with the recorded hashes. The events of
+the orphaned blocks are retracted: a Retraction is published to their output channel, their dedup record is set to
+retracted so they are processed again if they are re-included, and optionally a Compensation is queued. The events
+whose retraction could not be published are kept and retried with the next retractions and Checks
+*/
+type Monitor struct {
+	client              HeaderReader
+	sink                stream.Sink
+	dedupStore          dedup.Store
+	depth               uint64
+	compensationChannel string
+
+	// checking serializes the Checks, mu guards the state and is not held during the node calls and the retractions
+	checking    sync.Mutex
+	mu          sync.Mutex
+	blocks      map[uint64]*trackedBlock
+	canonical   map[uint64]common.Hash
+	lastChecked uint64
+	// unretracted are the orphans whose retraction failed, they are retried
+	unretracted []orphan
+}
+
+/*
+NewMonitor constructor for the Monitor object. client can be nil, then only the removed logs are detected.
+compensationChannel can be empty to disable the compensation requests
+*/
+func NewMonitor(client HeaderReader, sink stream.Sink, dedupStore dedup.Store, depth uint64, compensationChannel string) *Monitor {
+	if depth == 0 {
+		depth = 128
+	}
+
+	return &Monitor{
+		client:              client,
+		sink:                sink,
+		dedupStore:          dedupStore,
+		depth:               depth,
+		compensationChannel: compensationChannel,
+		blocks:              map[uint64]*trackedBlock{},
+		canonical:           map[uint64]common.Hash{},
+	}
+}
+
+/*
+Record stores the block hash of a processed event
+*/
+func (m *Monitor) Record(ctx context.Context, event ProcessedEvent) {
+	if m == nil {
+		return
+	}
+
+	m.mu.Lock()
+
+	// The block may be known to be orphaned already
+	canonicalHash, isKnown := m.canonical[event.BlockNumber]
+
+	if isKnown && canonicalHash != event.BlockHash {
+		m.mu.Unlock()
+		orphaned := orphan{event: event, reason: "orphaned block", canonicalHash: canonicalHash}
+		m.logRetractionError(m.retractAll(ctx, []orphan{orphaned}))
+		return
+	}
+
+	block, isOk := m.blocks[event.BlockNumber]
+
+	// Another hash at the same height, the previously recorded block has been replaced
+	var replaced []orphan
+
+	if isOk && block.hash != event.BlockHash {
+		for _, replacedEvent := range block.events {
+			replaced = append(replaced,
+				orphan{event: replacedEvent, reason: "replaced block", canonicalHash: event.BlockHash})
+		}
+
+		isOk = false
+	}
+
+	if !isOk {
+		block = &trackedBlock{hash: event.BlockHash}
+		m.blocks[event.BlockNumber] = block
+	}
+
+	block.events = append(block.events, event)
+	m.mu.Unlock()
+
+	if len(replaced) > 0 {
+		metrics.ReorgsDetected.Add(1)
+		m.logRetractionError(m.retractAll(ctx, replaced))
+	}
+}
+
+/*
+RetractRemoved handles a log that is reported as removed by the node, the recorded event with the same key is
+retracted. Returns false if no such event has been recorded
+*/
+func (m *Monitor) RetractRemoved(ctx context.Context, key dedup.Key) bool {
+	if m == nil {
+		return false
+	}
+
+	m.mu.Lock()
+
+	var found *ProcessedEvent
+
+	for number, block := range m.blocks {
+		for i, event := range block.events {
+			if event.Key == key {
+				found = &block.events[i]
+				block.events = append(block.events[:i:i], block.events[i+1:]...)
+				break
+			}
+		}
+
+		if found != nil {
+			if len(block.events) == 0 {
+				delete(m.blocks, number)
+			}
+
+			break
+		}
+	}
+
+	m.mu.Unlock()
+
+	if found == nil {
+		return false
+	}
+
+	metrics.ReorgsDetected.Add(1)
+	m.logRetractionError(m.retractAll(ctx, []orphan{{event: *found, reason: "removed log"}}))
+
+	return true
+}
+
+/*
+Run checks the canonical chain every interval until the context is done
+*/
+func (m *Monitor) Run(ctx context.Context, interval time.Duration) {
+	if m == nil || m.client == nil {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if cErr := m.Check(ctx); cErr != nil && ctx.Err() == nil {
+				logger.LogW("Error while checking the chain for reorganizations: ", cErr)
+			}
+		}
+	}
+}
+
+/*
+Check follows the canonical chain from the last checked block to the head. A block whose parent hash does not match
+the previously seen hash of its parent means a reorganization, the chain is then walked back to the common ancestor.
+The tracked blocks that are not covered by the followed chain are compared with the canonical hashes directly. The
+node is queried and the orphaned events are retracted without the lock, so the processing is not blocked meanwhile.
+The failed retractions are returned, their events are retried by the next Check
+*/
+func (m *Monitor) Check(ctx context.Context) (err error) {
+	m.checking.Lock()
+	defer m.checking.Unlock()
+
+	var orphans []orphan
+
+	// The orphans found before an error are retracted as well
+	defer func() {
+		if rErr := m.retractAll(ctx, orphans); rErr != nil {
+			err = errors.Join(err, rErr)
+		}
+	}()
+
+	head, hErr := m.client.HeaderByNumber(ctx, nil)
+
+	if hErr != nil {
+		return hErr
+	}
+
+	headNumber := head.Number.Uint64()
+
+	m.mu.Lock()
+	start := m.lastChecked + 1
+
+	if m.lastChecked == 0 || headNumber-m.lastChecked > m.depth {
+		start = lowerBound(headNumber, m.depth)
+	}
+	m.mu.Unlock()
+
+	for number := start; number <= headNumber; number++ {
+		header := head
+
+		if number != headNumber {
+			var gErr error
+
+			header, gErr = m.client.HeaderByNumber(ctx, new(big.Int).SetUint64(number))
+
+			if gErr != nil {
+				return gErr
+			}
+		}
+
+		m.mu.Lock()
+		parentHash, isKnown := m.canonical[number-1]
+		m.mu.Unlock()
+
+		if isKnown && number > 0 && parentHash != header.ParentHash {
+			logger.LogW("Chain reorganization detected at the block ", number-1)
+			metrics.ReorgsDetected.Add(1)
+
+			if wErr := m.walkBack(ctx, number-1, header.ParentHash, &orphans); wErr != nil {
+				return wErr
+			}
+		}
+
+		m.mu.Lock()
+		orphans = append(orphans, m.setCanonical(number, header.Hash())...)
+		m.lastChecked = number
+		m.mu.Unlock()
+	}
+
+	// Blocks that are recorded but not followed yet
+	m.mu.Lock()
+	unfollowed := map[uint64]common.Hash{}
+
+	for number, block := range m.blocks {
+		if _, isKnown := m.canonical[number]; !isKnown && number <= headNumber {
+			unfollowed[number] = block.hash
+		}
+	}
+	m.mu.Unlock()
+
+	for number, recordedHash := range unfollowed {
+		header, gErr := m.client.HeaderByNumber(ctx, new(big.Int).SetUint64(number))
+
+		if gErr != nil {
+			return gErr
+		}
+
+		if header.Hash() != recordedHash {
+			metrics.ReorgsDetected.Add(1)
+		}
+
+		m.mu.Lock()
+		orphans = append(orphans, m.setCanonical(number, header.Hash())...)
+		m.mu.Unlock()
+	}
+
+	m.mu.Lock()
+	m.prune(headNumber)
+	m.mu.Unlock()
+
+	return nil
+}
+
+/*
+walkBack updates the canonical hashes from the given block down to the common ancestor, the events of the orphaned
+blocks are added to orphans. Has to be called without the lock held
+*/
+func (m *Monitor) walkBack(ctx context.Context, number uint64, canonicalHash common.Hash, orphans *[]orphan) error {
+	for {
+		m.mu.Lock()
+		knownHash, isKnown := m.canonical[number]
+
+		if !isKnown || knownHash == canonicalHash {
+			m.mu.Unlock()
+			return nil
+		}
+
+		*orphans = append(*orphans, m.setCanonical(number, canonicalHash)...)
+		m.mu.Unlock()
+
+		if number == 0 {
+			return nil
+		}
+
+		header, gErr := m.client.HeaderByNumber(ctx, new(big.Int).SetUint64(number))
+
+		if gErr != nil {
+			return gErr
+		}
+
+		number--
+		canonicalHash = header.ParentHash
+	}
+}
+
+/*
+setCanonical stores the canonical hash of the block and returns the events recorded with another hash, they have to be
+retracted. Has to be called with the lock held
+*/
+func (m *Monitor) setCanonical(number uint64, hash common.Hash) []orphan {
+	m.canonical[number] = hash
+
+	block, isOk := m.blocks[number]
+
+	if !isOk || block.hash == hash {
+		return nil
+	}
+
+	delete(m.blocks, number)
+
+	orphans := make([]orphan, 0, len(block.events))
+
+	for _, event := range block.events {
+		orphans = append(orphans, orphan{event: event, reason: "orphaned block", canonicalHash: hash})
+	}
+
+	return orphans
+}
+
+/*
+prune forgets the blocks that are deeper than the monitored depth. Has to be called with the lock held
+*/
+func (m *Monitor) prune(headNumber uint64) {
+	limit := lowerBound(headNumber, m.depth)
+
+	for number := range m.canonical {
+		if number < limit {
+			delete(m.canonical, number)
+		}
+	}
+
+	for number := range m.blocks {
+		if number < limit {
+			delete(m.blocks, number)
+		}
+	}
+}
+
+/*
+retractAll retracts the orphans together with the ones whose retraction failed before. The orphans that fail again are
+kept for the next attempt, their errors are returned. Has to be called without the lock held
+*/
+func (m *Monitor) retractAll(ctx context.Context, orphans []orphan) error {
+	m.mu.Lock()
+	orphans = append(m.unretracted, orphans...)
+	m.unretracted = nil
+	m.mu.Unlock()
+
+	var failed []orphan
+	var failures []error
+
+	for _, o := range orphans {
+		if rErr := m.retract(ctx, o.event, o.reason, o.canonicalHash); rErr != nil {
+			failed = append(failed, o)
+			failures = append(failures, rErr)
+		}
+	}
+
+	if len(failed) > 0 {
+		m.mu.Lock()
+		m.unretracted = append(m.unretracted, failed...)
+		m.mu.Unlock()
+	}
+
+	return errors.Join(failures...)
+}
+
+/*
+logRetractionError logs the failed retractions of the calls that have no caller to return them to
+*/
+func (m *Monitor) logRetractionError(err error) {
+	if err != nil {
+		logger.LogW("Error while retracting, the events are retried with the next retraction: ", err)
+	}
+}
+
+/*
+retract publishes the retraction of the event, marks it as retracted in the dedup store and queues the compensation.
+An error is returned when the retraction is not published, the event is then neither marked nor compensated. Has to
+be called without the lock held
+*/
+func (m *Monitor) retract(ctx context.Context, event ProcessedEvent, reason string, canonicalHash common.Hash) error {
+	logger.LogWf("Retracting the event %s of the block %d (%s): %s", event.Key.String(), event.BlockNumber,
+		event.BlockHash.Hex(), reason)
+
+	retraction := Retraction{
+		Type:        "retraction",
+		Reason:      reason,
+		ChainID:     event.Key.ChainID,
+		TxHash:      strings.ToLower(event.Key.TxHash.Hex()),
+		LogIndex:    event.Key.LogIndex,
+		BlockNumber: event.BlockNumber,
+		BlockHash:   event.BlockHash.Hex(),
+		Event:       event.Payload,
+		RetractedAt: time.Now().UTC(),
+	}
+
+	if canonicalHash != (common.Hash{}) {
+		retraction.CanonicalBlockHash = canonicalHash.Hex()
+	}
+
+	if pErr := m.sink.Publish(ctx, event.OutputChannel, retraction); pErr != nil {
+		return fmt.Errorf("publishing the retraction of %s: %w", event.Key.String(), pErr)
+	}
+
+	metrics.Retractions.Add(1)
+
+	// Allow the processing of the event again when it is re-included in the canonical chain
+	if pErr := m.dedupStore.Put(ctx, event.Key, dedup.OutcomeRetracted); pErr != nil {
+		logger.LogW("Error while recording the retraction: ", pErr)
+	}
+
+	if m.compensationChannel == "" {
+		return nil
+	}
+
+	compensation := Compensation{Type: "compensation", Action: "unregister", Reason: reason, Event: event.Payload}
+
+	if pErr := m.sink.Publish(ctx, m.compensationChannel, compensation); pErr != nil {
+		logger.LogW("Error while queueing the compensation: ", pErr)
+	}
+
+	return nil
+}
+
+func lowerBound(headNumber uint64, depth uint64) uint64 {
+	if headNumber < depth {
+		return 0
+	}
+
+	return headNumber - depth
+}
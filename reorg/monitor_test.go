@@ -0,0 +1,202 @@
+package reorg
+
+import (
+	"context"
+	"eigenlayer_hack/dedup"
+	"eigenlayer_hack/stream"
+	"errors"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"math/big"
+	"testing"
+	"time"
+)
+
+/*
+fakeChain serves the headers of a chain whose blocks are linked by their parent hashes
+*/
+type fakeChain struct {
+	headers map[uint64]*types.Header
+	head    uint64
+}
+
+func newFakeChain(head uint64, salt int64) *fakeChain {
+	chain := &fakeChain{headers: map[uint64]*types.Header{}, head: head}
+	parentHash := common.Hash{}
+
+	for number := uint64(0); number <= head; number++ {
+		header := &types.Header{Number: new(big.Int).SetUint64(number), ParentHash: parentHash, Extra: big.NewInt(salt).Bytes()}
+		chain.headers[number] = header
+		parentHash = header.Hash()
+	}
+
+	return chain
+}
+
+func (fC *fakeChain) HeaderByNumber(_ context.Context, number *big.Int) (*types.Header, error) {
+	if number == nil {
+		return fC.headers[fC.head], nil
+	}
+
+	return fC.headers[number.Uint64()], nil
+}
+
+/*
+reentrantSink calls back into the monitor on every publish, which deadlocks if the monitor holds its lock
+*/
+type reentrantSink struct {
+	*stream.MemorySink
+	monitor *Monitor
+}
+
+func (rS *reentrantSink) Publish(ctx context.Context, channel string, payload interface{}) error {
+	rS.monitor.RetractRemoved(ctx, dedup.Key{ChainID: 99})
+
+	return rS.MemorySink.Publish(ctx, channel, payload)
+}
+
+/*
+failingSink fails every publish while isFailing is set
+*/
+type failingSink struct {
+	*stream.MemorySink
+	isFailing bool
+}
+
+func (fS *failingSink) Publish(ctx context.Context, channel string, payload interface{}) error {
+	if fS.isFailing {
+		return errors.New("broker is unreachable")
+	}
+
+	return fS.MemorySink.Publish(ctx, channel, payload)
+}
+
+func TestCheckRetractsOrphanedEventsWithoutTheLock(t *testing.T) {
+	chain := newFakeChain(10, 1)
+	sink := &reentrantSink{MemorySink: stream.NewMemorySink()}
+	store := dedup.NewMemoryStore(time.Hour)
+	monitor := NewMonitor(chain, sink, store, 0, "compensations")
+	sink.monitor = monitor
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if cErr := monitor.Check(ctx); cErr != nil {
+		t.Fatal(cErr)
+	}
+
+	key := dedup.Key{ChainID: 1, TxHash: common.HexToHash("0x1"), LogIndex: 2}
+	monitor.Record(ctx, ProcessedEvent{Key: key, BlockNumber: 9, BlockHash: chain.headers[9].Hash(), OutputChannel: "out"})
+
+	// Blocks 8 and up are replaced
+	reorged := newFakeChain(11, 2)
+	for number := uint64(0); number < 8; number++ {
+		reorged.headers[number] = chain.headers[number]
+	}
+	reorged.headers[8].ParentHash = chain.headers[7].Hash()
+	for number := uint64(9); number <= 11; number++ {
+		reorged.headers[number].ParentHash = reorged.headers[number-1].Hash()
+	}
+	*chain = *reorged
+
+	done := make(chan error, 1)
+	go func() {
+		done <- monitor.Check(ctx)
+	}()
+
+	select {
+	case cErr := <-done:
+		if cErr != nil {
+			t.Fatal(cErr)
+		}
+	case <-ctx.Done():
+		t.Fatal("Check is blocked, the lock is held while publishing")
+	}
+
+	if retractions := sink.Messages("out"); len(retractions) != 1 {
+		t.Fatalf("expected 1 retraction, got %d", len(retractions))
+	}
+
+	if compensations := sink.Messages("compensations"); len(compensations) != 1 {
+		t.Fatalf("expected 1 compensation, got %d", len(compensations))
+	}
+
+	if record, isRecorded, _ := store.Get(ctx, key); !isRecorded || record.Outcome != dedup.OutcomeRetracted {
+		t.Fatalf("expected the event to be retracted, got %+v", record)
+	}
+}
+
+func TestCheckRetriesTheRetractionsThatFailedToPublish(t *testing.T) {
+	chain := newFakeChain(10, 1)
+	sink := &failingSink{MemorySink: stream.NewMemorySink()}
+	store := dedup.NewMemoryStore(time.Hour)
+	monitor := NewMonitor(chain, sink, store, 0, "")
+	ctx := context.Background()
+
+	if cErr := monitor.Check(ctx); cErr != nil {
+		t.Fatal(cErr)
+	}
+
+	// The block 9 of the event is not canonical, its retraction cannot be published
+	sink.isFailing = true
+
+	key := dedup.Key{ChainID: 1, TxHash: common.HexToHash("0x1"), LogIndex: 2}
+	monitor.Record(ctx, ProcessedEvent{Key: key, BlockNumber: 9, BlockHash: common.HexToHash("0xdead"),
+		OutputChannel: "out"})
+
+	if cErr := monitor.Check(ctx); cErr == nil {
+		t.Fatal("expected the failed retraction to be returned")
+	}
+
+	if _, isRecorded, _ := store.Get(ctx, key); isRecorded {
+		t.Fatal("expected the event not to be marked as retracted before its retraction is published")
+	}
+
+	sink.isFailing = false
+
+	if cErr := monitor.Check(ctx); cErr != nil {
+		t.Fatal(cErr)
+	}
+
+	if retractions := sink.Messages("out"); len(retractions) != 1 {
+		t.Fatalf("expected the retraction to be published by the retry, got %d", len(retractions))
+	}
+
+	if record, isRecorded, _ := store.Get(ctx, key); !isRecorded || record.Outcome != dedup.OutcomeRetracted {
+		t.Fatalf("expected the event to be retracted, got %+v", record)
+	}
+
+	// Nothing is left to retry
+	if cErr := monitor.Check(ctx); cErr != nil || len(sink.Messages("out")) != 1 {
+		t.Fatalf("expected no further retraction, got %d (%v)", len(sink.Messages("out")), cErr)
+	}
+}
+
+func TestRetractRemovedKeepsTheFailedRetractionForTheNextOne(t *testing.T) {
+	sink := &failingSink{MemorySink: stream.NewMemorySink(), isFailing: true}
+	monitor := NewMonitor(nil, sink, dedup.NewMemoryStore(time.Hour), 0, "")
+	ctx := context.Background()
+
+	first := dedup.Key{ChainID: 1, TxHash: common.HexToHash("0x1")}
+	second := dedup.Key{ChainID: 1, TxHash: common.HexToHash("0x2")}
+
+	for _, key := range []dedup.Key{first, second} {
+		monitor.Record(ctx, ProcessedEvent{Key: key, BlockNumber: 5, BlockHash: common.HexToHash("0x5"),
+			OutputChannel: "out"})
+	}
+
+	if !monitor.RetractRemoved(ctx, first) {
+		t.Fatal("expected the recorded event to be found")
+	}
+
+	// Without a node there is no Check, the next retraction publishes the failed one as well
+	sink.isFailing = false
+
+	if !monitor.RetractRemoved(ctx, second) {
+		t.Fatal("expected the recorded event to be found")
+	}
+
+	if retractions := sink.Messages("out"); len(retractions) != 2 {
+		t.Fatalf("expected both retractions, got %d", len(retractions))
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"eigenlayer_hack/config"
+	"eigenlayer_hack/deadletter"
+	"eigenlayer_hack/stream"
+	"flag"
+	"os"
+	"os/signal"
+	"solity/utils/logger"
+	"strings"
+	"syscall"
+	"time"
+)
+
+/*
+runReplay moves the dead-lettered messages back to their original listen channels so that they are processed again.
+By default every configured dead-letter channel is replayed, -channels selects a subset. -source and -sink allow
+replaying from/to files instead of kafka
+*/
+func runReplay(cfg config.Config, args []string) {
+	flags := flag.NewFlagSet("replay-dlq", flag.ExitOnError)
+	channels := flags.String("channels", strings.Join(cfg.Kafka.DeadLetterChannels, ":"),
+		"colon separated dead-letter channels to replay")
+	idleTimeout := flags.Duration("idle-timeout", 30*time.Second,
+		"stop after no message is received for this long (0 keeps running)")
+	sourceKind := flags.String("source", "kafka", "source of the dead-lettered messages: kafka, file or stdin")
+	sourceLocation := flags.String("source-location", "", "file path of the file source")
+	sinkKind := flags.String("sink", "kafka", "sink of the replayed messages: kafka, file or stdout")
+	sinkLocation := flags.String("sink-location", "", "file path of the file sink")
+	_ = flags.Parse(args)
+
+	deadLetterChannels := stream.SplitChannels(*channels)
+
+	if len(deadLetterChannels) == 0 && *sourceKind == "kafka" {
+		logger.LogE("No dead-letter channel to replay")
+	}
+
+	logger.LogI("Replaying dead-letter channels ", deadLetterChannels)
+
+	source, err := stream.NewSource(stream.SourceConfig{
+		Kind:        *sourceKind,
+		Location:    *sourceLocation,
+		Channels:    deadLetterChannels,
+		KafkaURI:    cfg.Kafka.URI,
+		GroupID:     cfg.Kafka.GroupID + "-dlq-replay",
+		MaxLineSize: cfg.Source.MaxLineSize,
+	})
+	if err != nil {
+		logger.LogE("Error while initializing the source: ", err)
+	}
+	defer source.Close()
+
+	sink, err := stream.NewSink(*sinkKind, *sinkLocation, cfg.Kafka.URI, 30*time.Second)
+	if err != nil {
+		logger.LogE("Error while initializing the sink: ", err)
+	}
+	defer sink.Close()
+
+	ctx, stopSignals := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stopSignals()
+
+	replayed, rErr := deadletter.Replay(ctx, source, sink, *idleTimeout)
+
+	logger.LogI("Replayed ", replayed, " dead-lettered messages")
+
+	if rErr != nil {
+		logger.LogW("Replay stopped with error: ", rErr)
+	}
+}
@@ -0,0 +1,528 @@
+package scoring
+
+import (
+	"context"
+	"eigenlayer_hack/metadata"
+	"eigenlayer_hack/metrics"
+	"eigenlayer_hack/snapshots"
+	"eigenlayer_hack/stream"
+	"eigenlayer_hack/structs"
+	"math"
+	"solity/utils/logger"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Component names of the Ranking, the per strategy TVL components are named tvl:<strategy>
+const (
+	componentStakers   = "stakers"
+	componentOperators = "operators"
+	componentTotalTVL  = "totalTVL"
+	componentVelocity  = "velocity"
+	componentTVLPrefix = "tvl:"
+)
+
+// Snapshot metrics of the last ranking, read back after a restart
+const (
+	metricRank  = "popularity_rank"
+	metricScore = "popularity_score"
+)
+
+/*
+Config holds the settings of the Engine
+*/
+type Config struct {
+	Weights Weights
+	// Registrations within this many blocks of the latest observed block count for the velocity
+	VelocityBlocks uint64
+	// Entities without a registration within this many blocks of the latest observed block are no longer scored
+	RetentionBlocks uint64
+	// Channel of the score change messages, empty disables the publishing
+	Channel string
+}
+
+/*
+Ranking is the score and the rank of an entity among the entities of the same kind. The components are the normalized
+values (0-1) the score is computed from
+*/
+type Ranking struct {
+	Kind          metadata.Kind      `json:"kind"`
+	Address       string             `json:"address"`
+	Name          string             `json:"name,omitempty"`
+	Rank          int                `json:"rank"`
+	PreviousRank  int                `json:"previousRank"`
+	RankDelta     int                `json:"rankDelta"`
+	Score         float64            `json:"score"`
+	PreviousScore float64            `json:"previousScore"`
+	ScoreDelta    float64            `json:"scoreDelta"`
+	Velocity      int                `json:"velocity"`
+	Components    map[string]float64 `json:"components"`
+}
+
+/*
+ScoreChange is published to the score channel when the rank of an entity moves. A newly ranked entity has no previous
+rank (0), RankDelta is positive when the entity moved up
+*/
+type ScoreChange struct {
+	Type string `json:"type"`
+	Ranking
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+type entity struct {
+	kind     metadata.Kind
+	address  string
+	stats    structs.Row
+	hasStats bool
+	// block number of the newest registration, the entity is dropped when it is out of the retention
+	lastRegistration uint64
+	// block number of the registrations by event key, so that a reprocessed event is counted once
+	registrations map[string]uint64
+}
+
+/*
+Engine scores the AVSs and operators seen in the registrations by their Dune stats (stakers, operators, total and per
+strategy TVL) and their registration velocity. Every component is log scaled and normalized by the largest value among
+the entities of the same kind, the score is their weighted average on a 0-100 scale. The rankings are kept in the
+snapshot store, so that a restarted engine publishes only the ranks that moved since the last run
+*/
+type Engine struct {
+	config  Config
+	fetcher StatsFetcher
+	sink    stream.Sink
+	store   *snapshots.Store
+
+	mu          sync.Mutex
+	entities    map[string]*entity
+	latestBlock uint64
+	rankings    map[metadata.Kind][]Ranking
+}
+
+/*
+NewEngine constructor for the Engine object. sink can be nil when Channel is empty, store can be nil to keep the
+rankings in memory only
+*/
+func NewEngine(fetcher StatsFetcher, sink stream.Sink, store *snapshots.Store, config Config) *Engine {
+	if config.Weights.StrategyTVL == nil {
+		config.Weights.StrategyTVL = map[string]float64{}
+	}
+
+	if config.VelocityBlocks == 0 {
+		// About a day of mainnet blocks
+		config.VelocityBlocks = 7200
+	}
+
+	if config.RetentionBlocks == 0 {
+		// About a month of mainnet blocks
+		config.RetentionBlocks = 216000
+	}
+
+	return &Engine{
+		config:   config,
+		fetcher:  fetcher,
+		sink:     sink,
+		store:    store,
+		entities: map[string]*entity{},
+		rankings: map[metadata.Kind][]Ranking{},
+	}
+}
+
+/*
+ObserveRegistration records a registration of the entity at the given block. eventKey identifies the event, the same
+event is counted once
+*/
+func (e *Engine) ObserveRegistration(kind metadata.Kind, address string, eventKey string, blockNumber uint64) {
+	if e == nil {
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	tracked := e.entity(kind, address)
+	tracked.registrations[eventKey] = blockNumber
+
+	if blockNumber > tracked.lastRegistration {
+		tracked.lastRegistration = blockNumber
+	}
+
+	if blockNumber > e.latestBlock {
+		e.latestBlock = blockNumber
+	}
+}
+
+/*
+UpdateStats sets the stats row of the entity, unless the entity is not tracked (anymore)
+*/
+func (e *Engine) UpdateStats(kind metadata.Kind, address string, stats structs.Row) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	tracked, isOk := e.entities[string(kind)+":"+strings.ToLower(address)]
+	if !isOk {
+		return
+	}
+
+	tracked.stats = stats
+	tracked.hasStats = true
+}
+
+/*
+entity returns the tracked entity, it is created on the first use. The lock must be held
+*/
+func (e *Engine) entity(kind metadata.Kind, address string) *entity {
+	address = strings.ToLower(address)
+	key := string(kind) + ":" + address
+
+	tracked, isOk := e.entities[key]
+	if !isOk {
+		tracked = &entity{kind: kind, address: address, registrations: map[string]uint64{}}
+		e.entities[key] = tracked
+	}
+
+	return tracked
+}
+
+/*
+Rankings returns the current ranking of the entities of the kind, best first
+*/
+func (e *Engine) Rankings(kind metadata.Kind) []Ranking {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return append([]Ranking{}, e.rankings[kind]...)
+}
+
+/*
+Run refreshes the stats and the rankings every interval until the context is cancelled
+*/
+func (e *Engine) Run(ctx context.Context, interval time.Duration) {
+	if e == nil {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			e.Refresh(ctx)
+		}
+	}
+}
+
+/*
+Refresh fetches the stats of every tracked entity, recomputes the rankings and publishes the score changes. An entity
+whose stats cannot be fetched keeps its previous stats
+*/
+func (e *Engine) Refresh(ctx context.Context) {
+	e.mu.Lock()
+	tracked := make([]entity, 0, len(e.entities))
+	for _, each := range e.entities {
+		tracked = append(tracked, entity{kind: each.kind, address: each.address})
+	}
+	e.mu.Unlock()
+
+	for _, each := range tracked {
+		if ctx.Err() != nil {
+			return
+		}
+
+		stats, isFound, fErr := e.fetcher.Stats(ctx, each.kind, each.address)
+		if fErr != nil {
+			logger.LogW("Error while fetching the stats of the ", string(each.kind), " ", each.address, ": ", fErr)
+			continue
+		}
+
+		if isFound {
+			e.UpdateStats(each.kind, each.address, stats)
+		}
+	}
+
+	e.Publish(ctx, e.Recompute(time.Now().UTC()))
+}
+
+/*
+Publish sends the score changes to the score channel
+*/
+func (e *Engine) Publish(ctx context.Context, changes []ScoreChange) {
+	if e.config.Channel == "" || e.sink == nil {
+		return
+	}
+
+	for _, change := range changes {
+		if pErr := e.sink.Publish(ctx, e.config.Channel, change); pErr != nil {
+			logger.LogW("Error while publishing the score change of ", change.Address, ": ", pErr)
+		}
+	}
+}
+
+/*
+Recompute scores the tracked entities and ranks them per kind. The entities whose rank moved are returned as score
+changes. The entities out of the retention are dropped, and the new rankings are recorded in the snapshot store
+*/
+func (e *Engine) Recompute(now time.Time) []ScoreChange {
+	e.mu.Lock()
+
+	byKind := map[metadata.Kind][]*entity{}
+	for key, each := range e.entities {
+		if each.lastRegistration+e.config.RetentionBlocks <= e.latestBlock {
+			delete(e.entities, key)
+			continue
+		}
+
+		e.pruneRegistrations(each)
+		byKind[each.kind] = append(byKind[each.kind], each)
+	}
+
+	changes := []ScoreChange{}
+	ranked := []Ranking{}
+
+	for kind, entities := range byKind {
+		previous := map[string]Ranking{}
+		for _, ranking := range e.rankings[kind] {
+			previous[ranking.Address] = ranking
+		}
+
+		rankings := e.rank(kind, entities)
+
+		for index := range rankings {
+			ranking := &rankings[index]
+
+			before, isRanked := previous[ranking.Address]
+			if !isRanked {
+				before, isRanked = e.storedRanking(kind, ranking.Address)
+			}
+
+			if isRanked {
+				ranking.PreviousRank = before.Rank
+				ranking.RankDelta = before.Rank - ranking.Rank
+				ranking.PreviousScore = before.Score
+				ranking.ScoreDelta = ranking.Score - before.Score
+			}
+
+			if ranking.PreviousRank != ranking.Rank {
+				changes = append(changes, ScoreChange{Type: "score-change", Ranking: *ranking, UpdatedAt: now})
+			}
+		}
+
+		e.rankings[kind] = rankings
+		ranked = append(ranked, rankings...)
+	}
+
+	// Kinds without tracked entities left
+	for kind := range e.rankings {
+		if _, isOk := byKind[kind]; !isOk {
+			delete(e.rankings, kind)
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Kind != changes[j].Kind {
+			return changes[i].Kind < changes[j].Kind
+		}
+
+		return changes[i].Rank < changes[j].Rank
+	})
+
+	metrics.ScoredEntities.Set(int64(len(e.entities)))
+	metrics.ScoreChanges.Add(int64(len(changes)))
+
+	e.mu.Unlock()
+
+	e.recordRankings(ranked, now)
+
+	return changes
+}
+
+/*
+storedRanking returns the rank and the score of the entity recorded in the snapshot store, the previous ranking of an
+entity that is not ranked in memory yet, e.g. after a restart
+*/
+func (e *Engine) storedRanking(kind metadata.Kind, address string) (Ranking, bool) {
+	rank, isFound, lErr := e.store.Latest(kind, address, metricRank)
+	if lErr != nil {
+		logger.LogW("Error while reading the last rank of the ", string(kind), " ", address, ": ", lErr)
+		return Ranking{}, false
+	}
+
+	if !isFound {
+		return Ranking{}, false
+	}
+
+	score, _, lErr := e.store.Latest(kind, address, metricScore)
+	if lErr != nil {
+		logger.LogW("Error while reading the last score of the ", string(kind), " ", address, ": ", lErr)
+	}
+
+	return Ranking{Rank: int(rank.Value), Score: score.Value}, true
+}
+
+/*
+recordRankings keeps the rank and the score of every ranked entity in the snapshot store, a failed recording is logged
+*/
+func (e *Engine) recordRankings(rankings []Ranking, now time.Time) {
+	if e.store == nil {
+		return
+	}
+
+	for _, ranking := range rankings {
+		values := map[string]float64{metricRank: float64(ranking.Rank), metricScore: ranking.Score}
+
+		if rErr := e.store.RecordValues(ranking.Kind, ranking.Address, values, now); rErr != nil {
+			logger.LogW("Error while recording the ranking of the ", string(ranking.Kind), " ", ranking.Address, ": ",
+				rErr)
+		}
+	}
+}
+
+/*
+pruneRegistrations drops the registrations that are out of the velocity window. The lock must be held
+*/
+func (e *Engine) pruneRegistrations(tracked *entity) {
+	for key, blockNumber := range tracked.registrations {
+		if blockNumber+e.config.VelocityBlocks <= e.latestBlock {
+			delete(tracked.registrations, key)
+		}
+	}
+}
+
+/*
+rank computes the scores of the entities of one kind and orders them, best first
+*/
+func (e *Engine) rank(kind metadata.Kind, entities []*entity) []Ranking {
+	weights := e.componentWeights(kind)
+
+	raw := make([]map[string]float64, len(entities))
+	maxima := map[string]float64{}
+
+	for index, each := range entities {
+		raw[index] = e.components(each, weights)
+
+		for component, value := range raw[index] {
+			maxima[component] = math.Max(maxima[component], value)
+		}
+	}
+
+	totalWeight := 0.0
+	for _, weight := range weights {
+		totalWeight += weight
+	}
+
+	rankings := make([]Ranking, len(entities))
+
+	for index, each := range entities {
+		normalized := map[string]float64{}
+		score := 0.0
+
+		for component, weight := range weights {
+			value := 0.0
+			if maxima[component] > 0 {
+				value = raw[index][component] / maxima[component]
+			}
+
+			normalized[component] = value
+			score += weight * value
+		}
+
+		if totalWeight > 0 {
+			score = 100 * score / totalWeight
+		}
+
+		rankings[index] = Ranking{
+			Kind:       kind,
+			Address:    each.address,
+			Name:       entityName(each),
+			Score:      score,
+			Velocity:   len(each.registrations),
+			Components: normalized,
+		}
+	}
+
+	sort.Slice(rankings, func(i, j int) bool {
+		if rankings[i].Score != rankings[j].Score {
+			return rankings[i].Score > rankings[j].Score
+		}
+
+		return rankings[i].Address < rankings[j].Address
+	})
+
+	for index := range rankings {
+		rankings[index].Rank = index + 1
+	}
+
+	return rankings
+}
+
+/*
+componentWeights returns the positive weights of the components that apply to the kind. Operators are not scored by
+the operator count
+*/
+func (e *Engine) componentWeights(kind metadata.Kind) map[string]float64 {
+	weights := map[string]float64{
+		componentStakers:  e.config.Weights.Stakers,
+		componentTotalTVL: e.config.Weights.TotalTVL,
+		componentVelocity: e.config.Weights.Velocity,
+	}
+
+	if kind == metadata.KindAVS {
+		weights[componentOperators] = e.config.Weights.Operators
+	}
+
+	for strategy, weight := range e.config.Weights.StrategyTVL {
+		weights[componentTVLPrefix+strategy] = weight
+	}
+
+	for component, weight := range weights {
+		if weight <= 0 {
+			delete(weights, component)
+		}
+	}
+
+	return weights
+}
+
+/*
+components returns the log scaled values of the weighted components of the entity
+*/
+func (e *Engine) components(tracked *entity, weights map[string]float64) map[string]float64 {
+	values := map[string]float64{}
+
+	for component := range weights {
+		value := 0.0
+
+		switch {
+		case component == componentVelocity:
+			value = float64(len(tracked.registrations))
+		case !tracked.hasStats:
+		case component == componentStakers:
+			value = float64(tracked.stats.NumStakers())
+		case component == componentOperators:
+			value = float64(tracked.stats.NumOperators())
+		case component == componentTotalTVL:
+			value = tracked.stats.TotalTVL()
+		case strings.HasPrefix(component, componentTVLPrefix):
+			value = tracked.stats.TVL[strings.TrimPrefix(component, componentTVLPrefix)]
+		}
+
+		values[component] = math.Log1p(math.Max(value, 0))
+	}
+
+	return values
+}
+
+/*
+entityName returns the name of the entity from its stats
+*/
+func entityName(tracked *entity) string {
+	if tracked.kind == metadata.KindAVS {
+		return tracked.stats.AVSName()
+	}
+
+	return tracked.stats.OperatorName()
+}
@@ -0,0 +1,74 @@
+package scoring
+
+import (
+	"context"
+	"eigenlayer_hack/metadata"
+	"eigenlayer_hack/snapshots"
+	"eigenlayer_hack/structs"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+/*
+noStats knows no entity, the entities are scored by their velocity only
+*/
+type noStats struct{}
+
+func (noStats) Stats(context.Context, metadata.Kind, string) (structs.Row, bool, error) {
+	return structs.Row{}, false, nil
+}
+
+func newTestEngine(store *snapshots.Store) *Engine {
+	engine := NewEngine(noStats{}, nil, store, Config{
+		Weights:         Weights{Velocity: 1},
+		VelocityBlocks:  100,
+		RetentionBlocks: 1000,
+	})
+
+	// 0xa is registered twice, 0xb once
+	engine.ObserveRegistration(metadata.KindAVS, "0xa", "1", 10)
+	engine.ObserveRegistration(metadata.KindAVS, "0xa", "2", 11)
+	engine.ObserveRegistration(metadata.KindAVS, "0xb", "3", 12)
+
+	return engine
+}
+
+func TestEngineComparesWithTheStoredRankingAfterARestart(t *testing.T) {
+	store, oErr := snapshots.Open(filepath.Join(t.TempDir(), "snapshots.db"), snapshots.Policy{})
+	if oErr != nil {
+		t.Fatal(oErr)
+	}
+	defer store.Close()
+
+	if changes := newTestEngine(store).Recompute(time.Now()); len(changes) != 2 {
+		t.Fatalf("expected both entities to be newly ranked, got %d changes", len(changes))
+	}
+
+	restarted := newTestEngine(store)
+
+	if changes := restarted.Recompute(time.Now()); len(changes) != 0 {
+		t.Fatalf("expected no change after the restart, got %+v", changes)
+	}
+
+	rankings := restarted.Rankings(metadata.KindAVS)
+	if len(rankings) != 2 || rankings[0].Address != "0xa" || rankings[0].PreviousRank != 1 {
+		t.Fatalf("expected 0xa to keep the first rank, got %+v", rankings)
+	}
+}
+
+func TestEngineDropsTheEntitiesOutOfTheRetention(t *testing.T) {
+	engine := newTestEngine(nil)
+	engine.ObserveRegistration(metadata.KindAVS, "0xb", "4", 1011)
+
+	engine.Recompute(time.Now())
+
+	rankings := engine.Rankings(metadata.KindAVS)
+	if len(rankings) != 1 || rankings[0].Address != "0xb" {
+		t.Fatalf("expected only 0xb to be ranked, got %+v", rankings)
+	}
+
+	if len(engine.entities) != 1 {
+		t.Fatalf("expected 1 tracked entity, got %d", len(engine.entities))
+	}
+}
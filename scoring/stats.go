@@ -0,0 +1,42 @@
+package scoring
+
+import (
+	"context"
+	"eigenlayer_hack/metadata"
+	"eigenlayer_hack/structs"
+)
+
+/*
+StatsFetcher returns the current stats row of an entity, false if the source does not know the entity
+*/
+type StatsFetcher interface {
+	Stats(ctx context.Context, kind metadata.Kind, address string) (structs.Row, bool, error)
+}
+
+/*
+ResolverStats reads the Dune stats rows through the metadata resolver, so the scoring shares its cache and asks Dune
+again only when the cached entry expires
+*/
+type ResolverStats struct {
+	resolver *metadata.CachingResolver
+}
+
+/*
+NewResolverStats constructor for the ResolverStats object
+*/
+func NewResolverStats(resolver *metadata.CachingResolver) *ResolverStats {
+	return &ResolverStats{resolver: resolver}
+}
+
+func (rS *ResolverStats) Stats(ctx context.Context, kind metadata.Kind, address string) (structs.Row, bool, error) {
+	entry, rErr := rS.resolver.Resolve(ctx, kind, address)
+	if rErr != nil {
+		return structs.Row{}, false, rErr
+	}
+
+	if entry.Stats == nil {
+		return structs.Row{}, false, nil
+	}
+
+	return *entry.Stats, true, nil
+}
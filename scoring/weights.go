@@ -0,0 +1,97 @@
+package scoring
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Weight keys of the common components, every other key is the strategy of a per strategy TVL column
+const (
+	WeightStakers   = "stakers"
+	WeightOperators = "operators"
+	WeightTotalTVL  = "totalTVL"
+	WeightVelocity  = "velocity"
+)
+
+/*
+Weights are the weights of the score components. A component with a zero weight does not count
+*/
+type Weights struct {
+	Stakers   float64
+	Operators float64
+	TotalTVL  float64
+	Velocity  float64
+	// TVL of the single strategies by strategy name, e.g. stETH
+	StrategyTVL map[string]float64
+}
+
+/*
+DefaultWeights counts the stakers, operators, total TVL and registration velocity equally
+*/
+func DefaultWeights() Weights {
+	return Weights{Stakers: 1, Operators: 1, TotalTVL: 1, Velocity: 1, StrategyTVL: map[string]float64{}}
+}
+
+/*
+ParseWeights reads the weights from key=value pairs, e.g. {"totalTVL": "2", "stETH": "0.5"}. The components missing
+from the pairs keep their default weight
+*/
+func ParseWeights(pairs map[string]string) (Weights, error) {
+	weights := DefaultWeights()
+	problems := []error{}
+
+	keys := make([]string, 0, len(pairs))
+	for key := range pairs {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		weight, pErr := strconv.ParseFloat(strings.TrimSpace(pairs[key]), 64)
+
+		if pErr != nil || weight < 0 {
+			problems = append(problems, fmt.Errorf("weight of %s must be a non-negative number, got %q", key, pairs[key]))
+			continue
+		}
+
+		switch key {
+		case WeightStakers:
+			weights.Stakers = weight
+		case WeightOperators:
+			weights.Operators = weight
+		case WeightTotalTVL:
+			weights.TotalTVL = weight
+		case WeightVelocity:
+			weights.Velocity = weight
+		default:
+			weights.StrategyTVL[key] = weight
+		}
+	}
+
+	if len(problems) > 0 {
+		return Weights{}, errors.Join(problems...)
+	}
+
+	if weights.total() == 0 {
+		return Weights{}, errors.New("at least one weight must be positive")
+	}
+
+	return weights, nil
+}
+
+/*
+total returns the sum of the weights
+*/
+func (w Weights) total() float64 {
+	total := w.Stakers + w.Operators + w.TotalTVL + w.Velocity
+
+	for _, weight := range w.StrategyTVL {
+		total += weight
+	}
+
+	return total
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"eigenlayer_hack/config"
+	"eigenlayer_hack/metadata"
+	"eigenlayer_hack/snapshots"
+	"encoding/json"
+	"flag"
+	"os"
+	"solity/utils/logger"
+	"time"
+)
+
+/*
+runSnapshotQuery prints the stored stats of an entity as JSON. Without -metric the latest value of every metric is
+printed, with it the series of the last -since, downsampled per -step when it is given
+*/
+func runSnapshotQuery(cfg config.Config, args []string) {
+	flags := flag.NewFlagSet("snapshots", flag.ExitOnError)
+	file := flags.String("file", cfg.Snapshots.File, "path of the snapshot store")
+	kind := flags.String("kind", string(metadata.KindAVS), "entity kind: avs or operator")
+	address := flags.String("address", "", "address of the AVS or operator")
+	metric := flags.String("metric", "", "metric (Dune column) to print, e.g. total_TVL")
+	since := flags.Duration("since", 7*24*time.Hour, "length of the printed series")
+	step := flags.Duration("step", 0, "downsampling step of the series (0 prints every point)")
+	aggregation := flags.String("aggregation", snapshots.AggregateAvg, "aggregation of the downsampled points: avg, min, max or last")
+	_ = flags.Parse(args)
+
+	if *address == "" {
+		logger.LogE("The entity has to be given with -address")
+	}
+
+	store, oErr := snapshots.OpenReadOnly(*file, 5*time.Second)
+	if oErr != nil {
+		logger.LogE("Error while opening the snapshot store, while the tracker runs use its /snapshots endpoint: ", oErr)
+	}
+	defer store.Close()
+
+	to := time.Now().UTC()
+
+	result, qErr := store.Query(snapshots.Query{
+		Kind:        metadata.Kind(*kind),
+		Address:     *address,
+		Metric:      *metric,
+		From:        to.Add(-*since),
+		To:          to,
+		Step:        *step,
+		Aggregation: *aggregation,
+	})
+	if qErr != nil {
+		logger.LogE("Error while reading the snapshots: ", qErr)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+
+	if eErr := encoder.Encode(result); eErr != nil {
+		logger.LogE("Error while printing the snapshots: ", eErr)
+	}
+}
@@ -0,0 +1,149 @@
+package snapshots
+
+import (
+	"eigenlayer_hack/metadata"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+)
+
+/*
+Query selects the snapshots of an entity. Without a Metric the latest value of every metric is returned, with it the
+points between From and To, downsampled per Step when it is set
+*/
+type Query struct {
+	Kind        metadata.Kind
+	Address     string
+	Metric      string
+	From        time.Time
+	To          time.Time
+	Step        time.Duration
+	Aggregation string
+}
+
+/*
+Query executes the query, the result is a map of the latest points by metric or the list of points of the metric
+*/
+func (s *Store) Query(query Query) (interface{}, error) {
+	if query.Address == "" {
+		return nil, errors.New("address is required")
+	}
+
+	if query.Metric == "" {
+		metrics, mErr := s.Metrics(query.Kind, query.Address)
+		if mErr != nil {
+			return nil, mErr
+		}
+
+		latest := map[string]Point{}
+
+		for _, metric := range metrics {
+			point, isFound, lErr := s.Latest(query.Kind, query.Address, metric)
+			if lErr != nil {
+				return nil, lErr
+			}
+
+			if isFound {
+				latest[metric] = point
+			}
+		}
+
+		return latest, nil
+	}
+
+	if query.Step > 0 {
+		aggregation := query.Aggregation
+		if aggregation == "" {
+			aggregation = AggregateAvg
+		}
+
+		return s.Downsample(query.Kind, query.Address, query.Metric, query.From, query.To, query.Step, aggregation)
+	}
+
+	return s.Range(query.Kind, query.Address, query.Metric, query.From, query.To)
+}
+
+/*
+Handler serves the queries over http: GET ?kind=avs&address=0x..&metric=total_TVL&since=168h&step=24h&aggregation=avg.
+from and to (RFC 3339) can be given instead of since
+*/
+func (s *Store) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query, pErr := parseQuery(r)
+		if pErr != nil {
+			http.Error(w, pErr.Error(), http.StatusBadRequest)
+			return
+		}
+
+		result, qErr := s.Query(query)
+		if qErr != nil {
+			http.Error(w, qErr.Error(), http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(result)
+	})
+}
+
+/*
+parseQuery reads the query from the url parameters
+*/
+func parseQuery(r *http.Request) (Query, error) {
+	values := r.URL.Query()
+
+	query := Query{
+		Kind:        metadata.Kind(values.Get("kind")),
+		Address:     values.Get("address"),
+		Metric:      values.Get("metric"),
+		To:          time.Now().UTC(),
+		Aggregation: values.Get("aggregation"),
+	}
+
+	if query.Kind == "" {
+		query.Kind = metadata.KindAVS
+	}
+
+	since := 7 * 24 * time.Hour
+
+	if raw := values.Get("since"); raw != "" {
+		parsed, dErr := time.ParseDuration(raw)
+		if dErr != nil {
+			return Query{}, errors.New("invalid since: " + raw)
+		}
+
+		since = parsed
+	}
+
+	if raw := values.Get("to"); raw != "" {
+		parsed, tErr := time.Parse(time.RFC3339, raw)
+		if tErr != nil {
+			return Query{}, errors.New("invalid to: " + raw)
+		}
+
+		query.To = parsed
+	}
+
+	query.From = query.To.Add(-since)
+
+	if raw := values.Get("from"); raw != "" {
+		parsed, tErr := time.Parse(time.RFC3339, raw)
+		if tErr != nil {
+			return Query{}, errors.New("invalid from: " + raw)
+		}
+
+		query.From = parsed
+	}
+
+	if raw := values.Get("step"); raw != "" {
+		parsed, dErr := time.ParseDuration(raw)
+		if dErr != nil {
+			return Query{}, errors.New("invalid step: " + raw)
+		}
+
+		query.Step = parsed
+	}
+
+	return query, nil
+}
@@ -0,0 +1,479 @@
+package snapshots
+
+import (
+	"context"
+	"eigenlayer_hack/metadata"
+	"eigenlayer_hack/structs"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	bolt "go.etcd.io/bbolt"
+	"math"
+	"solity/utils/logger"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Root bucket of the series, every series is a nested bucket keyed by the timestamp of its points
+var seriesBucket = []byte("series")
+
+// Aggregations of the downsampled series
+const (
+	AggregateAvg  = "avg"
+	AggregateMin  = "min"
+	AggregateMax  = "max"
+	AggregateLast = "last"
+)
+
+/*
+Point is a single value of a series
+*/
+type Point struct {
+	Time  time.Time `json:"time"`
+	Value float64   `json:"value"`
+}
+
+/*
+Policy holds the retention and compaction settings of the Store. Points older than CompactAfter are averaged into one
+point per CompactResolution, points older than Retention are deleted. Zero durations disable the step
+*/
+type Policy struct {
+	Retention         time.Duration
+	CompactAfter      time.Duration
+	CompactResolution time.Duration
+	CheckInterval     time.Duration
+}
+
+/*
+Store keeps the stats of the AVSs and operators as time series in a single bolt database file. A series is identified
+by the entity kind, its address and the metric, which is the Dune column name (e.g. total_TVL, stETH_TVL, num_stakers)
+*/
+type Store struct {
+	db     *bolt.DB
+	policy Policy
+}
+
+/*
+Open opens the store at path, the file is created if it does not exist
+*/
+func Open(path string, policy Policy) (*Store, error) {
+	db, oErr := bolt.Open(path, 0o644, &bolt.Options{Timeout: 5 * time.Second})
+	if oErr != nil {
+		return nil, fmt.Errorf("opening the snapshot store %s: %w", path, oErr)
+	}
+
+	uErr := db.Update(func(tx *bolt.Tx) error {
+		_, cErr := tx.CreateBucketIfNotExists(seriesBucket)
+		return cErr
+	})
+	if uErr != nil {
+		_ = db.Close()
+		return nil, uErr
+	}
+
+	return &Store{db: db, policy: policy}, nil
+}
+
+/*
+OpenReadOnly opens an existing store for queries. The file is locked by a running tracker, so this waits at most for
+timeout
+*/
+func OpenReadOnly(path string, timeout time.Duration) (*Store, error) {
+	db, oErr := bolt.Open(path, 0o644, &bolt.Options{ReadOnly: true, Timeout: timeout})
+	if oErr != nil {
+		return nil, fmt.Errorf("opening the snapshot store %s: %w", path, oErr)
+	}
+
+	return &Store{db: db}, nil
+}
+
+/*
+Close closes the database file
+*/
+func (s *Store) Close() error {
+	if s == nil {
+		return nil
+	}
+
+	return s.db.Close()
+}
+
+/*
+seriesKey returns the bucket name of the series
+*/
+func seriesKey(kind metadata.Kind, address string, metric string) []byte {
+	return []byte(string(kind) + "/" + strings.ToLower(address) + "/" + metric)
+}
+
+func encodeTime(at time.Time) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, uint64(at.UnixNano()))
+
+	return key
+}
+
+func decodeTime(key []byte) time.Time {
+	return time.Unix(0, int64(binary.BigEndian.Uint64(key))).UTC()
+}
+
+func encodeValue(value float64) []byte {
+	encoded := make([]byte, 8)
+	binary.BigEndian.PutUint64(encoded, math.Float64bits(value))
+
+	return encoded
+}
+
+func decodeValue(encoded []byte) float64 {
+	return math.Float64frombits(binary.BigEndian.Uint64(encoded))
+}
+
+/*
+Record stores a single value of the series
+*/
+func (s *Store) Record(kind metadata.Kind, address string, metric string, at time.Time, value float64) error {
+	if s == nil {
+		return nil
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return putPoint(tx, seriesKey(kind, address, metric), at, value)
+	})
+}
+
+/*
+RecordStats stores every numeric column of the stats row as a point of its metric, in a single transaction
+*/
+func (s *Store) RecordStats(kind metadata.Kind, address string, stats structs.Row, at time.Time) error {
+	return s.RecordValues(kind, address, stats.Numbers(), at)
+}
+
+/*
+RecordValues stores the values of the entity as points of their metrics, in a single transaction
+*/
+func (s *Store) RecordValues(kind metadata.Kind, address string, values map[string]float64, at time.Time) error {
+	if s == nil {
+		return nil
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		for metric, value := range values {
+			if pErr := putPoint(tx, seriesKey(kind, address, metric), at, value); pErr != nil {
+				return pErr
+			}
+		}
+
+		return nil
+	})
+}
+
+func putPoint(tx *bolt.Tx, key []byte, at time.Time, value float64) error {
+	series, cErr := tx.Bucket(seriesBucket).CreateBucketIfNotExists(key)
+	if cErr != nil {
+		return cErr
+	}
+
+	return series.Put(encodeTime(at), encodeValue(value))
+}
+
+/*
+Range returns the points of the series between from and to (both inclusive), oldest first
+*/
+func (s *Store) Range(kind metadata.Kind, address string, metric string, from time.Time, to time.Time) ([]Point, error) {
+	points := []Point{}
+
+	vErr := s.db.View(func(tx *bolt.Tx) error {
+		series := tx.Bucket(seriesBucket).Bucket(seriesKey(kind, address, metric))
+		if series == nil {
+			return nil
+		}
+
+		cursor := series.Cursor()
+		end := to.UnixNano()
+
+		for key, value := cursor.Seek(encodeTime(from)); key != nil; key, value = cursor.Next() {
+			at := decodeTime(key)
+
+			if at.UnixNano() > end {
+				break
+			}
+
+			points = append(points, Point{Time: at, Value: decodeValue(value)})
+		}
+
+		return nil
+	})
+
+	return points, vErr
+}
+
+/*
+Latest returns the newest point of the series, false if the series is empty or the store is disabled
+*/
+func (s *Store) Latest(kind metadata.Kind, address string, metric string) (Point, bool, error) {
+	if s == nil {
+		return Point{}, false, nil
+	}
+
+	point := Point{}
+	isFound := false
+
+	vErr := s.db.View(func(tx *bolt.Tx) error {
+		series := tx.Bucket(seriesBucket).Bucket(seriesKey(kind, address, metric))
+		if series == nil {
+			return nil
+		}
+
+		key, value := series.Cursor().Last()
+		if key == nil {
+			return nil
+		}
+
+		point, isFound = Point{Time: decodeTime(key), Value: decodeValue(value)}, true
+
+		return nil
+	})
+
+	return point, isFound, vErr
+}
+
+/*
+Downsample returns the points of the series between from and to aggregated into one point per step, at the start of
+the step. Steps without points are left out
+*/
+func (s *Store) Downsample(kind metadata.Kind, address string, metric string, from time.Time, to time.Time,
+	step time.Duration, aggregation string) ([]Point, error) {
+	if step <= 0 {
+		return nil, errors.New("step must be positive")
+	}
+
+	points, rErr := s.Range(kind, address, metric, from, to)
+	if rErr != nil {
+		return nil, rErr
+	}
+
+	return aggregate(points, step, aggregation)
+}
+
+/*
+aggregate groups the ordered points by step and aggregates every group into a single point
+*/
+func aggregate(points []Point, step time.Duration, aggregation string) ([]Point, error) {
+	combine, isKnown := aggregations[aggregation]
+	if !isKnown {
+		return nil, errors.New("unknown aggregation " + aggregation)
+	}
+
+	downsampled := []Point{}
+
+	for start := 0; start < len(points); {
+		bucket := points[start].Time.Truncate(step)
+		end := start
+
+		for end < len(points) && points[end].Time.Truncate(step).Equal(bucket) {
+			end++
+		}
+
+		downsampled = append(downsampled, Point{Time: bucket, Value: combine(points[start:end])})
+		start = end
+	}
+
+	return downsampled, nil
+}
+
+var aggregations = map[string]func(points []Point) float64{
+	AggregateAvg: func(points []Point) float64 {
+		sum := 0.0
+		for _, point := range points {
+			sum += point.Value
+		}
+
+		return sum / float64(len(points))
+	},
+	AggregateMin: func(points []Point) float64 {
+		minimum := points[0].Value
+		for _, point := range points {
+			minimum = math.Min(minimum, point.Value)
+		}
+
+		return minimum
+	},
+	AggregateMax: func(points []Point) float64 {
+		maximum := points[0].Value
+		for _, point := range points {
+			maximum = math.Max(maximum, point.Value)
+		}
+
+		return maximum
+	},
+	AggregateLast: func(points []Point) float64 {
+		return points[len(points)-1].Value
+	},
+}
+
+/*
+Metrics returns the metrics stored for the entity, sorted by name
+*/
+func (s *Store) Metrics(kind metadata.Kind, address string) ([]string, error) {
+	prefix := string(seriesKey(kind, address, ""))
+	metrics := []string{}
+
+	vErr := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(seriesBucket).ForEachBucket(func(key []byte) error {
+			if strings.HasPrefix(string(key), prefix) {
+				metrics = append(metrics, strings.TrimPrefix(string(key), prefix))
+			}
+
+			return nil
+		})
+	})
+
+	sort.Strings(metrics)
+
+	return metrics, vErr
+}
+
+/*
+Run applies the retention and the compaction every CheckInterval until the context is cancelled
+*/
+func (s *Store) Run(ctx context.Context) {
+	if s == nil || s.policy.CheckInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(s.policy.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.Maintain(time.Now())
+		}
+	}
+}
+
+/*
+Maintain deletes the points out of the retention and compacts the old points
+*/
+func (s *Store) Maintain(now time.Time) {
+	removed, rErr := s.ApplyRetention(now)
+	if rErr != nil {
+		logger.LogW("Error while applying the snapshot retention: ", rErr)
+	}
+
+	compacted, cErr := s.Compact(now)
+	if cErr != nil {
+		logger.LogW("Error while compacting the snapshots: ", cErr)
+	}
+
+	if removed > 0 || compacted > 0 {
+		logger.LogI("Snapshot maintenance removed ", removed, " and compacted ", compacted, " points")
+	}
+}
+
+/*
+ApplyRetention deletes the points older than the Retention, empty series are removed. Returns the number of deleted
+points
+*/
+func (s *Store) ApplyRetention(now time.Time) (int, error) {
+	if s.policy.Retention <= 0 {
+		return 0, nil
+	}
+
+	cutoff := encodeTime(now.Add(-s.policy.Retention))
+	removed := 0
+
+	uErr := s.db.Update(func(tx *bolt.Tx) error {
+		root := tx.Bucket(seriesBucket)
+		emptied := [][]byte{}
+
+		fErr := root.ForEachBucket(func(key []byte) error {
+			series := root.Bucket(key)
+			cursor := series.Cursor()
+
+			for pointKey, _ := cursor.First(); pointKey != nil && string(pointKey) < string(cutoff); pointKey, _ = cursor.First() {
+				if dErr := cursor.Delete(); dErr != nil {
+					return dErr
+				}
+
+				removed++
+			}
+
+			if first, _ := cursor.First(); first == nil {
+				emptied = append(emptied, append([]byte{}, key...))
+			}
+
+			return nil
+		})
+		if fErr != nil {
+			return fErr
+		}
+
+		for _, key := range emptied {
+			if dErr := root.DeleteBucket(key); dErr != nil {
+				return dErr
+			}
+		}
+
+		return nil
+	})
+
+	return removed, uErr
+}
+
+/*
+Compact replaces the points older than CompactAfter with their average per CompactResolution. Only the steps that are
+entirely older than CompactAfter are compacted, so a step is compacted once. Returns the number of removed points
+*/
+func (s *Store) Compact(now time.Time) (int, error) {
+	if s.policy.CompactAfter <= 0 || s.policy.CompactResolution <= 0 {
+		return 0, nil
+	}
+
+	cutoff := now.Add(-s.policy.CompactAfter).Truncate(s.policy.CompactResolution)
+	compacted := 0
+
+	uErr := s.db.Update(func(tx *bolt.Tx) error {
+		root := tx.Bucket(seriesBucket)
+
+		return root.ForEachBucket(func(key []byte) error {
+			series := root.Bucket(key)
+			old := []Point{}
+
+			cursor := series.Cursor()
+			for pointKey, value := cursor.First(); pointKey != nil; pointKey, value = cursor.Next() {
+				at := decodeTime(pointKey)
+				if !at.Before(cutoff) {
+					break
+				}
+
+				old = append(old, Point{Time: at, Value: decodeValue(value)})
+			}
+
+			averaged, _ := aggregate(old, s.policy.CompactResolution, AggregateAvg)
+			if len(averaged) == len(old) {
+				return nil
+			}
+
+			for _, point := range old {
+				if dErr := series.Delete(encodeTime(point.Time)); dErr != nil {
+					return dErr
+				}
+			}
+
+			for _, point := range averaged {
+				if pErr := series.Put(encodeTime(point.Time), encodeValue(point.Value)); pErr != nil {
+					return pErr
+				}
+			}
+
+			compacted += len(old) - len(averaged)
+
+			return nil
+		})
+	})
+
+	return compacted, uErr
+}
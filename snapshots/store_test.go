@@ -0,0 +1,180 @@
+package snapshots
+
+import (
+	"eigenlayer_hack/metadata"
+	"eigenlayer_hack/structs"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+var start = time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+func openTestStore(t *testing.T, path string, policy Policy) *Store {
+	t.Helper()
+
+	if path == "" {
+		path = filepath.Join(t.TempDir(), "snapshots.db")
+	}
+
+	store, oErr := Open(path, policy)
+	if oErr != nil {
+		t.Fatal(oErr)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+
+	return store
+}
+
+/*
+recordHourly stores the values as hourly points of the series, starting at start
+*/
+func recordHourly(t *testing.T, store *Store, metric string, values ...float64) {
+	t.Helper()
+
+	for index, value := range values {
+		at := start.Add(time.Duration(index) * time.Hour)
+
+		if rErr := store.Record(metadata.KindAVS, "0xAVS", metric, at, value); rErr != nil {
+			t.Fatal(rErr)
+		}
+	}
+}
+
+func TestStoreKeepsTheStatsOverRestarts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snapshots.db")
+	store := openTestStore(t, path, Policy{})
+
+	row, dErr := structs.DecodeRow(map[string]json.RawMessage{
+		"avs_name":    json.RawMessage(`"EigenDA"`),
+		"total_TVL":   json.RawMessage(`1250.5`),
+		"num_stakers": json.RawMessage(`42`),
+	}, map[string]string{"avs_name": "varchar", "total_TVL": "double", "num_stakers": "bigint"})
+	if dErr != nil {
+		t.Fatal(dErr)
+	}
+
+	if rErr := store.RecordStats(metadata.KindAVS, "0xAVS", row, start); rErr != nil {
+		t.Fatal(rErr)
+	}
+
+	if rErr := store.Record(metadata.KindAVS, "0xavs", "total_TVL", start.Add(time.Hour), 1300); rErr != nil {
+		t.Fatal(rErr)
+	}
+
+	if cErr := store.Close(); cErr != nil {
+		t.Fatal(cErr)
+	}
+
+	reopened := openTestStore(t, path, Policy{})
+
+	// Only the numeric columns are series, the addresses are case-insensitive
+	metrics, mErr := reopened.Metrics(metadata.KindAVS, "0xavs")
+	if mErr != nil || len(metrics) != 2 || metrics[0] != "num_stakers" || metrics[1] != "total_TVL" {
+		t.Fatalf("unexpected metrics %v (%v)", metrics, mErr)
+	}
+
+	points, rErr := reopened.Range(metadata.KindAVS, "0xAVS", "total_TVL", start, start.Add(time.Hour))
+	if rErr != nil || len(points) != 2 || points[0].Value != 1250.5 || points[1].Value != 1300 {
+		t.Fatalf("unexpected points %+v (%v)", points, rErr)
+	}
+
+	latest, isFound, lErr := reopened.Latest(metadata.KindAVS, "0xavs", "num_stakers")
+	if lErr != nil || !isFound || latest.Value != 42 || !latest.Time.Equal(start) {
+		t.Fatalf("unexpected latest point %+v %v (%v)", latest, isFound, lErr)
+	}
+
+	if _, isFound, _ = reopened.Latest(metadata.KindOperator, "0xavs", "num_stakers"); isFound {
+		t.Fatal("expected the series of another kind to be empty")
+	}
+}
+
+func TestStoreDownsamplesTheSeries(t *testing.T) {
+	store := openTestStore(t, "", Policy{})
+	recordHourly(t, store, "total_TVL", 1, 2, 3, 10, 20)
+
+	tests := []struct {
+		aggregation string
+		expected    []float64
+	}{
+		{aggregation: AggregateAvg, expected: []float64{2, 15}},
+		{aggregation: AggregateMin, expected: []float64{1, 10}},
+		{aggregation: AggregateMax, expected: []float64{3, 20}},
+		{aggregation: AggregateLast, expected: []float64{3, 20}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.aggregation, func(t *testing.T) {
+			points, dErr := store.Downsample(metadata.KindAVS, "0xavs", "total_TVL", start, start.Add(24*time.Hour),
+				3*time.Hour, test.aggregation)
+			if dErr != nil {
+				t.Fatal(dErr)
+			}
+
+			if len(points) != len(test.expected) {
+				t.Fatalf("expected %d points, got %+v", len(test.expected), points)
+			}
+
+			for index, point := range points {
+				if point.Value != test.expected[index] || !point.Time.Equal(start.Add(time.Duration(index)*3*time.Hour)) {
+					t.Fatalf("unexpected point %d: %+v", index, point)
+				}
+			}
+		})
+	}
+
+	if _, dErr := store.Downsample(metadata.KindAVS, "0xavs", "total_TVL", start, start, time.Hour, "median"); dErr == nil {
+		t.Fatal("expected the unknown aggregation to be rejected")
+	}
+}
+
+func TestStoreAppliesTheRetention(t *testing.T) {
+	store := openTestStore(t, "", Policy{Retention: 2 * time.Hour})
+	recordHourly(t, store, "total_TVL", 1, 2, 3, 4)
+	recordHourly(t, store, "num_stakers", 5)
+
+	removed, rErr := store.ApplyRetention(start.Add(4 * time.Hour))
+	if rErr != nil || removed != 3 {
+		t.Fatalf("expected 3 removed points, got %d (%v)", removed, rErr)
+	}
+
+	points, _ := store.Range(metadata.KindAVS, "0xavs", "total_TVL", start, start.Add(24*time.Hour))
+	if len(points) != 2 || points[0].Value != 3 {
+		t.Fatalf("expected the points of the last 2 hours, got %+v", points)
+	}
+
+	// The emptied series are removed
+	if metrics, _ := store.Metrics(metadata.KindAVS, "0xavs"); len(metrics) != 1 || metrics[0] != "total_TVL" {
+		t.Fatalf("expected the empty series to be removed, got %v", metrics)
+	}
+}
+
+func TestStoreCompactsTheOldPointsOnce(t *testing.T) {
+	store := openTestStore(t, "", Policy{CompactAfter: 6 * time.Hour, CompactResolution: 3 * time.Hour})
+	recordHourly(t, store, "total_TVL", 1, 2, 3, 10, 20, 30, 7, 8, 9)
+
+	now := start.Add(12 * time.Hour)
+
+	compacted, cErr := store.Compact(now)
+	if cErr != nil || compacted != 4 {
+		t.Fatalf("expected 4 compacted points, got %d (%v)", compacted, cErr)
+	}
+
+	points, _ := store.Range(metadata.KindAVS, "0xavs", "total_TVL", start, now)
+	expected := []float64{2, 20, 7, 8, 9}
+
+	if len(points) != len(expected) {
+		t.Fatalf("expected the averaged steps and the recent points, got %+v", points)
+	}
+
+	for index, point := range points {
+		if point.Value != expected[index] {
+			t.Fatalf("expected %v, got %+v", expected, points)
+		}
+	}
+
+	if compacted, cErr = store.Compact(now); cErr != nil || compacted != 0 {
+		t.Fatalf("expected the compacted steps to be left alone, got %d (%v)", compacted, cErr)
+	}
+}
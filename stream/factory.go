@@ -0,0 +1,66 @@
+package stream
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"time"
+)
+
+/*
+SourceConfig holds the settings used by NewSource
+*/
+type SourceConfig struct {
+	// Kind is one of kafka, file, stdin, webhook
+	Kind string
+	// Location is the file path for file, the listen address for webhook
+	Location string
+	// Channels are the kafka topics to subscribe, the first one is the default channel of the line based sources
+	Channels []string
+	KafkaURI string
+	GroupID  string
+	// ProcessTimeout is the time a webhook request waits for its message to be processed
+	ProcessTimeout time.Duration
+	// MaxLineSize is the longest line of the file and stdin sources, 0 uses DefaultMaxLineSize
+	MaxLineSize int
+}
+
+/*
+NewSource creates the source described by the config
+*/
+func NewSource(config SourceConfig) (Source, error) {
+	defaultChannel := ""
+
+	if len(config.Channels) > 0 {
+		defaultChannel = config.Channels[0]
+	}
+
+	switch strings.ToLower(config.Kind) {
+	case "", "kafka":
+		return NewKafkaSource(config.KafkaURI, config.Channels, config.GroupID)
+	case "file":
+		return NewFileSource(config.Location, defaultChannel, config.MaxLineSize)
+	case "stdin":
+		return NewLineSource(os.Stdin, defaultChannel, config.MaxLineSize), nil
+	case "webhook":
+		return NewWebhookSource(config.Location, config.ProcessTimeout), nil
+	default:
+		return nil, errors.New("unknown source kind: " + config.Kind)
+	}
+}
+
+/*
+NewSink creates the sink of the given kind: kafka (location is unused), file (location is the path) or stdout
+*/
+func NewSink(kind string, location string, kafkaURI string, flushTimeout time.Duration) (Sink, error) {
+	switch strings.ToLower(kind) {
+	case "", "kafka":
+		return NewKafkaSink(kafkaURI, flushTimeout)
+	case "file":
+		return NewFileSink(location)
+	case "stdout":
+		return NewLineSink(os.Stdout), nil
+	default:
+		return nil, errors.New("unknown sink kind: " + kind)
+	}
+}
@@ -0,0 +1,105 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"solity/utils/logger"
+	"sync/atomic"
+	"time"
+)
+
+/*
+WebhookSource receives the messages over HTTP: POST /ingest/{channel} with the message value as the body. The request
+is answered once the message is committed, so the callers can retry on failure (at-least-once delivery)
+*/
+type WebhookSource struct {
+	server         *http.Server
+	messages       chan *Message
+	processTimeout time.Duration
+	sequence       int64
+}
+
+/*
+NewWebhookSource starts the webhook http server on the given address, processTimeout is the time a request waits for
+the processing of its message
+*/
+func NewWebhookSource(addr string, processTimeout time.Duration) *WebhookSource {
+	wS := &WebhookSource{messages: make(chan *Message), processTimeout: processTimeout}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /ingest/{channel}", wS.handleIngest)
+
+	wS.server = &http.Server{Addr: addr, Handler: mux}
+
+	go func() {
+		logger.LogI("Webhook source is listening on ", addr)
+
+		if err := wS.server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.LogW("Webhook server stopped: ", err)
+		}
+	}()
+
+	return wS
+}
+
+func (wS *WebhookSource) handleIngest(w http.ResponseWriter, r *http.Request) {
+	body, rErr := io.ReadAll(http.MaxBytesReader(w, r.Body, 16*1024*1024))
+
+	if rErr != nil {
+		http.Error(w, rErr.Error(), http.StatusBadRequest)
+		return
+	}
+
+	msg := NewMessage(r.PathValue("channel"), body)
+	msg.Offset = atomic.AddInt64(&wS.sequence, 1)
+	msg.ack = make(chan struct{})
+
+	for key, values := range r.Header {
+		if len(values) > 0 {
+			msg.Headers[key] = values[0]
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), wS.processTimeout)
+	defer cancel()
+
+	select {
+	case wS.messages <- msg:
+	case <-ctx.Done():
+		http.Error(w, "message could not be queued", http.StatusServiceUnavailable)
+		return
+	}
+
+	select {
+	case <-msg.ack:
+		w.WriteHeader(http.StatusAccepted)
+	case <-ctx.Done():
+		http.Error(w, "message is not processed in time", http.StatusServiceUnavailable)
+	}
+}
+
+func (wS *WebhookSource) Read(ctx context.Context) (*Message, error) {
+	select {
+	case msg := <-wS.messages:
+		return msg, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (wS *WebhookSource) Commit(msg *Message) error {
+	if msg.ack != nil {
+		close(msg.ack)
+	}
+
+	return nil
+}
+
+func (wS *WebhookSource) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return wS.server.Shutdown(ctx)
+}
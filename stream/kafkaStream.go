@@ -0,0 +1,261 @@
+package stream
+
+import (
+	"context"
+	"eigenlayer_hack/consumer"
+	"encoding/json"
+	"errors"
+	"github.com/confluentinc/confluent-kafka-go/kafka"
+	"solity/utils/logger"
+	"time"
+)
+
+/*
+KafkaSource reads the messages of the subscribed topics. Offsets are committed only when every message before them has
+been committed as well, so the messages that are not fully processed are redelivered after a restart
+*/
+type KafkaSource struct {
+	consumer      *kafka.Consumer
+	offsetTracker *consumer.OffsetTracker
+	// buffered are the messages received by Poll, they are returned by the next Reads
+	buffered []*kafka.Message
+}
+
+/*
+NewKafkaSource creates a kafka consumer with manual commits subscribed to the given topics
+*/
+func NewKafkaSource(kafkaURI string, topics []string, groupID string) (*KafkaSource, error) {
+	c, cErr := consumer.NewManualCommitConsumer(kafkaURI, topics, groupID)
+
+	if cErr != nil {
+		return nil, cErr
+	}
+
+	return &KafkaSource{consumer: c, offsetTracker: consumer.NewOffsetTracker()}, nil
+}
+
+func (kS *KafkaSource) Read(ctx context.Context) (*Message, error) {
+	if len(kS.buffered) > 0 {
+		msg := kS.buffered[0]
+		kS.buffered = kS.buffered[1:]
+
+		return kS.message(msg), nil
+	}
+
+	for ctx.Err() == nil {
+		// A finite timeout is used so that the context is noticed
+		msg, mErr := kS.consumer.ReadMessage(time.Second)
+
+		if mErr != nil {
+			if kErr, isKafkaErr := mErr.(kafka.Error); isKafkaErr && kErr.Code() == kafka.ErrTimedOut {
+				continue
+			}
+
+			// The client will automatically try to recover from all errors.
+			logger.LogWf("Consumer error: %v (%v)\n", mErr, msg)
+			continue
+		}
+
+		return kS.message(msg), nil
+	}
+
+	return nil, ctx.Err()
+}
+
+/*
+message tracks the offset of the read kafka message and converts it
+*/
+func (kS *KafkaSource) message(msg *kafka.Message) *Message {
+	kS.offsetTracker.Track(msg.TopicPartition)
+
+	ret := &Message{
+		Channel:   *msg.TopicPartition.Topic,
+		Key:       msg.Key,
+		Value:     msg.Value,
+		Headers:   map[string]string{},
+		Partition: msg.TopicPartition.Partition,
+		Offset:    int64(msg.TopicPartition.Offset),
+	}
+
+	for _, header := range msg.Headers {
+		ret.Headers[header.Key] = string(header.Value)
+	}
+
+	return ret
+}
+
+func (kS *KafkaSource) Commit(msg *Message) error {
+	channel := msg.Channel
+
+	toCommit, shouldCommit := kS.offsetTracker.Done(kafka.TopicPartition{
+		Topic:     &channel,
+		Partition: msg.Partition,
+		Offset:    kafka.Offset(msg.Offset),
+	})
+
+	if !shouldCommit {
+		return nil
+	}
+
+	_, cErr := kS.consumer.CommitOffsets([]kafka.TopicPartition{toCommit})
+
+	return cErr
+}
+
+/*
+Pending returns the number of read messages that are not committed yet
+*/
+func (kS *KafkaSource) Pending() int {
+	return kS.offsetTracker.Pending()
+}
+
+func (kS *KafkaSource) Pause() error {
+	assigned, aErr := kS.consumer.Assignment()
+
+	if aErr != nil {
+		return aErr
+	}
+
+	return kS.consumer.Pause(assigned)
+}
+
+/*
+Poll serves the paused consumer, so that it keeps its group membership and max.poll.interval.ms is not exceeded. The
+partitions assigned by a rebalance while paused are not paused, their messages are kept for the next Reads and the
+new assignment is paused as well
+*/
+func (kS *KafkaSource) Poll(timeout time.Duration) error {
+	switch event := kS.consumer.Poll(int(timeout.Milliseconds())).(type) {
+	case *kafka.Message:
+		if event.TopicPartition.Error != nil {
+			return event.TopicPartition.Error
+		}
+
+		kS.buffered = append(kS.buffered, event)
+
+		return kS.Pause()
+	case kafka.Error:
+		return event
+	}
+
+	return nil
+}
+
+func (kS *KafkaSource) Resume() error {
+	assigned, aErr := kS.consumer.Assignment()
+
+	if aErr != nil {
+		return aErr
+	}
+
+	return kS.consumer.Resume(assigned)
+}
+
+func (kS *KafkaSource) Close() error {
+	return kS.consumer.Close()
+}
+
+// solitySource is the producer name of the published solity messages
+const solitySource = "TRCK-Eigenlayer"
+
+/*
+KafkaSink produces the messages to the kafka topics, payloads are sent in the solity message format
+*/
+type KafkaSink struct {
+	producer     *kafka.Producer
+	flushTimeout time.Duration
+}
+
+/*
+NewKafkaSink creates a kafka producer, flushTimeout is the time waited for the pending messages on Close
+*/
+func NewKafkaSink(kafkaURI string, flushTimeout time.Duration) (*KafkaSink, error) {
+	p, pErr := consumer.NewProducer(kafkaURI)
+
+	if pErr != nil {
+		return nil, pErr
+	}
+
+	return &KafkaSink{producer: p, flushTimeout: flushTimeout}, nil
+}
+
+func (kS *KafkaSink) Send(ctx context.Context, msg *Message) error {
+	channel := msg.Channel
+	headers := []kafka.Header{}
+
+	for key, value := range msg.Headers {
+		headers = append(headers, kafka.Header{Key: key, Value: []byte(value)})
+	}
+
+	deliveryChan := make(chan kafka.Event, 1)
+
+	pErr := kS.producer.Produce(&kafka.Message{
+		TopicPartition: kafka.TopicPartition{Topic: &channel, Partition: kafka.PartitionAny},
+		Key:            msg.Key,
+		Value:          msg.Value,
+		Headers:        headers,
+	}, deliveryChan)
+
+	if pErr != nil {
+		return pErr
+	}
+
+	// Wait for the delivery
+	select {
+	case event := <-deliveryChan:
+		delivered, isMessage := event.(*kafka.Message)
+
+		if !isMessage {
+			return errors.New("unexpected delivery event: " + event.String())
+		}
+
+		return delivered.TopicPartition.Error
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+/*
+solityMessage is the envelope of the payloads published to kafka, e.g.
+{"source":"TRCK-Eigenlayer","timestamp":1718000000,"payload":{...}}: the producer name, the unix time of the
+publication in seconds and the payload as JSON. It is written here instead of with solity's
+ConvertAndSendSolityMessageSingleClient, which produces without reporting the delivery. The consumers of the output
+topics read these three fields, so they must not be renamed
+*/
+type solityMessage struct {
+	Source    string      `json:"source"`
+	Timestamp int64       `json:"timestamp"`
+	Payload   interface{} `json:"payload"`
+}
+
+/*
+encodeSolityMessage returns the kafka value of the payload published at the given time
+*/
+func encodeSolityMessage(payload interface{}, publishedAt time.Time) ([]byte, error) {
+	return json.Marshal(solityMessage{Source: solitySource, Timestamp: publishedAt.Unix(), Payload: payload})
+}
+
+/*
+Publish wraps the payload in the solity envelope and sends it, it returns once the message is delivered so a failed
+delivery fails the processing of the event instead of being lost
+*/
+func (kS *KafkaSink) Publish(ctx context.Context, channel string, payload interface{}) error {
+	value, mErr := encodeSolityMessage(payload, time.Now())
+
+	if mErr != nil {
+		return mErr
+	}
+
+	return kS.Send(ctx, NewMessage(channel, value))
+}
+
+func (kS *KafkaSink) Close() error {
+	// Make sure that every produced message is delivered before closing the producer
+	if remaining := kS.producer.Flush(int(kS.flushTimeout.Milliseconds())); remaining > 0 {
+		logger.LogW(remaining, " produced messages could not be delivered before the shutdown")
+	}
+
+	kS.producer.Close()
+
+	return nil
+}
@@ -0,0 +1,54 @@
+package stream
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestEncodeSolityMessage(t *testing.T) {
+	type registration struct {
+		AvsName         string `json:"avsName"`
+		OperatorAddress string `json:"operatorAddress"`
+	}
+
+	publishedAt := time.Unix(1718000000, 500)
+
+	value, eErr := encodeSolityMessage(registration{AvsName: "EigenDA", OperatorAddress: "0xb0"}, publishedAt)
+	if eErr != nil {
+		t.Fatal(eErr)
+	}
+
+	// The consumers of the output topics depend on this exact layout
+	expected := `{"source":"TRCK-Eigenlayer","timestamp":1718000000,` +
+		`"payload":{"avsName":"EigenDA","operatorAddress":"0xb0"}}`
+	if string(value) != expected {
+		t.Fatalf("expected %s, got %s", expected, value)
+	}
+
+	// Read the way a consumer does, the payload is kept as the handler produced it
+	var consumed struct {
+		Source    string          `json:"source"`
+		Timestamp int64           `json:"timestamp"`
+		Payload   json.RawMessage `json:"payload"`
+	}
+
+	if uErr := json.Unmarshal(value, &consumed); uErr != nil {
+		t.Fatal(uErr)
+	}
+
+	var payload registration
+	if uErr := json.Unmarshal(consumed.Payload, &payload); uErr != nil {
+		t.Fatal(uErr)
+	}
+
+	if consumed.Source != solitySource || consumed.Timestamp != publishedAt.Unix() || payload.AvsName != "EigenDA" {
+		t.Fatalf("unexpected consumed message %+v %+v", consumed, payload)
+	}
+}
+
+func TestEncodeSolityMessageRejectsAnUnencodablePayload(t *testing.T) {
+	if _, eErr := encodeSolityMessage(map[string]interface{}{"channel": make(chan int)}, time.Now()); eErr == nil {
+		t.Fatal("expected the payload to be rejected")
+	}
+}
@@ -0,0 +1,235 @@
+package stream
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// DefaultMaxLineSize is the longest line the line sources read when no limit is configured
+const DefaultMaxLineSize = 16 * 1024 * 1024
+
+/*
+lineRecord is the JSON lines representation of a message. Value is embedded as JSON if it is valid JSON, else as a
+string
+*/
+type lineRecord struct {
+	Channel   string            `json:"channel"`
+	Key       string            `json:"key,omitempty"`
+	Headers   map[string]string `json:"headers,omitempty"`
+	Partition int32             `json:"partition"`
+	Offset    int64             `json:"offset"`
+	Value     json.RawMessage   `json:"value"`
+}
+
+/*
+LineSource reads one message per line from a reader (JSON lines file, stdin). A line is either a lineRecord written by
+a LineSink, or the raw message value which is then assigned to the default channel. The line number is used as the
+offset of the message. A line that cannot be read, e.g. one longer than the maximum line size, fails the source
+*/
+type LineSource struct {
+	mu             sync.Mutex
+	scanner        *bufio.Scanner
+	closer         io.Closer
+	defaultChannel string
+	lineNumber     int64
+	// err is the read error that failed the source, the scanner is not used after it
+	err error
+}
+
+/*
+NewLineSource creates a LineSource reading lines of up to maxLineSize bytes from the given reader, a maxLineSize <= 0
+uses DefaultMaxLineSize
+*/
+func NewLineSource(reader io.Reader, defaultChannel string, maxLineSize int) *LineSource {
+	if maxLineSize <= 0 {
+		maxLineSize = DefaultMaxLineSize
+	}
+
+	scanner := bufio.NewScanner(reader)
+	// Transaction messages with receipts can be large
+	scanner.Buffer(make([]byte, 0, min(64*1024, maxLineSize)), maxLineSize)
+
+	lS := &LineSource{scanner: scanner, defaultChannel: defaultChannel}
+
+	if closer, isCloser := reader.(io.Closer); isCloser {
+		lS.closer = closer
+	}
+
+	return lS
+}
+
+/*
+NewFileSource creates a LineSource that replays the JSON lines file at the given path
+*/
+func NewFileSource(path string, defaultChannel string, maxLineSize int) (*LineSource, error) {
+	file, oErr := os.Open(path)
+
+	if oErr != nil {
+		return nil, oErr
+	}
+
+	return NewLineSource(file, defaultChannel, maxLineSize), nil
+}
+
+func (lS *LineSource) Read(ctx context.Context) (*Message, error) {
+	lS.mu.Lock()
+	defer lS.mu.Unlock()
+
+	if lS.err != nil {
+		return nil, lS.err
+	}
+
+	for ctx.Err() == nil {
+		if !lS.scanner.Scan() {
+			// The rest of a line that could not be read must not be delivered as a message of its own
+			if sErr := lS.scanner.Err(); sErr != nil {
+				lS.err = fmt.Errorf("%w: line %d: %v", ErrFailed, lS.lineNumber+1, sErr)
+				return nil, lS.err
+			}
+
+			return nil, ErrClosed
+		}
+
+		line := lS.scanner.Bytes()
+		lS.lineNumber++
+
+		// Skip the empty lines
+		if len(line) == 0 {
+			continue
+		}
+
+		msg := NewMessage(lS.defaultChannel, nil)
+		msg.Offset = lS.lineNumber
+
+		record := lineRecord{}
+
+		if uErr := json.Unmarshal(line, &record); uErr == nil && record.Channel != "" && len(record.Value) > 0 {
+			msg.Channel = record.Channel
+			msg.Key = []byte(record.Key)
+			msg.Partition = record.Partition
+			msg.Offset = record.Offset
+
+			if record.Headers != nil {
+				msg.Headers = record.Headers
+			}
+
+			// String values were written as JSON strings
+			var stringValue string
+			if json.Unmarshal(record.Value, &stringValue) == nil {
+				msg.Value = []byte(stringValue)
+			} else {
+				msg.Value = append([]byte{}, record.Value...)
+			}
+
+			return msg, nil
+		}
+
+		msg.Value = append([]byte{}, line...)
+
+		return msg, nil
+	}
+
+	return nil, ctx.Err()
+}
+
+/*
+Commit is a no-op, the lines are not re-read
+*/
+func (lS *LineSource) Commit(_ *Message) error {
+	return nil
+}
+
+func (lS *LineSource) Close() error {
+	if lS.closer != nil {
+		return lS.closer.Close()
+	}
+
+	return nil
+}
+
+/*
+LineSink writes every message as a lineRecord JSON line to a writer (JSON lines file, stdout)
+*/
+type LineSink struct {
+	mu     sync.Mutex
+	writer io.Writer
+	closer io.Closer
+}
+
+/*
+NewLineSink creates a LineSink writing to the given writer
+*/
+func NewLineSink(writer io.Writer) *LineSink {
+	lS := &LineSink{writer: writer}
+
+	if closer, isCloser := writer.(io.Closer); isCloser && writer != os.Stdout && writer != os.Stderr {
+		lS.closer = closer
+	}
+
+	return lS
+}
+
+/*
+NewFileSink creates a LineSink appending to the file at the given path
+*/
+func NewFileSink(path string) (*LineSink, error) {
+	file, oErr := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+
+	if oErr != nil {
+		return nil, oErr
+	}
+
+	return NewLineSink(file), nil
+}
+
+func (lS *LineSink) Send(_ context.Context, msg *Message) error {
+	record := lineRecord{
+		Channel:   msg.Channel,
+		Key:       string(msg.Key),
+		Headers:   msg.Headers,
+		Partition: msg.Partition,
+		Offset:    msg.Offset,
+	}
+
+	if json.Valid(msg.Value) {
+		record.Value = msg.Value
+	} else {
+		stringValue, mErr := json.Marshal(string(msg.Value))
+
+		if mErr != nil {
+			return mErr
+		}
+
+		record.Value = stringValue
+	}
+
+	line, mErr := json.Marshal(record)
+
+	if mErr != nil {
+		return mErr
+	}
+
+	lS.mu.Lock()
+	defer lS.mu.Unlock()
+
+	_, wErr := lS.writer.Write(append(line, '\n'))
+
+	return wErr
+}
+
+func (lS *LineSink) Publish(ctx context.Context, channel string, payload interface{}) error {
+	return publishAsJSON(ctx, lS, channel, payload)
+}
+
+func (lS *LineSink) Close() error {
+	if lS.closer != nil {
+		return lS.closer.Close()
+	}
+
+	return nil
+}
@@ -0,0 +1,90 @@
+package stream
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestLineSourceReadsTheRecordsAndTheRawLines(t *testing.T) {
+	var buffer bytes.Buffer
+
+	sink := NewLineSink(&buffer)
+
+	written := &Message{Channel: "transactions", Key: []byte("key"), Value: []byte(`{"hash":"0x1"}`),
+		Headers: map[string]string{"attempt": "2"}, Partition: 3, Offset: 42}
+	if sErr := sink.Send(context.Background(), written); sErr != nil {
+		t.Fatal(sErr)
+	}
+
+	buffer.WriteString("\n" + `{"raw":true}` + "\n")
+
+	source := NewLineSource(&buffer, "default", 0)
+	ctx := context.Background()
+
+	record, rErr := source.Read(ctx)
+	if rErr != nil {
+		t.Fatal(rErr)
+	}
+
+	if record.Channel != "transactions" || string(record.Key) != "key" || string(record.Value) != `{"hash":"0x1"}` ||
+		record.Headers["attempt"] != "2" || record.Partition != 3 || record.Offset != 42 {
+		t.Fatalf("unexpected message of the record %+v", record)
+	}
+
+	// The empty line is skipped, the line number is the offset of the raw line
+	raw, rErr := source.Read(ctx)
+	if rErr != nil {
+		t.Fatal(rErr)
+	}
+
+	if raw.Channel != "default" || string(raw.Value) != `{"raw":true}` || raw.Offset != 3 {
+		t.Fatalf("unexpected message of the raw line %+v", raw)
+	}
+
+	if _, rErr = source.Read(ctx); !errors.Is(rErr, ErrClosed) {
+		t.Fatal("expected the exhausted source, got ", rErr)
+	}
+}
+
+func TestLineSourceReadsTheLinesUpToTheMaxLineSize(t *testing.T) {
+	// Longer than the initial 64 KiB buffer of the scanner
+	long := `{"value":"` + strings.Repeat("a", 100*1024) + `"}`
+
+	tests := []struct {
+		name        string
+		maxLineSize int
+		isFailed    bool
+	}{
+		{name: "default limit", maxLineSize: 0},
+		{name: "larger limit", maxLineSize: 128 * 1024},
+		{name: "smaller limit", maxLineSize: 64 * 1024, isFailed: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			source := NewLineSource(strings.NewReader(long+"\n"+`{"next":true}`+"\n"), "default", test.maxLineSize)
+
+			msg, rErr := source.Read(context.Background())
+
+			if !test.isFailed {
+				if rErr != nil || string(msg.Value) != long {
+					t.Fatalf("expected the long line, got %v", rErr)
+				}
+
+				return
+			}
+
+			// A failed source stops the pipeline instead of being read again and again
+			if !errors.Is(rErr, ErrFailed) || !strings.Contains(rErr.Error(), "line 1") {
+				t.Fatal("expected the source to fail on the long line, got ", rErr)
+			}
+
+			if _, rErr = source.Read(context.Background()); !errors.Is(rErr, ErrFailed) {
+				t.Fatal("expected the source to stay failed, got ", rErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package stream
+
+import (
+	"context"
+	"sync"
+)
+
+/*
+MemorySource delivers the messages pushed to it, it is used to run the pipeline without a broker
+*/
+type MemorySource struct {
+	messages  chan *Message
+	closeOnce sync.Once
+	mu        sync.Mutex
+	committed []*Message
+}
+
+/*
+NewMemorySource creates a MemorySource buffering up to bufferSize messages
+*/
+func NewMemorySource(bufferSize int) *MemorySource {
+	return &MemorySource{messages: make(chan *Message, bufferSize)}
+}
+
+/*
+Push adds the message to the source, blocks if the buffer is full
+*/
+func (mS *MemorySource) Push(ctx context.Context, msg *Message) error {
+	select {
+	case mS.messages <- msg:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (mS *MemorySource) Read(ctx context.Context) (*Message, error) {
+	select {
+	case msg, isOk := <-mS.messages:
+		if !isOk {
+			return nil, ErrClosed
+		}
+
+		return msg, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (mS *MemorySource) Commit(msg *Message) error {
+	mS.mu.Lock()
+	defer mS.mu.Unlock()
+
+	mS.committed = append(mS.committed, msg)
+
+	return nil
+}
+
+/*
+Committed returns the committed messages in commit order
+*/
+func (mS *MemorySource) Committed() []*Message {
+	mS.mu.Lock()
+	defer mS.mu.Unlock()
+
+	return append([]*Message{}, mS.committed...)
+}
+
+/*
+Close stops accepting new messages, Read returns ErrClosed once the buffered messages are read
+*/
+func (mS *MemorySource) Close() error {
+	mS.closeOnce.Do(func() {
+		close(mS.messages)
+	})
+
+	return nil
+}
+
+/*
+MemorySink keeps the sent messages in memory
+*/
+type MemorySink struct {
+	mu       sync.Mutex
+	messages []*Message
+}
+
+/*
+NewMemorySink constructor for the MemorySink object
+*/
+func NewMemorySink() *MemorySink {
+	return &MemorySink{}
+}
+
+func (mS *MemorySink) Send(_ context.Context, msg *Message) error {
+	mS.mu.Lock()
+	defer mS.mu.Unlock()
+
+	mS.messages = append(mS.messages, msg)
+
+	return nil
+}
+
+func (mS *MemorySink) Publish(ctx context.Context, channel string, payload interface{}) error {
+	return publishAsJSON(ctx, mS, channel, payload)
+}
+
+/*
+Messages returns the sent messages of the given channel, every message if channel is empty
+*/
+func (mS *MemorySink) Messages(channel string) []*Message {
+	mS.mu.Lock()
+	defer mS.mu.Unlock()
+
+	ret := []*Message{}
+
+	for _, msg := range mS.messages {
+		if channel == "" || msg.Channel == channel {
+			ret = append(ret, msg)
+		}
+	}
+
+	return ret
+}
+
+func (mS *MemorySink) Close() error {
+	return nil
+}
@@ -0,0 +1,116 @@
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+// ErrClosed is returned by the sources when there are no more messages to read (e.g. end of the replayed file)
+var ErrClosed = errors.New("source is closed")
+
+// ErrFailed is returned by the sources that cannot deliver the next message because of a permanent error
+var ErrFailed = errors.New("source has failed")
+
+/*
+Message is a single message read from a Source or sent to a Sink. Partition and Offset describe the position of the
+message in its source, they are -1 when the source has no such notion
+*/
+type Message struct {
+	Channel   string
+	Key       []byte
+	Value     []byte
+	Headers   map[string]string
+	Partition int32
+	Offset    int64
+
+	// Source specific acknowledgement data
+	ack chan struct{}
+}
+
+/*
+Source delivers the messages to be processed. Every message that is read has to be committed once its processing is
+completed, sources that support it (e.g. kafka) redeliver the uncommitted messages after a restart
+*/
+type Source interface {
+	/*
+		Read blocks until a message is available, the context is done or the source is exhausted (ErrClosed)
+	*/
+	Read(ctx context.Context) (*Message, error)
+	/*
+		Commit marks the message as fully processed
+	*/
+	Commit(msg *Message) error
+	/*
+		Close releases the resources of the source
+	*/
+	Close() error
+}
+
+/*
+Pausable is implemented by the sources that can stop fetching new messages temporarily, it is used for backpressure.
+While paused the source has to be polled, so that it keeps its connection (e.g. the kafka group membership) alive
+*/
+type Pausable interface {
+	Pause() error
+	Resume() error
+	/*
+		Poll serves the paused source for up to the timeout without delivering messages
+	*/
+	Poll(timeout time.Duration) error
+}
+
+/*
+Sink receives the produced messages
+*/
+type Sink interface {
+	/*
+		Send delivers the raw message to its channel and returns once it is delivered
+	*/
+	Send(ctx context.Context, msg *Message) error
+	/*
+		Publish delivers the payload to the given channel in the sink's message format
+	*/
+	Publish(ctx context.Context, channel string, payload interface{}) error
+	/*
+		Close flushes the pending messages and releases the resources of the sink
+	*/
+	Close() error
+}
+
+/*
+NewMessage creates a message without a source position
+*/
+func NewMessage(channel string, value []byte) *Message {
+	return &Message{Channel: channel, Value: value, Headers: map[string]string{}, Partition: -1, Offset: -1}
+}
+
+/*
+publishAsJSON is the Publish implementation of the sinks that do not have a message envelope of their own
+*/
+func publishAsJSON(ctx context.Context, sink Sink, channel string, payload interface{}) error {
+	value, mErr := json.Marshal(payload)
+
+	if mErr != nil {
+		return mErr
+	}
+
+	return sink.Send(ctx, NewMessage(channel, value))
+}
+
+/*
+SplitChannels splits a colon separated channel list, empty entries are dropped
+*/
+func SplitChannels(channels string) []string {
+	ret := []string{}
+
+	for _, channel := range strings.Split(channels, ":") {
+		if channel != "" {
+			ret = append(ret, channel)
+		}
+	}
+
+	return ret
+}
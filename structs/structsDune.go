@@ -0,0 +1,91 @@
+package structs
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+type Metadata struct {
+	ColumnNames         []string `json:"column_names"`
+	ColumnTypes         []string `json:"column_types"`
+	RowCount            int      `json:"row_count"`
+	ResultSetBytes      int      `json:"result_set_bytes"`
+	TotalRowCount       int      `json:"total_row_count"`
+	TotalResultSetBytes int      `json:"total_result_set_bytes"`
+	DatapointCount      int      `json:"datapoint_count"`
+	PendingTimeMillis   int      `json:"pending_time_millis"`
+	ExecutionTimeMillis int      `json:"execution_time_millis"`
+}
+
+type ExecutionResult struct {
+	Rows     []Row    `json:"rows"`
+	Metadata Metadata `json:"metadata"`
+}
+
+/*
+Response is a page of Dune results. The rows are decoded with the column types of the result metadata, so new columns
+are kept instead of being dropped
+*/
+type Response struct {
+	ExecutionID         string          `json:"execution_id"`
+	QueryID             int             `json:"query_id"`
+	IsExecutionFinished bool            `json:"is_execution_finished"`
+	State               string          `json:"state"`
+	SubmittedAt         time.Time       `json:"submitted_at"`
+	ExpiresAt           time.Time       `json:"expires_at"`
+	ExecutionStartedAt  time.Time       `json:"execution_started_at"`
+	ExecutionEndedAt    time.Time       `json:"execution_ended_at"`
+	Result              ExecutionResult `json:"result"`
+	NextURI             string          `json:"next_uri"`
+	NextOffset          int             `json:"next_offset"`
+}
+
+/*
+UnmarshalJSON decodes the response, the rows are typed after the metadata is read
+*/
+func (r *Response) UnmarshalJSON(data []byte) error {
+	type plainResponse Response
+
+	page := struct {
+		*plainResponse
+		Result struct {
+			Rows     []map[string]json.RawMessage `json:"rows"`
+			Metadata Metadata                     `json:"metadata"`
+		} `json:"result"`
+	}{plainResponse: (*plainResponse)(r)}
+
+	if uErr := json.Unmarshal(data, &page); uErr != nil {
+		return uErr
+	}
+
+	r.Result.Metadata = page.Result.Metadata
+	columnTypes := r.Result.Metadata.ColumnTypeMap()
+	r.Result.Rows = make([]Row, 0, len(page.Result.Rows))
+
+	for index, raw := range page.Result.Rows {
+		row, dErr := DecodeRow(raw, columnTypes)
+		if dErr != nil {
+			return fmt.Errorf("row %d: %w", index, dErr)
+		}
+
+		r.Result.Rows = append(r.Result.Rows, row)
+	}
+
+	return nil
+}
+
+/*
+ColumnTypeMap returns the Dune type of every column by name
+*/
+func (m Metadata) ColumnTypeMap() map[string]string {
+	columnTypes := map[string]string{}
+
+	for index, column := range m.ColumnNames {
+		if index < len(m.ColumnTypes) {
+			columnTypes[column] = m.ColumnTypes[index]
+		}
+	}
+
+	return columnTypes
+}
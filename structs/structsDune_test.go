@@ -0,0 +1,100 @@
+package structs
+
+import (
+	"encoding/json"
+	"math/big"
+	"reflect"
+	"testing"
+)
+
+// A page of the results endpoint as returned by the Dune API, the metadata is nested in the result
+const duneResultsPage = `{
+	"execution_id": "01HZ8Y3KQ7G5V3C2P8N4R6T9WX",
+	"query_id": 3685341,
+	"is_execution_finished": true,
+	"state": "QUERY_STATE_COMPLETED",
+	"submitted_at": "2024-05-01T12:00:00.123456Z",
+	"expires_at": "2024-07-30T12:00:01.456789Z",
+	"execution_started_at": "2024-05-01T12:00:00.234567Z",
+	"execution_ended_at": "2024-05-01T12:00:01.456789Z",
+	"result": {
+		"rows": [
+			{
+				"avs_name": "EigenDA",
+				"avs_contract_address": "0x870679e138bcdf293b7ff14dd44b70fc97e12fc0",
+				"num_stakers": 112345,
+				"num_operators": 231,
+				"total_TVL": "4182736.25",
+				"stETH_TVL": 1523456.5,
+				"total_staked": "1234567890123456789012345678"
+			}
+		],
+		"metadata": {
+			"column_names": ["avs_name", "avs_contract_address", "num_stakers", "num_operators", "total_TVL",
+				"stETH_TVL", "total_staked"],
+			"column_types": ["varchar", "varchar", "bigint", "bigint", "double", "double", "uint256"],
+			"row_count": 1,
+			"result_set_bytes": 212,
+			"total_row_count": 1,
+			"total_result_set_bytes": 212,
+			"datapoint_count": 7,
+			"pending_time_millis": 111,
+			"execution_time_millis": 1222
+		}
+	},
+	"next_uri": "https://api.dune.com/api/v1/execution/01HZ8Y3KQ7G5V3C2P8N4R6T9WX/results?offset=1&limit=1",
+	"next_offset": 1
+}`
+
+func TestResponseDecodesTheRowsWithTheResultMetadata(t *testing.T) {
+	var response Response
+	if uErr := json.Unmarshal([]byte(duneResultsPage), &response); uErr != nil {
+		t.Fatal(uErr)
+	}
+
+	if response.ExecutionID != "01HZ8Y3KQ7G5V3C2P8N4R6T9WX" || response.State != "QUERY_STATE_COMPLETED" ||
+		!response.IsExecutionFinished || response.NextOffset != 1 {
+		t.Fatalf("unexpected response %+v", response)
+	}
+
+	metadata := response.Result.Metadata
+	if metadata.RowCount != 1 || metadata.DatapointCount != 7 || len(metadata.ColumnTypes) != 7 ||
+		!reflect.DeepEqual(metadata.ColumnNames[:2], []string{"avs_name", "avs_contract_address"}) {
+		t.Fatalf("unexpected result metadata %+v", metadata)
+	}
+
+	if len(response.Result.Rows) != 1 {
+		t.Fatalf("expected 1 row, got %d", len(response.Result.Rows))
+	}
+
+	row := response.Result.Rows[0]
+
+	// The bigint columns are integers and the double columns are floats, even when Dune sends them as strings
+	if numStakers, isInt := row.Values["num_stakers"].(int64); !isInt || numStakers != 112345 {
+		t.Fatalf("expected the bigint column as int64, got %#v", row.Values["num_stakers"])
+	}
+
+	if totalTVL, isFloat := row.Values["total_TVL"].(float64); !isFloat || totalTVL != 4182736.25 {
+		t.Fatalf("expected the double column as float64, got %#v", row.Values["total_TVL"])
+	}
+
+	staked, isBig := row.Values["total_staked"].(*big.Int)
+	if !isBig || staked.String() != "1234567890123456789012345678" {
+		t.Fatalf("expected the uint256 column as an exact integer, got %#v", row.Values["total_staked"])
+	}
+
+	if row.AVSName() != "EigenDA" || row.NumOperators() != 231 || row.TotalTVL() != 4182736.25 ||
+		row.TVL["stETH"] != 1523456.5 {
+		t.Fatalf("unexpected typed accessors %+v", row.Values)
+	}
+}
+
+func TestResponseRejectsTheRowsThatDoNotMatchTheColumnTypes(t *testing.T) {
+	page := `{"result": {"rows": [{"num_stakers": 1}, {"num_stakers": "many"}],
+		"metadata": {"column_names": ["num_stakers"], "column_types": ["bigint"]}}}`
+
+	var response Response
+	if uErr := json.Unmarshal([]byte(page), &response); uErr == nil {
+		t.Fatal("expected the second row to be rejected")
+	}
+}
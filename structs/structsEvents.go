@@ -0,0 +1,55 @@
+package structs
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"math/big"
+)
+
+// Event types of the published payloads
+const (
+	EventOperatorAVSRegistration = "OperatorAVSRegistrationStatusUpdated"
+	EventOperatorRegistered      = "OperatorRegistered"
+	EventStakerDelegated         = "StakerDelegated"
+	EventStakerUndelegated       = "StakerUndelegated"
+	EventDeposit                 = "Deposit"
+)
+
+type EventMeta struct {
+	EventType   string
+	TxHash      common.Hash
+	BlockNumber uint64
+	LogIndex    uint
+}
+
+type OperatorAVSRegistrationPayload struct {
+	EventMeta
+	OperatorAddress common.Address
+	OperatorName    string
+	AvsAddress      common.Address
+	AvsName         string
+	// 0: unregistered, 1: registered
+	Status uint8
+}
+
+type OperatorRegisteredPayload struct {
+	EventMeta
+	OperatorAddress          common.Address
+	OperatorName             string
+	EarningsReceiver         common.Address
+	DelegationApprover       common.Address
+	StakerOptOutWindowBlocks uint32
+}
+
+type StakerDelegationPayload struct {
+	EventMeta
+	StakerAddress   common.Address
+	OperatorAddress common.Address
+}
+
+type DepositPayload struct {
+	EventMeta
+	StakerAddress   common.Address
+	TokenAddress    common.Address
+	StrategyAddress common.Address
+	Shares          *big.Int
+}
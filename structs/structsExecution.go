@@ -0,0 +1,58 @@
+package structs
+
+import "time"
+
+// Dune execution states
+const (
+	QueryStatePending          = "QUERY_STATE_PENDING"
+	QueryStateExecuting        = "QUERY_STATE_EXECUTING"
+	QueryStateCompleted        = "QUERY_STATE_COMPLETED"
+	QueryStateCompletedPartial = "QUERY_STATE_COMPLETED_PARTIAL"
+	QueryStateFailed           = "QUERY_STATE_FAILED"
+	QueryStateCancelled        = "QUERY_STATE_CANCELLED"
+	QueryStateExpired          = "QUERY_STATE_EXPIRED"
+)
+
+type ExecuteQueryRequest struct {
+	QueryParameters map[string]interface{} `json:"query_parameters,omitempty"`
+	Performance     string                 `json:"performance,omitempty"`
+}
+
+type Execution struct {
+	ExecutionID string `json:"execution_id"`
+	State       string `json:"state"`
+}
+
+type ExecutionError struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
+
+type ExecutionStatus struct {
+	ExecutionID         string          `json:"execution_id"`
+	QueryID             int             `json:"query_id"`
+	State               string          `json:"state"`
+	IsExecutionFinished bool            `json:"is_execution_finished"`
+	SubmittedAt         time.Time       `json:"submitted_at"`
+	ExpiresAt           time.Time       `json:"expires_at"`
+	ExecutionStartedAt  time.Time       `json:"execution_started_at"`
+	ExecutionEndedAt    time.Time       `json:"execution_ended_at"`
+	ResultMetadata      Metadata        `json:"result_metadata"`
+	Error               *ExecutionError `json:"error,omitempty"`
+}
+
+type CancelResponse struct {
+	Success bool `json:"success"`
+}
+
+/*
+IsTerminalState returns true if the execution in the given state will not change anymore
+*/
+func IsTerminalState(state string) bool {
+	switch state {
+	case QueryStateCompleted, QueryStateCompletedPartial, QueryStateFailed, QueryStateCancelled, QueryStateExpired:
+		return true
+	}
+
+	return false
+}
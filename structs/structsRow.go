@@ -0,0 +1,392 @@
+package structs
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Columns of the Dune EigenLayer stats that have typed accessors
+const (
+	ColumnOperatorName            = "operator_name"
+	ColumnOperatorContractAddress = "operator_contract_address"
+	ColumnAVSName                 = "avs_name"
+	ColumnAVSContractAddress      = "avs_contract_address"
+	ColumnNumStakers              = "num_stakers"
+	ColumnNumOperators            = "num_operators"
+	ColumnTotalTVL                = "total_TVL"
+
+	// Every other column with this suffix is the TVL of the strategy in front of it, e.g. stETH_TVL
+	tvlSuffix = "_TVL"
+)
+
+// Kinds the Dune column types are decoded into
+const (
+	ColumnKindString    = "string"
+	ColumnKindInt       = "int"
+	ColumnKindFloat     = "float"
+	ColumnKindBool      = "bool"
+	ColumnKindTimestamp = "timestamp"
+)
+
+var knownColumns = map[string]bool{
+	ColumnOperatorName:            true,
+	ColumnOperatorContractAddress: true,
+	ColumnAVSName:                 true,
+	ColumnAVSContractAddress:      true,
+	ColumnNumStakers:              true,
+	ColumnNumOperators:            true,
+	ColumnTotalTVL:                true,
+}
+
+// int, integer, bigint, uint256, int128, ...
+var integerType = regexp.MustCompile(`^(u?int(eger|\d+)?|tinyint|smallint|bigint)$`)
+
+// Layouts of the Dune timestamp values
+var timestampLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999 MST",
+	"2006-01-02 15:04:05.999",
+	"2006-01-02 15:04:05 MST",
+	"2006-01-02",
+}
+
+/*
+Row is a Dune result row decoded with the column types of the result metadata. Values holds every column, numbers as
+int64 or float64 (*big.Int when they do not fit), timestamps as time.Time. The strategy TVL columns are collected in
+TVL by strategy name, so strategies added by Dune are kept without a code change
+*/
+type Row struct {
+	Values map[string]interface{}
+	TVL    map[string]float64
+}
+
+/*
+ColumnKind returns the kind a Dune column type is decoded into, e.g. "double" -> float, "decimal(38,0)" -> float,
+"bigint" -> int. Unknown types are kept as strings
+*/
+func ColumnKind(columnType string) string {
+	columnType = strings.ToLower(strings.TrimSpace(columnType))
+
+	switch {
+	case columnType == "boolean":
+		return ColumnKindBool
+	case columnType == "double" || columnType == "real" || strings.HasPrefix(columnType, "decimal"):
+		return ColumnKindFloat
+	case integerType.MatchString(columnType):
+		return ColumnKindInt
+	case strings.HasPrefix(columnType, "timestamp") || columnType == "date":
+		return ColumnKindTimestamp
+	}
+
+	return ColumnKindString
+}
+
+/*
+DecodeRow decodes the raw columns of a row. columnTypes maps the column names to their Dune types, the kind of the
+columns without a type is taken from the JSON value
+*/
+func DecodeRow(raw map[string]json.RawMessage, columnTypes map[string]string) (Row, error) {
+	row := Row{Values: map[string]interface{}{}, TVL: map[string]float64{}}
+
+	for column, rawValue := range raw {
+		value, dErr := decodeValue(rawValue, columnTypes[column])
+		if dErr != nil {
+			return Row{}, fmt.Errorf("column %s: %w", column, dErr)
+		}
+
+		row.Values[column] = value
+
+		if strategy, isTVL := tvlStrategy(column); isTVL {
+			if tvl, isNumber := toFloat(value); isNumber {
+				row.TVL[strategy] = tvl
+			}
+		}
+	}
+
+	return row, nil
+}
+
+/*
+tvlStrategy returns the strategy of a per strategy TVL column. total_TVL is not a strategy
+*/
+func tvlStrategy(column string) (string, bool) {
+	if column == ColumnTotalTVL || !strings.HasSuffix(column, tvlSuffix) || len(column) == len(tvlSuffix) {
+		return "", false
+	}
+
+	return strings.TrimSuffix(column, tvlSuffix), true
+}
+
+/*
+decodeValue decodes a single value into the kind of its column type
+*/
+func decodeValue(rawValue json.RawMessage, columnType string) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(rawValue))
+	decoder.UseNumber()
+
+	var value interface{}
+
+	if dErr := decoder.Decode(&value); dErr != nil {
+		return nil, dErr
+	}
+
+	if value == nil {
+		return nil, nil
+	}
+
+	kind := ColumnKindString
+	if columnType != "" {
+		kind = ColumnKind(columnType)
+	} else if _, isNumber := value.(json.Number); isNumber {
+		kind = ColumnKindFloat
+	}
+
+	switch kind {
+	case ColumnKindInt:
+		return decodeInt(value)
+	case ColumnKindFloat:
+		return decodeFloat(value)
+	case ColumnKindBool:
+		if flag, isBool := value.(bool); isBool {
+			return flag, nil
+		}
+
+		return strconv.ParseBool(fmt.Sprint(value))
+	case ColumnKindTimestamp:
+		return decodeTimestamp(value)
+	}
+
+	// Strings, and values of the types that are not decoded (arrays, maps, varbinary) are preserved
+	if number, isNumber := value.(json.Number); isNumber {
+		return number.String(), nil
+	}
+
+	return value, nil
+}
+
+/*
+decodeInt decodes an integer column, large values (e.g. uint256 amounts) are returned as *big.Int
+*/
+func decodeInt(value interface{}) (interface{}, error) {
+	text := fmt.Sprint(value)
+
+	if integer, pErr := strconv.ParseInt(text, 10, 64); pErr == nil {
+		return integer, nil
+	}
+
+	if integer, isOk := new(big.Int).SetString(text, 10); isOk {
+		return integer, nil
+	}
+
+	return nil, fmt.Errorf("invalid integer %s", text)
+}
+
+/*
+decodeFloat decodes a floating point or decimal column. Dune sends them as numbers or as strings
+*/
+func decodeFloat(value interface{}) (interface{}, error) {
+	float, pErr := strconv.ParseFloat(fmt.Sprint(value), 64)
+	if pErr != nil {
+		return nil, fmt.Errorf("invalid number %v", value)
+	}
+
+	return float, nil
+}
+
+/*
+decodeTimestamp decodes a timestamp column, values in an unknown layout are kept as strings
+*/
+func decodeTimestamp(value interface{}) (interface{}, error) {
+	text, isString := value.(string)
+	if !isString {
+		return nil, fmt.Errorf("invalid timestamp %v", value)
+	}
+
+	for _, layout := range timestampLayouts {
+		if timestamp, pErr := time.Parse(layout, text); pErr == nil {
+			return timestamp, nil
+		}
+	}
+
+	return text, nil
+}
+
+/*
+toFloat converts a decoded numeric value to float64
+*/
+func toFloat(value interface{}) (float64, bool) {
+	switch number := value.(type) {
+	case float64:
+		return number, true
+	case int64:
+		return float64(number), true
+	case *big.Int:
+		float, _ := new(big.Float).SetInt(number).Float64()
+		return float, true
+	}
+
+	return 0, false
+}
+
+/*
+String returns the value of a column as string, empty if the column is missing
+*/
+func (r Row) String(column string) string {
+	value, isOk := r.Values[column]
+	if !isOk || value == nil {
+		return ""
+	}
+
+	if text, isString := value.(string); isString {
+		return text
+	}
+
+	return fmt.Sprint(value)
+}
+
+/*
+Float returns the value of a numeric column, false if the column is missing or is not a number
+*/
+func (r Row) Float(column string) (float64, bool) {
+	return toFloat(r.Values[column])
+}
+
+/*
+Int returns the value of an integer column, false if the column is missing or is not an integer
+*/
+func (r Row) Int(column string) (int64, bool) {
+	switch number := r.Values[column].(type) {
+	case int64:
+		return number, true
+	case float64:
+		// Columns without a type are decoded as floats
+		if number == float64(int64(number)) {
+			return int64(number), true
+		}
+	}
+
+	return 0, false
+}
+
+func (r Row) OperatorName() string {
+	return r.String(ColumnOperatorName)
+}
+
+func (r Row) OperatorContractAddress() string {
+	return r.String(ColumnOperatorContractAddress)
+}
+
+func (r Row) AVSName() string {
+	return r.String(ColumnAVSName)
+}
+
+func (r Row) AVSContractAddress() string {
+	return r.String(ColumnAVSContractAddress)
+}
+
+func (r Row) NumStakers() int64 {
+	count, _ := r.Int(ColumnNumStakers)
+	return count
+}
+
+func (r Row) NumOperators() int64 {
+	count, _ := r.Int(ColumnNumOperators)
+	return count
+}
+
+func (r Row) TotalTVL() float64 {
+	tvl, _ := r.Float(ColumnTotalTVL)
+	return tvl
+}
+
+/*
+Strategies returns the strategies with a TVL column, sorted by name
+*/
+func (r Row) Strategies() []string {
+	strategies := make([]string, 0, len(r.TVL))
+
+	for strategy := range r.TVL {
+		strategies = append(strategies, strategy)
+	}
+
+	sort.Strings(strategies)
+
+	return strategies
+}
+
+/*
+Numbers returns the numeric columns of the row as float64 by column name
+*/
+func (r Row) Numbers() map[string]float64 {
+	numbers := map[string]float64{}
+
+	for column, value := range r.Values {
+		if number, isNumber := toFloat(value); isNumber {
+			numbers[column] = number
+		}
+	}
+
+	return numbers
+}
+
+/*
+Unknown returns the columns that have neither a typed accessor nor are a strategy TVL
+*/
+func (r Row) Unknown() map[string]interface{} {
+	unknown := map[string]interface{}{}
+
+	for column, value := range r.Values {
+		if _, isTVL := tvlStrategy(column); knownColumns[column] || isTVL {
+			continue
+		}
+
+		unknown[column] = value
+	}
+
+	return unknown
+}
+
+/*
+MarshalJSON writes the row back in the Dune format, a column per key
+*/
+func (r Row) MarshalJSON() ([]byte, error) {
+	values := map[string]interface{}{}
+
+	for column, value := range r.Values {
+		if number, isBig := value.(*big.Int); isBig {
+			// Kept exact instead of being rounded by the JSON readers
+			value = json.Number(number.String())
+		}
+
+		values[column] = value
+	}
+
+	return json.Marshal(values)
+}
+
+/*
+UnmarshalJSON decodes a row without the result metadata, the column kinds are taken from the JSON values
+*/
+func (r *Row) UnmarshalJSON(data []byte) error {
+	raw := map[string]json.RawMessage{}
+
+	if uErr := json.Unmarshal(data, &raw); uErr != nil {
+		return uErr
+	}
+
+	row, dErr := DecodeRow(raw, nil)
+	if dErr != nil {
+		return dErr
+	}
+
+	*r = row
+
+	return nil
+}
@@ -0,0 +1,142 @@
+package structs
+
+import (
+	"encoding/json"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestColumnKind(t *testing.T) {
+	tests := map[string]string{
+		"varchar":           ColumnKindString,
+		"bigint":            ColumnKindInt,
+		"uint256":           ColumnKindInt,
+		"integer":           ColumnKindInt,
+		"double":            ColumnKindFloat,
+		"decimal(38,0)":     ColumnKindFloat,
+		"boolean":           ColumnKindBool,
+		"timestamp(3)":      ColumnKindTimestamp,
+		"date":              ColumnKindTimestamp,
+		"array(varchar)":    ColumnKindString,
+		" BIGINT ":          ColumnKindInt,
+		"timestamp with tz": ColumnKindTimestamp,
+	}
+
+	for columnType, expected := range tests {
+		t.Run(columnType, func(t *testing.T) {
+			if kind := ColumnKind(columnType); kind != expected {
+				t.Fatalf("expected %s, got %s", expected, kind)
+			}
+		})
+	}
+}
+
+func rawRow(t *testing.T, row string) map[string]json.RawMessage {
+	t.Helper()
+
+	raw := map[string]json.RawMessage{}
+	if uErr := json.Unmarshal([]byte(row), &raw); uErr != nil {
+		t.Fatal(uErr)
+	}
+
+	return raw
+}
+
+func TestDecodeRowTypesTheColumns(t *testing.T) {
+	raw := rawRow(t, `{"operator_name": "P2P", "operator_contract_address": "0xabc", "num_stakers": 42,
+		"total_TVL": "1234.5", "stETH_TVL": 1000.25, "rETH_TVL": 234, "staked": "123456789012345678901234567890",
+		"updated_at": "2024-05-01 12:00:00.000 UTC", "is_active": true, "region": "eu", "comment": null}`)
+
+	columnTypes := map[string]string{
+		"operator_name":             "varchar",
+		"operator_contract_address": "varchar",
+		"num_stakers":               "bigint",
+		"total_TVL":                 "double",
+		"stETH_TVL":                 "double",
+		"rETH_TVL":                  "decimal(38,0)",
+		"staked":                    "uint256",
+		"updated_at":                "timestamp(3) with time zone",
+		"is_active":                 "boolean",
+	}
+
+	row, dErr := DecodeRow(raw, columnTypes)
+	if dErr != nil {
+		t.Fatal(dErr)
+	}
+
+	if row.OperatorName() != "P2P" || row.OperatorContractAddress() != "0xabc" || row.NumStakers() != 42 ||
+		row.TotalTVL() != 1234.5 {
+		t.Fatalf("unexpected typed columns %+v", row.Values)
+	}
+
+	if strategies := row.Strategies(); !reflect.DeepEqual(strategies, []string{"rETH", "stETH"}) {
+		t.Fatalf("expected the rETH and stETH strategies, got %v", strategies)
+	}
+
+	if row.TVL["stETH"] != 1000.25 || row.TVL["rETH"] != 234 {
+		t.Fatalf("unexpected strategy TVL %v", row.TVL)
+	}
+
+	staked, isBig := row.Values["staked"].(*big.Int)
+	if !isBig || staked.String() != "123456789012345678901234567890" {
+		t.Fatalf("expected the exact large integer, got %#v", row.Values["staked"])
+	}
+
+	updatedAt, isTime := row.Values["updated_at"].(time.Time)
+	if !isTime || !updatedAt.Equal(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected the timestamp, got %#v", row.Values["updated_at"])
+	}
+
+	// The columns without a typed accessor are kept
+	unknown := row.Unknown()
+	if len(unknown) != 5 || unknown["region"] != "eu" || unknown["is_active"] != true || unknown["comment"] != nil {
+		t.Fatalf("unexpected unknown columns %v", unknown)
+	}
+}
+
+func TestDecodeRowRejectsInvalidValues(t *testing.T) {
+	tests := map[string]string{
+		"bigint":    `{"value": "many"}`,
+		"double":    `{"value": "much"}`,
+		"timestamp": `{"value": 12}`,
+		"boolean":   `{"value": "maybe"}`,
+	}
+
+	for columnType, row := range tests {
+		t.Run(columnType, func(t *testing.T) {
+			if _, dErr := DecodeRow(rawRow(t, row), map[string]string{"value": columnType}); dErr == nil {
+				t.Fatal("expected the invalid value to be rejected")
+			}
+		})
+	}
+}
+
+func TestRowKeepsTheLargeIntegersOverJSON(t *testing.T) {
+	row, dErr := DecodeRow(rawRow(t, `{"staked": "123456789012345678901234567890", "num_stakers": 7}`),
+		map[string]string{"staked": "uint256", "num_stakers": "bigint"})
+	if dErr != nil {
+		t.Fatal(dErr)
+	}
+
+	encoded, mErr := json.Marshal(row)
+	if mErr != nil {
+		t.Fatal(mErr)
+	}
+
+	if !strings.Contains(string(encoded), `"staked":123456789012345678901234567890`) {
+		t.Fatalf("expected the exact integer in %s", encoded)
+	}
+
+	// Read back without the metadata, the kinds come from the JSON values
+	var decoded Row
+	if uErr := json.Unmarshal(encoded, &decoded); uErr != nil {
+		t.Fatal(uErr)
+	}
+
+	if decoded.NumStakers() != 7 {
+		t.Fatalf("unexpected row after the round trip %v", decoded.Values)
+	}
+}
@@ -0,0 +1,721 @@
+package main
+
+import (
+	"context"
+	"eigenlayer_hack/config"
+	"eigenlayer_hack/consumer"
+	"eigenlayer_hack/deadletter"
+	"eigenlayer_hack/dedup"
+	"eigenlayer_hack/deferred"
+	"eigenlayer_hack/handlers"
+	"eigenlayer_hack/ingest"
+	"eigenlayer_hack/metadata"
+	"eigenlayer_hack/metrics"
+	"eigenlayer_hack/registryindex"
+	"eigenlayer_hack/reorg"
+	"eigenlayer_hack/scoring"
+	"eigenlayer_hack/snapshots"
+	"eigenlayer_hack/stream"
+	"eigenlayer_hack/utils"
+	"encoding/json"
+	"errors"
+	"github.com/ethereum/go-ethereum/common"
+	"os"
+	"os/signal"
+	"solity/schemas"
+	"solity/utils/logger"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+/*
+runTracker consumes the transaction messages of the listen channels, processes them and publishes the results to the
+output channels until a shutdown signal is received
+*/
+func runTracker(cfg config.Config) {
+	rErr := runPipeline(cfg, livePipeline, func(registry *handlers.Registry) (stream.Source, error) {
+		if cfg.Source.Type == "chain" {
+			// Logs are read directly from the node instead of the navigator messages
+			return newChainSource(cfg, registry)
+		}
+
+		// Offsets are committed manually after the processing of a message is completed
+		return stream.NewSource(stream.SourceConfig{
+			Kind:           cfg.Source.Type,
+			Location:       cfg.Source.Location,
+			Channels:       cfg.Kafka.ListenChannels,
+			KafkaURI:       cfg.Kafka.URI,
+			GroupID:        cfg.Kafka.GroupID,
+			ProcessTimeout: cfg.Limits.ShutdownTimeout,
+			MaxLineSize:    cfg.Source.MaxLineSize,
+		})
+	})
+	if rErr != nil {
+		logger.LogE("The tracker has stopped: ", rErr)
+	}
+}
+
+// pipelineKind selects the services that run next to the processing of the messages
+type pipelineKind int
+
+const (
+	// livePipeline runs the metrics endpoint, the reorg monitor, the scoring and the reconciliation as well
+	livePipeline pipelineKind = iota
+	// backfillPipeline only processes the messages, the services of the live tracker are left to it
+	backfillPipeline
+)
+
+/*
+runPipeline builds the pipeline of the configuration around the source created by newSource, and runs it until a
+shutdown signal is received or the source is exhausted. Returns the error of a failed source
+*/
+func runPipeline(cfg config.Config, kind pipelineKind,
+	newSource func(registry *handlers.Registry) (stream.Source, error)) error {
+	isLive := kind == livePipeline
+
+	listenChannels := cfg.Kafka.ListenChannels
+	logger.LogI("Currently listening ", listenChannels)
+
+	outputChannels := cfg.Kafka.OutputChannels
+	logger.LogI("Kafka out channel ", outputChannels)
+	if len(outputChannels) != len(listenChannels) {
+		logger.LogE("Kafka listen channels and output channels length miss-match")
+	}
+	//Listen Channels
+	channelMapping := map[string]string{}
+
+	for i, listenChannel := range listenChannels {
+		channelMapping[listenChannel] = outputChannels[i]
+	}
+
+	deadLetterMapping := parseDeadLetterChannels(cfg.Kafka.DeadLetterChannels, listenChannels)
+
+	shutdownTimeout := cfg.Limits.ShutdownTimeout
+
+	registry := newHandlerRegistry(cfg.Handlers)
+
+	source, err := newSource(registry)
+	if err != nil {
+		logger.LogE("Error while initializing the source: ", err)
+	}
+
+	sink, err := stream.NewSink(cfg.Sink.Type, cfg.Sink.Location, cfg.Kafka.URI, shutdownTimeout)
+	if err != nil {
+		logger.LogE("Error while initializing the sink: ", err)
+	}
+
+	defer source.Close()
+	defer sink.Close()
+
+	dedupStore, err := dedup.NewStore(cfg.Dedup.Store, cfg.Dedup.Location, cfg.Dedup.Password, cfg.Dedup.Retention)
+	if err != nil {
+		logger.LogE("Error while initializing the dedup store: ", err)
+	}
+	defer dedupStore.Close()
+
+	if isLive {
+		metrics.Serve(cfg.Metrics.Addr)
+	}
+
+	logger.LogI("Worker pool size: ", cfg.Limits.WorkerCount, " queue size per worker: ", cfg.Limits.WorkerQueueSize)
+
+	// Cancelled on SIGINT/SIGTERM, stops the reading of new messages
+	shutdownCtx, stopSignals := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stopSignals()
+
+	snapshotStore := newSnapshotStore(cfg.Snapshots)
+	defer snapshotStore.Close()
+	go snapshotStore.Run(shutdownCtx)
+
+	if snapshotStore != nil {
+		metrics.Handle("/snapshots", snapshotStore.Handler())
+	}
+
+	// Confirmed registry writes are journaled and reconciled with the entries read back from the registry
+	registryIndex := newRegistryIndex(cfg.Index)
+	defer registryIndex.Close()
+
+	// Long-lived, so that the nonces of the concurrent registry writes are assigned by a single transaction manager. A
+	// backfill next to the tracker sends from the same account, the nonces used by the other process are reloaded.
+	// Closed before the registry index, which journals the writes of the last batches
+	registryWriter, err := utils.NewRegistryWriterFromConfig(cfg)
+	if err != nil {
+		logger.LogE("Error while initializing the registry writer: ", err)
+	}
+	defer registryWriter.Close()
+
+	var reconciler *registryindex.Reconciler
+	var registryReader *utils.RegistryReader
+
+	if isLive {
+		reconciler, registryReader = newReconciler(cfg, registryIndex, registryWriter)
+	}
+	defer registryReader.Close()
+	go reconciler.Run(shutdownCtx, cfg.Index.ReconcileInterval)
+
+	if reconciler != nil {
+		metrics.Handle("/registry/reconciliation", reconciler.Handler())
+	}
+
+	metadataResolver := newMetadataResolver(cfg, snapshotStore)
+	defer metadataResolver.Close()
+
+	// Events with unknown names are re-attempted from this queue, it survives restarts
+	deferredQueue, err := deferred.NewQueue(cfg.Deferred.QueueFile, deferred.Schedule{
+		MinDelay:      cfg.Deferred.MinDelay,
+		MaxDelay:      cfg.Deferred.MaxDelay,
+		MaxAge:        cfg.Deferred.MaxAge,
+		CheckInterval: cfg.Deferred.CheckInterval,
+	})
+	if err != nil {
+		logger.LogE("Error while loading the deferred enrichment queue: ", err)
+	}
+
+	var reorgMonitor *reorg.Monitor
+	var scoringEngine *scoring.Engine
+
+	if isLive {
+		reorgMonitor = newReorgMonitor(cfg, sink, dedupStore)
+		scoringEngine = newScoringEngine(cfg, sink, metadataResolver, snapshotStore)
+	}
+
+	go reorgMonitor.Run(shutdownCtx, cfg.Reorg.CheckInterval)
+	go scoringEngine.Run(shutdownCtx, cfg.Scoring.Interval)
+
+	tracker := &Tracker{
+		Source:         source,
+		Sink:           sink,
+		ChannelMapping: channelMapping,
+		// Failed messages are forwarded to the dead-letter channels together with the failure metadata
+		DeadLetter:      deadletter.NewPublisher(sink, deadLetterMapping),
+		DedupStore:      dedupStore,
+		ClaimTTL:        cfg.Dedup.ClaimTTL,
+		ReorgMonitor:    reorgMonitor,
+		Registry:        registry,
+		Metadata:        metadataResolver,
+		Deferred:        deferredQueue,
+		Scoring:         scoringEngine,
+		RegistryWriter:  registryWriter,
+		RegistryIndex:   registryIndex,
+		WorkerCount:     cfg.Limits.WorkerCount,
+		QueueSize:       cfg.Limits.WorkerQueueSize,
+		ShutdownTimeout: shutdownTimeout,
+	}
+
+	return tracker.Run(shutdownCtx)
+}
+
+/*
+Tracker is the processing pipeline: it reads the transaction messages from the Source, processes them on a worker pool
+and publishes the results to the Sink. It depends only on the stream interfaces, so it can run on kafka as well as on
+files or in memory
+*/
+type Tracker struct {
+	Source stream.Source
+	Sink   stream.Sink
+	// listen channel -> output channel
+	ChannelMapping  map[string]string
+	DeadLetter      *deadletter.Publisher
+	DedupStore      dedup.Store
+	ClaimTTL        time.Duration
+	ReorgMonitor    *reorg.Monitor
+	Metadata        *metadata.CachingResolver
+	Deferred        *deferred.Queue
+	Scoring         *scoring.Engine
+	RegistryWriter  *utils.RegistryWriter
+	RegistryIndex   *registryindex.Store
+	Registry        *handlers.Registry
+	WorkerCount     int
+	QueueSize       int
+	ShutdownTimeout time.Duration
+}
+
+/*
+Run processes the messages until the context is cancelled or the source is exhausted, then waits for the in-flight
+messages up to the ShutdownTimeout. Returns the error of a failed source (stream.ErrFailed), after the same drain
+*/
+func (t *Tracker) Run(ctx context.Context) error {
+	// Cancelled only when the drain deadline is exceeded, so that in-flight messages can finish after a shutdown signal
+	processingCtx, cancelProcessing := context.WithCancel(context.Background())
+	defer cancelProcessing()
+
+	// Messages of the same operator are processed in order by the same worker
+	workerPool := consumer.NewWorkerPool(t.WorkerCount, t.QueueSize)
+
+	inFlight := new(sync.WaitGroup)
+	pending := int64(0)
+
+	deps := &handlers.Dependencies{
+		Sink:           t.Sink,
+		DedupStore:     t.DedupStore,
+		ClaimTTL:       t.ClaimTTL,
+		ReorgMonitor:   t.ReorgMonitor,
+		Metadata:       t.Metadata,
+		Deferred:       t.Deferred,
+		Scoring:        t.Scoring,
+		RegistryWriter: t.RegistryWriter,
+		RegistryIndex:  t.RegistryIndex,
+	}
+
+	// The deferred events are re-attempted in the background until the shutdown signal
+	go t.Deferred.Run(ctx, func(attemptCtx context.Context, item deferred.Item) (bool, error) {
+		return handlers.RetryDeferred(attemptCtx, deps, item)
+	})
+
+	var sourceErr error
+
+	// Start message reading loop
+	for ctx.Err() == nil {
+		msg, rErr := t.Source.Read(ctx)
+
+		if rErr != nil {
+			if errors.Is(rErr, stream.ErrClosed) {
+				logger.LogI("Source is exhausted")
+				break
+			}
+
+			if errors.Is(rErr, stream.ErrFailed) {
+				logger.LogW("Source has failed: ", rErr)
+				sourceErr = rErr
+				break
+			}
+
+			if ctx.Err() == nil {
+				logger.LogW("Error while reading the message: ", rErr)
+			}
+
+			continue
+		}
+
+		logger.LogIf("Topic is: %s", msg.Channel)
+
+		// This service expects schemas.SolityETHCompleteTransactionMessage format
+		receivedMessage := schemas.SolityETHCompleteTransactionMessage{}
+		rMErr := json.Unmarshal(msg.Value, &receivedMessage)
+		if rMErr != nil {
+			logger.LogW("Error while unmarshalling the message object : ", rMErr)
+			// Since this message cannot be unmarshalled, we skip the execution for this message
+			t.deadLetterAndCommit(processingCtx, msg, deadletter.NewFailure(deadletter.StageUnmarshal, rMErr))
+			continue
+		}
+
+		theOutputChannel, isOk := t.ChannelMapping[msg.Channel]
+
+		if !isOk {
+			t.commit(msg)
+			continue
+		}
+
+		job := func() {
+			defer inFlight.Done()
+			defer atomic.AddInt64(&pending, -1)
+
+			// Process the message
+			pErr := t.Registry.ProcessTransaction(processingCtx, receivedMessage, deps, theOutputChannel)
+
+			// If the processing was aborted by the shutdown deadline the message is left uncommitted
+			if processingCtx.Err() != nil {
+				return
+			}
+
+			metrics.ProcessedMessages.Add(1)
+
+			if pErr != nil {
+				t.deadLetterAndCommit(processingCtx, msg, pErr)
+				return
+			}
+
+			t.commit(msg)
+		}
+
+		key := t.Registry.ExtractOrderingKey(receivedMessage)
+
+		inFlight.Add(1)
+		atomic.AddInt64(&pending, 1)
+		submitted, sErr := workerPool.TrySubmit(key, job)
+
+		if sErr == nil && !submitted {
+			// The queue of this key is full, stop fetching until there is space again
+			sErr = t.submitWithBackpressure(ctx, workerPool, key, job)
+		}
+
+		if sErr != nil {
+			// The message stays uncommitted and is redelivered after the restart
+			logger.LogW("Message could not be queued: ", sErr)
+			atomic.AddInt64(&pending, -1)
+			inFlight.Done()
+		}
+	}
+
+	logger.LogI("Stopped reading, waiting for ", atomic.LoadInt64(&pending), " in-flight messages")
+
+	drained := make(chan struct{})
+	go func() {
+		inFlight.Wait()
+		close(drained)
+	}()
+
+	go workerPool.Close()
+
+	select {
+	case <-drained:
+		logger.LogI("All in-flight messages are processed")
+	case <-time.After(t.ShutdownTimeout):
+		// Unfinished messages are not committed, they will be redelivered after the restart
+		logger.LogW("Shutdown deadline exceeded, aborting ", atomic.LoadInt64(&pending), " in-flight messages")
+		cancelProcessing()
+		inFlight.Wait()
+	}
+
+	return sourceErr
+}
+
+/*
+submitWithBackpressure waits until the job fits into the worker queue. Sources that support pausing are paused and
+polled meanwhile, so that they stay connected, and are resumed only once the queue of the key has drained to half of
+its size. Other sources are simply not read while waiting
+*/
+func (t *Tracker) submitWithBackpressure(ctx context.Context, workerPool *consumer.WorkerPool, key string, job func()) error {
+	pausable, isPausable := t.Source.(stream.Pausable)
+	if !isPausable {
+		return workerPool.Submit(ctx, key, job)
+	}
+
+	if pErr := pausable.Pause(); pErr != nil {
+		logger.LogW("Error while pausing the source: ", pErr)
+	}
+
+	metrics.PartitionPauses.Add(1)
+	logger.LogW("Worker queue is full, consumption is paused. Queue depth: ", workerPool.Depth())
+
+	defer func() {
+		if rErr := pausable.Resume(); rErr != nil {
+			logger.LogW("Error while resuming the source: ", rErr)
+		}
+	}()
+
+	isSubmitted := false
+
+	for {
+		if !isSubmitted {
+			submitted, sErr := workerPool.TrySubmit(key, job)
+			if sErr != nil {
+				return sErr
+			}
+
+			isSubmitted = submitted
+		}
+
+		if isSubmitted && (workerPool.HasRoom(key) || ctx.Err() != nil) {
+			return nil
+		}
+
+		if !isSubmitted && ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		if pErr := pausable.Poll(100 * time.Millisecond); pErr != nil {
+			logger.LogW("Error while polling the paused source: ", pErr)
+		}
+	}
+}
+
+/*
+deadLetterAndCommit forwards the failed message to its dead-letter channel and commits it. If the message could not be
+dead-lettered it is left uncommitted, so it is redelivered after the restart
+*/
+func (t *Tracker) deadLetterAndCommit(ctx context.Context, msg *stream.Message, failure error) {
+	published, pErr := t.DeadLetter.Publish(ctx, msg, failure)
+
+	if pErr != nil {
+		logger.LogW("Error while dead-lettering the message, it will not be committed: ", pErr)
+		return
+	}
+
+	if published {
+		metrics.DeadLetteredMessages.Add(1)
+		logger.LogWf("Message %s[%d]@%d is dead-lettered at stage [%s]", msg.Channel, msg.Partition, msg.Offset,
+			deadletter.StageOf(failure))
+	}
+
+	t.commit(msg)
+}
+
+/*
+commit marks the message as processed on the source
+*/
+func (t *Tracker) commit(msg *stream.Message) {
+	if cErr := t.Source.Commit(msg); cErr != nil {
+		logger.LogW("Error while committing the message: ", cErr)
+	}
+}
+
+/*
+parseDeadLetterChannels maps the listen channels to the dead-letter channels, which are given in the same order (e.g.
+"listenA:listenB" -> "dlqA:dlqB"). An empty entry disables the dead-letter channel of that listen channel
+*/
+func parseDeadLetterChannels(deadLetterChannels []string, listenChannels []string) map[string]string {
+	deadLetterMapping := map[string]string{}
+
+	if len(deadLetterChannels) == 0 {
+		logger.LogW("No dead-letter channel is configured, failed messages will be dropped")
+		return deadLetterMapping
+	}
+
+	if len(deadLetterChannels) != len(listenChannels) {
+		logger.LogE("Kafka listen channels and dead-letter channels length miss-match")
+	}
+
+	for i, listenChannel := range listenChannels {
+		deadLetterMapping[listenChannel] = deadLetterChannels[i]
+	}
+
+	logger.LogI("Kafka dead-letter channel ", deadLetterChannels)
+
+	return deadLetterMapping
+}
+
+/*
+newHandlerRegistry registers the enabled event handlers (all builtin handlers by default). The output channels and
+contracts of the settings override the output channel of a handler and limit it to a single contract
+*/
+func newHandlerRegistry(settings config.HandlersConfig) *handlers.Registry {
+	enabled := []string{}
+
+	for _, name := range settings.Enabled {
+		if name = strings.TrimSpace(name); name != "" {
+			enabled = append(enabled, name)
+		}
+	}
+
+	registry, rErr := handlers.NewRegistryFromConfig(enabled, settings.OutputChannels, settings.Contracts)
+	if rErr != nil {
+		logger.LogE("Error while registering the event handlers: ", rErr)
+	}
+
+	logger.LogI("Event handlers: ", registry.Names())
+
+	return registry
+}
+
+/*
+newChainSource creates the source that polls the logs of the registered events from the node at the source location
+*/
+func newChainSource(cfg config.Config, registry *handlers.Registry) (stream.Source, error) {
+	client, dErr := utils.DialChain(cfg.Source.Location, cfg.Chain.ID)
+
+	if dErr != nil {
+		return nil, dErr
+	}
+
+	return ingest.NewChainSource(client, ingest.NewFileCursor(cfg.Chain.CursorFile), ingest.ChainSourceConfig{
+		Channel:       cfg.Kafka.ListenChannels[0],
+		Addresses:     chainAddresses(cfg.Chain.Addresses, registry),
+		Topics:        registry.Topics(),
+		StartBlock:    cfg.Chain.StartBlock,
+		Confirmations: cfg.Chain.Confirmations,
+		PollInterval:  cfg.Chain.PollInterval,
+		MaxRange:      cfg.Chain.MaxRange,
+	}), nil
+}
+
+/*
+chainAddresses returns the given addresses, or the contracts of the handlers if no address is given
+*/
+func chainAddresses(addresses []string, registry *handlers.Registry) []common.Address {
+	if parsed := parseAddresses(addresses); len(parsed) > 0 {
+		return parsed
+	}
+
+	return registry.Contracts()
+}
+
+/*
+parseAddresses parses an address list, skipping the empty entries
+*/
+func parseAddresses(addresses []string) []common.Address {
+	ret := []common.Address{}
+
+	for _, address := range addresses {
+		if address = strings.TrimSpace(address); address != "" {
+			ret = append(ret, common.HexToAddress(address))
+		}
+	}
+
+	return ret
+}
+
+/*
+newReorgMonitor creates the monitor that retracts the events of the orphaned blocks. The canonical chain is followed
+through the reorg RPC (the source location for the chain source), without it only the removed logs are detected
+*/
+func newReorgMonitor(cfg config.Config, sink stream.Sink, dedupStore dedup.Store) *reorg.Monitor {
+	rpcURL := cfg.Reorg.RPC
+
+	if rpcURL == "" && cfg.Source.Type == "chain" {
+		rpcURL = cfg.Source.Location
+	}
+
+	var headerReader reorg.HeaderReader
+
+	if rpcURL != "" {
+		client, dErr := utils.DialChain(rpcURL, cfg.Chain.ID)
+
+		if dErr != nil {
+			logger.LogE("Error while connecting to the node for the reorg monitor: ", dErr)
+		}
+
+		headerReader = client
+	} else {
+		logger.LogW("REORG_RPC is not set, only the removed logs are retracted")
+	}
+
+	return reorg.NewMonitor(headerReader, sink, dedupStore, cfg.Reorg.Depth, cfg.Reorg.CompensationChannel)
+}
+
+/*
+newSnapshotStore opens the time series store of the Dune stats, it is disabled (nil) when its file is empty
+*/
+func newSnapshotStore(settings config.SnapshotsConfig) *snapshots.Store {
+	if settings.File == "" {
+		return nil
+	}
+
+	store, oErr := snapshots.Open(settings.File, snapshots.Policy{
+		Retention:         settings.Retention,
+		CompactAfter:      settings.CompactAfter,
+		CompactResolution: settings.CompactResolution,
+		CheckInterval:     settings.MaintenanceInterval,
+	})
+	if oErr != nil {
+		logger.LogE("Error while opening the snapshot store: ", oErr)
+	}
+
+	return store
+}
+
+/*
+newRegistryIndex opens the registry index and write journal, it is disabled (nil) when its file is empty
+*/
+func newRegistryIndex(settings config.IndexConfig) *registryindex.Store {
+	if settings.File == "" {
+		return nil
+	}
+
+	store, oErr := registryindex.Open(settings.File, 5*time.Second)
+	if oErr != nil {
+		logger.LogE("Error while opening the registry index: ", oErr)
+	}
+
+	return store
+}
+
+/*
+newReconciler creates the reconciliation of the journal with the registry, which sends the missing writes again
+through the writer when resubmit is set. It is disabled (nil) without an index or with a zero reconcile interval. The
+returned reader is closed by the caller
+*/
+func newReconciler(cfg config.Config, store *registryindex.Store,
+	writer *utils.RegistryWriter) (*registryindex.Reconciler, *utils.RegistryReader) {
+	if store == nil || cfg.Index.ReconcileInterval <= 0 {
+		return nil, nil
+	}
+
+	reader, rErr := utils.NewRegistryReaderFromConfig(cfg.Registry)
+	if rErr != nil {
+		logger.LogE("Error while connecting to the registry for the reconciliation: ", rErr)
+	}
+
+	if !cfg.Index.Resubmit {
+		writer = nil
+	}
+
+	return registryindex.NewReconciler(newIndexer(cfg, reader, store), store, writer), reader
+}
+
+/*
+newIndexer creates the indexer of the registry from its deployment block
+*/
+func newIndexer(cfg config.Config, reader *utils.RegistryReader, store *registryindex.Store) *registryindex.Indexer {
+	return registryindex.NewIndexer(reader, store, registryindex.Config{
+		DeploymentBlock: cfg.Registry.DeploymentBlock,
+		LogRange:        cfg.Index.LogRange,
+		Confirmations:   cfg.Index.Confirmations,
+	})
+}
+
+/*
+newScoringEngine creates the popularity scoring of the registered AVSs and operators, publishing the ranking moves to
+the scoring channel. The stats are read through the metadata resolver and its cache, the rankings are kept in the
+snapshot store. It is disabled (nil) when the channel is empty
+*/
+func newScoringEngine(cfg config.Config, sink stream.Sink, resolver *metadata.CachingResolver,
+	snapshotStore *snapshots.Store) *scoring.Engine {
+	if cfg.Scoring.Channel == "" {
+		return nil
+	}
+
+	weights, wErr := scoring.ParseWeights(cfg.Scoring.Weights)
+	if wErr != nil {
+		logger.LogE("Invalid SCORING_WEIGHTS: ", wErr)
+	}
+
+	return scoring.NewEngine(scoring.NewResolverStats(resolver), sink, snapshotStore, scoring.Config{
+		Weights:         weights,
+		VelocityBlocks:  cfg.Scoring.VelocityBlocks,
+		RetentionBlocks: cfg.Scoring.RetentionBlocks,
+		Channel:         cfg.Scoring.Channel,
+	})
+}
+
+/*
+newMetadataResolver creates the cached metadata lookups. The providers are asked in order: Dune, the on-chain metadata
+URIs (read through the metadata RPC) and the override file. The cache is in memory, or in redis to share the results
+between the replicas. The Dune stats rows are kept in the snapshot store
+*/
+func newMetadataResolver(cfg config.Config, snapshotStore *snapshots.Store) *metadata.CachingResolver {
+	settings := cfg.Metadata
+
+	cache, cErr := metadata.NewCache(settings.Cache, settings.CacheLocation, settings.CachePassword)
+	if cErr != nil {
+		logger.LogE("Error while initializing the metadata cache: ", cErr)
+	}
+
+	providers := []metadata.MetadataProvider{metadata.NewDuneProvider(utils.NewDuneClientFromConfig(cfg.Dune),
+		snapshotStore)}
+
+	if settings.RPC != "" {
+		client, dErr := utils.DialChain(settings.RPC, cfg.Chain.ID)
+		if dErr != nil {
+			logger.LogE("Error while connecting to the node for the on-chain metadata: ", dErr)
+		}
+
+		providers = append(providers, metadata.NewOnChainProvider(client, metadata.OnChainProviderConfig{
+			DelegationManager: common.HexToAddress(settings.DelegationManager),
+			AVSDirectory:      common.HexToAddress(settings.AVSDirectory),
+			FromBlock:         settings.FromBlock,
+			LogRange:          settings.LogRange,
+			IPFSGateway:       settings.IPFSGateway,
+		}))
+	}
+
+	if settings.OverrideFile != "" {
+		overrides, oErr := metadata.NewOverrideProvider(settings.OverrideFile)
+		if oErr != nil {
+			logger.LogE("Error while loading the metadata override file: ", oErr)
+		}
+
+		providers = append(providers, overrides)
+	}
+
+	return metadata.NewCachingResolver(cache, metadata.NewProviderChain(providers...), metadata.ResolverConfig{
+		TTLs: map[metadata.Kind]time.Duration{
+			metadata.KindOperator: settings.OperatorTTL,
+			metadata.KindAVS:      settings.AVSTTL,
+		},
+		NegativeTTL: settings.NegativeTTL,
+	})
+}
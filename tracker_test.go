@@ -0,0 +1,383 @@
+package main
+
+import (
+	"context"
+	"eigenlayer_hack/consumer"
+	"eigenlayer_hack/deadletter"
+	"eigenlayer_hack/dedup"
+	"eigenlayer_hack/handlers"
+	"eigenlayer_hack/stream"
+	"encoding/json"
+	"errors"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"math/big"
+	"solity/schemas"
+	"sync"
+	"testing"
+	"time"
+)
+
+/*
+pausableSource records the pauses, polls and resumes of the backpressure
+*/
+type pausableSource struct {
+	*stream.MemorySource
+
+	mu      sync.Mutex
+	paused  bool
+	polls   int
+	resumes int
+}
+
+func (s *pausableSource) Pause() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.paused = true
+
+	return nil
+}
+
+func (s *pausableSource) Resume() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.paused = false
+	s.resumes++
+
+	return nil
+}
+
+func (s *pausableSource) Poll(time.Duration) error {
+	s.mu.Lock()
+	s.polls++
+	s.mu.Unlock()
+
+	time.Sleep(5 * time.Millisecond)
+
+	return nil
+}
+
+func (s *pausableSource) state() (bool, int, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.paused, s.polls, s.resumes
+}
+
+/*
+waitFor fails the test when the condition does not hold within a few seconds
+*/
+func waitFor(t *testing.T, condition func() bool, what string) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for ", what)
+		}
+
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestSubmitWithBackpressurePollsThePausedSourceUntilTheQueueDrains(t *testing.T) {
+	source := &pausableSource{MemorySource: stream.NewMemorySource(1)}
+	tracker := &Tracker{Source: source}
+
+	pool := consumer.NewWorkerPool(1, 2)
+	defer pool.Close()
+
+	// Every job runs only when a step is sent, the first one occupies the worker and the next two fill its queue
+	step := make(chan struct{})
+	defer close(step)
+
+	job := func() { <-step }
+
+	for index := 0; index < 3; index++ {
+		if sErr := pool.Submit(context.Background(), "key", job); sErr != nil {
+			t.Fatal(sErr)
+		}
+
+		if index == 0 {
+			waitFor(t, func() bool { return pool.Depth() == 0 }, "the worker to take the first job")
+		}
+	}
+
+	submitted := make(chan error, 1)
+	go func() {
+		submitted <- tracker.submitWithBackpressure(context.Background(), pool, "key", job)
+	}()
+
+	waitFor(t, func() bool {
+		paused, polls, _ := source.state()
+		return paused && polls > 1
+	}, "the paused source to be polled")
+
+	// The job fits into the queue now, but the queue is still full so the source stays paused
+	step <- struct{}{}
+	waitFor(t, func() bool { return pool.Depth() == 2 }, "the job to be queued")
+
+	select {
+	case sErr := <-submitted:
+		t.Fatal("the source was resumed with a full queue: ", sErr)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// Half of the queue is free after the next job
+	step <- struct{}{}
+
+	select {
+	case sErr := <-submitted:
+		if sErr != nil {
+			t.Fatal(sErr)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the source was not resumed after the queue drained")
+	}
+
+	if paused, _, resumes := source.state(); paused || resumes != 1 {
+		t.Fatalf("expected the source to be resumed once, paused: %v resumes: %d", paused, resumes)
+	}
+}
+
+func TestSubmitWithBackpressureGivesUpWhenTheContextIsDone(t *testing.T) {
+	source := &pausableSource{MemorySource: stream.NewMemorySource(1)}
+	tracker := &Tracker{Source: source}
+
+	pool := consumer.NewWorkerPool(1, 1)
+	defer pool.Close()
+
+	step := make(chan struct{})
+	defer close(step)
+
+	job := func() { <-step }
+
+	for index := 0; index < 2; index++ {
+		if sErr := pool.Submit(context.Background(), "key", job); sErr != nil {
+			t.Fatal(sErr)
+		}
+
+		if index == 0 {
+			waitFor(t, func() bool { return pool.Depth() == 0 }, "the worker to take the first job")
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if sErr := tracker.submitWithBackpressure(ctx, pool, "key", job); sErr != context.DeadlineExceeded {
+		t.Fatal("expected the deadline error, got ", sErr)
+	}
+
+	if paused, _, resumes := source.state(); paused || resumes != 1 {
+		t.Fatalf("expected the source to be resumed, paused: %v resumes: %d", paused, resumes)
+	}
+}
+
+const (
+	testListenChannel = "transactions"
+	testOutputChannel = "registrations"
+	testDLQChannel    = "transactions-dlq"
+)
+
+/*
+testHandler handles TestEvent(address,uint256), Handle waits for release when it is set and fails with err when it is set
+*/
+type testHandler struct {
+	release chan struct{}
+	err     error
+}
+
+func (h *testHandler) Name() string                       { return "TestEvent" }
+func (h *testHandler) Signature() string                  { return "TestEvent(address,uint256)" }
+func (h *testHandler) Types() []string                    { return []string{"uint256"} }
+func (h *testHandler) IndexedTypes() []string             { return []string{"address"} }
+func (h *testHandler) OrderingKey(*handlers.Event) string { return "" }
+
+func (h *testHandler) Handle(ctx context.Context, _ *handlers.Dependencies, event *handlers.Event) (interface{}, error) {
+	if h.release != nil {
+		select {
+		case <-h.release:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	if h.err != nil {
+		return nil, h.err
+	}
+
+	return map[string]string{"block": event.Log.BlockHash.Hex()}, nil
+}
+
+/*
+newTestTracker runs the pipeline with the handler on memory streams until the returned stop function is called
+*/
+func newTestTracker(t *testing.T, handler handlers.Handler) (*stream.MemorySource, *stream.MemorySink, func()) {
+	t.Helper()
+
+	registry := handlers.NewRegistry()
+	if rErr := registry.Register(handler, "", common.Address{}); rErr != nil {
+		t.Fatal(rErr)
+	}
+
+	source := stream.NewMemorySource(10)
+	sink := stream.NewMemorySink()
+
+	tracker := &Tracker{
+		Source:          source,
+		Sink:            sink,
+		ChannelMapping:  map[string]string{testListenChannel: testOutputChannel},
+		DeadLetter:      deadletter.NewPublisher(sink, map[string]string{testListenChannel: testDLQChannel}),
+		DedupStore:      dedup.NewMemoryStore(time.Hour),
+		ClaimTTL:        time.Minute,
+		Registry:        registry,
+		WorkerCount:     2,
+		QueueSize:       4,
+		ShutdownTimeout: 5 * time.Second,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	go func() {
+		if rErr := tracker.Run(ctx); rErr != nil {
+			t.Error(rErr)
+		}
+		close(done)
+	}()
+
+	return source, sink, func() {
+		cancel()
+		<-done
+	}
+}
+
+/*
+newTestTransaction returns the transaction message with a single TestEvent log
+*/
+func newTestTransaction(t *testing.T, handler handlers.Handler) *stream.Message {
+	t.Helper()
+
+	key, kErr := crypto.GenerateKey()
+	if kErr != nil {
+		t.Fatal(kErr)
+	}
+
+	signer := types.LatestSignerForChainID(big.NewInt(1))
+
+	tx, sErr := types.SignNewTx(key, signer, &types.DynamicFeeTx{
+		ChainID:   big.NewInt(1),
+		Gas:       21000,
+		GasFeeCap: big.NewInt(1),
+		GasTipCap: big.NewInt(1),
+	})
+	if sErr != nil {
+		t.Fatal(sErr)
+	}
+
+	receipt := &types.Receipt{
+		Type:   types.DynamicFeeTxType,
+		Status: types.ReceiptStatusSuccessful,
+		TxHash: tx.Hash(),
+		Logs: []*types.Log{{
+			Address:   common.HexToAddress("0x1"),
+			Topics:    []common.Hash{handlers.Topic(handler), common.HexToHash("0x2")},
+			Data:      common.LeftPadBytes([]byte{1}, 32),
+			TxHash:    tx.Hash(),
+			BlockHash: common.HexToHash("0x3"),
+		}},
+	}
+	receipt.Bloom = types.CreateBloom(types.Receipts{receipt})
+
+	txData, tErr := tx.MarshalJSON()
+	if tErr != nil {
+		t.Fatal(tErr)
+	}
+
+	receiptData, rErr := receipt.MarshalJSON()
+	if rErr != nil {
+		t.Fatal(rErr)
+	}
+
+	value, mErr := json.Marshal(schemas.SolityETHCompleteTransactionMessage{
+		TransactionData: txData,
+		ReceiptData:     receiptData,
+	})
+	if mErr != nil {
+		t.Fatal(mErr)
+	}
+
+	return stream.NewMessage(testListenChannel, value)
+}
+
+func TestTrackerCommitsAfterProcessing(t *testing.T) {
+	handler := &testHandler{release: make(chan struct{})}
+	source, sink, stop := newTestTracker(t, handler)
+	defer stop()
+
+	if pErr := source.Push(context.Background(), newTestTransaction(t, handler)); pErr != nil {
+		t.Fatal(pErr)
+	}
+
+	// The handler is blocked, the message must not be committed yet
+	time.Sleep(100 * time.Millisecond)
+
+	if committed := source.Committed(); len(committed) != 0 {
+		t.Fatalf("message committed before its processing completed: %d commits", len(committed))
+	}
+
+	close(handler.release)
+
+	waitFor(t, func() bool { return len(source.Committed()) == 1 }, "the commit")
+
+	if published := sink.Messages(testOutputChannel); len(published) != 1 {
+		t.Fatalf("expected 1 published payload, got %d", len(published))
+	}
+
+	if dlq := sink.Messages(testDLQChannel); len(dlq) != 0 {
+		t.Fatalf("expected no dead-lettered message, got %d", len(dlq))
+	}
+}
+
+func TestTrackerDeadLettersFailures(t *testing.T) {
+	handler := &testHandler{err: deadletter.NewFailure(deadletter.StageEnrichment, errors.New("no metadata"))}
+	source, sink, stop := newTestTracker(t, handler)
+	defer stop()
+
+	failing := newTestTransaction(t, handler)
+	malformed := stream.NewMessage(testListenChannel, []byte("not json"))
+
+	for _, msg := range []*stream.Message{failing, malformed} {
+		if pErr := source.Push(context.Background(), msg); pErr != nil {
+			t.Fatal(pErr)
+		}
+	}
+
+	waitFor(t, func() bool { return len(source.Committed()) == 2 }, "the commits")
+
+	if published := sink.Messages(testOutputChannel); len(published) != 0 {
+		t.Fatalf("expected no published payload, got %d", len(published))
+	}
+
+	stages := map[string]bool{}
+
+	for _, msg := range sink.Messages(testDLQChannel) {
+		stages[msg.Headers[deadletter.StageHeader]] = true
+
+		if msg.Headers[deadletter.OriginalTopicHeader] != testListenChannel {
+			t.Errorf("original topic header is %q", msg.Headers[deadletter.OriginalTopicHeader])
+		}
+
+		if msg.Headers[deadletter.AttemptsHeader] != "1" {
+			t.Errorf("attempts header is %q", msg.Headers[deadletter.AttemptsHeader])
+		}
+	}
+
+	if len(stages) != 2 || !stages[deadletter.StageEnrichment] || !stages[deadletter.StageUnmarshal] {
+		t.Fatalf("expected the enrichment and unmarshal stages, got %v", stages)
+	}
+}
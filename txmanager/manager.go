@@ -0,0 +1,464 @@
+package txmanager
+
+import (
+	"context"
+	"eigenlayer_hack/ethereum/node"
+	"eigenlayer_hack/metrics"
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"math/big"
+	"solity/utils/logger"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Final status of a transaction
+const (
+	StatusConfirmed = "confirmed"
+	StatusReverted  = "reverted"
+	StatusReplaced  = "replaced"
+	StatusStuck     = "stuck"
+	StatusPending   = "pending"
+	StatusFailed    = "failed"
+)
+
+var (
+	// ErrReverted is returned when the transaction is mined with a failed status
+	ErrReverted = errors.New("transaction reverted")
+	// ErrReplaced is returned when the nonce of the transaction is used by a transaction the manager did not send
+	ErrReplaced = errors.New("nonce is used by another transaction")
+	// ErrStuck is returned when the transaction is not mined after the last rebroadcast
+	ErrStuck = errors.New("transaction is not mined after the rebroadcasts")
+)
+
+// Messages of the node errors, compared as text because they arrive over RPC
+const (
+	errNonceTooLow        = "nonce too low"
+	errAlreadyKnown       = "already known"
+	errReplaceUnderpriced = "replacement transaction underpriced"
+)
+
+// maxNonceReloads bounds the nonce reloads of a submission when other senders keep taking the nonces
+const maxNonceReloads = 10
+
+/*
+Backend is the part of the node API used by the Manager, *ethclient.Client and the simulated backend's client
+implement it
+*/
+type Backend interface {
+	node.FeeBackend
+	ethereum.GasEstimator
+	BlockNumber(ctx context.Context) (uint64, error)
+	PendingNonceAt(ctx context.Context, account common.Address) (uint64, error)
+	NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error)
+	SendTransaction(ctx context.Context, tx *types.Transaction) error
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
+}
+
+/*
+Config holds the settings of the Manager
+*/
+type Config struct {
+	// Signer signs every transaction of the manager, they are sent from its address
+	Signer  node.Signer
+	ChainID *big.Int
+	// Confirmations is the number of blocks, including the block of the transaction, a receipt has to be covered by
+	Confirmations uint64
+	PollInterval  time.Duration
+	// StuckAfter is the time without a receipt after which the transaction is sent again with a higher fee
+	StuckAfter time.Duration
+	// FeeBumpPercent raises the fees of every rebroadcast, nodes require at least 10
+	FeeBumpPercent  int64
+	MaxRebroadcasts int
+	// Fees prices the transactions, its MaxFeeCap also caps the bumped fees
+	Fees node.FeeOracleConfig
+	// GasMarginPercent is added to the estimated gas of the requests without a GasLimit
+	GasMarginPercent uint64
+}
+
+/*
+Request is a transaction to be sent by the Manager, a zero GasLimit is estimated
+*/
+type Request struct {
+	To       common.Address
+	Data     []byte
+	Value    *big.Int
+	GasLimit uint64
+}
+
+/*
+Result is the final state of a sent transaction. Hashes holds every broadcast version, the last one first
+*/
+type Result struct {
+	Status   string
+	Nonce    uint64
+	Hash     common.Hash
+	Hashes   []common.Hash
+	Receipt  *types.Receipt
+	Fees     node.Fees
+	Attempts int
+}
+
+/*
+Manager sends the transactions of a single signer. Nonces are assigned locally and the submissions are serialized, so
+concurrent senders do not collide. Every transaction is followed until its receipt has the configured confirmations;
+transactions without a receipt after StuckAfter, including the ones dropped by the node, are sent again with the same
+nonce and bumped fees
+*/
+type Manager struct {
+	client Backend
+	config Config
+	fees   *node.FeeOracle
+
+	mu          sync.Mutex
+	nonce       uint64
+	nonceLoaded bool
+}
+
+/*
+NewManager constructor for the Manager object, zero settings get their defaults
+*/
+func NewManager(client Backend, config Config) *Manager {
+	if config.Confirmations == 0 {
+		config.Confirmations = 1
+	}
+
+	if config.PollInterval <= 0 {
+		config.PollInterval = 2 * time.Second
+	}
+
+	if config.StuckAfter <= 0 {
+		config.StuckAfter = 3 * time.Minute
+	}
+
+	if config.FeeBumpPercent < 10 {
+		config.FeeBumpPercent = 10
+	}
+
+	if config.MaxRebroadcasts <= 0 {
+		config.MaxRebroadcasts = 5
+	}
+
+	return &Manager{client: client, config: config, fees: node.NewFeeOracle(client, config.Fees)}
+}
+
+/*
+From returns the address the transactions are sent from
+*/
+func (m *Manager) From() common.Address {
+	return m.config.Signer.Address()
+}
+
+/*
+Send submits the transaction and waits for its final status. The error is nil only for confirmed transactions. If the
+context is cancelled before, the result has the pending status and the transaction keeps its nonce. A pending or stuck
+transaction may still be dropped by the node, so the next submission reads the nonce from the node again. Requests
+without a GasLimit are estimated first, so calls that would revert are not sent
+*/
+func (m *Manager) Send(ctx context.Context, request Request) (Result, error) {
+	if request.GasLimit == 0 {
+		gasLimit, eErr := node.EstimateGasLimit(ctx, m.client, ethereum.CallMsg{
+			From:  m.From(),
+			To:    &request.To,
+			Value: request.Value,
+			Data:  request.Data,
+		}, m.config.GasMarginPercent)
+		if eErr != nil {
+			metrics.TxFailed.Add(1)
+			return Result{Status: StatusFailed}, eErr
+		}
+
+		request.GasLimit = gasLimit
+	}
+
+	tx, fees, sErr := m.submit(ctx, request)
+	if sErr != nil {
+		metrics.TxFailed.Add(1)
+		return Result{Status: StatusFailed}, sErr
+	}
+
+	metrics.TxPending.Add(1)
+	defer metrics.TxPending.Add(-1)
+
+	result, wErr := m.wait(ctx, request, tx, fees)
+
+	switch result.Status {
+	case StatusConfirmed:
+		metrics.TxConfirmed.Add(1)
+	case StatusPending:
+		m.resyncNonce()
+	case StatusStuck:
+		m.resyncNonce()
+		metrics.TxFailed.Add(1)
+	default:
+		metrics.TxFailed.Add(1)
+	}
+
+	return result, wErr
+}
+
+/*
+submit assigns the next nonce to the transaction and broadcasts it. The submissions of the signer are serialized, the
+nonce is only used up when the node accepts the transaction
+*/
+func (m *Manager) submit(ctx context.Context, request Request) (*types.Transaction, node.Fees, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	fees, fErr := m.fees.Fees(ctx)
+	if fErr != nil {
+		return nil, node.Fees{}, fErr
+	}
+
+	for attempt := 0; ; attempt++ {
+		if !m.nonceLoaded {
+			nonce, nErr := m.client.PendingNonceAt(ctx, m.From())
+			if nErr != nil {
+				return nil, node.Fees{}, fmt.Errorf("reading the nonce: %w", nErr)
+			}
+
+			m.nonce, m.nonceLoaded = nonce, true
+		}
+
+		tx, bErr := m.broadcast(ctx, request, m.nonce, fees)
+
+		// Another sender of the account (e.g. a backfill next to the tracker) used the nonce, it is read again
+		if bErr != nil && isNonceError(bErr) && attempt < maxNonceReloads {
+			logger.LogW("Nonce ", m.nonce, " of ", m.From().Hex(), " is used, reloading it")
+			m.nonceLoaded = false
+			continue
+		}
+
+		if bErr != nil {
+			return nil, node.Fees{}, bErr
+		}
+
+		m.nonce++
+		metrics.TxSubmitted.Add(1)
+
+		return tx, fees, nil
+	}
+}
+
+/*
+resyncNonce makes the next submission read the pending nonce from the node. A transaction the manager stopped
+following can be dropped, the local nonce would then leave a gap that blocks every later transaction
+*/
+func (m *Manager) resyncNonce() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.nonceLoaded = false
+}
+
+/*
+broadcast signs the transaction with the given nonce and fees and sends it to the node
+*/
+func (m *Manager) broadcast(ctx context.Context, request Request, nonce uint64, fees node.Fees) (*types.Transaction,
+	error) {
+	value := request.Value
+	if value == nil {
+		value = big.NewInt(0)
+	}
+
+	to := request.To
+
+	var txData types.TxData = &types.LegacyTx{
+		Nonce:    nonce,
+		GasPrice: fees.GasPrice,
+		Gas:      request.GasLimit,
+		To:       &to,
+		Value:    value,
+		Data:     request.Data,
+	}
+
+	if fees.IsDynamic {
+		txData = &types.DynamicFeeTx{
+			Nonce:     nonce,
+			GasTipCap: fees.GasTipCap,
+			GasFeeCap: fees.GasFeeCap,
+			Gas:       request.GasLimit,
+			To:        &to,
+			Value:     value,
+			Data:      request.Data,
+		}
+	}
+
+	tx := types.NewTx(txData)
+
+	signedTx, sErr := m.config.Signer.SignTx(ctx, tx, m.config.ChainID)
+	if sErr != nil {
+		return nil, fmt.Errorf("signing the transaction: %w", sErr)
+	}
+
+	if tErr := m.client.SendTransaction(ctx, signedTx); tErr != nil && !isNodeError(tErr, errAlreadyKnown) {
+		return nil, fmt.Errorf("sending the transaction %s: %w", signedTx.Hash().Hex(), tErr)
+	}
+
+	logger.LogI("Sent the transaction ", signedTx.Hash().Hex(), " with nonce ", nonce, " and ", fees)
+
+	return signedTx, nil
+}
+
+/*
+wait follows the transaction until one of its versions has a receipt with enough confirmations. Transactions without
+a receipt after StuckAfter are sent again with bumped fees
+*/
+func (m *Manager) wait(ctx context.Context, request Request, tx *types.Transaction, fees node.Fees) (Result, error) {
+	result := Result{
+		Status:   StatusPending,
+		Nonce:    tx.Nonce(),
+		Hash:     tx.Hash(),
+		Hashes:   []common.Hash{tx.Hash()},
+		Fees:     fees,
+		Attempts: 1,
+	}
+
+	lastBroadcast := time.Now()
+
+	ticker := time.NewTicker(m.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return result, ctx.Err()
+		case <-ticker.C:
+		}
+
+		receipt, rErr := m.findReceipt(ctx, result.Hashes)
+		if rErr != nil {
+			logger.LogW("Error while reading the receipt of ", result.Hash.Hex(), ": ", rErr)
+			continue
+		}
+
+		if receipt != nil {
+			isFinal, cErr := m.isConfirmed(ctx, receipt)
+			if cErr != nil {
+				logger.LogW("Error while checking the confirmations of ", receipt.TxHash.Hex(), ": ", cErr)
+				continue
+			}
+
+			if !isFinal {
+				continue
+			}
+
+			result.Receipt, result.Hash = receipt, receipt.TxHash
+
+			if receipt.Status != types.ReceiptStatusSuccessful {
+				result.Status = StatusReverted
+				return result, fmt.Errorf("%w: %s", ErrReverted, receipt.TxHash.Hex())
+			}
+
+			result.Status = StatusConfirmed
+			logger.LogS("Transaction ", receipt.TxHash.Hex(), " is confirmed in the block ", receipt.BlockNumber)
+
+			return result, nil
+		}
+
+		// Without a receipt of ours a used nonce means that another transaction took it
+		minedNonce, nErr := m.client.NonceAt(ctx, m.From(), nil)
+		if nErr == nil && minedNonce > result.Nonce {
+			if receipt, _ := m.findReceipt(ctx, result.Hashes); receipt == nil {
+				result.Status = StatusReplaced
+				return result, fmt.Errorf("%w: nonce %d", ErrReplaced, result.Nonce)
+			}
+
+			continue
+		}
+
+		if time.Since(lastBroadcast) < m.config.StuckAfter {
+			continue
+		}
+
+		if result.Attempts > m.config.MaxRebroadcasts {
+			result.Status = StatusStuck
+			return result, fmt.Errorf("%w: nonce %d, %d attempts", ErrStuck, result.Nonce, result.Attempts)
+		}
+
+		replacement, bumped, bErr := m.rebroadcast(ctx, request, result)
+		lastBroadcast = time.Now()
+		result.Attempts++
+
+		if bErr != nil {
+			logger.LogW("Error while rebroadcasting the transaction with nonce ", result.Nonce, ": ", bErr)
+			continue
+		}
+
+		result.Hash, result.Fees = replacement.Hash(), bumped
+		result.Hashes = append([]common.Hash{replacement.Hash()}, result.Hashes...)
+	}
+}
+
+/*
+rebroadcast sends the transaction again with the same nonce and the fees bumped by FeeBumpPercent, or the current fees
+if they are higher
+*/
+func (m *Manager) rebroadcast(ctx context.Context, request Request, result Result) (*types.Transaction, node.Fees,
+	error) {
+	fees := m.fees.Bump(ctx, result.Fees, m.config.FeeBumpPercent)
+
+	logger.LogW("Transaction ", result.Hash.Hex(), " is not mined after ", m.config.StuckAfter,
+		", rebroadcasting it with ", fees)
+	metrics.TxRebroadcasts.Add(1)
+
+	tx, bErr := m.broadcast(ctx, request, result.Nonce, fees)
+	if bErr != nil && isNodeError(bErr, errReplaceUnderpriced) {
+		// The capped fees are not enough to replace the pending version, that one stays in place
+		return nil, fees, fmt.Errorf("%s are too low to replace the pending transaction", fees)
+	}
+
+	return tx, fees, bErr
+}
+
+/*
+findReceipt returns the receipt of the first mined version of the transaction, nil if none is mined
+*/
+func (m *Manager) findReceipt(ctx context.Context, hashes []common.Hash) (*types.Receipt, error) {
+	for _, hash := range hashes {
+		receipt, rErr := m.client.TransactionReceipt(ctx, hash)
+
+		if errors.Is(rErr, ethereum.NotFound) {
+			continue
+		}
+
+		if rErr != nil {
+			return nil, rErr
+		}
+
+		return receipt, nil
+	}
+
+	return nil, nil
+}
+
+/*
+isConfirmed reports whether the block of the receipt has Confirmations blocks on top of it, including itself
+*/
+func (m *Manager) isConfirmed(ctx context.Context, receipt *types.Receipt) (bool, error) {
+	head, hErr := m.client.BlockNumber(ctx)
+	if hErr != nil {
+		return false, hErr
+	}
+
+	return head+1 >= receipt.BlockNumber.Uint64()+m.config.Confirmations, nil
+}
+
+/*
+isNonceError reports whether the node refused the transaction because its nonce is mined or taken by a pending
+transaction of another sender
+*/
+func isNonceError(err error) bool {
+	return isNodeError(err, errNonceTooLow) || isNodeError(err, errReplaceUnderpriced)
+}
+
+/*
+isNodeError reports whether the error of the node has the given message
+*/
+func isNodeError(err error, message string) bool {
+	return strings.Contains(strings.ToLower(err.Error()), message)
+}
@@ -0,0 +1,364 @@
+package txmanager
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"eigenlayer_hack/ethereum/node"
+	"encoding/hex"
+	"errors"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient/simulated"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+)
+
+/*
+testBackend records the sent transactions, and drops them instead of sending them while drop is set
+*/
+type testBackend struct {
+	simulated.Client
+
+	mu   sync.Mutex
+	sent []*types.Transaction
+	drop bool
+}
+
+func (tB *testBackend) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	tB.mu.Lock()
+	tB.sent = append(tB.sent, tx)
+	drop := tB.drop
+	tB.mu.Unlock()
+
+	if drop {
+		return nil
+	}
+
+	return tB.Client.SendTransaction(ctx, tx)
+}
+
+func (tB *testBackend) setDrop(drop bool) {
+	tB.mu.Lock()
+	defer tB.mu.Unlock()
+
+	tB.drop = drop
+}
+
+func (tB *testBackend) sentTransactions() []*types.Transaction {
+	tB.mu.Lock()
+	defer tB.mu.Unlock()
+
+	return append([]*types.Transaction{}, tB.sent...)
+}
+
+/*
+newTestManager returns the manager of a funded account on a simulated chain at the block 1, the blocks are mined with
+Commit
+*/
+func newTestManager(t *testing.T, config Config) (*Manager, *simulated.Backend, *testBackend, *ecdsa.PrivateKey) {
+	t.Helper()
+
+	key, kErr := crypto.GenerateKey()
+	if kErr != nil {
+		t.Fatal(kErr)
+	}
+
+	balance := new(big.Int).Mul(big.NewInt(1000), big.NewInt(1e18))
+	sim := simulated.NewBackend(types.GenesisAlloc{crypto.PubkeyToAddress(key.PublicKey): {Balance: balance}})
+	t.Cleanup(func() { _ = sim.Close() })
+
+	// The receipts of unknown transactions are only reported as missing once a block is indexed
+	sim.Commit()
+
+	backend := &testBackend{Client: sim.Client()}
+
+	signer, sErr := node.NewKeySigner(hex.EncodeToString(crypto.FromECDSA(key)))
+	if sErr != nil {
+		t.Fatal(sErr)
+	}
+
+	chainID, cErr := backend.ChainID(context.Background())
+	if cErr != nil {
+		t.Fatal(cErr)
+	}
+
+	config.Signer, config.ChainID = signer, chainID
+
+	if config.PollInterval == 0 {
+		config.PollInterval = 10 * time.Millisecond
+	}
+
+	return NewManager(backend, config), sim, backend, key
+}
+
+/*
+mine commits a block every few milliseconds until the test ends
+*/
+func mine(t *testing.T, sim *simulated.Backend) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	go func() {
+		defer close(done)
+
+		for ctx.Err() == nil {
+			sim.Commit()
+			time.Sleep(20 * time.Millisecond)
+		}
+	}()
+}
+
+/*
+waitFor polls the condition until it holds or the test times out
+*/
+func waitFor(t *testing.T, what string, condition func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for ", what)
+		}
+
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func transfer() Request {
+	return Request{To: common.HexToAddress("0x1234"), Value: big.NewInt(1), GasLimit: 21000}
+}
+
+func TestManagerAssignsTheNoncesOfConcurrentSends(t *testing.T) {
+	manager, sim, backend, _ := newTestManager(t, Config{Confirmations: 2})
+	mine(t, sim)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	const sends = 5
+
+	results := make(chan Result, sends)
+	var group sync.WaitGroup
+
+	for index := 0; index < sends; index++ {
+		group.Add(1)
+
+		go func() {
+			defer group.Done()
+
+			result, sErr := manager.Send(ctx, transfer())
+			if sErr != nil {
+				t.Error(sErr)
+			}
+
+			results <- result
+		}()
+	}
+
+	group.Wait()
+	close(results)
+
+	nonces := map[uint64]bool{}
+
+	for result := range results {
+		if result.Status != StatusConfirmed {
+			t.Fatalf("expected a confirmed transaction, got %s", result.Status)
+		}
+
+		nonces[result.Nonce] = true
+	}
+
+	for nonce := uint64(0); nonce < sends; nonce++ {
+		if !nonces[nonce] {
+			t.Fatalf("nonce %d is not used, got %v", nonce, nonces)
+		}
+	}
+
+	// Every nonce was taken locally, none was refused by the node
+	if sent := backend.sentTransactions(); len(sent) != sends {
+		t.Fatalf("expected %d broadcasts, got %d", sends, len(sent))
+	}
+}
+
+func TestManagerWaitsForTheConfirmations(t *testing.T) {
+	manager, sim, backend, _ := newTestManager(t, Config{Confirmations: 3})
+
+	done := make(chan Result, 1)
+
+	go func() {
+		result, _ := manager.Send(context.Background(), transfer())
+		done <- result
+	}()
+
+	waitFor(t, "the broadcast", func() bool { return len(backend.sentTransactions()) == 1 })
+	sim.Commit()
+
+	select {
+	case result := <-done:
+		t.Fatalf("returned with a single confirmation: %s", result.Status)
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	sim.Commit()
+	sim.Commit()
+
+	select {
+	case result := <-done:
+		if result.Status != StatusConfirmed || result.Receipt.BlockNumber.Uint64() != 2 {
+			t.Fatalf("expected the transaction to be confirmed in the block 2, got %s", result.Status)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("not confirmed after 3 blocks")
+	}
+}
+
+func TestManagerRebroadcastsStuckTransactionsWithBumpedFees(t *testing.T) {
+	manager, sim, backend, _ := newTestManager(t, Config{StuckAfter: 50 * time.Millisecond, FeeBumpPercent: 20})
+
+	done := make(chan Result, 1)
+
+	go func() {
+		result, _ := manager.Send(context.Background(), transfer())
+		done <- result
+	}()
+
+	waitFor(t, "the rebroadcast", func() bool { return len(backend.sentTransactions()) >= 2 })
+	mine(t, sim)
+
+	var result Result
+
+	select {
+	case result = <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("not confirmed after the rebroadcast")
+	}
+
+	if result.Status != StatusConfirmed || result.Attempts < 2 {
+		t.Fatalf("expected a confirmed rebroadcast, got %s after %d attempts", result.Status, result.Attempts)
+	}
+
+	if result.Hash == result.Hashes[len(result.Hashes)-1] {
+		t.Fatal("expected a replacement to be mined, got the first version")
+	}
+
+	sent := backend.sentTransactions()
+	original, replacement := sent[0], sent[1]
+
+	if replacement.Nonce() != original.Nonce() {
+		t.Fatalf("replacement has the nonce %d instead of %d", replacement.Nonce(), original.Nonce())
+	}
+
+	minFeeCap := new(big.Int).Div(new(big.Int).Mul(original.GasFeeCap(), big.NewInt(120)), big.NewInt(100))
+	minTip := new(big.Int).Div(new(big.Int).Mul(original.GasTipCap(), big.NewInt(120)), big.NewInt(100))
+
+	if replacement.GasFeeCap().Cmp(minFeeCap) < 0 || replacement.GasTipCap().Cmp(minTip) < 0 {
+		t.Fatalf("fees are not bumped: %s/%s after %s/%s", replacement.GasTipCap(), replacement.GasFeeCap(),
+			original.GasTipCap(), original.GasFeeCap())
+	}
+}
+
+func TestManagerReportsReplacedTransactions(t *testing.T) {
+	manager, sim, backend, key := newTestManager(t, Config{StuckAfter: time.Hour})
+
+	// The node never sees the transaction of the manager
+	backend.setDrop(true)
+
+	done := make(chan error, 1)
+
+	go func() {
+		_, sErr := manager.Send(context.Background(), transfer())
+		done <- sErr
+	}()
+
+	waitFor(t, "the broadcast", func() bool { return len(backend.sentTransactions()) == 1 })
+
+	// Another sender of the account takes the nonce
+	other, sErr := types.SignNewTx(key, types.LatestSignerForChainID(manager.config.ChainID), &types.DynamicFeeTx{
+		Nonce:     0,
+		GasTipCap: big.NewInt(1e9),
+		GasFeeCap: big.NewInt(100e9),
+		Gas:       21000,
+		To:        &common.Address{0x12, 0x35},
+		Value:     big.NewInt(1),
+	})
+	if sErr != nil {
+		t.Fatal(sErr)
+	}
+
+	if sErr := backend.Client.SendTransaction(context.Background(), other); sErr != nil {
+		t.Fatal(sErr)
+	}
+
+	sim.Commit()
+
+	select {
+	case sErr := <-done:
+		if !errors.Is(sErr, ErrReplaced) {
+			t.Fatalf("expected ErrReplaced, got %v", sErr)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the replacement is not noticed")
+	}
+}
+
+func TestManagerReloadsTheNonceOfAnAbandonedTransaction(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  Config
+		timeout time.Duration
+		status  string
+	}{
+		{
+			name:    "stuck",
+			config:  Config{StuckAfter: 20 * time.Millisecond, MaxRebroadcasts: 1},
+			timeout: 5 * time.Second,
+			status:  StatusStuck,
+		},
+		{
+			name:    "cancelled",
+			config:  Config{StuckAfter: time.Hour},
+			timeout: 100 * time.Millisecond,
+			status:  StatusPending,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			manager, sim, backend, _ := newTestManager(t, test.config)
+
+			// The node drops the first transaction, its nonce stays free
+			backend.setDrop(true)
+
+			ctx, cancel := context.WithTimeout(context.Background(), test.timeout)
+			defer cancel()
+
+			if result, _ := manager.Send(ctx, transfer()); result.Status != test.status {
+				t.Fatalf("expected the %s status, got %s", test.status, result.Status)
+			}
+
+			backend.setDrop(false)
+			mine(t, sim)
+
+			ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			result, sErr := manager.Send(ctx, transfer())
+			if sErr != nil {
+				t.Fatal(sErr)
+			}
+
+			if result.Nonce != 0 {
+				t.Fatalf("expected the free nonce 0 to be reused, got %d", result.Nonce)
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"time"
+)
+
+/*
+DialChain connects to the node at rpcURL and checks that it serves the chain with the given id, so that a node of the
+wrong network is rejected at the startup instead of being read from. A zero id accepts any chain
+*/
+func DialChain(rpcURL string, chainID uint64) (*ethclient.Client, error) {
+	client, dErr := ethclient.Dial(rpcURL)
+	if dErr != nil {
+		return nil, dErr
+	}
+
+	if chainID == 0 {
+		return client, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	served, cErr := client.ChainID(ctx)
+	if cErr != nil {
+		client.Close()
+		return nil, fmt.Errorf("reading the chain id of %s: %w", rpcURL, cErr)
+	}
+
+	if !served.IsUint64() || served.Uint64() != chainID {
+		client.Close()
+		return nil, fmt.Errorf("%s serves the chain %s, the configured chain is %d", rpcURL, served, chainID)
+	}
+
+	return client, nil
+}
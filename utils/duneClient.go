@@ -0,0 +1,498 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"eigenlayer_hack/config"
+	"eigenlayer_hack/structs"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Default Dune API location
+const defaultDuneURL = "https://api.dune.com/api/v1"
+
+var (
+	// ErrDuneUnauthorized is returned when the API key is missing or rejected
+	ErrDuneUnauthorized = errors.New("dune: unauthorized")
+	// ErrDuneRateLimited is returned when the rate limit is still exceeded after the retries
+	ErrDuneRateLimited = errors.New("dune: rate limited")
+	// ErrDuneNotFound is returned for unknown endpoints, queries or executions
+	ErrDuneNotFound = errors.New("dune: not found")
+	// ErrDuneInvalidResponse is returned when the response cannot be parsed
+	ErrDuneInvalidResponse = errors.New("dune: invalid response")
+)
+
+/*
+DuneError is the error of a failed Dune API call, errors.Is matches it against the ErrDune* errors by status code
+*/
+type DuneError struct {
+	StatusCode int
+	Message    string
+	// RetryAfter is the wait time requested by the API, zero if it was not given
+	RetryAfter time.Duration
+}
+
+func (e *DuneError) Error() string {
+	return fmt.Sprintf("dune: status %d: %s", e.StatusCode, e.Message)
+}
+
+func (e *DuneError) Is(target error) bool {
+	switch target {
+	case ErrDuneUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden
+	case ErrDuneRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	case ErrDuneNotFound:
+		return e.StatusCode == http.StatusNotFound
+	}
+
+	return false
+}
+
+/*
+Temporary returns true if the call can succeed when it is retried
+*/
+func (e *DuneError) Temporary() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= http.StatusInternalServerError
+}
+
+/*
+DuneClientConfig holds the settings of the DuneClient, zero values are replaced with the defaults except for
+MaxRetries, where zero disables the retries
+*/
+type DuneClientConfig struct {
+	BaseURL string
+	APIKey  string
+	// Timeout of a single request
+	Timeout time.Duration
+	// MaxRetries is the number of retries after the first attempt
+	MaxRetries int
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	// MaxRetryAfter is the longest Retry-After that is waited for (default 2m), longer waits return the error
+	MaxRetryAfter time.Duration
+	// PageSize is the number of rows requested per page, zero leaves it to the API
+	PageSize int
+	// MaxPages stops runaway pagination (default 1000)
+	MaxPages int
+	// PollInterval of the execution status (default 2s)
+	PollInterval time.Duration
+	// AVSQueryID is the saved query that returns the AVS stats, zero uses the eigenlayer/avs-stats endpoint. The AVS
+	// address is passed in the avs_contract_address parameter
+	AVSQueryID int
+}
+
+/*
+DuneClient calls the Dune API. Failed reads are retried with a jittered exponential backoff, rate limited calls wait
+for the Retry-After time of the response. The other calls (e.g. the query executions) are retried only when they are
+rate limited with a Retry-After, other failures may have been carried out and are returned
+*/
+type DuneClient struct {
+	config     DuneClientConfig
+	httpClient *http.Client
+}
+
+/*
+NewDuneClient constructor for the DuneClient object
+*/
+func NewDuneClient(config DuneClientConfig) *DuneClient {
+	if config.BaseURL == "" {
+		config.BaseURL = defaultDuneURL
+	}
+
+	if config.Timeout <= 0 {
+		config.Timeout = 30 * time.Second
+	}
+
+	if config.MaxRetries < 0 {
+		config.MaxRetries = 0
+	}
+
+	if config.MinBackoff <= 0 {
+		config.MinBackoff = 500 * time.Millisecond
+	}
+
+	if config.MaxBackoff < config.MinBackoff {
+		config.MaxBackoff = 30 * time.Second
+	}
+
+	if config.MaxRetryAfter <= 0 {
+		config.MaxRetryAfter = 2 * time.Minute
+	}
+
+	if config.MaxPages <= 0 {
+		config.MaxPages = 1000
+	}
+
+	if config.PollInterval <= 0 {
+		config.PollInterval = 2 * time.Second
+	}
+
+	config.BaseURL = strings.TrimRight(config.BaseURL, "/")
+
+	return &DuneClient{config: config, httpClient: &http.Client{Timeout: config.Timeout}}
+}
+
+/*
+NewDuneClientFromConfig creates the client from the dune settings
+*/
+func NewDuneClientFromConfig(dune config.DuneConfig) *DuneClient {
+	return NewDuneClient(DuneClientConfig{
+		BaseURL:      dune.URL,
+		APIKey:       dune.Key,
+		Timeout:      dune.Timeout,
+		MaxRetries:   dune.MaxRetries,
+		PageSize:     dune.PageSize,
+		PollInterval: dune.PollInterval,
+		AVSQueryID:   dune.AVSQueryID,
+	})
+}
+
+/*
+GetOperatorStats returns the EigenLayer stats of the given operator
+*/
+func (c *DuneClient) GetOperatorStats(ctx context.Context, operatorAddress string) (structs.Response, error) {
+	return c.collectResponsePages(ctx, "/eigenlayer/operator-stats", url.Values{
+		"filters": {"operator_contract_address = " + strings.ToLower(operatorAddress)},
+	})
+}
+
+/*
+GetAVSStats returns the EigenLayer stats of the given AVS
+*/
+func (c *DuneClient) GetAVSStats(ctx context.Context, avsAddress string) (structs.Response, error) {
+	// Our own saved query is used instead of the preset endpoint when it is configured
+	if c.config.AVSQueryID > 0 {
+		return c.RunQuery(ctx, c.config.AVSQueryID, map[string]interface{}{
+			"avs_contract_address": strings.ToLower(avsAddress),
+		})
+	}
+
+	return c.collectResponsePages(ctx, "/eigenlayer/avs-stats", url.Values{
+		"filters": {"avs_contract_address = " + strings.ToLower(avsAddress)},
+	})
+}
+
+/*
+collectResponsePages fetches every page of the given endpoint and returns them as a single response
+*/
+func (c *DuneClient) collectResponsePages(ctx context.Context, path string, query url.Values) (structs.Response, error) {
+	stats := structs.Response{}
+
+	err := c.GetAllPages(ctx, path, query, func(body json.RawMessage) (string, error) {
+		page := structs.Response{}
+
+		if uErr := json.Unmarshal(body, &page); uErr != nil {
+			return "", fmt.Errorf("%w: %v", ErrDuneInvalidResponse, uErr)
+		}
+
+		rows := append(stats.Result.Rows, page.Result.Rows...)
+		stats = page
+		stats.Result.Rows = rows
+
+		return page.NextURI, nil
+	})
+
+	return stats, err
+}
+
+/*
+GetAllPages calls the given endpoint and follows the next_uri links until the last page. onPage is called with the
+body of every page and returns the next_uri of the page
+*/
+func (c *DuneClient) GetAllPages(ctx context.Context, path string, query url.Values,
+	onPage func(body json.RawMessage) (string, error)) error {
+	if c.config.PageSize > 0 && query.Get("limit") == "" {
+		query = cloneValues(query)
+		query.Set("limit", strconv.Itoa(c.config.PageSize))
+	}
+
+	body := json.RawMessage{}
+
+	if gErr := c.Get(ctx, path, query, &body); gErr != nil {
+		return gErr
+	}
+
+	for page := 1; ; page++ {
+		nextURI, pErr := onPage(body)
+		if pErr != nil {
+			return pErr
+		}
+
+		if nextURI == "" {
+			return nil
+		}
+
+		if page >= c.config.MaxPages {
+			return fmt.Errorf("%w: more than %d pages", ErrDuneInvalidResponse, c.config.MaxPages)
+		}
+
+		nextURL, rErr := c.resolveNextURI(nextURI)
+		if rErr != nil {
+			return rErr
+		}
+
+		body = json.RawMessage{}
+
+		if gErr := c.doURL(ctx, http.MethodGet, nextURL, nil, &body); gErr != nil {
+			return gErr
+		}
+	}
+}
+
+/*
+resolveNextURI returns the absolute URL of the next page. The API key is only sent to the host of the BaseURL
+*/
+func (c *DuneClient) resolveNextURI(nextURI string) (string, error) {
+	base, bErr := url.Parse(c.config.BaseURL + "/")
+	if bErr != nil {
+		return "", bErr
+	}
+
+	next, nErr := base.Parse(nextURI)
+	if nErr != nil {
+		return "", fmt.Errorf("%w: invalid next_uri %s", ErrDuneInvalidResponse, nextURI)
+	}
+
+	if next.Host != base.Host {
+		return "", fmt.Errorf("%w: next_uri points to another host %s", ErrDuneInvalidResponse, next.Host)
+	}
+
+	return next.String(), nil
+}
+
+/*
+cloneValues returns a copy of the query values
+*/
+func cloneValues(values url.Values) url.Values {
+	clone := url.Values{}
+
+	for key, value := range values {
+		clone[key] = append([]string{}, value...)
+	}
+
+	return clone
+}
+
+/*
+Get calls the given endpoint with the query parameters and decodes the JSON response into out
+*/
+func (c *DuneClient) Get(ctx context.Context, path string, query url.Values, out interface{}) error {
+	return c.Do(ctx, http.MethodGet, path, query, nil, out)
+}
+
+/*
+Do calls the given endpoint and decodes the JSON response into out (if it is not nil). The body is sent as JSON
+*/
+func (c *DuneClient) Do(ctx context.Context, method string, path string, query url.Values, body interface{},
+	out interface{}) error {
+	endpoint := c.config.BaseURL + "/" + strings.TrimLeft(path, "/")
+
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+
+	return c.doURL(ctx, method, endpoint, body, out)
+}
+
+/*
+doURL calls the given absolute URL, temporary failures are retried up to MaxRetries times. Only GET calls are retried
+on the network and server errors, the others only when they are rate limited with a Retry-After
+*/
+func (c *DuneClient) doURL(ctx context.Context, method string, endpoint string, body interface{}, out interface{}) error {
+	if _, pErr := url.Parse(endpoint); pErr != nil {
+		return pErr
+	}
+
+	var payload []byte
+
+	if body != nil {
+		var mErr error
+
+		if payload, mErr = json.Marshal(body); mErr != nil {
+			return mErr
+		}
+	}
+
+	var lastErr error
+
+	for attempt := 0; attempt <= c.config.MaxRetries; attempt++ {
+		if attempt > 0 {
+			wait, isWaitable := c.retryWait(ctx, attempt, lastErr)
+			if !isWaitable {
+				return lastErr
+			}
+
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(wait):
+			}
+		}
+
+		lastErr = c.do(ctx, method, endpoint, payload, out)
+
+		if lastErr == nil || !isRetryable(ctx, method, lastErr) {
+			return lastErr
+		}
+	}
+
+	return lastErr
+}
+
+/*
+retryWait returns the wait before the given retry, the Retry-After of the failed call or the backoff. Returns false
+when the requested Retry-After is longer than MaxRetryAfter or ends after the deadline of the context
+*/
+func (c *DuneClient) retryWait(ctx context.Context, attempt int, lastErr error) (time.Duration, bool) {
+	var duneErr *DuneError
+	if !errors.As(lastErr, &duneErr) || duneErr.RetryAfter <= 0 {
+		return c.backoff(attempt), true
+	}
+
+	if duneErr.RetryAfter > c.config.MaxRetryAfter {
+		return 0, false
+	}
+
+	if deadline, hasDeadline := ctx.Deadline(); hasDeadline && time.Now().Add(duneErr.RetryAfter).After(deadline) {
+		return 0, false
+	}
+
+	return duneErr.RetryAfter, true
+}
+
+/*
+do executes a single request
+*/
+func (c *DuneClient) do(ctx context.Context, method string, endpoint string, payload []byte, out interface{}) error {
+	var body io.Reader
+
+	if payload != nil {
+		body = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, body)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("X-DUNE-API-KEY", c.config.APIKey)
+
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return &DuneError{
+			StatusCode: resp.StatusCode,
+			Message:    errorMessage(respBody, resp.Status),
+			RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+		}
+	}
+
+	if out == nil {
+		return nil
+	}
+
+	if uErr := json.Unmarshal(respBody, out); uErr != nil {
+		return fmt.Errorf("%w: %v", ErrDuneInvalidResponse, uErr)
+	}
+
+	return nil
+}
+
+/*
+backoff returns the jittered wait time before the given retry: a random duration between the half and the whole of
+MinBackoff * 2^(attempt-1), capped at MaxBackoff
+*/
+func (c *DuneClient) backoff(attempt int) time.Duration {
+	ceiling := c.config.MinBackoff << (attempt - 1)
+
+	if ceiling <= 0 || ceiling > c.config.MaxBackoff {
+		ceiling = c.config.MaxBackoff
+	}
+
+	return ceiling/2 + time.Duration(rand.Int63n(int64(ceiling/2)+1))
+}
+
+/*
+isRetryable returns true if the failed call should be retried. Calls that are not reads may have been carried out by
+the API despite the error (e.g. a started execution costs credits), they are retried only when rate limited with a
+Retry-After
+*/
+func isRetryable(ctx context.Context, method string, err error) bool {
+	// The caller gave up, retrying is pointless
+	if ctx.Err() != nil {
+		return false
+	}
+
+	var duneErr *DuneError
+	isDuneErr := errors.As(err, &duneErr)
+
+	if method != http.MethodGet {
+		return isDuneErr && duneErr.StatusCode == http.StatusTooManyRequests && duneErr.RetryAfter > 0
+	}
+
+	if isDuneErr {
+		return duneErr.Temporary()
+	}
+
+	// Network errors and timeouts are retried as well
+	return !errors.Is(err, ErrDuneInvalidResponse)
+}
+
+/*
+errorMessage returns the error of the Dune error response, falls back to the HTTP status
+*/
+func errorMessage(body []byte, status string) string {
+	errorResponse := struct {
+		Error string `json:"error"`
+	}{}
+
+	if json.Unmarshal(body, &errorResponse) == nil && errorResponse.Error != "" {
+		return errorResponse.Error
+	}
+
+	return status
+}
+
+/*
+parseRetryAfter parses the Retry-After header, given either in seconds or as an HTTP date
+*/
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+
+	if seconds, pErr := strconv.Atoi(value); pErr == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+
+	if date, pErr := http.ParseTime(value); pErr == nil {
+		if wait := time.Until(date); wait > 0 {
+			return wait
+		}
+	}
+
+	return 0
+}
@@ -0,0 +1,137 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+/*
+newFakeDune serves the responses in order, the last one is repeated. Returns the client and the call counter
+*/
+func newFakeDune(t *testing.T, responses ...func(w http.ResponseWriter)) (*DuneClient, *int32) {
+	t.Helper()
+
+	calls := new(int32)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		call := int(atomic.AddInt32(calls, 1)) - 1
+		responses[min(call, len(responses)-1)](w)
+	}))
+	t.Cleanup(server.Close)
+
+	return NewDuneClient(DuneClientConfig{
+		BaseURL:       server.URL,
+		MaxRetries:    3,
+		MinBackoff:    time.Millisecond,
+		MaxBackoff:    5 * time.Millisecond,
+		MaxRetryAfter: 2 * time.Second,
+	}), calls
+}
+
+func status(code int, retryAfter string) func(w http.ResponseWriter) {
+	return func(w http.ResponseWriter) {
+		if retryAfter != "" {
+			w.Header().Set("Retry-After", retryAfter)
+		}
+
+		w.WriteHeader(code)
+		_, _ = w.Write([]byte(`{"error":"failed"}`))
+	}
+}
+
+func executed(w http.ResponseWriter) {
+	_, _ = w.Write([]byte(`{"execution_id":"01H","state":"QUERY_STATE_PENDING"}`))
+}
+
+func TestDuneClientRetries(t *testing.T) {
+	tests := []struct {
+		name      string
+		responses []func(w http.ResponseWriter)
+		execute   bool
+		timeout   time.Duration
+		calls     int32
+		err       error
+	}{
+		{
+			name:      "execution is not retried on a server error",
+			responses: []func(w http.ResponseWriter){status(http.StatusBadGateway, ""), executed},
+			execute:   true,
+			calls:     1,
+			err:       &DuneError{},
+		},
+		{
+			name:      "execution is not retried on a rate limit without Retry-After",
+			responses: []func(w http.ResponseWriter){status(http.StatusTooManyRequests, ""), executed},
+			execute:   true,
+			calls:     1,
+			err:       ErrDuneRateLimited,
+		},
+		{
+			name:      "execution is retried after the Retry-After",
+			responses: []func(w http.ResponseWriter){status(http.StatusTooManyRequests, "1"), executed},
+			execute:   true,
+			calls:     2,
+		},
+		{
+			name:      "read is retried on a server error",
+			responses: []func(w http.ResponseWriter){status(http.StatusServiceUnavailable, ""), executed},
+			calls:     2,
+		},
+		{
+			name:      "Retry-After above the maximum is not waited for",
+			responses: []func(w http.ResponseWriter){status(http.StatusTooManyRequests, "60"), executed},
+			calls:     1,
+			err:       ErrDuneRateLimited,
+		},
+		{
+			name:      "Retry-After after the deadline is not waited for",
+			responses: []func(w http.ResponseWriter){status(http.StatusTooManyRequests, "1"), executed},
+			timeout:   500 * time.Millisecond,
+			calls:     1,
+			err:       ErrDuneRateLimited,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client, calls := newFakeDune(t, test.responses...)
+
+			ctx := context.Background()
+
+			if test.timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, test.timeout)
+				defer cancel()
+			}
+
+			var err error
+
+			if test.execute {
+				_, err = client.ExecuteQuery(ctx, 42, map[string]interface{}{"avs": "0x1"})
+			} else {
+				_, err = client.GetExecutionStatus(ctx, "01H")
+			}
+
+			if got := atomic.LoadInt32(calls); got != test.calls {
+				t.Errorf("expected %d calls, got %d", test.calls, got)
+			}
+
+			var duneErr *DuneError
+
+			switch {
+			case test.err == nil && err != nil:
+				t.Errorf("unexpected error: %v", err)
+			case test.err == nil:
+			case errors.As(test.err, &duneErr) && !errors.As(err, &duneErr):
+				t.Errorf("expected a Dune error, got %v", err)
+			case !errors.As(test.err, &duneErr) && !errors.Is(err, test.err):
+				t.Errorf("expected %v, got %v", test.err, err)
+			}
+		})
+	}
+}
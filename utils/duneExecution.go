@@ -0,0 +1,153 @@
+package utils
+
+import (
+	"context"
+	"eigenlayer_hack/structs"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"solity/utils/logger"
+	"strconv"
+	"time"
+)
+
+// ErrDuneExecutionFailed is returned when an execution ends in the failed, cancelled or expired state
+var ErrDuneExecutionFailed = errors.New("dune: execution failed")
+
+/*
+ExecuteQuery starts the execution of the saved query with the given parameters
+*/
+func (c *DuneClient) ExecuteQuery(ctx context.Context, queryID int, parameters map[string]interface{}) (structs.Execution, error) {
+	execution := structs.Execution{}
+
+	err := c.Do(ctx, http.MethodPost, "/query/"+strconv.Itoa(queryID)+"/execute", nil,
+		structs.ExecuteQueryRequest{QueryParameters: parameters}, &execution)
+
+	return execution, err
+}
+
+/*
+GetExecutionStatus returns the current status of the execution
+*/
+func (c *DuneClient) GetExecutionStatus(ctx context.Context, executionID string) (structs.ExecutionStatus, error) {
+	status := structs.ExecutionStatus{}
+
+	err := c.Get(ctx, "/execution/"+url.PathEscape(executionID)+"/status", nil, &status)
+
+	return status, err
+}
+
+/*
+WaitForExecution polls the status of the execution every PollInterval until it reaches a terminal state. Executions
+that do not complete are returned with ErrDuneExecutionFailed
+*/
+func (c *DuneClient) WaitForExecution(ctx context.Context, executionID string) (structs.ExecutionStatus, error) {
+	for {
+		status, sErr := c.GetExecutionStatus(ctx, executionID)
+		if sErr != nil {
+			return status, sErr
+		}
+
+		if structs.IsTerminalState(status.State) {
+			return status, executionError(status)
+		}
+
+		select {
+		case <-ctx.Done():
+			return status, ctx.Err()
+		case <-time.After(c.config.PollInterval):
+		}
+	}
+}
+
+/*
+CancelExecution cancels the execution
+*/
+func (c *DuneClient) CancelExecution(ctx context.Context, executionID string) error {
+	response := structs.CancelResponse{}
+
+	if err := c.Do(ctx, http.MethodPost, "/execution/"+url.PathEscape(executionID)+"/cancel", nil, nil, &response); err != nil {
+		return err
+	}
+
+	if !response.Success {
+		return errors.New("dune: execution " + executionID + " could not be cancelled")
+	}
+
+	return nil
+}
+
+/*
+GetExecutionResults returns every row of the execution. If the execution is not finished yet it is waited for first
+*/
+func (c *DuneClient) GetExecutionResults(ctx context.Context, executionID string) (structs.Response, error) {
+	path := "/execution/" + url.PathEscape(executionID) + "/results"
+
+	results, err := c.collectResponsePages(ctx, path, url.Values{})
+	if err != nil {
+		return results, err
+	}
+
+	// A pending execution returns no rows, it is not the final result
+	if !results.IsExecutionFinished && !structs.IsTerminalState(results.State) {
+		if _, wErr := c.WaitForExecution(ctx, executionID); wErr != nil {
+			return results, wErr
+		}
+
+		return c.collectResponsePages(ctx, path, url.Values{})
+	}
+
+	return results, executionError(structs.ExecutionStatus{ExecutionID: executionID, State: results.State})
+}
+
+/*
+GetLatestQueryResults returns the rows of the latest execution of the saved query without executing it again
+*/
+func (c *DuneClient) GetLatestQueryResults(ctx context.Context, queryID int) (structs.Response, error) {
+	return c.collectResponsePages(ctx, "/query/"+strconv.Itoa(queryID)+"/results", url.Values{})
+}
+
+/*
+RunQuery executes the saved query with the given parameters, waits for it and returns every row of the result. If
+the context is cancelled while the query is running, the execution is cancelled so that it does not consume credits
+*/
+func (c *DuneClient) RunQuery(ctx context.Context, queryID int, parameters map[string]interface{}) (structs.Response, error) {
+	execution, eErr := c.ExecuteQuery(ctx, queryID, parameters)
+	if eErr != nil {
+		return structs.Response{}, eErr
+	}
+
+	if _, wErr := c.WaitForExecution(ctx, execution.ExecutionID); wErr != nil {
+		if ctx.Err() != nil {
+			cancelCtx, cancel := context.WithTimeout(context.Background(), c.config.Timeout)
+			defer cancel()
+
+			if cErr := c.CancelExecution(cancelCtx, execution.ExecutionID); cErr != nil {
+				logger.LogW("Error while cancelling the dune execution ", execution.ExecutionID, ": ", cErr)
+			}
+		}
+
+		return structs.Response{}, wErr
+	}
+
+	return c.GetExecutionResults(ctx, execution.ExecutionID)
+}
+
+/*
+executionError returns ErrDuneExecutionFailed for the executions that ended without a (partial) result
+*/
+func executionError(status structs.ExecutionStatus) error {
+	switch status.State {
+	case structs.QueryStateFailed, structs.QueryStateCancelled, structs.QueryStateExpired:
+		message := status.State
+
+		if status.Error != nil {
+			message += ": " + status.Error.Message
+		}
+
+		return fmt.Errorf("%w: %s %s", ErrDuneExecutionFailed, status.ExecutionID, message)
+	}
+
+	return nil
+}
@@ -0,0 +1,235 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+/*
+duneServer fakes the Dune API, every route serves its responses in order and repeats the last one
+*/
+type duneServer struct {
+	mu       sync.Mutex
+	routes   map[string][]string
+	requests []*http.Request
+	bodies   []string
+}
+
+func newDuneServer(t *testing.T, routes map[string][]string) (*duneServer, *httptest.Server) {
+	t.Helper()
+
+	fake := &duneServer{routes: routes}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		fake.mu.Lock()
+		defer fake.mu.Unlock()
+
+		fake.requests = append(fake.requests, r)
+		fake.bodies = append(fake.bodies, string(body))
+
+		responses := fake.routes[r.Method+" "+r.URL.Path]
+
+		if len(responses) == 0 {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":"not found"}`))
+			return
+		}
+
+		_, _ = w.Write([]byte(responses[0]))
+
+		if len(responses) > 1 {
+			fake.routes[r.Method+" "+r.URL.Path] = responses[1:]
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return fake, server
+}
+
+/*
+calls returns the number of requests of the route
+*/
+func (d *duneServer) calls(route string) int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	count := 0
+
+	for _, r := range d.requests {
+		if r.Method+" "+r.URL.Path == route {
+			count++
+		}
+	}
+
+	return count
+}
+
+func TestDuneClientFollowsTheNextPages(t *testing.T) {
+	routes := map[string][]string{}
+	fake, server := newDuneServer(t, routes)
+	serverURL := server.URL
+
+	routes["GET /eigenlayer/avs-stats"] = []string{
+		`{"state":"QUERY_STATE_COMPLETED","result":{"rows":[{"avs_name":"EigenDA"},{"avs_name":"Lagrange"}]},` +
+			`"next_uri":"` + serverURL + `/eigenlayer/avs-stats?limit=2&offset=2","next_offset":2}`,
+		`{"state":"QUERY_STATE_COMPLETED","result":{"rows":[{"avs_name":"Witness Chain"}]}}`,
+	}
+
+	client := NewDuneClient(DuneClientConfig{BaseURL: serverURL, APIKey: "secret", PageSize: 2})
+
+	stats, gErr := client.GetAVSStats(context.Background(), "0xAVS")
+	if gErr != nil {
+		t.Fatal(gErr)
+	}
+
+	if len(stats.Result.Rows) != 3 {
+		t.Fatalf("expected the rows of both pages, got %d", len(stats.Result.Rows))
+	}
+
+	if fake.calls("GET /eigenlayer/avs-stats") != 2 {
+		t.Fatalf("expected 2 page requests, got %d", fake.calls("GET /eigenlayer/avs-stats"))
+	}
+
+	first := fake.requests[0]
+
+	if first.URL.Query().Get("limit") != "2" || first.URL.Query().Get("filters") != "avs_contract_address = 0xavs" {
+		t.Fatalf("unexpected query of the first page %s", first.URL.RawQuery)
+	}
+
+	for _, r := range fake.requests {
+		if r.Header.Get("X-DUNE-API-KEY") != "secret" {
+			t.Fatalf("expected the API key on %s", r.URL)
+		}
+	}
+}
+
+func TestDuneClientStopsTheRunawayPagination(t *testing.T) {
+	tests := []struct {
+		name    string
+		nextURI func(serverURL string) string
+		calls   int
+	}{
+		{
+			name:    "next_uri of another host",
+			nextURI: func(string) string { return "https://attacker.example/eigenlayer/avs-stats?offset=1" },
+			calls:   1,
+		},
+		{
+			name:    "more pages than MaxPages",
+			nextURI: func(serverURL string) string { return serverURL + "/eigenlayer/avs-stats?offset=1" },
+			calls:   3,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			routes := map[string][]string{}
+			fake, server := newDuneServer(t, routes)
+
+			routes["GET /eigenlayer/avs-stats"] = []string{
+				`{"result":{"rows":[]},"next_uri":"` + test.nextURI(server.URL) + `"}`,
+			}
+
+			client := NewDuneClient(DuneClientConfig{BaseURL: server.URL, APIKey: "secret", MaxPages: 3})
+
+			if _, gErr := client.GetAVSStats(context.Background(), "0xavs"); !errors.Is(gErr, ErrDuneInvalidResponse) {
+				t.Fatalf("expected ErrDuneInvalidResponse, got %v", gErr)
+			}
+
+			if calls := fake.calls("GET /eigenlayer/avs-stats"); calls != test.calls {
+				t.Fatalf("expected %d requests, got %d", test.calls, calls)
+			}
+		})
+	}
+}
+
+func TestDuneClientRunsTheSavedQuery(t *testing.T) {
+	fake, server := newDuneServer(t, map[string][]string{
+		"POST /query/42/execute": {`{"execution_id":"01H","state":"QUERY_STATE_PENDING"}`},
+		"GET /execution/01H/status": {
+			`{"execution_id":"01H","state":"QUERY_STATE_EXECUTING"}`,
+			`{"execution_id":"01H","state":"QUERY_STATE_COMPLETED","is_execution_finished":true}`,
+		},
+		"GET /execution/01H/results": {
+			`{"execution_id":"01H","state":"QUERY_STATE_COMPLETED","is_execution_finished":true,` +
+				`"result":{"rows":[{"avs_name":"EigenDA"}]}}`,
+		},
+	})
+
+	client := NewDuneClient(DuneClientConfig{BaseURL: server.URL, AVSQueryID: 42, PollInterval: time.Millisecond})
+
+	stats, gErr := client.GetAVSStats(context.Background(), "0xAVS")
+	if gErr != nil {
+		t.Fatal(gErr)
+	}
+
+	if len(stats.Result.Rows) != 1 || stats.ExecutionID != "01H" {
+		t.Fatalf("expected the row of the execution, got %+v", stats)
+	}
+
+	if fake.calls("GET /execution/01H/status") != 2 {
+		t.Fatalf("expected the status to be polled until completion, got %d polls",
+			fake.calls("GET /execution/01H/status"))
+	}
+
+	request := struct {
+		QueryParameters map[string]string `json:"query_parameters"`
+	}{}
+
+	if uErr := json.Unmarshal([]byte(fake.bodies[0]), &request); uErr != nil {
+		t.Fatal(uErr)
+	}
+
+	if request.QueryParameters["avs_contract_address"] != "0xavs" {
+		t.Fatalf("expected the AVS parameter, got %s", fake.bodies[0])
+	}
+}
+
+func TestDuneClientReportsTheFailedExecution(t *testing.T) {
+	_, server := newDuneServer(t, map[string][]string{
+		"POST /query/42/execute": {`{"execution_id":"01H","state":"QUERY_STATE_PENDING"}`},
+		"GET /execution/01H/status": {
+			`{"execution_id":"01H","state":"QUERY_STATE_FAILED","error":{"type":"FAILED_TYPE_EXECUTION_FAILED",` +
+				`"message":"line 1:8: Column 'avs' cannot be resolved"}}`,
+		},
+	})
+
+	client := NewDuneClient(DuneClientConfig{BaseURL: server.URL, PollInterval: time.Millisecond})
+
+	_, rErr := client.RunQuery(context.Background(), 42, nil)
+
+	if !errors.Is(rErr, ErrDuneExecutionFailed) || !strings.Contains(rErr.Error(), "cannot be resolved") {
+		t.Fatalf("expected the execution error, got %v", rErr)
+	}
+}
+
+func TestDuneClientCancelsTheAbandonedExecution(t *testing.T) {
+	fake, server := newDuneServer(t, map[string][]string{
+		"POST /query/42/execute":     {`{"execution_id":"01H","state":"QUERY_STATE_PENDING"}`},
+		"GET /execution/01H/status":  {`{"execution_id":"01H","state":"QUERY_STATE_EXECUTING"}`},
+		"POST /execution/01H/cancel": {`{"success":true}`},
+	})
+
+	client := NewDuneClient(DuneClientConfig{BaseURL: server.URL, PollInterval: 5 * time.Millisecond})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if _, rErr := client.RunQuery(ctx, 42, nil); !errors.Is(rErr, context.DeadlineExceeded) {
+		t.Fatalf("expected the deadline to stop the query, got %v", rErr)
+	}
+
+	if fake.calls("POST /execution/01H/cancel") != 1 {
+		t.Fatal("expected the execution to be cancelled")
+	}
+}
@@ -0,0 +1,231 @@
+package utils
+
+import (
+	"context"
+	"eigenlayer_hack/metrics"
+	"eigenlayer_hack/structs"
+	"eigenlayer_hack/txmanager"
+	"errors"
+	"solity/utils/logger"
+	"sync"
+	"time"
+)
+
+// ErrWriterClosed is returned for the writes submitted after the registry writer is closed
+var ErrWriterClosed = errors.New("registry writer is closed")
+
+/*
+batchSender sends the payloads in a single transaction and returns its final status
+*/
+type batchSender func(ctx context.Context, payloads []structs.EigenlayerPayload) (txmanager.Result, error)
+
+type batchRequest struct {
+	payload structs.EigenlayerPayload
+	outcome chan batchOutcome
+}
+
+type batchOutcome struct {
+	result WriteResult
+	err    error
+}
+
+/*
+registryBatcher collects the writes until maxSize writes are waiting or the oldest one waited maxWait, then sends them
+in a single registerEvents transaction. Every write is acknowledged with the result of its batch and its position in it.
+The batches are sent with the context of the batcher, it is cancelled when the batches in flight are not final within
+shutdownTimeout of close
+*/
+type registryBatcher struct {
+	send            batchSender
+	maxSize         int
+	maxWait         time.Duration
+	shutdownTimeout time.Duration
+
+	requests chan batchRequest
+	ctx      context.Context
+	cancel   context.CancelFunc
+	inFlight sync.WaitGroup
+	done     chan struct{}
+
+	mu       sync.RWMutex
+	isClosed bool
+}
+
+/*
+newRegistryBatcher constructor for the registryBatcher object, it collects the writes until close
+*/
+func newRegistryBatcher(send batchSender, maxSize int, maxWait time.Duration,
+	shutdownTimeout time.Duration) *registryBatcher {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	batcher := &registryBatcher{
+		send:            send,
+		maxSize:         maxSize,
+		maxWait:         maxWait,
+		shutdownTimeout: shutdownTimeout,
+		requests:        make(chan batchRequest, maxSize),
+		ctx:             ctx,
+		cancel:          cancel,
+		done:            make(chan struct{}),
+	}
+
+	go batcher.run()
+
+	return batcher
+}
+
+/*
+submit adds the payload to the next batch and waits for the final status of the batch transaction. If the context is
+cancelled before, the result has the pending status and the payload stays in its batch
+*/
+func (b *registryBatcher) submit(ctx context.Context, payload structs.EigenlayerPayload) (WriteResult, error) {
+	request := batchRequest{payload: payload, outcome: make(chan batchOutcome, 1)}
+
+	b.mu.RLock()
+	if b.isClosed {
+		b.mu.RUnlock()
+		return WriteResult{}, ErrWriterClosed
+	}
+
+	b.requests <- request
+	b.mu.RUnlock()
+
+	select {
+	case outcome := <-request.outcome:
+		return outcome.result, outcome.err
+	case <-ctx.Done():
+		return WriteResult{Result: txmanager.Result{Status: txmanager.StatusPending}}, ctx.Err()
+	}
+}
+
+/*
+run collects the requests into batches until the requests channel is closed, the last batch is sent then
+*/
+func (b *registryBatcher) run() {
+	defer close(b.done)
+
+	pending := []batchRequest{}
+	var deadline <-chan time.Time
+
+	for {
+		select {
+		case request, isOpen := <-b.requests:
+			if !isOpen {
+				b.flush(pending)
+				return
+			}
+
+			pending = append(pending, request)
+
+			if len(pending) == 1 {
+				deadline = time.After(b.maxWait)
+			}
+
+			if len(pending) >= b.maxSize {
+				b.flush(pending)
+				pending, deadline = []batchRequest{}, nil
+			}
+		case <-deadline:
+			b.flush(pending)
+			pending, deadline = []batchRequest{}, nil
+		}
+	}
+}
+
+/*
+flush sends the batch in the background, so the next batch is collected while this one waits for its confirmations
+*/
+func (b *registryBatcher) flush(batch []batchRequest) {
+	if len(batch) == 0 {
+		return
+	}
+
+	b.inFlight.Add(1)
+
+	go func() {
+		defer b.inFlight.Done()
+
+		payloads := make([]structs.EigenlayerPayload, len(batch))
+		for i, request := range batch {
+			payloads[i] = request.payload
+		}
+
+		metrics.RegistryBatches.Add(1)
+
+		result, sErr := b.send(b.ctx, payloads)
+
+		// Not sent, most likely because an entry makes the batch revert in the estimation, or mined and reverted: the
+		// entries are sent one by one so that only the failing ones fail
+		isFailed := result.Status == txmanager.StatusFailed || result.Status == txmanager.StatusReverted
+		if sErr != nil && isFailed && len(batch) > 1 {
+			logger.LogW("Batch of ", len(batch), " registry writes failed with the ", result.Status,
+				" status, sending them one by one: ", sErr)
+			b.sendEach(batch)
+
+			return
+		}
+
+		for i, request := range batch {
+			request.outcome <- batchOutcome{
+				result: WriteResult{Result: result, BatchIndex: i, BatchSize: len(batch)},
+				err:    sErr,
+			}
+		}
+	}()
+}
+
+/*
+sendEach sends every request of the batch in its own transaction
+*/
+func (b *registryBatcher) sendEach(batch []batchRequest) {
+	group := new(sync.WaitGroup)
+
+	for _, request := range batch {
+		group.Add(1)
+
+		go func(request batchRequest) {
+			defer group.Done()
+
+			result, sErr := b.send(b.ctx, []structs.EigenlayerPayload{request.payload})
+			request.outcome <- batchOutcome{result: WriteResult{Result: result, BatchSize: 1}, err: sErr}
+		}(request)
+	}
+
+	group.Wait()
+}
+
+/*
+close sends the collected writes and waits for the batches in flight, up to shutdownTimeout: the batches that are not
+final then are abandoned with the pending status, their transactions may still be mined. The writes submitted after
+are rejected
+*/
+func (b *registryBatcher) close() {
+	b.mu.Lock()
+	if b.isClosed {
+		b.mu.Unlock()
+		return
+	}
+
+	b.isClosed = true
+	close(b.requests)
+	b.mu.Unlock()
+
+	<-b.done
+
+	finished := make(chan struct{})
+
+	go func() {
+		b.inFlight.Wait()
+		close(finished)
+	}()
+
+	select {
+	case <-finished:
+	case <-time.After(b.shutdownTimeout):
+		logger.LogW("Registry batches are not final after ", b.shutdownTimeout, ", leaving them pending")
+		b.cancel()
+		<-finished
+	}
+
+	b.cancel()
+}
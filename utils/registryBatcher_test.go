@@ -0,0 +1,103 @@
+package utils
+
+import (
+	"context"
+	"eigenlayer_hack/structs"
+	"eigenlayer_hack/txmanager"
+	"errors"
+	"github.com/ethereum/go-ethereum/common"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRegistryBatcherSendsRevertedBatchesOneByOne(t *testing.T) {
+	sizes := make(chan int, 10)
+
+	batcher := newRegistryBatcher(func(_ context.Context, payloads []structs.EigenlayerPayload) (txmanager.Result,
+		error) {
+		sizes <- len(payloads)
+
+		if len(payloads) > 1 {
+			return txmanager.Result{Status: txmanager.StatusReverted}, txmanager.ErrReverted
+		}
+
+		return txmanager.Result{Status: txmanager.StatusConfirmed}, nil
+	}, 2, time.Second, time.Second)
+	defer batcher.close()
+
+	var group sync.WaitGroup
+
+	for index := 0; index < 2; index++ {
+		group.Add(1)
+
+		go func(index int) {
+			defer group.Done()
+
+			result, sErr := batcher.submit(context.Background(), structs.EigenlayerPayload{
+				AvsName:         "avs",
+				OperatorAddress: common.BigToAddress(big.NewInt(int64(index))),
+			})
+			if sErr != nil {
+				t.Error(sErr)
+			}
+
+			if result.Status != txmanager.StatusConfirmed || result.BatchSize != 1 {
+				t.Errorf("expected a confirmed single write, got %s in a batch of %d", result.Status, result.BatchSize)
+			}
+		}(index)
+	}
+
+	group.Wait()
+	close(sizes)
+
+	sent := []int{}
+	for size := range sizes {
+		sent = append(sent, size)
+	}
+
+	if len(sent) != 3 || sent[0] != 2 {
+		t.Fatalf("expected the batch of 2 and then 2 single writes, got %v", sent)
+	}
+}
+
+func TestRegistryBatcherCancelsTheBatchesAfterTheShutdownTimeout(t *testing.T) {
+	batcher := newRegistryBatcher(func(ctx context.Context, _ []structs.EigenlayerPayload) (txmanager.Result, error) {
+		// Never mined
+		<-ctx.Done()
+
+		return txmanager.Result{Status: txmanager.StatusPending}, ctx.Err()
+	}, 2, time.Millisecond, 50*time.Millisecond)
+
+	outcome := make(chan error, 1)
+
+	go func() {
+		_, sErr := batcher.submit(context.Background(), structs.EigenlayerPayload{AvsName: "avs", OperatorName: "op"})
+		outcome <- sErr
+	}()
+
+	// The write is in flight once its batch is sent
+	time.Sleep(20 * time.Millisecond)
+
+	closed := make(chan struct{})
+
+	go func() {
+		batcher.close()
+		close(closed)
+	}()
+
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("close waits for the batch past the shutdown timeout")
+	}
+
+	if sErr := <-outcome; !errors.Is(sErr, context.Canceled) {
+		t.Fatalf("expected the write to be cancelled, got %v", sErr)
+	}
+
+	if _, sErr := batcher.submit(context.Background(), structs.EigenlayerPayload{}); !errors.Is(sErr, ErrWriterClosed) {
+		t.Fatalf("expected ErrWriterClosed after close, got %v", sErr)
+	}
+}
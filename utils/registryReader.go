@@ -0,0 +1,169 @@
+package utils
+
+import (
+	"context"
+	"eigenlayer_hack/config"
+	registery "eigenlayer_hack/eigenlayerContract"
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"math/big"
+	"time"
+)
+
+/*
+RegistryEntry is an entry of the registry contract, ID is its index in events
+*/
+type RegistryEntry struct {
+	ID              uint64    `json:"id"`
+	AvsName         string    `json:"avsName"`
+	OperatorName    string    `json:"operatorName"`
+	AvsAddress      string    `json:"avsAddress"`
+	OperatorAddress string    `json:"operatorAddress"`
+	Timestamp       time.Time `json:"timestamp"`
+}
+
+/*
+RegistryBackend is the part of the node client used to read the registry
+*/
+type RegistryBackend interface {
+	bind.ContractBackend
+	BlockNumber(ctx context.Context) (uint64, error)
+	ChainID(ctx context.Context) (*big.Int, error)
+}
+
+/*
+RegistryReader reads the state of the registry contract
+*/
+type RegistryReader struct {
+	client   RegistryBackend
+	closer   func()
+	contract *registery.Registery
+	address  common.Address
+}
+
+/*
+NewRegistryReaderFromConfig connects to the registry of the registry settings
+*/
+func NewRegistryReaderFromConfig(registry config.RegistryConfig) (*RegistryReader, error) {
+	client, dErr := DialChain(registry.RPC, registry.ChainID)
+	if dErr != nil {
+		return nil, dErr
+	}
+
+	reader, rErr := NewRegistryReader(client, common.HexToAddress(registry.Address))
+	if rErr != nil {
+		client.Close()
+		return nil, rErr
+	}
+
+	reader.closer = client.Close
+
+	return reader, nil
+}
+
+/*
+NewRegistryReader creates the reader of the registry at address
+*/
+func NewRegistryReader(client RegistryBackend, address common.Address) (*RegistryReader, error) {
+	contract, bErr := registery.NewRegistery(address, client)
+	if bErr != nil {
+		return nil, bErr
+	}
+
+	return &RegistryReader{client: client, contract: contract, address: address}, nil
+}
+
+func (rR *RegistryReader) Address() common.Address {
+	return rR.address
+}
+
+func (rR *RegistryReader) Client() RegistryBackend {
+	return rR.client
+}
+
+func (rR *RegistryReader) Contract() *registery.Registery {
+	return rR.contract
+}
+
+/*
+Head returns the latest block number, the reads of a command are pinned to it so they see a single state
+*/
+func (rR *RegistryReader) Head(ctx context.Context) (uint64, error) {
+	return rR.client.BlockNumber(ctx)
+}
+
+/*
+Count returns the number of entries at the block, nil is the latest block
+*/
+func (rR *RegistryReader) Count(ctx context.Context, block *big.Int) (uint64, error) {
+	count, cErr := rR.contract.EventCount(&bind.CallOpts{Context: ctx, BlockNumber: block})
+	if cErr != nil {
+		return 0, rR.callError(ctx, cErr)
+	}
+
+	return count.Uint64(), nil
+}
+
+/*
+Entry reads the entry with the id at the block, nil is the latest block
+*/
+func (rR *RegistryReader) Entry(ctx context.Context, block *big.Int, id uint64) (RegistryEntry, error) {
+	event, eErr := rR.contract.Events(&bind.CallOpts{Context: ctx, BlockNumber: block}, new(big.Int).SetUint64(id))
+	if eErr != nil {
+		return RegistryEntry{}, fmt.Errorf("reading the registry entry %d: %w", id, rR.callError(ctx, eErr))
+	}
+
+	return RegistryEntry{
+		ID:              id,
+		AvsName:         event.AvsName,
+		OperatorName:    event.OperatorName,
+		AvsAddress:      event.AvsAddress.Hex(),
+		OperatorAddress: event.OperatorAddress.Hex(),
+		Timestamp:       time.Unix(event.Timestamp.Int64(), 0).UTC(),
+	}, nil
+}
+
+/*
+Entries reads the entries from the id up to, not including, the id to at the block, fn is called for each of them in
+order and stops the read with its error
+*/
+func (rR *RegistryReader) Entries(ctx context.Context, block *big.Int, from uint64, to uint64,
+	fn func(entry RegistryEntry) error) error {
+	for id := from; id < to; id++ {
+		entry, eErr := rR.Entry(ctx, block, id)
+		if eErr != nil {
+			return eErr
+		}
+
+		if fErr := fn(entry); fErr != nil {
+			return fErr
+		}
+	}
+
+	return nil
+}
+
+/*
+callError explains the failed calls to an address without code, which the bindings report as an unmarshal error
+*/
+func (rR *RegistryReader) callError(ctx context.Context, err error) error {
+	if !errors.Is(err, bind.ErrNoCode) {
+		code, cErr := rR.client.CodeAt(ctx, rR.address, nil)
+		if cErr != nil || len(code) > 0 {
+			return err
+		}
+	}
+
+	return fmt.Errorf("no registry contract at %s on this chain", rR.address.Hex())
+}
+
+/*
+Close closes the connection when the reader opened it
+*/
+func (rR *RegistryReader) Close() {
+	if rR != nil && rR.closer != nil {
+		rR.closer()
+	}
+}
@@ -0,0 +1,327 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"eigenlayer_hack/config"
+	registery "eigenlayer_hack/eigenlayerContract"
+	"eigenlayer_hack/ethereum/node"
+	"eigenlayer_hack/structs"
+	"eigenlayer_hack/txmanager"
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	"math/big"
+	"solity/utils/logger"
+	"time"
+)
+
+var (
+	// ErrAlreadyRegistered is returned when the registry rejects the write because the pair is registered
+	ErrAlreadyRegistered = errors.New("operator - AVS pair is already registered")
+	// ErrNotWriter is returned when the signer is not on the writer allowlist of the registry
+	ErrNotWriter = errors.New("signer is not a writer of the registry")
+)
+
+/*
+RegistryWriter writes the registrations to the registry contract through a long-lived connection and transaction
+manager, so that concurrent writes get distinct nonces and every write is followed until it is confirmed. With
+batching enabled the writes are collected and sent together through registerEvents
+*/
+type RegistryWriter struct {
+	client   *ethclient.Client
+	manager  *txmanager.Manager
+	address  common.Address
+	abi      *abi.ABI
+	gasLimit uint64
+	batcher  *registryBatcher
+	// signer is closed by Close, only set when the writer created it
+	signer node.Signer
+}
+
+/*
+WriteResult is the final state of a write. Batched writes share the transaction of their batch, BatchIndex is the
+position of the write in the registerEvents call. EventID is the id of the registry entry from its EventRegistered
+log, nil until the write is confirmed
+*/
+type WriteResult struct {
+	txmanager.Result
+	BatchIndex int
+	BatchSize  int
+	EventID    *big.Int
+}
+
+/*
+NewRegistryWriterFromConfig creates the RegistryWriter of the registry settings, signing with the signer settings (see
+NewSignerFromConfig) and sending through a transaction manager with the transaction and fee settings
+*/
+func NewRegistryWriterFromConfig(cfg config.Config) (*RegistryWriter, error) {
+	registryABI, aErr := registery.RegisteryMetaData.GetAbi()
+	if aErr != nil {
+		return nil, aErr
+	}
+
+	signer, sErr := NewSignerFromConfig(cfg.Signer, cfg.Registry.PrivateKey)
+	if sErr != nil {
+		return nil, sErr
+	}
+
+	client, dErr := DialChain(cfg.Registry.RPC, cfg.Registry.ChainID)
+	if dErr != nil {
+		CloseSigner(signer)
+		return nil, dErr
+	}
+
+	chainID, cErr := client.ChainID(context.Background())
+	if cErr != nil {
+		CloseSigner(signer)
+		client.Close()
+		return nil, cErr
+	}
+
+	manager := txmanager.NewManager(client, txmanager.Config{
+		Signer:           signer,
+		ChainID:          chainID,
+		Confirmations:    cfg.Tx.Confirmations,
+		PollInterval:     cfg.Tx.PollInterval,
+		StuckAfter:       cfg.Tx.StuckAfter,
+		FeeBumpPercent:   int64(cfg.Tx.FeeBumpPercent),
+		MaxRebroadcasts:  cfg.Tx.MaxRebroadcasts,
+		Fees:             FeeOracleConfig(cfg.Tx),
+		GasMarginPercent: cfg.Tx.GasMarginPercent,
+	})
+
+	registryAddress := common.HexToAddress(cfg.Registry.Address)
+
+	// Not fatal, the allowlist can be updated while the tracker runs
+	if caller, bErr := registery.NewRegisteryCaller(registryAddress, client); bErr == nil {
+		isWriter, wErr := caller.Writers(&bind.CallOpts{Context: context.Background()}, signer.Address())
+		if wErr != nil {
+			logger.LogW("Error while checking the registry writer allowlist: ", wErr)
+		} else if !isWriter {
+			logger.LogW(signer.Address().Hex(), " is not a writer of the registry ", registryAddress.Hex(),
+				", the owner has to add it with addWriter")
+		}
+	}
+
+	writer := NewRegistryWriter(client, manager, registryAddress, registryABI, cfg.Registry.GasLimit)
+	writer.signer = signer
+	writer.EnableBatching(cfg.Registry.BatchSize, cfg.Registry.BatchWait, cfg.Limits.ShutdownTimeout)
+
+	return writer, nil
+}
+
+/*
+FeeOracleConfig returns the fee settings of the fee oracle from the transaction settings
+*/
+func FeeOracleConfig(tx config.TxConfig) node.FeeOracleConfig {
+	return node.FeeOracleConfig{
+		HistoryBlocks:     tx.FeeHistoryBlocks,
+		RewardPercentile:  tx.FeePercentile,
+		BaseFeeMultiplier: tx.BaseFeeMultiplier,
+		MinTip:            parseWei(tx.MinTip),
+		MaxTip:            parseWei(tx.MaxTip),
+		MaxFeeCap:         parseWei(tx.MaxGasPrice),
+		Legacy:            tx.Legacy,
+	}
+}
+
+/*
+parseWei parses an amount in wei, empty and invalid amounts are nil
+*/
+func parseWei(raw string) *big.Int {
+	if raw == "" {
+		return nil
+	}
+
+	amount, isOk := new(big.Int).SetString(raw, 10)
+	if !isOk {
+		return nil
+	}
+
+	return amount
+}
+
+/*
+NewRegistryWriter constructor for the RegistryWriter object. client is closed by Close, it can be nil
+*/
+func NewRegistryWriter(client *ethclient.Client, manager *txmanager.Manager, address common.Address, registryABI *abi.ABI,
+	gasLimit uint64) *RegistryWriter {
+	return &RegistryWriter{client: client, manager: manager, address: address, abi: registryABI, gasLimit: gasLimit}
+}
+
+/*
+EnableBatching collects up to maxSize writes, for at most maxWait, into a single transaction. A maxSize below two
+keeps one transaction per write. Close waits up to shutdownTimeout for the batches in flight
+*/
+func (w *RegistryWriter) EnableBatching(maxSize int, maxWait time.Duration, shutdownTimeout time.Duration) {
+	if maxSize < 2 || w.batcher != nil {
+		return
+	}
+
+	w.batcher = newRegistryBatcher(w.send, maxSize, maxWait, shutdownTimeout)
+}
+
+/*
+Write registers the operator - AVS pair and waits until the transaction is confirmed. The result carries the final
+status of the transaction, the error is nil only if it is confirmed. Pairs the registry already holds fail with
+ErrAlreadyRegistered
+*/
+func (w *RegistryWriter) Write(ctx context.Context, payload structs.EigenlayerPayload) (WriteResult, error) {
+	if w == nil {
+		return WriteResult{}, errors.New("registry writer is not configured")
+	}
+
+	var result WriteResult
+	var wErr error
+
+	if w.batcher != nil {
+		result, wErr = w.batcher.submit(ctx, payload)
+	} else {
+		var sent txmanager.Result
+		sent, wErr = w.send(ctx, []structs.EigenlayerPayload{payload})
+		result = WriteResult{Result: sent, BatchSize: 1}
+	}
+
+	if wErr != nil {
+		return result, w.registryError(wErr)
+	}
+
+	result.EventID = w.eventID(result.Receipt, payload)
+
+	return result, nil
+}
+
+/*
+eventID returns the id of the registry entry of the payload from the EventRegistered logs of the receipt, nil if the
+receipt has no log of it
+*/
+func (w *RegistryWriter) eventID(receipt *types.Receipt, payload structs.EigenlayerPayload) *big.Int {
+	if receipt == nil {
+		return nil
+	}
+
+	registered := w.abi.Events["EventRegistered"]
+
+	for _, log := range receipt.Logs {
+		if log.Address != w.address || len(log.Topics) != 3 || log.Topics[0] != registered.ID {
+			continue
+		}
+
+		if common.BytesToAddress(log.Topics[1].Bytes()) != payload.AvsAddress ||
+			common.BytesToAddress(log.Topics[2].Bytes()) != payload.OperatorAddress {
+			continue
+		}
+
+		values, uErr := registered.Inputs.NonIndexed().Unpack(log.Data)
+		if uErr != nil || len(values) == 0 {
+			return nil
+		}
+
+		id, _ := values[0].(*big.Int)
+
+		return id
+	}
+
+	return nil
+}
+
+/*
+registryError maps the custom errors of the registry, returned by the node when the gas estimation reverts, to
+ErrAlreadyRegistered and ErrNotWriter
+*/
+func (w *RegistryWriter) registryError(err error) error {
+	var dataErr rpc.DataError
+	if !errors.As(err, &dataErr) {
+		return err
+	}
+
+	encoded, isString := dataErr.ErrorData().(string)
+	if !isString {
+		return err
+	}
+
+	data, dErr := hexutil.Decode(encoded)
+	if dErr != nil || len(data) < 4 {
+		return err
+	}
+
+	for name, registryErr := range w.abi.Errors {
+		if !bytes.Equal(registryErr.ID[:4], data[:4]) {
+			continue
+		}
+
+		switch name {
+		case "DuplicateEvent":
+			return fmt.Errorf("%w: %v", ErrAlreadyRegistered, err)
+		case "NotWriter":
+			return fmt.Errorf("%w: %v", ErrNotWriter, err)
+		}
+
+		return fmt.Errorf("registry rejected the write with %s: %w", name, err)
+	}
+
+	return err
+}
+
+/*
+send registers the payloads in a single transaction, through registerEvent for a single payload and registerEvents
+for more
+*/
+func (w *RegistryWriter) send(ctx context.Context, payloads []structs.EigenlayerPayload) (txmanager.Result, error) {
+	var data []byte
+	var pErr error
+
+	if len(payloads) == 1 {
+		payload := payloads[0]
+		data, pErr = w.abi.Pack("registerEvent", payload.AvsName, payload.OperatorName, payload.AvsAddress,
+			payload.OperatorAddress)
+	} else {
+		batch := make([]registery.RegisteryEventInput, len(payloads))
+
+		for i, payload := range payloads {
+			batch[i] = registery.RegisteryEventInput{
+				AvsName:         payload.AvsName,
+				OperatorName:    payload.OperatorName,
+				AvsAddress:      payload.AvsAddress,
+				OperatorAddress: payload.OperatorAddress,
+			}
+		}
+
+		data, pErr = w.abi.Pack("registerEvents", batch)
+	}
+
+	if pErr != nil {
+		return txmanager.Result{Status: txmanager.StatusFailed}, pErr
+	}
+
+	return w.manager.Send(ctx, txmanager.Request{To: w.address, Data: data, GasLimit: w.gasLimit * uint64(len(payloads))})
+}
+
+/*
+Close sends the collected writes, waits for the batches in flight (up to the shutdown timeout of the batching) and
+closes the connections to the node and the signer
+*/
+func (w *RegistryWriter) Close() {
+	if w == nil {
+		return
+	}
+
+	if w.batcher != nil {
+		w.batcher.close()
+	}
+
+	if w.client != nil {
+		w.client.Close()
+	}
+
+	if w.signer != nil {
+		CloseSigner(w.signer)
+	}
+}
@@ -0,0 +1,48 @@
+package utils
+
+import (
+	"context"
+	"eigenlayer_hack/config"
+	"eigenlayer_hack/ethereum/node"
+	"errors"
+	"github.com/ethereum/go-ethereum/common"
+	"solity/utils/logger"
+	"time"
+)
+
+// Signer types of SIGNER_TYPE
+const (
+	SignerKeystore = "keystore"
+	SignerRemote   = "remote"
+	SignerKey      = "key"
+)
+
+/*
+NewSignerFromConfig creates the signer of the transactions: an encrypted keystore file (with its passphrase file), a
+Clef compatible remote signer (the address selects the account) or, for development, the raw private key
+*/
+func NewSignerFromConfig(signer config.SignerConfig, privateKey string) (node.Signer, error) {
+	switch signerType := signer.Kind(); signerType {
+	case SignerKeystore:
+		return node.NewKeystoreSigner(signer.KeystoreFile, signer.PassphraseFile)
+	case SignerRemote:
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		return node.NewRemoteSigner(ctx, signer.RemoteURL, common.HexToAddress(signer.Address))
+	case SignerKey:
+		logger.LogW("Signing with the plain text PRV_KEY, use a keystore or a remote signer outside of development")
+		return node.NewKeySigner(privateKey)
+	default:
+		return nil, errors.New("unknown signer type " + signerType)
+	}
+}
+
+/*
+CloseSigner closes the connection of remote signers
+*/
+func CloseSigner(signer node.Signer) {
+	if closer, isCloser := signer.(interface{ Close() }); isCloser {
+		closer.Close()
+	}
+}